@@ -43,4 +43,37 @@ type Accumulator interface {
 	SetPrecision(precision, interval time.Duration)
 
 	AddError(err error)
+
+	// WithTracking returns an Accumulator that reports when the metrics
+	// added as a tracked group have been delivered, with at most maxTracked
+	// groups undelivered at any time.
+	WithTracking(maxTracked int) TrackingAccumulator
+}
+
+// TrackingID identifies a group of tracked metrics.
+type TrackingID uint64
+
+// DeliveryInfo is the outcome of the delivery of a group of tracked metrics.
+type DeliveryInfo interface {
+	// ID is the id of the group, as returned by AddTrackingMetricGroup.
+	ID() TrackingID
+	// Delivered returns true if all the metrics of the group were written
+	// or intentionally dropped, and false if any was rejected by an output.
+	Delivered() bool
+}
+
+// TrackingAccumulator is an Accumulator that tracks the delivery of groups of
+// metrics to the outputs, so that an input can acknowledge the messages it
+// reads from a queue only once their metrics are safe.
+type TrackingAccumulator interface {
+	Accumulator
+
+	// AddTrackingMetricGroup adds a group of metrics, and returns the id
+	// their DeliveryInfo will have. The caller must not add more groups than
+	// the maxTracked it was created with before their delivery is received.
+	AddTrackingMetricGroup(group []Metric) TrackingID
+
+	// Delivered returns the channel on which the DeliveryInfo of each group
+	// is sent once all its metrics are written, rejected or dropped.
+	Delivered() <-chan DeliveryInfo
 }
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/selfstat"
 )
 
@@ -105,10 +106,19 @@ func (ac *accumulator) AddError(err error) {
 		return
 	}
 	NErrors.Incr(1)
+	if r, ok := ac.maker.(errorRecorder); ok {
+		r.RecordError(err)
+	}
 	//TODO suppress/throttle consecutive duplicate errors?
 	log.Printf("E! Error in plugin [%s]: %s", ac.maker.Name(), err)
 }
 
+// errorRecorder is implemented by the plugins whose errors are reported by
+// the health API.
+type errorRecorder interface {
+	RecordError(err error)
+}
+
 // SetPrecision takes two time.Duration objects. If the first is non-zero,
 // it sets that as the precision. Otherwise, it takes the second argument
 // as the order of time that the metrics should be rounded to, with the
@@ -130,6 +140,48 @@ func (ac *accumulator) SetPrecision(precision, interval time.Duration) {
 	}
 }
 
+// WithTracking returns an accumulator that tracks the delivery of groups of
+// metrics, see telegraf.TrackingAccumulator.
+func (ac *accumulator) WithTracking(maxTracked int) telegraf.TrackingAccumulator {
+	return &trackingAccumulator{
+		accumulator: ac,
+		delivered:   make(chan telegraf.DeliveryInfo, maxTracked),
+	}
+}
+
+type trackingAccumulator struct {
+	*accumulator
+	delivered chan telegraf.DeliveryInfo
+}
+
+// AddTrackingMetricGroup adds the metrics of the group that pass the filters
+// of the plugin, tracked so that their delivery is sent on Delivered.
+func (a *trackingAccumulator) AddTrackingMetricGroup(group []telegraf.Metric) telegraf.TrackingID {
+	made := make([]telegraf.Metric, 0, len(group))
+	for _, m := range group {
+		tm := []time.Time{m.Time()}
+		if m := a.maker.MakeMetric(m.Name(), m.Fields(), m.Tags(), m.Type(), a.getTime(tm)); m != nil {
+			made = append(made, m)
+		}
+	}
+
+	tracked, id := metric.WithGroupTracking(made, a.onDelivery)
+	for _, m := range tracked {
+		a.metrics <- m
+	}
+	return id
+}
+
+func (a *trackingAccumulator) Delivered() <-chan telegraf.DeliveryInfo {
+	return a.delivered
+}
+
+// onDelivery does not block as long as the plugin keeps at most maxTracked
+// groups undelivered.
+func (a *trackingAccumulator) onDelivery(info telegraf.DeliveryInfo) {
+	a.delivered <- info
+}
+
 func (ac accumulator) getTime(t []time.Time) time.Time {
 	var timestamp time.Time
 	if len(t) > 0 {
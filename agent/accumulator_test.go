@@ -309,6 +309,41 @@ func TestAddCounter(t *testing.T) {
 	assert.Equal(t, testm.Type(), telegraf.Counter)
 }
 
+func TestAddTrackingMetricGroup(t *testing.T) {
+	metrics := make(chan telegraf.Metric, 10)
+	defer close(metrics)
+	a := NewAccumulator(&TestMetricMaker{}, metrics).WithTracking(1)
+
+	now := time.Now()
+	m1, err := metric.New("acctest", map[string]string{},
+		map[string]interface{}{"value": float64(101)}, now, telegraf.Counter)
+	require.NoError(t, err)
+	m2, err := metric.New("acctest", map[string]string{},
+		map[string]interface{}{"value": float64(102)}, now, telegraf.Gauge)
+	require.NoError(t, err)
+
+	id := a.AddTrackingMetricGroup([]telegraf.Metric{m1, m2})
+	require.Len(t, metrics, 2)
+	out1 := <-metrics
+	out2 := <-metrics
+	assert.Equal(t, telegraf.Counter, out1.Type())
+	assert.Equal(t, telegraf.Gauge, out2.Type())
+
+	out1.Accept()
+	require.Len(t, a.Delivered(), 0)
+	out2.Reject()
+	require.Len(t, a.Delivered(), 1)
+	info := <-a.Delivered()
+	assert.Equal(t, id, info.ID())
+	assert.False(t, info.Delivered())
+
+	// a group with no metrics is delivered at once
+	id = a.AddTrackingMetricGroup(nil)
+	info = <-a.Delivered()
+	assert.Equal(t, id, info.ID())
+	assert.True(t, info.Delivered())
+}
+
 type TestMetricMaker struct {
 }
 
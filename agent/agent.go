@@ -1,10 +1,13 @@
 package agent
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"runtime"
+	"strings"
 	"sync"
 	"time"
 
@@ -18,6 +21,50 @@ import (
 // Agent runs telegraf and collects data based on the given config
 type Agent struct {
 	Config *config.Config
+
+	// mu protects Config, which is replaced when the configuration is
+	// reloaded while the agent runs.
+	mu sync.RWMutex
+
+	// runMu serializes starting and stopping plugins between Run and
+	// Reload. It must not be held when taking mu for writing while plugins
+	// are being stopped, as they may be waiting on the flusher.
+	runMu       sync.Mutex
+	running     bool
+	metricC     chan telegraf.Metric
+	aggC        chan telegraf.Metric
+	rebuildC    chan struct{}
+	fatalC      chan error
+	inputs      map[*models.RunningInput]*runner
+	aggregators map[*models.RunningAggregator]*runner
+	writers     map[*models.RunningOutput]*runner
+
+	healthServer *http.Server
+}
+
+// runner runs a single plugin in its own goroutine, so that it can be
+// stopped independently of the others.
+type runner struct {
+	stop chan struct{}
+	done chan struct{}
+}
+
+func startRunner(run func(stop chan struct{})) *runner {
+	r := &runner{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go func() {
+		defer close(r.done)
+		run(r.stop)
+	}()
+	return r
+}
+
+// Stop stops the plugin and waits for its goroutine to return.
+func (r *runner) Stop() {
+	close(r.stop)
+	<-r.done
 }
 
 // NewAgent returns an Agent struct based off the given Config
@@ -26,46 +73,74 @@ func NewAgent(config *config.Config) (*Agent, error) {
 		Config: config,
 	}
 
-	if !a.Config.Agent.OmitHostname {
-		if a.Config.Agent.Hostname == "" {
+	if err := setHostname(config); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// setHostname sets the host tag of the config, unless it is disabled.
+func setHostname(c *config.Config) error {
+	if !c.Agent.OmitHostname {
+		if c.Agent.Hostname == "" {
 			hostname, err := os.Hostname()
 			if err != nil {
-				return nil, err
+				return err
 			}
 
-			a.Config.Agent.Hostname = hostname
+			c.Agent.Hostname = hostname
 		}
 
-		config.Tags["host"] = a.Config.Agent.Hostname
+		c.Tags["host"] = c.Agent.Hostname
 	}
+	return nil
+}
 
-	return a, nil
+// agentConfig returns a copy of the current agent settings.
+func (a *Agent) agentConfig() config.AgentConfig {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return *a.Config.Agent
 }
 
 // Connect connects to all configured outputs
 func (a *Agent) Connect() error {
 	for _, o := range a.Config.Outputs {
-		switch ot := o.Output.(type) {
-		case telegraf.ServiceOutput:
-			if err := ot.Start(); err != nil {
-				log.Printf("E! Service for output %s failed to start, exiting\n%s\n",
-					o.Name, err.Error())
-				return err
-			}
+		if err := connectOutput(o); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		log.Printf("D! Attempting connection to output: %s\n", o.Name)
-		err := o.Output.Connect()
-		if err != nil {
-			log.Printf("E! Failed to connect to output %s, retrying in 15s, "+
-				"error was '%s' \n", o.Name, err)
-			time.Sleep(15 * time.Second)
-			err = o.Output.Connect()
-			if err != nil {
-				return err
-			}
+func connectOutput(o *models.RunningOutput) error {
+	if err := o.OpenBuffer(); err != nil {
+		log.Printf("E! Failed to open buffer for output %s, exiting\n%s\n",
+			o.Name, err.Error())
+		return err
+	}
+
+	switch ot := o.Output.(type) {
+	case telegraf.ServiceOutput:
+		if err := ot.Start(); err != nil {
+			log.Printf("E! Service for output %s failed to start, exiting\n%s\n",
+				o.Name, err.Error())
+			return err
 		}
-		log.Printf("D! Successfully connected to output: %s\n", o.Name)
+	}
+
+	if err := o.Connect(); err != nil {
+		// the output's writer keeps trying to connect, following the
+		// output's retry policy.
+		log.Printf("E! Failed to connect to output %s, will retry, "+
+			"error was '%s' \n", o.Name, err)
+		if err, ok := o.RetryFailed(err).(*models.GiveUpError); ok {
+			return err
+		}
+	}
+	if n := o.BufferLen(); n > 0 {
+		log.Printf("I! Output [%s] replaying %d buffered metrics\n", o.Name, n)
 	}
 	return nil
 }
@@ -74,11 +149,16 @@ func (a *Agent) Connect() error {
 func (a *Agent) Close() error {
 	var err error
 	for _, o := range a.Config.Outputs {
-		err = o.Output.Close()
-		switch ot := o.Output.(type) {
-		case telegraf.ServiceOutput:
-			ot.Stop()
-		}
+		err = closeOutput(o)
+	}
+	return err
+}
+
+func closeOutput(o *models.RunningOutput) error {
+	err := o.Close()
+	switch ot := o.Output.(type) {
+	case telegraf.ServiceOutput:
+		ot.Stop()
 	}
 	return err
 }
@@ -110,18 +190,32 @@ func (a *Agent) gatherer(
 		map[string]string{"input": input.Config.Name},
 	)
 
+	agentConf := a.agentConfig()
 	acc := NewAccumulator(input, metricC)
-	acc.SetPrecision(a.Config.Agent.Precision.Duration,
-		a.Config.Agent.Interval.Duration)
+	acc.SetPrecision(agentConf.Precision.Duration,
+		agentConf.Interval.Duration)
+
+	// Round collection to nearest interval by sleeping
+	if agentConf.RoundInterval {
+		i := int64(agentConf.Interval.Duration)
+		select {
+		case <-shutdown:
+			return
+		case <-time.After(time.Duration(i - (time.Now().UnixNano() % i))):
+		}
+	}
 
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
+	// pending is where an abandoned gather returns
+	var pending chan error
 	for {
-		internal.RandomSleep(a.Config.Agent.CollectionJitter.Duration, shutdown)
+		internal.RandomSleep(agentConf.CollectionJitter.Duration, shutdown)
 
 		start := time.Now()
-		gatherWithTimeout(shutdown, input, acc, interval)
+		pending = gatherWithTimeout(shutdown, input, acc, interval, pending)
+		input.RecordGather(start)
 		elapsed := time.Since(start)
 
 		GatherTime.Incr(elapsed.Nanoseconds())
@@ -140,37 +234,88 @@ func (a *Agent) gatherer(
 //   but continues waiting for it to return. This is to avoid leaving behind
 //   hung processes, and to prevent re-calling the same hung process over and
 //   over.
+//
+//   An input with a gather_timeout is abandoned instead when it is reached:
+//   the context of the gather is cancelled if it is a ContextInput, the
+//   timeout is counted, and the channel on which the gather will return is
+//   returned. It must be passed back as pending, the input is not gathered
+//   again until the abandoned gather has returned.
 func gatherWithTimeout(
 	shutdown chan struct{},
 	input *models.RunningInput,
 	acc *accumulator,
 	timeout time.Duration,
-) {
-	ticker := time.NewTicker(timeout)
-	defer ticker.Stop()
-	done := make(chan error)
+	pending chan error,
+) chan error {
+	if pending != nil {
+		select {
+		case err := <-pending:
+			if err != nil {
+				acc.AddError(err)
+			}
+		default:
+			acc.AddError(fmt.Errorf("skipping collection, the previous " +
+				"collection has not returned since it was abandoned"))
+			return pending
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
 	go func() {
-		done <- input.Input.Gather(acc)
+		done <- gather(ctx, input.Input, acc)
 	}()
 
+	if input.Config.Timeout > 0 {
+		timeout = input.Config.Timeout
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+
+		select {
+		case err := <-done:
+			if err != nil {
+				acc.AddError(err)
+			}
+			return nil
+		case <-timer.C:
+			input.GatherTimeouts.Incr(1)
+			acc.AddError(fmt.Errorf("took longer to collect than timeout (%s), "+
+				"abandoning the collection", timeout))
+			return done
+		case <-shutdown:
+			return nil
+		}
+	}
+
+	ticker := time.NewTicker(timeout)
+	defer ticker.Stop()
 	for {
 		select {
 		case err := <-done:
 			if err != nil {
 				acc.AddError(err)
 			}
-			return
+			return nil
 		case <-ticker.C:
 			err := fmt.Errorf("took longer to collect than collection interval (%s)",
 				timeout)
 			acc.AddError(err)
 			continue
 		case <-shutdown:
-			return
+			return nil
 		}
 	}
 }
 
+// gather gathers from the input, with the context if it is a ContextInput.
+func gather(ctx context.Context, input telegraf.Input, acc telegraf.Accumulator) error {
+	if ci, ok := input.(telegraf.ContextInput); ok {
+		return ci.GatherContext(ctx, acc)
+	}
+	return input.Gather(acc)
+}
+
 // Test verifies that we can 'Gather' from all inputs with their configured
 // Config struct
 func (a *Agent) Test() error {
@@ -227,203 +372,541 @@ func (a *Agent) Test() error {
 	return nil
 }
 
-// flush writes a list of metrics to all configured outputs
-func (a *Agent) flush() {
-	var wg sync.WaitGroup
+// onceMaxAttempts is the number of failed writes after which Once gives up
+// on an output whose retry policy never gives up.
+const onceMaxAttempts = 3
+
+// Once runs the whole agent a single time: the service inputs are started,
+// every input is gathered once, the metrics go through the processors and
+// aggregators, whose current period is pushed, and are written to the
+// outputs, retrying failed writes. It returns an error if any output could
+// not write all its metrics. The outputs must have been connected, they are
+// closed when it returns.
+func (a *Agent) Once(shutdown chan struct{}) error {
+	defer a.Close()
+	agentConf := *a.Config.Agent
+	size := channelSize(agentConf)
+	metricC := make(chan telegraf.Metric, size)
+	aggC := make(chan telegraf.Metric, size)
+
+	runFlusher := func(run func()) {
+		flusherShutdown := make(chan struct{})
+		flusherDone := make(chan struct{})
+		go func() {
+			defer close(flusherDone)
+			a.flusher(flusherShutdown, metricC, aggC)
+		}()
+		run()
+		close(flusherShutdown)
+		<-flusherDone
+	}
+
+	now := time.Now()
+	aggregators := make(map[*models.RunningAggregator]*runner)
+	for _, agg := range a.Config.Aggregators {
+		agg := agg
+		aggregators[agg] = startRunner(func(stop chan struct{}) {
+			acc := NewAccumulator(agg, aggC)
+			acc.SetPrecision(agentConf.Precision.Duration,
+				agentConf.Interval.Duration)
+			agg.Run(acc, now, stop)
+		})
+	}
+
+	var err error
+	runFlusher(func() {
+		var services []telegraf.ServiceInput
+		defer func() {
+			for _, service := range services {
+				service.Stop()
+			}
+		}()
+		for _, input := range a.Config.Inputs {
+			input.SetDefaultTags(a.Config.Tags)
+			if service, ok := input.Input.(telegraf.ServiceInput); ok {
+				acc := NewAccumulator(input, metricC)
+				acc.SetPrecision(time.Nanosecond, 0)
+				if err = service.Start(acc); err != nil {
+					log.Printf("E! Service for input %s failed to start, "+
+						"exiting\n%s\n", input.Name(), err.Error())
+					return
+				}
+				services = append(services, service)
+			}
+		}
+
+		var wg sync.WaitGroup
+		for _, input := range a.Config.Inputs {
+			interval := agentConf.Interval.Duration
+			if input.Config.Interval != 0 {
+				interval = input.Config.Interval
+			}
+			acc := NewAccumulator(input, metricC)
+			acc.SetPrecision(agentConf.Precision.Duration,
+				agentConf.Interval.Duration)
+
+			wg.Add(1)
+			go func(input *models.RunningInput) {
+				defer wg.Done()
+				defer panicRecover(input)
+				start := time.Now()
+				// an abandoned gather is not waited for
+				gatherWithTimeout(shutdown, input, acc, interval, nil)
+				input.RecordGather(start)
+			}(input)
+		}
+		wg.Wait()
+	})
+	if err != nil {
+		for _, r := range aggregators {
+			r.Stop()
+		}
+		return err
+	}
 
-	wg.Add(len(a.Config.Outputs))
+	// the aggregators have received all the metrics of the inputs, the
+	// period they are in is pushed without waiting for its end.
+	runFlusher(func() {
+		for agg, r := range aggregators {
+			r.Stop()
+			acc := NewAccumulator(agg, aggC)
+			acc.SetPrecision(agentConf.Precision.Duration,
+				agentConf.Interval.Duration)
+			agg.Push(acc)
+		}
+	})
+
+	var failed []string
 	for _, o := range a.Config.Outputs {
-		go func(output *models.RunningOutput) {
-			defer wg.Done()
-			err := output.Write()
-			if err != nil {
-				log.Printf("E! Error writing to output [%s]: %s\n",
-					output.Name, err.Error())
+		if err := o.WriteAll(shutdown, onceMaxAttempts); err != nil {
+			log.Printf("E! Error writing to output [%s]: %s\n", o.Name, err)
+			failed = append(failed, o.Name)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to write to outputs: %s",
+			strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// writer writes the metrics buffered by an output on the output's flush
+// interval, or as soon as a full batch is ready. Each output has its own
+// writer, so that a slow or failing output does not delay the others.
+// If the output's retry policy requires telegraf to exit, the error is sent
+// on fatalC and the writer stops.
+func (a *Agent) writer(
+	shutdown chan struct{},
+	output *models.RunningOutput,
+	fatalC chan error,
+) {
+	agentConf := a.agentConfig()
+	interval := agentConf.FlushInterval.Duration
+	if output.Config.FlushInterval > 0 {
+		interval = output.Config.FlushInterval
+	}
+	jitter := agentConf.FlushJitter.Duration
+	if output.Config.FlushJitter > 0 {
+		jitter = output.Config.FlushJitter
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		var err error
+		select {
+		case <-shutdown:
+			writeOutput(output)
+			return
+		case <-ticker.C:
+			internal.RandomSleep(jitter, shutdown)
+			err = writeOutput(output)
+		case <-output.BatchReady:
+			err = writeOutput(output)
+		}
+		if _, ok := err.(*models.GiveUpError); ok {
+			select {
+			case fatalC <- err:
+			default:
 			}
-		}(o)
+			return
+		}
 	}
+}
 
-	wg.Wait()
+func writeOutput(output *models.RunningOutput) error {
+	err := output.Write()
+	if err != nil {
+		log.Printf("E! Error writing to output [%s]: %s\n",
+			output.Name, err.Error())
+	}
+	return err
 }
 
-// flusher monitors the metrics input channel and flushes on the minimum interval
-func (a *Agent) flusher(shutdown chan struct{}, metricC chan telegraf.Metric, aggC chan telegraf.Metric) error {
+// flusher monitors the metrics input channels and passes metrics through the
+// processors and aggregators to the outputs, until shutdown is closed.
+func (a *Agent) flusher(shutdown chan struct{}, metricC chan telegraf.Metric, aggC chan telegraf.Metric) {
 	// Inelegant, but this sleep is to allow the Gather threads to run, so that
 	// the flusher will flush after metrics are collected.
 	time.Sleep(time.Millisecond * 300)
 
-	// create an output metric channel and a gorouting that continuously passes
-	// each metric onto the output plugins & aggregators.
-	outMetricC := make(chan telegraf.Metric, 100)
+	// create an output metric channel and a goroutine that continuously
+	// passes each metric onto the output plugins & aggregators.
+	outMetricC := make(chan telegraf.Metric, channelSize(a.agentConfig()))
+	outputsDepth := selfstat.Register("agent", "queue_depth",
+		map[string]string{"stage": "outputs"})
 	var wg sync.WaitGroup
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		for {
-			select {
-			case <-shutdown:
-				if len(outMetricC) > 0 {
-					// keep going until outMetricC is flushed
-					continue
-				}
-				return
-			case m := <-outMetricC:
-				// if dropOriginal is set to true, then we will only send this
-				// metric to the aggregators, not the outputs.
-				var dropOriginal bool
-				if !m.IsAggregate() {
-					for _, agg := range a.Config.Aggregators {
-						if ok := agg.Add(m.Copy()); ok {
-							dropOriginal = true
-						}
-					}
-				}
-				if !dropOriginal {
-					for i, o := range a.Config.Outputs {
-						if i == len(a.Config.Outputs)-1 {
-							o.AddMetric(m)
-						} else {
-							o.AddMetric(m.Copy())
-						}
-					}
-				}
-			}
-		}
-	}()
-
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		for {
-			select {
-			case <-shutdown:
-				if len(aggC) > 0 {
-					// keep going until aggC is flushed
-					continue
-				}
-				return
-			case metric := <-aggC:
-				metrics := []telegraf.Metric{metric}
-				for _, processor := range a.Config.Processors {
-					metrics = processor.Apply(metrics...)
-				}
-				for _, m := range metrics {
-					outMetricC <- m
-				}
-			}
+		for m := range outMetricC {
+			outputsDepth.Set(int64(len(outMetricC)))
+			a.output(m)
 		}
 	}()
 
-	ticker := time.NewTicker(a.Config.Agent.FlushInterval.Duration)
-	semaphore := make(chan struct{}, 1)
+	inputsDepth := selfstat.Register("agent", "queue_depth",
+		map[string]string{"stage": "inputs"})
+	p := a.newPipeline(outMetricC)
 	for {
 		select {
 		case <-shutdown:
-			log.Println("I! Hang on, flushing any cached metrics before shutdown")
-			// wait for outMetricC to get flushed before flushing outputs
+			if len(metricC) > 0 || len(aggC) > 0 {
+				// keep going until metricC and aggC are flushed
+				continue
+			}
+			// wait for the pipeline and outMetricC to get flushed before
+			// returning
+			p.Close()
+			close(outMetricC)
 			wg.Wait()
-			a.flush()
-			return nil
-		case <-ticker.C:
-			go func() {
-				select {
-				case semaphore <- struct{}{}:
-					internal.RandomSleep(a.Config.Agent.FlushJitter.Duration, shutdown)
-					a.flush()
-					<-semaphore
-				default:
-					// skipping this flush because one is already happening
-					log.Println("W! Skipping a scheduled flush because there is" +
-						" already a flush ongoing.")
-				}
-			}()
+			return
+		case <-a.rebuildC:
+			// the metrics already in the pipeline go through the previous
+			// processors first, so that the order of each series is kept.
+			p.Close()
+			p = a.newPipeline(outMetricC)
 		case metric := <-metricC:
-			// NOTE potential bottleneck here as we put each metric through the
-			// processors serially.
-			mS := []telegraf.Metric{metric}
-			for _, processor := range a.Config.Processors {
-				mS = processor.Apply(mS...)
-			}
-			for _, m := range mS {
-				outMetricC <- m
+			inputsDepth.Set(int64(len(metricC) + len(aggC)))
+			p.in <- metric
+		case metric := <-aggC:
+			inputsDepth.Set(int64(len(metricC) + len(aggC)))
+			p.in <- metric
+		}
+	}
+}
+
+// newPipeline starts a processor pipeline for the processors and agent
+// settings of the current config, sending the processed metrics on out.
+func (a *Agent) newPipeline(out chan telegraf.Metric) *pipeline {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return newPipeline(a.Config.Processors, a.Config.Agent.ProcessorWorkers,
+		channelSize(*a.Config.Agent), out)
+}
+
+// channelSize returns the size of the channels between the stages of the
+// agent, which defaults to 100.
+func channelSize(c config.AgentConfig) int {
+	if c.MetricChannelSize <= 0 {
+		return 100
+	}
+	return c.MetricChannelSize
+}
+
+// output passes a processed metric to the aggregators and outputs.
+func (a *Agent) output(m telegraf.Metric) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	// if dropOriginal is set to true, then we will only send this metric to
+	// the aggregators, not the outputs.
+	var dropOriginal bool
+	if !m.IsAggregate() {
+		for _, agg := range a.Config.Aggregators {
+			if ok := agg.Add(m.Copy()); ok {
+				dropOriginal = true
 			}
 		}
 	}
+	if dropOriginal || len(a.Config.Outputs) == 0 {
+		m.Drop()
+		return
+	}
+	for i, o := range a.Config.Outputs {
+		if i == len(a.Config.Outputs)-1 {
+			o.AddMetric(m)
+		} else {
+			o.AddMetric(m.Copy())
+		}
+	}
 }
 
-// Run runs the agent daemon, gathering every Interval
-func (a *Agent) Run(shutdown chan struct{}) error {
-	var wg sync.WaitGroup
+// startInput starts the input, and a goroutine gathering it on its interval.
+func (a *Agent) startInput(input *models.RunningInput) error {
+	input.SetDefaultTags(a.Config.Tags)
+
+	service, isService := input.Input.(telegraf.ServiceInput)
+	if isService {
+		acc := NewAccumulator(input, a.metricC)
+		// Service input plugins should set their own precision of their
+		// metrics.
+		acc.SetPrecision(time.Nanosecond, 0)
+		if err := service.Start(acc); err != nil {
+			log.Printf("E! Service for input %s failed to start, exiting\n%s\n",
+				input.Name(), err.Error())
+			return err
+		}
+	}
+
+	interval := a.Config.Agent.Interval.Duration
+	// overwrite global interval if this plugin has it's own.
+	if input.Config.Interval != 0 {
+		interval = input.Config.Interval
+	}
+	input.SetStarted(time.Now())
+	a.inputs[input] = startRunner(func(stop chan struct{}) {
+		a.gatherer(stop, input, interval, a.metricC)
+		if isService {
+			service.Stop()
+		}
+	})
+	return nil
+}
+
+func (a *Agent) stopInput(input *models.RunningInput) {
+	if r, ok := a.inputs[input]; ok {
+		r.Stop()
+		delete(a.inputs, input)
+	}
+}
+
+func (a *Agent) startAggregator(agg *models.RunningAggregator, now time.Time) {
+	agentConf := *a.Config.Agent
+	a.aggregators[agg] = startRunner(func(stop chan struct{}) {
+		acc := NewAccumulator(agg, a.aggC)
+		acc.SetPrecision(agentConf.Precision.Duration,
+			agentConf.Interval.Duration)
+		agg.Run(acc, now, stop)
+	})
+}
+
+func (a *Agent) stopAggregator(agg *models.RunningAggregator) {
+	if r, ok := a.aggregators[agg]; ok {
+		r.Stop()
+		delete(a.aggregators, agg)
+	}
+}
+
+func (a *Agent) startWriter(output *models.RunningOutput) {
+	output.SetStarted(time.Now())
+	a.writers[output] = startRunner(func(stop chan struct{}) {
+		a.writer(stop, output, a.fatalC)
+	})
+}
+
+// stopWriter stops the writer of the output, which writes any metric it
+// still has buffered.
+func (a *Agent) stopWriter(output *models.RunningOutput) {
+	if r, ok := a.writers[output]; ok {
+		r.Stop()
+		delete(a.writers, output)
+	}
+}
 
+// Run runs the agent daemon, gathering every Interval. It returns an error
+// if it had to stop before shutdown was closed.
+func (a *Agent) Run(shutdown chan struct{}) error {
 	log.Printf("I! Agent Config: Interval:%s, Quiet:%#v, Hostname:%#v, "+
 		"Flush Interval:%s \n",
 		a.Config.Agent.Interval.Duration, a.Config.Agent.Quiet,
 		a.Config.Agent.Hostname, a.Config.Agent.FlushInterval.Duration)
 
+	a.runMu.Lock()
 	// channel shared between all input threads for accumulating metrics
-	metricC := make(chan telegraf.Metric, 100)
-	aggC := make(chan telegraf.Metric, 100)
+	size := channelSize(*a.Config.Agent)
+	a.metricC = make(chan telegraf.Metric, size)
+	a.aggC = make(chan telegraf.Metric, size)
+	a.rebuildC = make(chan struct{}, 1)
+	a.fatalC = make(chan error, 1)
+	a.inputs = make(map[*models.RunningInput]*runner)
+	a.aggregators = make(map[*models.RunningAggregator]*runner)
+	a.writers = make(map[*models.RunningOutput]*runner)
+
+	flusherShutdown := make(chan struct{})
+	flusherDone := make(chan struct{})
+	go func() {
+		defer close(flusherDone)
+		a.flusher(flusherShutdown, a.metricC, a.aggC)
+	}()
 
+	for _, o := range a.Config.Outputs {
+		a.startWriter(o)
+	}
 	now := time.Now()
-
-	// Start all ServicePlugins
+	for _, agg := range a.Config.Aggregators {
+		a.startAggregator(agg, now)
+	}
+	var err error
 	for _, input := range a.Config.Inputs {
-		input.SetDefaultTags(a.Config.Tags)
-		switch p := input.Input.(type) {
-		case telegraf.ServiceInput:
-			acc := NewAccumulator(input, metricC)
-			// Service input plugins should set their own precision of their
-			// metrics.
-			acc.SetPrecision(time.Nanosecond, 0)
-			if err := p.Start(acc); err != nil {
-				log.Printf("E! Service for input %s failed to start, exiting\n%s\n",
-					input.Name(), err.Error())
-				return err
+		if err = a.startInput(input); err != nil {
+			break
+		}
+	}
+	if address := a.Config.Agent.HealthServiceAddress; err == nil && address != "" {
+		err = a.startHealthServer(address)
+	}
+	a.running = err == nil
+	a.runMu.Unlock()
+
+	if err == nil {
+		select {
+		case <-shutdown:
+		case err = <-a.fatalC:
+			log.Printf("E! Flusher routine failed, exiting: %s\n", err.Error())
+		}
+	}
+
+	a.runMu.Lock()
+	defer a.runMu.Unlock()
+	a.running = false
+	a.stopHealthServer()
+	// inputs and aggregators are stopped first, so that everything they
+	// produced is flushed to the outputs before those are written a last
+	// time.
+	for input := range a.inputs {
+		a.stopInput(input)
+	}
+	for agg := range a.aggregators {
+		a.stopAggregator(agg)
+	}
+	log.Println("I! Hang on, flushing any cached metrics before shutdown")
+	close(flusherShutdown)
+	<-flusherDone
+	for output := range a.writers {
+		a.stopWriter(output)
+	}
+	a.Close()
+	return err
+}
+
+// Reload replaces the configuration of the running agent by c. Only the
+// plugins whose configuration changed are stopped or started; the others
+// keep running with their buffered metrics and aggregation windows.
+func (a *Agent) Reload(c *config.Config) error {
+	a.runMu.Lock()
+	defer a.runMu.Unlock()
+	if !a.running {
+		return fmt.Errorf("agent is not running")
+	}
+
+	if err := setHostname(c); err != nil {
+		return err
+	}
+
+	prevHealthAddress := a.Config.Agent.HealthServiceAddress
+	diff := a.Config.Merge(c)
+	if diff.IsEmpty() {
+		log.Printf("I! Config unchanged, nothing to reload\n")
+		a.mu.Lock()
+		a.Config = c
+		a.mu.Unlock()
+		return nil
+	}
+
+	// new outputs are connected before any other change, so that the
+	// running config is left untouched if they fail.
+	for i, o := range diff.AddedOutputs {
+		if err := connectOutput(o); err != nil {
+			for _, added := range diff.AddedOutputs[:i] {
+				closeOutput(added)
 			}
-			defer p.Stop()
+			return err
 		}
 	}
 
-	// Round collection to nearest interval by sleeping
-	if a.Config.Agent.RoundInterval {
-		i := int64(a.Config.Agent.Interval.Duration)
-		time.Sleep(time.Duration(i - (time.Now().UnixNano() % i)))
+	for _, input := range diff.RemovedInputs {
+		a.stopInput(input)
+	}
+	// unchanged inputs and outputs are restarted if the agent settings
+	// changed, as they read their intervals when they start.
+	var restartInputs []*models.RunningInput
+	var restartWriters []*models.RunningOutput
+	if diff.AgentChanged {
+		for input := range a.inputs {
+			a.stopInput(input)
+			restartInputs = append(restartInputs, input)
+		}
+		for output := range a.writers {
+			if !containsOutput(diff.RemovedOutputs, output) {
+				a.stopWriter(output)
+				restartWriters = append(restartWriters, output)
+			}
+		}
 	}
 
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		if err := a.flusher(shutdown, metricC, aggC); err != nil {
-			log.Printf("E! Flusher routine failed, exiting: %s\n", err.Error())
-			close(shutdown)
+	// once the config is replaced, removed aggregators and outputs no longer
+	// receive metrics.
+	a.mu.Lock()
+	a.Config = c
+	a.mu.Unlock()
+
+	if diff.ProcessorsChanged || diff.AgentChanged {
+		// the flusher rebuilds the pipeline from the new config; a pending
+		// rebuild will already use it.
+		select {
+		case a.rebuildC <- struct{}{}:
+		default:
 		}
-	}()
+	}
 
-	wg.Add(len(a.Config.Aggregators))
-	for _, aggregator := range a.Config.Aggregators {
-		go func(agg *models.RunningAggregator) {
-			defer wg.Done()
-			acc := NewAccumulator(agg, aggC)
-			acc.SetPrecision(a.Config.Agent.Precision.Duration,
-				a.Config.Agent.Interval.Duration)
-			agg.Run(acc, now, shutdown)
-		}(aggregator)
+	for _, agg := range diff.RemovedAggregators {
+		a.stopAggregator(agg)
+	}
+	for _, o := range diff.RemovedOutputs {
+		a.stopWriter(o)
+		if err := closeOutput(o); err != nil {
+			log.Printf("E! Error closing output [%s]: %s\n", o.Name, err)
+		}
 	}
 
-	wg.Add(len(a.Config.Inputs))
-	for _, input := range a.Config.Inputs {
-		interval := a.Config.Agent.Interval.Duration
-		// overwrite global interval if this plugin has it's own.
-		if input.Config.Interval != 0 {
-			interval = input.Config.Interval
+	for _, o := range append(diff.AddedOutputs, restartWriters...) {
+		a.startWriter(o)
+	}
+	now := time.Now()
+	for _, agg := range diff.AddedAggregators {
+		a.startAggregator(agg, now)
+	}
+	var err error
+	for _, input := range append(diff.AddedInputs, restartInputs...) {
+		if startErr := a.startInput(input); startErr != nil {
+			err = startErr
 		}
-		go func(in *models.RunningInput, interv time.Duration) {
-			defer wg.Done()
-			a.gatherer(shutdown, in, interv, metricC)
-		}(input, interval)
 	}
 
-	wg.Wait()
-	a.Close()
-	return nil
+	if address := c.Agent.HealthServiceAddress; address != prevHealthAddress {
+		a.stopHealthServer()
+		if address != "" {
+			if healthErr := a.startHealthServer(address); healthErr != nil {
+				log.Printf("E! Could not start health API: %s\n", healthErr)
+				err = healthErr
+			}
+		}
+	}
+
+	log.Printf("I! Reloaded config: %d inputs added, %d removed; "+
+		"%d outputs added, %d removed; %d aggregators added, %d removed\n",
+		len(diff.AddedInputs), len(diff.RemovedInputs),
+		len(diff.AddedOutputs), len(diff.RemovedOutputs),
+		len(diff.AddedAggregators), len(diff.RemovedAggregators))
+	return err
+}
+
+func containsOutput(outputs []*models.RunningOutput, o *models.RunningOutput) bool {
+	for _, output := range outputs {
+		if output == o {
+			return true
+		}
+	}
+	return false
 }
@@ -1,9 +1,19 @@
 package agent
 
 import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal/config"
+	"github.com/influxdata/telegraf/internal/models"
+	"github.com/influxdata/telegraf/plugins/aggregators/minmax"
+	"github.com/influxdata/telegraf/testutil"
 
 	// needing to load the plugins
 	_ "github.com/influxdata/telegraf/plugins/inputs/all"
@@ -11,6 +21,7 @@ import (
 	_ "github.com/influxdata/telegraf/plugins/outputs/all"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAgent_OmitHostname(t *testing.T) {
@@ -109,3 +120,291 @@ func TestAgent_LoadOutput(t *testing.T) {
 	a, _ = NewAgent(c)
 	assert.Equal(t, 3, len(a.Config.Outputs))
 }
+
+type blockingOutput struct {
+	sync.Mutex
+	block   chan struct{}
+	metrics []telegraf.Metric
+}
+
+func (o *blockingOutput) Connect() error       { return nil }
+func (o *blockingOutput) Close() error         { return nil }
+func (o *blockingOutput) Description() string  { return "" }
+func (o *blockingOutput) SampleConfig() string { return "" }
+func (o *blockingOutput) Write(metrics []telegraf.Metric) error {
+	if o.block != nil {
+		<-o.block
+	}
+	o.Lock()
+	defer o.Unlock()
+	o.metrics = append(o.metrics, metrics...)
+	return nil
+}
+
+func (o *blockingOutput) Len() int {
+	o.Lock()
+	defer o.Unlock()
+	return len(o.metrics)
+}
+
+// A hung output must not delay writes to the other outputs.
+func TestAgent_WritersAreIndependent(t *testing.T) {
+	c := config.NewConfig()
+	c.Agent.FlushInterval.Duration = time.Hour
+	a, err := NewAgent(c)
+	assert.NoError(t, err)
+
+	slow := &blockingOutput{block: make(chan struct{})}
+	fast := &blockingOutput{}
+	slowRo := models.NewRunningOutput("slow", slow,
+		&models.OutputConfig{FlushInterval: time.Millisecond}, 1, 10)
+	fastRo := models.NewRunningOutput("fast", fast,
+		&models.OutputConfig{}, 1, 10)
+
+	shutdown := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(2)
+	for _, ro := range []*models.RunningOutput{slowRo, fastRo} {
+		go func(ro *models.RunningOutput) {
+			defer wg.Done()
+			a.writer(shutdown, ro, make(chan error, 1))
+		}(ro)
+	}
+
+	slowRo.AddMetric(testutil.TestMetric(1))
+	// the fast output only writes early because a full batch is ready, as its
+	// flush interval is inherited from the agent.
+	fastRo.AddMetric(testutil.TestMetric(2))
+	deadline := time.Now().Add(5 * time.Second)
+	for fast.Len() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, 1, fast.Len())
+	assert.Equal(t, 0, slow.Len())
+
+	close(slow.block)
+	close(shutdown)
+	wg.Wait()
+	assert.Equal(t, 1, slow.Len())
+}
+
+func loadTestConfig(t *testing.T, toml string) *config.Config {
+	f, err := ioutil.TempFile("", "telegraf-agent")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(toml)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfig(f.Name()))
+	return c
+}
+
+const reloadTestConfig = `
+[agent]
+  interval = "1h"
+  round_interval = false
+  flush_interval = "1h"
+  omit_hostname = true
+
+[[inputs.mem]]
+
+[[outputs.discard]]
+`
+
+// Unchanged plugins keep running across a reload, with the metrics they
+// buffered.
+func TestAgent_Reload(t *testing.T) {
+	a, err := NewAgent(loadTestConfig(t, reloadTestConfig))
+	require.NoError(t, err)
+	require.NoError(t, a.Connect())
+	input := a.Config.Inputs[0]
+	output := a.Config.Outputs[0]
+
+	shutdown := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- a.Run(shutdown)
+	}()
+
+	// reloading fails until the agent runs
+	c := loadTestConfig(t, reloadTestConfig+`
+[[outputs.discard]]
+  metric_batch_size = 10
+`)
+	deadline := time.Now().Add(5 * time.Second)
+	for a.Reload(c) != nil && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	output.AddMetric(testutil.TestMetric(1))
+
+	a.runMu.Lock()
+	assert.True(t, a.Config == c)
+	require.Len(t, a.Config.Outputs, 2)
+	assert.True(t, input == a.Config.Inputs[0])
+	assert.True(t, output == a.Config.Outputs[0])
+	assert.Len(t, a.inputs, 1)
+	assert.Len(t, a.writers, 2)
+	a.runMu.Unlock()
+	assert.NotEqual(t, 0, output.BufferLen())
+
+	require.NoError(t, a.Reload(loadTestConfig(t, reloadTestConfig)))
+	a.runMu.Lock()
+	require.Len(t, a.Config.Outputs, 1)
+	assert.True(t, output == a.Config.Outputs[0])
+	assert.Len(t, a.writers, 1)
+	a.runMu.Unlock()
+
+	close(shutdown)
+	assert.NoError(t, <-done)
+	assert.Error(t, a.Reload(loadTestConfig(t, reloadTestConfig)))
+}
+
+// hungInput blocks in Gather until release is closed.
+type hungInput struct {
+	release chan struct{}
+}
+
+func (i *hungInput) SampleConfig() string { return "" }
+func (i *hungInput) Description() string  { return "" }
+
+func (i *hungInput) Gather(acc telegraf.Accumulator) error {
+	<-i.release
+	return nil
+}
+
+// hungContextInput blocks in GatherContext until its context is done.
+type hungContextInput struct {
+	hungInput
+}
+
+func (i *hungContextInput) GatherContext(ctx context.Context, acc telegraf.Accumulator) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestGatherWithTimeout_CancelsContextInput(t *testing.T) {
+	shutdown := make(chan struct{})
+	defer close(shutdown)
+	input := models.NewRunningInput(&hungContextInput{}, &models.InputConfig{
+		Name:    "hung_context",
+		Timeout: 10 * time.Millisecond,
+	})
+	acc := NewAccumulator(input, make(chan telegraf.Metric, 10))
+
+	pending := gatherWithTimeout(shutdown, input, acc, time.Hour, nil)
+	require.NotNil(t, pending)
+	assert.Equal(t, int64(1), input.GatherTimeouts.Get())
+	assert.Equal(t, context.Canceled, <-pending)
+}
+
+// A ContextInput without a gather_timeout is waited for, past its interval.
+func TestGatherWithTimeout_WaitsWithoutTimeout(t *testing.T) {
+	shutdown := make(chan struct{})
+	input := models.NewRunningInput(&hungContextInput{},
+		&models.InputConfig{Name: "hung_context_no_timeout"})
+	acc := NewAccumulator(input, make(chan telegraf.Metric, 10))
+
+	done := make(chan chan error)
+	go func() {
+		done <- gatherWithTimeout(shutdown, input, acc, 10*time.Millisecond, nil)
+	}()
+	select {
+	case <-done:
+		t.Fatal("the gather was abandoned")
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.Equal(t, int64(0), input.GatherTimeouts.Get())
+
+	close(shutdown)
+	assert.Nil(t, <-done)
+}
+
+func TestGatherWithTimeout_SkipsWhileAbandoned(t *testing.T) {
+	shutdown := make(chan struct{})
+	defer close(shutdown)
+	hung := &hungInput{release: make(chan struct{})}
+	input := models.NewRunningInput(hung, &models.InputConfig{
+		Name:    "hung",
+		Timeout: 10 * time.Millisecond,
+	})
+	acc := NewAccumulator(input, make(chan telegraf.Metric, 10))
+
+	pending := gatherWithTimeout(shutdown, input, acc, time.Hour, nil)
+	require.NotNil(t, pending)
+	assert.Equal(t, int64(1), input.GatherTimeouts.Get())
+
+	// the abandoned gather has not returned, the collection is skipped
+	assert.True(t, pending == gatherWithTimeout(shutdown, input, acc, time.Hour, pending))
+	assert.Equal(t, int64(1), input.GatherTimeouts.Get())
+
+	close(hung.release)
+	time.Sleep(10 * time.Millisecond)
+	assert.Nil(t, gatherWithTimeout(shutdown, input, acc, time.Hour, pending))
+}
+
+// valueInput adds a single metric each time it is gathered.
+type valueInput struct{}
+
+func (i *valueInput) SampleConfig() string { return "" }
+func (i *valueInput) Description() string  { return "" }
+
+func (i *valueInput) Gather(acc telegraf.Accumulator) error {
+	acc.AddFields("value", map[string]interface{}{"value": 42.0}, nil)
+	return nil
+}
+
+type failingOutput struct {
+	blockingOutput
+}
+
+func (o *failingOutput) Write(metrics []telegraf.Metric) error {
+	return fmt.Errorf("failed write")
+}
+
+// Once sends the gathered metrics, and the partial aggregation period, to
+// the outputs.
+func TestAgent_Once(t *testing.T) {
+	c := config.NewConfig()
+	c.Agent.OmitHostname = true
+	c.Inputs = append(c.Inputs, models.NewRunningInput(&valueInput{},
+		&models.InputConfig{Name: "value"}))
+	c.Aggregators = append(c.Aggregators, models.NewRunningAggregator(
+		minmax.NewMinMax(),
+		&models.AggregatorConfig{Name: "minmax", Period: time.Hour}))
+	output := &blockingOutput{}
+	c.Outputs = append(c.Outputs, models.NewRunningOutput("ok", output,
+		&models.OutputConfig{}, 10, 10))
+	a, err := NewAgent(c)
+	require.NoError(t, err)
+	require.NoError(t, a.Connect())
+
+	require.NoError(t, a.Once(make(chan struct{})))
+	require.Equal(t, 2, output.Len())
+	assert.Equal(t, map[string]interface{}{"value": 42.0},
+		output.metrics[0].Fields())
+	assert.Equal(t, map[string]interface{}{"value_min": 42.0, "value_max": 42.0},
+		output.metrics[1].Fields())
+}
+
+// Once returns an error when an output fails to write, once its retries are
+// exhausted.
+func TestAgent_OnceWriteFailure(t *testing.T) {
+	c := config.NewConfig()
+	c.Agent.OmitHostname = true
+	c.Inputs = append(c.Inputs, models.NewRunningInput(&valueInput{},
+		&models.InputConfig{Name: "value"}))
+	ok := &blockingOutput{}
+	c.Outputs = append(c.Outputs,
+		models.NewRunningOutput("failing", &failingOutput{},
+			&models.OutputConfig{}, 10, 10),
+		models.NewRunningOutput("ok", ok, &models.OutputConfig{}, 10, 10))
+	a, err := NewAgent(c)
+	require.NoError(t, err)
+	require.NoError(t, a.Connect())
+
+	assert.EqualError(t, a.Once(make(chan struct{})),
+		"failed to write to outputs: failing")
+	assert.Equal(t, 1, ok.Len())
+}
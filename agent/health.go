@@ -0,0 +1,177 @@
+package agent
+
+import (
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Health is the state of the agent, as served by the health API. It is ready
+// when it is healthy and all the outputs are connected.
+type Health struct {
+	Healthy bool           `json:"healthy"`
+	Ready   bool           `json:"ready"`
+	Inputs  []InputHealth  `json:"inputs"`
+	Outputs []OutputHealth `json:"outputs"`
+}
+
+// InputHealth is the state of an input. Times are in RFC3339 format, and
+// left out until they happen.
+type InputHealth struct {
+	Name          string `json:"name"`
+	Healthy       bool   `json:"healthy"`
+	LastGather    string `json:"last_gather,omitempty"`
+	LastSuccess   string `json:"last_success,omitempty"`
+	LastError     string `json:"last_error,omitempty"`
+	LastErrorTime string `json:"last_error_time,omitempty"`
+}
+
+// OutputHealth is the state of an output.
+type OutputHealth struct {
+	Name           string  `json:"name"`
+	Healthy        bool    `json:"healthy"`
+	Connected      bool    `json:"connected"`
+	Disabled       bool    `json:"disabled"`
+	BufferSize     int     `json:"buffer_size"`
+	BufferFullness float64 `json:"buffer_fullness"`
+	LastWrite      string  `json:"last_write,omitempty"`
+	LastError      string  `json:"last_error,omitempty"`
+	LastErrorTime  string  `json:"last_error_time,omitempty"`
+}
+
+// Health returns the state of the inputs and outputs at now, checked against
+// the thresholds of the agent config. The agent is healthy when all of them
+// are, and ready when all the outputs are connected as well.
+func (a *Agent) Health(now time.Time) *Health {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	conf := a.Config.Agent
+
+	h := &Health{
+		Healthy: true,
+		Ready:   true,
+		Inputs:  make([]InputHealth, 0, len(a.Config.Inputs)),
+		Outputs: make([]OutputHealth, 0, len(a.Config.Outputs)),
+	}
+
+	for _, input := range a.Config.Inputs {
+		status := input.Status()
+		ih := InputHealth{
+			Name:          input.Name(),
+			Healthy:       true,
+			LastGather:    formatTime(status.LastGather),
+			LastSuccess:   formatTime(status.LastSuccess),
+			LastErrorTime: formatTime(status.LastErrorTime),
+		}
+		if status.LastError != nil {
+			ih.LastError = status.LastError.Error()
+		}
+		if maxAge := conf.HealthMaxGatherAge.Duration; maxAge > 0 {
+			since := latest(status.Started, status.LastSuccess)
+			ih.Healthy = now.Sub(since) <= maxAge
+		}
+		h.Healthy = h.Healthy && ih.Healthy
+		h.Inputs = append(h.Inputs, ih)
+	}
+
+	for _, output := range a.Config.Outputs {
+		status := output.Status()
+		oh := OutputHealth{
+			Name:           "outputs." + output.Name,
+			Healthy:        !status.Disabled,
+			Connected:      status.Connected,
+			Disabled:       status.Disabled,
+			BufferSize:     status.BufferSize,
+			BufferFullness: status.BufferFullness,
+			LastWrite:      formatTime(status.LastWrite),
+			LastErrorTime:  formatTime(status.LastErrorTime),
+		}
+		if status.LastError != nil {
+			oh.LastError = status.LastError.Error()
+		}
+		if max := conf.HealthMaxBufferFullness; max > 0 &&
+			status.BufferFullness > max {
+			oh.Healthy = false
+		}
+		if maxAge := conf.HealthMaxWriteAge.Duration; maxAge > 0 &&
+			now.Sub(latest(status.Started, status.LastWrite)) > maxAge {
+			oh.Healthy = false
+		}
+		h.Healthy = h.Healthy && oh.Healthy
+		h.Ready = h.Ready && status.Connected
+		h.Outputs = append(h.Outputs, oh)
+	}
+	h.Ready = h.Ready && h.Healthy
+	return h
+}
+
+func latest(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// serveHealth responds with the state of the agent, and a 503 status code
+// when it is unhealthy, for liveness probes.
+func (a *Agent) serveHealth(w http.ResponseWriter, r *http.Request) {
+	a.serveState(w, r, func(h *Health) bool { return h.Healthy })
+}
+
+// serveReady responds with the state of the agent, and a 503 status code
+// when it is not ready, for readiness probes.
+func (a *Agent) serveReady(w http.ResponseWriter, r *http.Request) {
+	a.serveState(w, r, func(h *Health) bool { return h.Ready })
+}
+
+func (a *Agent) serveState(w http.ResponseWriter, r *http.Request, ok func(*Health) bool) {
+	if r.Method != "GET" && r.Method != "HEAD" {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed),
+			http.StatusMethodNotAllowed)
+		return
+	}
+
+	h := a.Health(time.Now())
+	w.Header().Set("Content-Type", "application/json")
+	if !ok(h) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(h)
+}
+
+// startHealthServer starts serving the health API on address.
+func (a *Agent) startHealthServer(address string) error {
+	ln, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", a.serveHealth)
+	mux.HandleFunc("/ready", a.serveReady)
+	srv := &http.Server{Handler: mux}
+	a.healthServer = srv
+	go func() {
+		if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+			log.Printf("E! Health API stopped: %s\n", err)
+		}
+	}()
+	log.Printf("I! Serving health API on %s\n", ln.Addr())
+	return nil
+}
+
+func (a *Agent) stopHealthServer() {
+	if a.healthServer != nil {
+		a.healthServer.Close()
+		a.healthServer = nil
+	}
+}
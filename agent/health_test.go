@@ -0,0 +1,151 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/config"
+	"github.com/influxdata/telegraf/internal/models"
+	"github.com/influxdata/telegraf/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type healthInput struct{}
+
+func (i *healthInput) Description() string                   { return "" }
+func (i *healthInput) SampleConfig() string                  { return "" }
+func (i *healthInput) Gather(acc telegraf.Accumulator) error { return nil }
+
+func newHealthAgent(t *testing.T) (*Agent, *models.RunningInput, *models.RunningOutput) {
+	c := config.NewConfig()
+	c.Agent.OmitHostname = true
+	c.Agent.HealthMaxGatherAge.Duration = time.Minute
+	c.Agent.HealthMaxWriteAge.Duration = time.Minute
+	c.Agent.HealthMaxBufferFullness = 0.5
+
+	input := models.NewRunningInput(&healthInput{}, &models.InputConfig{
+		Name: "test",
+	})
+	c.Inputs = append(c.Inputs, input)
+	output := models.NewRunningOutput("test", &blockingOutput{},
+		&models.OutputConfig{}, 1, 10)
+	c.Outputs = append(c.Outputs, output)
+
+	a, err := NewAgent(c)
+	require.NoError(t, err)
+	return a, input, output
+}
+
+func TestAgent_Health(t *testing.T) {
+	a, input, output := newHealthAgent(t)
+	now := time.Now()
+	input.SetStarted(now)
+	output.SetStarted(now)
+
+	h := a.Health(now)
+	assert.True(t, h.Healthy)
+	// the output is not connected
+	assert.False(t, h.Ready)
+	require.Len(t, h.Inputs, 1)
+	require.Len(t, h.Outputs, 1)
+	assert.Equal(t, "inputs.test", h.Inputs[0].Name)
+	assert.Equal(t, "outputs.test", h.Outputs[0].Name)
+
+	// nothing was gathered nor written since the agent started
+	h = a.Health(now.Add(2 * time.Minute))
+	assert.False(t, h.Healthy)
+	assert.False(t, h.Inputs[0].Healthy)
+	assert.False(t, h.Outputs[0].Healthy)
+
+	input.RecordGather(now)
+	require.NoError(t, output.Write())
+	h = a.Health(time.Now().Add(30 * time.Second))
+	assert.True(t, h.Healthy)
+	assert.NotEmpty(t, h.Inputs[0].LastSuccess)
+	assert.NotEmpty(t, h.Outputs[0].LastWrite)
+
+	input.RecordError(fmt.Errorf("gather failed"))
+	for i := 0; i < 6; i++ {
+		output.AddMetric(testutil.TestMetric(i))
+	}
+	h = a.Health(time.Now())
+	assert.False(t, h.Healthy)
+	assert.True(t, h.Inputs[0].Healthy)
+	assert.Equal(t, "gather failed", h.Inputs[0].LastError)
+	assert.False(t, h.Outputs[0].Healthy)
+	assert.Equal(t, 6, h.Outputs[0].BufferSize)
+	assert.Equal(t, 0.6, h.Outputs[0].BufferFullness)
+}
+
+func TestAgent_ServeHealth(t *testing.T) {
+	a, input, output := newHealthAgent(t)
+	input.SetStarted(time.Now())
+	output.SetStarted(time.Now())
+
+	srv := httptest.NewServer(http.HandlerFunc(a.serveHealth))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	var h Health
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&h))
+	assert.True(t, h.Healthy)
+
+	for i := 0; i < 6; i++ {
+		output.AddMetric(testutil.TestMetric(i))
+	}
+	resp, err = http.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	resp, err = http.Post(srv.URL, "text/plain", nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestAgent_ServeReady(t *testing.T) {
+	a, input, output := newHealthAgent(t)
+	input.SetStarted(time.Now())
+	output.SetStarted(time.Now())
+
+	srv := httptest.NewServer(http.HandlerFunc(a.serveReady))
+	defer srv.Close()
+
+	// healthy, but the output is not connected
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	var h Health
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&h))
+	assert.True(t, h.Healthy)
+	assert.False(t, h.Ready)
+	assert.False(t, h.Outputs[0].Connected)
+
+	require.NoError(t, output.Connect())
+	resp, err = http.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// not ready when unhealthy
+	for i := 0; i < 6; i++ {
+		output.AddMetric(testutil.TestMetric(i))
+	}
+	resp, err = http.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
@@ -0,0 +1,175 @@
+package agent
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/models"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+// pipeline applies the processors to the metrics sent on in, and sends the
+// processed metrics on out.
+//
+// The processors are split in stages that run concurrently, connected by
+// channels. Consecutive stateless processors form a single stage that runs on
+// several workers, every other processor is a stage of its own that runs on a
+// single worker. The metrics of a series are always sent to the same worker
+// of a stage, so that they leave the pipeline in the order they entered it.
+type pipeline struct {
+	in     chan telegraf.Metric
+	stages []*stage
+	done   chan struct{}
+}
+
+// stage is a set of processors applied one after the other by each of its
+// workers.
+type stage struct {
+	processors []*models.RunningProcessor
+	in         chan telegraf.Metric
+	workers    []chan telegraf.Metric
+	queueDepth selfstat.Stat
+}
+
+// newPipeline starts the stages of the processors, with the given number of
+// workers for the stateless processors and channels of the given size between
+// the stages. There is always at least one stage, which passes the metrics
+// through when there are no processors.
+func newPipeline(
+	processors []*models.RunningProcessor,
+	workers int,
+	channelSize int,
+	out chan telegraf.Metric,
+) *pipeline {
+	if workers < 1 {
+		workers = 1
+	}
+	if channelSize < 0 {
+		channelSize = 0
+	}
+
+	var stages []*stage
+	for i := 0; i < len(processors); {
+		s := &stage{processors: processors[i : i+1]}
+		if processors[i].Stateless() {
+			j := i + 1
+			for j < len(processors) && processors[j].Stateless() {
+				j++
+			}
+			s.processors = processors[i:j]
+		}
+		i += len(s.processors)
+		stages = append(stages, s)
+	}
+	if len(stages) == 0 {
+		stages = append(stages, &stage{})
+	}
+
+	p := &pipeline{
+		in:     make(chan telegraf.Metric, channelSize),
+		stages: stages,
+		done:   make(chan struct{}),
+	}
+	in := p.in
+	for i, s := range stages {
+		n := 1
+		if len(s.processors) > 0 && s.processors[0].Stateless() {
+			n = workers
+		}
+		s.in = in
+		s.workers = make([]chan telegraf.Metric, n)
+		for w := range s.workers {
+			s.workers[w] = make(chan telegraf.Metric, channelSize)
+		}
+		s.queueDepth = selfstat.Register("agent", "queue_depth", map[string]string{
+			"stage":      strconv.Itoa(i),
+			"processors": s.names(),
+		})
+
+		stageOut := out
+		if i < len(stages)-1 {
+			stageOut = make(chan telegraf.Metric, channelSize)
+		}
+		last := i == len(stages)-1
+		go func(s *stage, out chan telegraf.Metric) {
+			s.run(out)
+			if last {
+				close(p.done)
+			} else {
+				close(out)
+			}
+		}(s, stageOut)
+		in = stageOut
+	}
+	return p
+}
+
+// Close stops accepting metrics, and returns once all the metrics in the
+// pipeline were sent on its out channel. The stats of the stages are
+// unregistered, a new pipeline registers its own.
+func (p *pipeline) Close() {
+	close(p.in)
+	<-p.done
+	for _, s := range p.stages {
+		selfstat.Unregister(s.queueDepth)
+	}
+}
+
+// run dispatches the metrics received by the stage to its workers until its
+// input is closed, and returns once the workers are done.
+func (s *stage) run(out chan telegraf.Metric) {
+	var wg sync.WaitGroup
+	for _, c := range s.workers {
+		wg.Add(1)
+		go func(c chan telegraf.Metric) {
+			defer wg.Done()
+			s.work(c, out)
+		}(c)
+	}
+
+	for m := range s.in {
+		c := s.workers[0]
+		if len(s.workers) > 1 {
+			c = s.workers[m.HashID()%uint64(len(s.workers))]
+		}
+		c <- m
+		s.queueDepth.Set(s.depth())
+	}
+	for _, c := range s.workers {
+		close(c)
+	}
+	wg.Wait()
+}
+
+// work applies the processors to the metrics received on in.
+func (s *stage) work(in, out chan telegraf.Metric) {
+	for m := range in {
+		metrics := []telegraf.Metric{m}
+		for _, processor := range s.processors {
+			metrics = processor.Apply(metrics...)
+		}
+		for _, m := range metrics {
+			out <- m
+		}
+		s.queueDepth.Set(s.depth())
+	}
+}
+
+// depth returns the number of metrics queued for the stage.
+func (s *stage) depth() int64 {
+	n := len(s.in)
+	for _, c := range s.workers {
+		n += len(c)
+	}
+	return int64(n)
+}
+
+func (s *stage) names() string {
+	names := make([]string, 0, len(s.processors))
+	for _, processor := range s.processors {
+		names = append(names, processor.Name)
+	}
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,150 @@
+package agent
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/models"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/selfstat"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tagProcessor adds a tag with its name to the metrics, and drops the metrics
+// named "drop".
+type tagProcessor struct {
+	name      string
+	stateless bool
+}
+
+func (p *tagProcessor) SampleConfig() string { return "" }
+func (p *tagProcessor) Description() string  { return "" }
+func (p *tagProcessor) Stateless() bool      { return p.stateless }
+
+func (p *tagProcessor) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	out := make([]telegraf.Metric, 0, len(in))
+	for _, m := range in {
+		if m.Name() == "drop" {
+			continue
+		}
+		m.AddTag(p.name, "true")
+		out = append(out, m)
+	}
+	return out
+}
+
+func newTagProcessor(name string, stateless bool) *models.RunningProcessor {
+	return &models.RunningProcessor{
+		Name:      name,
+		Processor: &tagProcessor{name: name, stateless: stateless},
+		Config:    &models.ProcessorConfig{Name: name},
+	}
+}
+
+func newSeriesMetric(t *testing.T, series int, seq int) telegraf.Metric {
+	m, err := metric.New("test",
+		map[string]string{"series": fmt.Sprint(series)},
+		map[string]interface{}{"seq": int64(seq)},
+		time.Unix(0, 0))
+	require.NoError(t, err)
+	return m
+}
+
+func stageNames(p *pipeline) []string {
+	var names []string
+	for _, s := range p.stages {
+		names = append(names, fmt.Sprintf("%s/%d", s.names(), len(s.workers)))
+	}
+	return names
+}
+
+func TestPipeline_Stages(t *testing.T) {
+	out := make(chan telegraf.Metric)
+	p := newPipeline([]*models.RunningProcessor{
+		newTagProcessor("a", true),
+		newTagProcessor("b", true),
+		newTagProcessor("c", false),
+		newTagProcessor("d", true),
+		newTagProcessor("e", false),
+		newTagProcessor("f", false),
+	}, 4, 10, out)
+	defer p.Close()
+
+	assert.Equal(t, []string{"a,b/4", "c/1", "d/4", "e/1", "f/1"}, stageNames(p))
+}
+
+func TestPipeline_CloseUnregistersStats(t *testing.T) {
+	queueDepths := func() int {
+		n := 0
+		for _, m := range selfstat.Metrics() {
+			if m.Name() == "internal_agent" && m.Tags()["processors"] == "unregister" {
+				n++
+			}
+		}
+		return n
+	}
+
+	out := make(chan telegraf.Metric)
+	p := newPipeline([]*models.RunningProcessor{
+		newTagProcessor("unregister", false),
+	}, 4, 10, out)
+	assert.Equal(t, 1, queueDepths())
+	p.Close()
+	assert.Equal(t, 0, queueDepths())
+}
+
+func TestPipeline_NoProcessors(t *testing.T) {
+	out := make(chan telegraf.Metric, 10)
+	p := newPipeline(nil, 4, 10, out)
+	assert.Equal(t, []string{"/1"}, stageNames(p))
+
+	m := newSeriesMetric(t, 0, 0)
+	p.in <- m
+	p.Close()
+	require.Len(t, out, 1)
+	assert.True(t, m == <-out)
+}
+
+// The metrics of a series leave the pipeline in the order they entered it,
+// processed by all the processors.
+func TestPipeline_KeepsSeriesOrder(t *testing.T) {
+	const series = 10
+	const count = 1000
+
+	out := make(chan telegraf.Metric, series*count)
+	p := newPipeline([]*models.RunningProcessor{
+		newTagProcessor("a", true),
+		newTagProcessor("b", false),
+		newTagProcessor("c", true),
+	}, 4, 5, out)
+
+	for i := 0; i < count; i++ {
+		for s := 0; s < series; s++ {
+			p.in <- newSeriesMetric(t, s, i)
+		}
+	}
+	m, err := metric.New("drop", nil, map[string]interface{}{"value": 1}, time.Now())
+	require.NoError(t, err)
+	p.in <- m
+	p.Close()
+
+	require.Len(t, out, series*count)
+	next := make(map[string]int64)
+	for i := 0; i < series*count; i++ {
+		m := <-out
+		assert.Equal(t, map[string]string{
+			"series": m.Tags()["series"],
+			"a":      "true",
+			"b":      "true",
+			"c":      "true",
+		}, m.Tags())
+
+		s := m.Tags()["series"]
+		assert.Equal(t, next[s], m.Fields()["seq"], "series %s", s)
+		next[s]++
+	}
+}
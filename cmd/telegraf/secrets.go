@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/influxdata/telegraf/plugins/secretstores/encrypted"
+)
+
+const secretsUsage = `Usage:
+
+  telegraf secrets set <file> <key>     store the secret read from stdin
+  telegraf secrets list <file>          list the keys of the secrets
+  telegraf secrets delete <file> <key>  delete a secret
+
+The file is encrypted with the password in the TELEGRAF_SECRETS_PASSWORD
+environment variable, and is created by "set" if it does not exist.`
+
+// secretsCommand manages the secrets of the files read by the encrypted
+// secret store.
+func secretsCommand(args []string) error {
+	if len(args) < 2 {
+		return errors.New(secretsUsage)
+	}
+	password := os.Getenv("TELEGRAF_SECRETS_PASSWORD")
+	if password == "" {
+		return errors.New("TELEGRAF_SECRETS_PASSWORD is not set")
+	}
+
+	command, path := args[0], args[1]
+	switch command {
+	case "set":
+		if len(args) != 3 {
+			return errors.New(secretsUsage)
+		}
+		secrets, err := encrypted.Load(path, password)
+		if os.IsNotExist(err) {
+			secrets, err = make(map[string]string), nil
+		}
+		if err != nil {
+			return err
+		}
+		secret, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		secrets[args[2]] = strings.TrimRight(string(secret), "\r\n")
+		return encrypted.Save(path, password, secrets)
+	case "list":
+		if len(args) != 2 {
+			return errors.New(secretsUsage)
+		}
+		secrets, err := encrypted.Load(path, password)
+		if err != nil {
+			return err
+		}
+		keys := make([]string, 0, len(secrets))
+		for key := range secrets {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Println(key)
+		}
+		return nil
+	case "delete":
+		if len(args) != 3 {
+			return errors.New(secretsUsage)
+		}
+		secrets, err := encrypted.Load(path, password)
+		if err != nil {
+			return err
+		}
+		if _, ok := secrets[args[2]]; !ok {
+			return fmt.Errorf("secret %q not found in %s", args[2], path)
+		}
+		delete(secrets, args[2])
+		return encrypted.Save(path, password, secrets)
+	default:
+		return errors.New(secretsUsage)
+	}
+}
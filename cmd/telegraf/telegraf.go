@@ -11,6 +11,7 @@ import (
 	"runtime"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/influxdata/telegraf/agent"
 	"github.com/influxdata/telegraf/internal/config"
@@ -21,6 +22,7 @@ import (
 	"github.com/influxdata/telegraf/plugins/outputs"
 	_ "github.com/influxdata/telegraf/plugins/outputs/all"
 	_ "github.com/influxdata/telegraf/plugins/processors/all"
+	_ "github.com/influxdata/telegraf/plugins/secretstores/all"
 	"github.com/kardianos/service"
 )
 
@@ -31,9 +33,15 @@ var pprofAddr = flag.String("pprof-addr", "",
 var fQuiet = flag.Bool("quiet", false,
 	"run in quiet mode")
 var fTest = flag.Bool("test", false, "gather metrics, print them out, and exit")
+var fOnce = flag.Bool("once", false,
+	"gather metrics once, write them to the outputs, and exit")
 var fConfig = flag.String("config", "", "configuration file to load")
 var fConfigDirectory = flag.String("config-directory", "",
 	"directory containing additional *.conf files")
+var fCheckConfig = flag.Bool("check-config", false,
+	"check the configuration files for mistakes, and exit")
+var fWatchConfig = flag.Bool("watch-config", false,
+	"reload the configuration when the config files change")
 var fVersion = flag.Bool("version", false, "display the version")
 var fSampleConfig = flag.Bool("sample-config", false,
 	"print out full sample configuration")
@@ -81,11 +89,16 @@ Usage:
 The commands & flags are:
 
   config              print out full sample configuration to stdout
+  config validate     check the configuration files for mistakes
   version             print the version to stdout
+  secrets             manage the secrets of an encrypted secret store file
 
   --config <file>     configuration file to load
   --test              gather metrics once, print them to stdout, and exit
+  --once              gather metrics once, write them to the outputs, and exit
   --config-directory  directory containing additional *.conf files
+  --check-config      check the configuration files for mistakes, and exit
+  --watch-config      reload the configuration when the config files change
   --input-filter      filter the input plugins to enable, separator is :
   --output-filter     filter the output plugins to enable, separator is :
   --usage             print usage for a plugin, ie, 'telegraf --usage mysql'
@@ -104,18 +117,97 @@ Examples:
   # run a single telegraf collection, outputing metrics to stdout
   telegraf --config telegraf.conf --test
 
+  # run a single telegraf collection through the processors, aggregators
+  # and outputs, exiting with an error if any output failed
+  telegraf --config telegraf.conf --once
+
   # run telegraf with all plugins defined in config file
   telegraf --config telegraf.conf
 
   # run telegraf, enabling the cpu & memory input, and influxdb output plugins
   telegraf --config telegraf.conf --input-filter cpu:mem --output-filter influxdb
 
+  # check the configuration for unknown options and invalid settings
+  telegraf --config telegraf.conf --config-directory /etc/telegraf/telegraf.d config validate
+
+  # run telegraf, reloading the configuration when it is edited
+  telegraf --config telegraf.conf --watch-config
+
+  # store a secret in an encrypted secret store file
+  echo -n "s3cr3t" | telegraf secrets set /etc/telegraf/secrets.json mysql_password
+
   # run telegraf with pprof
   telegraf --config telegraf.conf --pprof-addr localhost:6060
 `
 
 var stop chan struct{}
 
+// watchInterval is how often the config files are checked for changes when
+// --watch-config is set.
+const watchInterval = 5 * time.Second
+
+// loadConfig loads and validates the configuration from the config file and
+// directory.
+func loadConfig(inputFilters []string, outputFilters []string) (*config.Config, error) {
+	c := config.NewConfig()
+	c.OutputFilters = outputFilters
+	c.InputFilters = inputFilters
+	err := c.LoadConfig(*fConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if *fConfigDirectory != "" {
+		err = c.LoadDirectory(*fConfigDirectory)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, problem := range c.Problems() {
+		log.Printf("W! %s\n", problem)
+	}
+	if !*fTest && len(c.Outputs) == 0 {
+		return nil, fmt.Errorf("Error: no outputs found, did you provide a valid config file?")
+	}
+	if len(c.Inputs) == 0 {
+		return nil, fmt.Errorf("Error: no inputs found, did you provide a valid config file?")
+	}
+
+	if int64(c.Agent.Interval.Duration) <= 0 {
+		return nil, fmt.Errorf("Agent interval must be positive, found %s",
+			c.Agent.Interval.Duration)
+	}
+
+	if int64(c.Agent.FlushInterval.Duration) <= 0 {
+		return nil, fmt.Errorf("Agent flush_interval must be positive; found %s",
+			c.Agent.Interval.Duration)
+	}
+
+	if f := c.Agent.HealthMaxBufferFullness; f < 0 || f > 1 {
+		return nil, fmt.Errorf("Agent health_max_buffer_fullness must be "+
+			"between 0 and 1; found %v", f)
+	}
+	return c, nil
+}
+
+// reloadConfig loads the configuration again and applies it to the running
+// agent. If it cannot be loaded, the agent keeps running with its current
+// configuration.
+func reloadConfig(ag *agent.Agent, inputFilters []string, outputFilters []string) {
+	c, err := loadConfig(inputFilters, outputFilters)
+	if err != nil {
+		log.Printf("E! Error reloading config, keeping the current one: %s\n", err)
+		return
+	}
+	if err := ag.Reload(c); err != nil {
+		log.Printf("E! Error reloading config: %s\n", err)
+		return
+	}
+	log.Printf("I! Loaded outputs: %s", strings.Join(c.OutputNames(), " "))
+	log.Printf("I! Loaded inputs: %s", strings.Join(c.InputNames(), " "))
+	log.Printf("I! Tags enabled: %s", c.ListTags())
+}
+
 func reloadLoop(
 	stop chan struct{},
 	inputFilters []string,
@@ -123,112 +215,123 @@ func reloadLoop(
 	aggregatorFilters []string,
 	processorFilters []string,
 ) {
-	reload := make(chan bool, 1)
-	reload <- true
-	for <-reload {
-		reload <- false
-
-		// If no other options are specified, load the config file and run.
-		c := config.NewConfig()
-		c.OutputFilters = outputFilters
-		c.InputFilters = inputFilters
-		err := c.LoadConfig(*fConfig)
-		if err != nil {
-			log.Fatal("E! " + err.Error())
-		}
-
-		if *fConfigDirectory != "" {
-			err = c.LoadDirectory(*fConfigDirectory)
-			if err != nil {
-				log.Fatal("E! " + err.Error())
-			}
-		}
-		if !*fTest && len(c.Outputs) == 0 {
-			log.Fatalf("E! Error: no outputs found, did you provide a valid config file?")
-		}
-		if len(c.Inputs) == 0 {
-			log.Fatalf("E! Error: no inputs found, did you provide a valid config file?")
-		}
+	// If no other options are specified, load the config file and run.
+	c, err := loadConfig(inputFilters, outputFilters)
+	if err != nil {
+		log.Fatal("E! " + err.Error())
+	}
 
-		if int64(c.Agent.Interval.Duration) <= 0 {
-			log.Fatalf("E! Agent interval must be positive, found %s",
-				c.Agent.Interval.Duration)
-		}
+	ag, err := agent.NewAgent(c)
+	if err != nil {
+		log.Fatal("E! " + err.Error())
+	}
 
-		if int64(c.Agent.FlushInterval.Duration) <= 0 {
-			log.Fatalf("E! Agent flush_interval must be positive; found %s",
-				c.Agent.Interval.Duration)
-		}
+	// Setup logging
+	logger.SetupLogging(
+		ag.Config.Agent.Debug || *fDebug,
+		ag.Config.Agent.Quiet || *fQuiet,
+		ag.Config.Agent.Logfile,
+	)
 
-		ag, err := agent.NewAgent(c)
+	if *fTest {
+		err = ag.Test()
 		if err != nil {
 			log.Fatal("E! " + err.Error())
 		}
+		os.Exit(0)
+	}
 
-		// Setup logging
-		logger.SetupLogging(
-			ag.Config.Agent.Debug || *fDebug,
-			ag.Config.Agent.Quiet || *fQuiet,
-			ag.Config.Agent.Logfile,
-		)
+	err = ag.Connect()
+	if err != nil {
+		log.Fatal("E! " + err.Error())
+	}
 
-		if *fTest {
-			err = ag.Test()
-			if err != nil {
-				log.Fatal("E! " + err.Error())
-			}
-			os.Exit(0)
-		}
+	if *fOnce {
+		runOnce(ag)
+		os.Exit(0)
+	}
 
-		err = ag.Connect()
-		if err != nil {
-			log.Fatal("E! " + err.Error())
+	shutdown := make(chan struct{})
+
+	// changes stays nil, and never fires, unless the config files are
+	// watched.
+	var changes chan struct{}
+	if *fWatchConfig {
+		var files []string
+		if *fConfig != "" {
+			files = append(files, *fConfig)
 		}
+		w := config.NewWatcher(files, *fConfigDirectory, watchInterval)
+		changes = w.C
+		go w.Run(shutdown)
+	}
 
-		shutdown := make(chan struct{})
-		signals := make(chan os.Signal)
-		signal.Notify(signals, os.Interrupt, syscall.SIGHUP)
-		go func() {
+	signals := make(chan os.Signal)
+	signal.Notify(signals, os.Interrupt, syscall.SIGHUP)
+	go func() {
+		for {
 			select {
 			case sig := <-signals:
 				if sig == os.Interrupt {
 					close(shutdown)
+					return
 				}
 				if sig == syscall.SIGHUP {
 					log.Printf("I! Reloading Telegraf config\n")
-					<-reload
-					reload <- true
-					close(shutdown)
+					reloadConfig(ag, inputFilters, outputFilters)
 				}
+			case <-changes:
+				log.Printf("I! Config files changed, reloading Telegraf config\n")
+				reloadConfig(ag, inputFilters, outputFilters)
 			case <-stop:
 				close(shutdown)
+				return
 			}
-		}()
+		}
+	}()
 
-		log.Printf("I! Starting Telegraf %s\n", displayVersion())
-		log.Printf("I! Loaded outputs: %s", strings.Join(c.OutputNames(), " "))
-		log.Printf("I! Loaded inputs: %s", strings.Join(c.InputNames(), " "))
-		log.Printf("I! Tags enabled: %s", c.ListTags())
+	log.Printf("I! Starting Telegraf %s\n", displayVersion())
+	log.Printf("I! Loaded outputs: %s", strings.Join(c.OutputNames(), " "))
+	log.Printf("I! Loaded inputs: %s", strings.Join(c.InputNames(), " "))
+	log.Printf("I! Tags enabled: %s", c.ListTags())
 
-		if *fPidfile != "" {
-			f, err := os.OpenFile(*fPidfile, os.O_CREATE|os.O_WRONLY, 0644)
-			if err != nil {
-				log.Printf("E! Unable to create pidfile: %s", err)
-			} else {
-				fmt.Fprintf(f, "%d\n", os.Getpid())
-
-				f.Close()
-
-				defer func() {
-					err := os.Remove(*fPidfile)
-					if err != nil {
-						log.Printf("E! Unable to remove pidfile: %s", err)
-					}
-				}()
-			}
+	if *fPidfile != "" {
+		f, err := os.OpenFile(*fPidfile, os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			log.Printf("E! Unable to create pidfile: %s", err)
+		} else {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+
+			f.Close()
+
+			defer func() {
+				err := os.Remove(*fPidfile)
+				if err != nil {
+					log.Printf("E! Unable to remove pidfile: %s", err)
+				}
+			}()
 		}
+	}
+
+	if err := ag.Run(shutdown); err != nil {
+		log.Fatal("E! " + err.Error())
+	}
+}
 
-		ag.Run(shutdown)
+// runOnce runs the agent a single time, and exits with an error if it could
+// not write to all the outputs. It is interrupted by SIGINT.
+func runOnce(ag *agent.Agent) {
+	shutdown := make(chan struct{})
+	signals := make(chan os.Signal)
+	signal.Notify(signals, os.Interrupt)
+	go func() {
+		<-signals
+		close(shutdown)
+	}()
+
+	log.Printf("I! Running Telegraf %s once\n", displayVersion())
+	if err := ag.Once(shutdown); err != nil {
+		log.Fatal("E! " + err.Error())
 	}
 }
 
@@ -314,6 +417,9 @@ func main() {
 			fmt.Printf("Telegraf %s (git: %s %s)\n", displayVersion(), branch, commit)
 			return
 		case "config":
+			if len(args) > 1 && args[1] == "validate" {
+				os.Exit(validateConfig())
+			}
 			config.PrintSampleConfig(
 				inputFilters,
 				outputFilters,
@@ -321,6 +427,11 @@ func main() {
 				processorFilters,
 			)
 			return
+		case "secrets":
+			if err := secretsCommand(args[1:]); err != nil {
+				log.Fatal("E! " + err.Error())
+			}
+			return
 		}
 	}
 
@@ -341,6 +452,8 @@ func main() {
 	case *fVersion:
 		fmt.Printf("Telegraf %s (git: %s %s)\n", displayVersion(), branch, commit)
 		return
+	case *fCheckConfig:
+		os.Exit(validateConfig())
 	case *fSampleConfig:
 		config.PrintSampleConfig(
 			inputFilters,
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/influxdata/telegraf/internal/config"
+)
+
+// validateConfig checks the config file and directory for mistakes, without
+// starting any plugin, and prints them. It returns the exit code: 1 if the
+// configuration has any mistake, 0 otherwise.
+func validateConfig() int {
+	problems, err := config.Validate(*fConfig, *fConfigDirectory)
+	for _, problem := range problems {
+		fmt.Println(problem)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if len(problems) > 0 {
+		fmt.Fprintf(os.Stderr, "Found %d problems in the configuration\n",
+			len(problems))
+		return 1
+	}
+	fmt.Println("The configuration is valid")
+	return 0
+}
@@ -0,0 +1,692 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Expression is a condition on a metric, ie:
+//
+//   usage_idle <= 99 and tags.cpu != "cpu-total"
+//   fields.status == "error" or time < now() - 1h
+//
+// The operands are:
+//
+//   name            the measurement name
+//   time            the metric time, compared to times or RFC3339 strings
+//   now()           the time the expression is evaluated
+//   tags.<key>      a tag, or tags["<key>"] for any key
+//   fields.<key>    a field, or fields["<key>"] for any key
+//   <key>           a field, if the key is none of the above
+//   literals        "strings", numbers, true, false, and durations like 5m
+//
+// They are compared with ==, !=, <, <=, >, >=, and matched against regular
+// expressions, written /^web-\d+$/ or as strings, with =~ and !~. A duration
+// can be added to or subtracted from a time. Conditions are combined with
+// and, or, not, or &&, ||, !, and grouped with parentheses. A single operand
+// is true if it is the boolean true.
+//
+// A comparison with a missing tag or field, or between values of different
+// types, is false. Tag and string field values are compared as numbers to
+// numbers when they can be parsed.
+type Expression struct {
+	source string
+	root   node
+}
+
+// CompileExpression parses an expression.
+func CompileExpression(source string) (*Expression, error) {
+	p := &exprParser{source: source}
+	if err := p.tokenize(); err != nil {
+		return nil, err
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, p.errorf(tok, "unexpected %q", tok.text)
+	}
+	return &Expression{source: source, root: root}, nil
+}
+
+// Match returns true if the metric with the given name, tags, fields and
+// time satisfies the expression.
+func (e *Expression) Match(
+	name string,
+	tags map[string]string,
+	fields map[string]interface{},
+	t time.Time,
+) bool {
+	return e.root.eval(&exprEnv{
+		name:   name,
+		tags:   tags,
+		fields: fields,
+		time:   t,
+		now:    time.Now(),
+	})
+}
+
+func (e *Expression) String() string {
+	return e.source
+}
+
+type exprEnv struct {
+	name   string
+	tags   map[string]string
+	fields map[string]interface{}
+	time   time.Time
+	now    time.Time
+}
+
+// node is a condition of the expression.
+type node interface {
+	eval(env *exprEnv) bool
+}
+
+type orNode struct{ left, right node }
+
+func (n *orNode) eval(env *exprEnv) bool {
+	return n.left.eval(env) || n.right.eval(env)
+}
+
+type andNode struct{ left, right node }
+
+func (n *andNode) eval(env *exprEnv) bool {
+	return n.left.eval(env) && n.right.eval(env)
+}
+
+type notNode struct{ operand node }
+
+func (n *notNode) eval(env *exprEnv) bool {
+	return !n.operand.eval(env)
+}
+
+// truthNode is an operand used as a condition.
+type truthNode struct{ operand operand }
+
+func (n *truthNode) eval(env *exprEnv) bool {
+	b, ok := n.operand.value(env).(bool)
+	return ok && b
+}
+
+type compareNode struct {
+	op          string
+	left, right operand
+	// re is the compiled regular expression of a literal right operand of
+	// =~ and !~.
+	re *regexp.Regexp
+}
+
+func (n *compareNode) eval(env *exprEnv) bool {
+	left := n.left.value(env)
+	if left == nil {
+		return false
+	}
+	if n.re != nil {
+		s, ok := left.(string)
+		if !ok {
+			return false
+		}
+		return n.re.MatchString(s) == (n.op == "=~")
+	}
+
+	right := n.right.value(env)
+	if right == nil {
+		return false
+	}
+	c, ok := compareValues(left, right)
+	if !ok {
+		return false
+	}
+	switch n.op {
+	case "==":
+		return c == 0
+	case "!=":
+		return c != 0
+	}
+	if _, ok := left.(bool); ok {
+		// booleans are not ordered
+		return false
+	}
+	switch n.op {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	}
+	return false
+}
+
+// compareValues returns -1, 0 or 1 if a is less than, equal to or greater
+// than b, after converting them to the same type. It returns false if they
+// cannot be compared. Booleans are only equal, 0, or not, 1.
+func compareValues(a, b interface{}) (int, bool) {
+	switch av := a.(type) {
+	case string:
+		switch bv := b.(type) {
+		case string:
+			return strings.Compare(av, bv), true
+		case float64:
+			f, err := strconv.ParseFloat(av, 64)
+			if err != nil {
+				return 0, false
+			}
+			return compareFloats(f, bv), true
+		case time.Time:
+			t, err := time.Parse(time.RFC3339Nano, av)
+			if err != nil {
+				return 0, false
+			}
+			return compareTimes(t, bv), true
+		}
+	case float64:
+		switch bv := b.(type) {
+		case float64:
+			return compareFloats(av, bv), true
+		case string:
+			c, ok := compareValues(bv, av)
+			return -c, ok
+		}
+	case bool:
+		if bv, ok := b.(bool); ok {
+			if av == bv {
+				return 0, true
+			}
+			return 1, true
+		}
+	case time.Time:
+		switch bv := b.(type) {
+		case time.Time:
+			return compareTimes(av, bv), true
+		case string:
+			c, ok := compareValues(bv, av)
+			return -c, ok
+		}
+	case time.Duration:
+		if bv, ok := b.(time.Duration); ok {
+			return compareFloats(float64(av), float64(bv)), true
+		}
+	}
+	return 0, false
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareTimes(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	}
+	return 0
+}
+
+// operand is a value of the expression. Its value is a string, float64,
+// bool, time.Time or time.Duration, or nil if it is missing.
+type operand interface {
+	value(env *exprEnv) interface{}
+}
+
+type literal struct{ v interface{} }
+
+func (o *literal) value(env *exprEnv) interface{} { return o.v }
+
+type nameRef struct{}
+
+func (o *nameRef) value(env *exprEnv) interface{} { return env.name }
+
+type timeRef struct{}
+
+func (o *timeRef) value(env *exprEnv) interface{} { return env.time }
+
+type nowRef struct{}
+
+func (o *nowRef) value(env *exprEnv) interface{} { return env.now }
+
+type tagRef struct{ key string }
+
+func (o *tagRef) value(env *exprEnv) interface{} {
+	if v, ok := env.tags[o.key]; ok {
+		return v
+	}
+	return nil
+}
+
+type fieldRef struct{ key string }
+
+func (o *fieldRef) value(env *exprEnv) interface{} {
+	switch v := env.fields[o.key].(type) {
+	case string, bool, float64:
+		return v
+	case float32:
+		return float64(v)
+	case int:
+		return float64(v)
+	case int8:
+		return float64(v)
+	case int16:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint:
+		return float64(v)
+	case uint8:
+		return float64(v)
+	case uint16:
+		return float64(v)
+	case uint32:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	}
+	return nil
+}
+
+// arithmetic adds or subtracts durations to times, durations and numbers.
+type arithmetic struct {
+	op          byte
+	left, right operand
+}
+
+func (o *arithmetic) value(env *exprEnv) interface{} {
+	left, right := o.left.value(env), o.right.value(env)
+	sign := 1.0
+	if o.op == '-' {
+		sign = -1
+	}
+	switch l := left.(type) {
+	case time.Time:
+		if r, ok := right.(time.Duration); ok {
+			return l.Add(time.Duration(sign * float64(r)))
+		}
+	case time.Duration:
+		if r, ok := right.(time.Duration); ok {
+			return l + time.Duration(sign*float64(r))
+		}
+	case float64:
+		if r, ok := right.(float64); ok {
+			return l + sign*r
+		}
+	}
+	return nil
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokRegex
+	tokNumber
+	tokDuration
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	// value is the parsed value of literals.
+	value interface{}
+	pos   int
+}
+
+type exprParser struct {
+	source string
+	tokens []token
+	next   int
+}
+
+func (p *exprParser) errorf(tok token, format string, args ...interface{}) error {
+	return fmt.Errorf("invalid expression %q at position %d: %s",
+		p.source, tok.pos+1, fmt.Sprintf(format, args...))
+}
+
+// operators are the operator tokens, longest first.
+var operators = []string{
+	"==", "!=", "<=", ">=", "=~", "!~", "&&", "||",
+	"<", ">", "!", "(", ")", "[", "]", "+", "-",
+}
+
+func (p *exprParser) tokenize() error {
+	src := p.source
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"' || c == '\'':
+			s, n, err := unquote(src[i:])
+			if err != nil {
+				return p.errorf(token{pos: i}, "%s", err)
+			}
+			p.tokens = append(p.tokens, token{kind: tokString, text: src[i : i+n], value: s, pos: i})
+			i += n
+		case c == '/' && p.expectsOperand():
+			s, n, err := unquote(src[i:])
+			if err != nil {
+				return p.errorf(token{pos: i}, "%s", err)
+			}
+			p.tokens = append(p.tokens, token{kind: tokRegex, text: src[i : i+n], value: s, pos: i})
+			i += n
+		case c >= '0' && c <= '9' || c == '.':
+			j := i
+			for j < len(src) && (isIdentChar(src[j]) || src[j] == '.') {
+				j++
+			}
+			tok := token{text: src[i:j], pos: i}
+			if strings.IndexFunc(tok.text, unicode.IsLetter) >= 0 {
+				d, err := time.ParseDuration(tok.text)
+				if err != nil {
+					return p.errorf(tok, "invalid duration %q", tok.text)
+				}
+				tok.kind, tok.value = tokDuration, d
+			} else {
+				f, err := strconv.ParseFloat(tok.text, 64)
+				if err != nil {
+					return p.errorf(tok, "invalid number %q", tok.text)
+				}
+				tok.kind, tok.value = tokNumber, f
+			}
+			p.tokens = append(p.tokens, tok)
+			i = j
+		case isIdentChar(src[i]):
+			j := i
+			for j < len(src) && (isIdentChar(src[j]) || src[j] == '.') {
+				j++
+			}
+			p.tokens = append(p.tokens, token{kind: tokIdent, text: src[i:j], pos: i})
+			i = j
+		default:
+			var op string
+			for _, o := range operators {
+				if strings.HasPrefix(src[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return p.errorf(token{pos: i}, "unexpected %q", src[i])
+			}
+			p.tokens = append(p.tokens, token{kind: tokOp, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	p.tokens = append(p.tokens, token{kind: tokEOF, text: "end of expression", pos: len(src)})
+	return nil
+}
+
+// expectsOperand returns true if the next token must start an operand, so
+// that a / starts a regular expression.
+func (p *exprParser) expectsOperand() bool {
+	if len(p.tokens) == 0 {
+		return true
+	}
+	last := p.tokens[len(p.tokens)-1]
+	return last.kind == tokOp && last.text != ")" && last.text != "]"
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' ||
+		c >= '0' && c <= '9'
+}
+
+// unquote returns the string delimited by the first character of s, in which
+// a backslash escapes the delimiter, and the length of the quoted string.
+// Other backslashes are kept in regular expressions, and interpreted as in Go
+// strings otherwise.
+func unquote(s string) (string, int, error) {
+	delim := s[0]
+	var buf []byte
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case delim:
+			return string(buf), i + 1, nil
+		case '\\':
+			if i+1 >= len(s) {
+				break
+			}
+			i++
+			switch {
+			case s[i] == delim:
+				buf = append(buf, delim)
+			case delim == '/':
+				buf = append(buf, '\\', s[i])
+			default:
+				r, _, tail, err := strconv.UnquoteChar(s[i-1:], delim)
+				if err != nil {
+					return "", 0, err
+				}
+				buf = append(buf, string(r)...)
+				// skip the rest of the escape sequence
+				i = len(s) - len(tail) - 1
+			}
+		default:
+			buf = append(buf, s[i])
+		}
+	}
+	return "", 0, fmt.Errorf("missing closing %c", delim)
+}
+
+func (p *exprParser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *exprParser) advance() token {
+	tok := p.tokens[p.next]
+	if tok.kind != tokEOF {
+		p.next++
+	}
+	return tok
+}
+
+// accept consumes the next token if it is one of the given operators or
+// keywords.
+func (p *exprParser) accept(texts ...string) (token, bool) {
+	tok := p.peek()
+	if tok.kind != tokOp && tok.kind != tokIdent {
+		return tok, false
+	}
+	for _, text := range texts {
+		if tok.text == text {
+			return p.advance(), true
+		}
+	}
+	return tok, false
+}
+
+func (p *exprParser) expect(text string) error {
+	if tok, ok := p.accept(text); !ok {
+		return p.errorf(tok, "expected %q, found %q", text, tok.text)
+	}
+	return nil
+}
+
+func (p *exprParser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("or", "||"); !ok {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orNode{left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("and", "&&"); !ok {
+			return left, nil
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &andNode{left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseNot() (node, error) {
+	if _, ok := p.accept("not", "!"); ok {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{operand: operand}, nil
+	}
+	if _, ok := p.accept("("); ok {
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return n, p.expect(")")
+	}
+	return p.parseComparison()
+}
+
+func (p *exprParser) parseComparison() (node, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	opTok, ok := p.accept("==", "!=", "<", "<=", ">", ">=", "=~", "!~")
+	if !ok {
+		return &truthNode{operand: left}, nil
+	}
+
+	n := &compareNode{op: opTok.text, left: left}
+	if n.op == "=~" || n.op == "!~" {
+		tok := p.advance()
+		if tok.kind != tokRegex && tok.kind != tokString {
+			return nil, p.errorf(tok, "expected a regular expression, found %q",
+				tok.text)
+		}
+		n.re, err = regexp.Compile(tok.value.(string))
+		if err != nil {
+			return nil, p.errorf(tok, "%s", err)
+		}
+		return n, nil
+	}
+
+	n.right, err = p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+func (p *exprParser) parseOperand() (operand, error) {
+	left, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok, ok := p.accept("+", "-")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		left = &arithmetic{op: tok.text[0], left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseValue() (operand, error) {
+	tok := p.advance()
+	switch tok.kind {
+	case tokString, tokNumber, tokDuration:
+		return &literal{v: tok.value}, nil
+	case tokOp:
+		if tok.text != "-" {
+			break
+		}
+		// negative number or duration
+		next := p.advance()
+		switch v := next.value.(type) {
+		case float64:
+			return &literal{v: -v}, nil
+		case time.Duration:
+			return &literal{v: -v}, nil
+		}
+		return nil, p.errorf(next, "expected a number, found %q", next.text)
+	case tokIdent:
+		return p.parseIdent(tok)
+	}
+	return nil, p.errorf(tok, "expected a value, found %q", tok.text)
+}
+
+func (p *exprParser) parseIdent(tok token) (operand, error) {
+	switch tok.text {
+	case "true":
+		return &literal{v: true}, nil
+	case "false":
+		return &literal{v: false}, nil
+	case "name":
+		return &nameRef{}, nil
+	case "time":
+		return &timeRef{}, nil
+	case "now":
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		return &nowRef{}, p.expect(")")
+	case "and", "or", "not":
+		return nil, p.errorf(tok, "expected a value, found %q", tok.text)
+	case "tags", "fields":
+		// tags["key"]
+		if err := p.expect("["); err != nil {
+			return nil, err
+		}
+		key := p.advance()
+		if key.kind != tokString {
+			return nil, p.errorf(key, "expected a quoted key, found %q", key.text)
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		if tok.text == "tags" {
+			return &tagRef{key: key.value.(string)}, nil
+		}
+		return &fieldRef{key: key.value.(string)}, nil
+	}
+
+	if strings.HasPrefix(tok.text, "tags.") {
+		return &tagRef{key: strings.TrimPrefix(tok.text, "tags.")}, nil
+	}
+	if strings.HasPrefix(tok.text, "fields.") {
+		return &fieldRef{key: strings.TrimPrefix(tok.text, "fields.")}, nil
+	}
+	return &fieldRef{key: tok.text}, nil
+}
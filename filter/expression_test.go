@@ -0,0 +1,99 @@
+package filter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpression(t *testing.T) {
+	now := time.Now()
+	tags := map[string]string{
+		"cpu":  "cpu0",
+		"host": "web-12",
+		"port": "8080",
+	}
+	fields := map[string]interface{}{
+		"usage_idle": 42.5,
+		"count":      int64(10),
+		"status":     "error",
+		"up":         true,
+		"free space": uint64(100),
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`usage_idle < 50`, true},
+		{`usage_idle >= 50`, false},
+		{`fields.count == 10`, true},
+		{`count != 10`, false},
+		{`fields["free space"] > 99`, true},
+		{`status == "error"`, true},
+		{`status == 'ok'`, false},
+		{`up`, true},
+		{`!up`, false},
+		{`up == true`, true},
+		{`up < true`, false},
+		{`name == "cpu"`, true},
+		{`name != "cpu"`, false},
+		{`tags.cpu == "cpu0"`, true},
+		{`tags["host"] =~ /^web-\d+$/`, true},
+		{`tags.host !~ "^web-"`, false},
+		{`tags.port > 1024`, true},
+		{`tags.port < 1024`, false},
+		{`usage_idle < 50 and tags.cpu != "cpu-total"`, true},
+		{`usage_idle < 50 && tags.cpu == "cpu-total"`, false},
+		{`usage_idle > 50 or status == "error"`, true},
+		{`usage_idle > 50 || status == "ok"`, false},
+		{`not (usage_idle > 50 or status == "ok")`, true},
+		{`usage_idle < 50 and (count > 20 or up)`, true},
+		{`time > now() - 1h`, true},
+		{`time < now() - 1h`, false},
+		{`time >= "2000-01-01T00:00:00Z"`, true},
+		{`usage_idle + 10 > 50`, true},
+		{`missing == 0`, false},
+		{`missing != 0`, false},
+		{`tags.missing != "x"`, false},
+		{`not missing`, true},
+		{`status < 1`, false},
+		{`up == 1`, false},
+	}
+	for _, tt := range tests {
+		e, err := CompileExpression(tt.expr)
+		require.NoError(t, err, tt.expr)
+		assert.Equal(t, tt.want, e.Match("cpu", tags, fields, now), tt.expr)
+		assert.Equal(t, tt.expr, e.String())
+	}
+}
+
+func TestExpressionTime(t *testing.T) {
+	e, err := CompileExpression(`time < now() - 5m`)
+	require.NoError(t, err)
+
+	assert.True(t, e.Match("m", nil, nil, time.Now().Add(-time.Hour)))
+	assert.False(t, e.Match("m", nil, nil, time.Now()))
+}
+
+func TestCompileExpressionErrors(t *testing.T) {
+	tests := []string{
+		``,
+		`usage_idle <`,
+		`usage_idle < 50 and`,
+		`(usage_idle < 50`,
+		`usage_idle < 50)`,
+		`status == "error`,
+		`tags.host =~ /[/`,
+		`tags.host =~ "["`,
+		`tags[host] == "a"`,
+		`usage_idle <> 50`,
+		`now( == 1`,
+	}
+	for _, expr := range tests {
+		_, err := CompileExpression(expr)
+		assert.Error(t, err, expr)
+	}
+}
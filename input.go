@@ -1,5 +1,7 @@
 package telegraf
 
+import "context"
+
 type Input interface {
 	// SampleConfig returns the default configuration of the Input
 	SampleConfig() string
@@ -12,6 +14,17 @@ type Input interface {
 	Gather(Accumulator) error
 }
 
+// ContextInput is an Input whose gathering can be cancelled. The agent calls
+// GatherContext instead of Gather, and cancels the context once the
+// gather_timeout of the input is reached or when Telegraf stops. GatherContext should then return as soon as possible.
+type ContextInput interface {
+	Input
+
+	// GatherContext takes in an accumulator and adds the metrics that the
+	// Input gathers, until the context is done.
+	GatherContext(context.Context, Accumulator) error
+}
+
 type ServiceInput interface {
 	// SampleConfig returns the default configuration of the Input
 	SampleConfig() string
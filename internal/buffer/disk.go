@@ -0,0 +1,665 @@
+package buffer
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+const (
+	// Default maximum number of bytes kept on disk by a DiskBuffer.
+	DEFAULT_DISK_BUFFER_MAX_SIZE = 1024 * 1024 * 1024
+
+	// Maximum size of a single segment file.
+	maxSegmentSize = 16 * 1024 * 1024
+
+	segmentExt = ".seg"
+	cursorFile = "cursor"
+
+	// length (4 bytes) + crc32 (4 bytes)
+	recordHeaderSize = 8
+)
+
+var (
+	errCorrupt  = errors.New("corrupt record")
+	errChecksum = errors.New("record checksum mismatch")
+)
+
+// FsyncPolicy controls when a DiskBuffer flushes its files to stable storage.
+type FsyncPolicy int
+
+const (
+	// FsyncFlush syncs once per output flush, when Sync is called.
+	FsyncFlush FsyncPolicy = iota
+	// FsyncAlways syncs after every call to Add.
+	FsyncAlways
+	// FsyncNever leaves syncing to the operating system.
+	FsyncNever
+)
+
+// ParseFsyncPolicy returns the FsyncPolicy named by s. The empty string
+// selects the default policy, "flush".
+func ParseFsyncPolicy(s string) (FsyncPolicy, error) {
+	switch s {
+	case "", "flush":
+		return FsyncFlush, nil
+	case "always":
+		return FsyncAlways, nil
+	case "never":
+		return FsyncNever, nil
+	default:
+		return FsyncFlush, fmt.Errorf("invalid fsync policy %q, must be one of "+
+			"\"always\", \"flush\" or \"never\"", s)
+	}
+}
+
+type segment struct {
+	seq   uint64
+	size  int64
+	count int
+}
+
+type position struct {
+	seq uint64
+	off int64
+}
+
+// peekEntry is the position following a metric returned by Peek, and the
+// number of records, including any skipped ones, that it accounts for in its
+// segment.
+type peekEntry struct {
+	pos     position
+	records int
+}
+
+// DiskBuffer is a persistent FIFO queue of metrics, stored as a series of
+// append-only segment files in a directory. Metrics are read with Peek and
+// are only removed from the queue once Remove is called, so that a batch
+// that fails to be written is not lost, even if telegraf is restarted.
+//
+// Each record is stored as a 4-byte length, a 4-byte CRC32 checksum and a
+// payload made of the metric value type followed by its line-protocol
+// serialization.
+type DiskBuffer struct {
+	dir     string
+	maxSize int64
+	segSize int64
+	fsync   FsyncPolicy
+
+	mu       sync.Mutex
+	segments []*segment
+	w        *os.File
+	bw       *bufio.Writer
+
+	// read position, and number of records before it in the head segment.
+	cursor   position
+	consumed int
+	// entries for each metric returned by the last call to Peek.
+	peeked []peekEntry
+
+	len  int
+	size int64
+}
+
+// NewDiskBuffer opens, or creates, a DiskBuffer in the directory dir.
+// maxSize is the maximum number of bytes that the buffer will keep on disk. If
+// Add is called when the buffer is full, then the oldest segment of metrics
+// will be dropped. If maxSize is 0, the default is used.
+func NewDiskBuffer(dir string, maxSize int64, fsync FsyncPolicy) (*DiskBuffer, error) {
+	if maxSize <= 0 {
+		maxSize = DEFAULT_DISK_BUFFER_MAX_SIZE
+	}
+	segSize := int64(maxSegmentSize)
+	if maxSize/4 < segSize {
+		segSize = maxSize / 4
+	}
+
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, err
+	}
+
+	b := &DiskBuffer{
+		dir:     dir,
+		maxSize: maxSize,
+		segSize: segSize,
+		fsync:   fsync,
+	}
+	if err := b.open(); err != nil {
+		return nil, fmt.Errorf("could not open disk buffer %s: %s", dir, err)
+	}
+	return b, nil
+}
+
+// Len returns the number of metrics in the buffer.
+func (b *DiskBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.len
+}
+
+// IsEmpty returns true if the buffer is empty.
+func (b *DiskBuffer) IsEmpty() bool {
+	return b.Len() == 0
+}
+
+// Size returns the number of bytes used on disk by the metrics in the
+// buffer.
+func (b *DiskBuffer) Size() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.size
+}
+
+// Add appends metrics to the buffer.
+func (b *DiskBuffer) Add(metrics ...telegraf.Metric) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var payload []byte
+	var header [recordHeaderSize]byte
+	for _, m := range metrics {
+		MetricsWritten.Incr(1)
+
+		payload = append(payload[:0], byte(m.Type()))
+		payload = append(payload, m.Serialize()...)
+		binary.BigEndian.PutUint32(header[0:4], uint32(len(payload)))
+		binary.BigEndian.PutUint32(header[4:8], crc32.ChecksumIEEE(payload))
+
+		tail := b.segments[len(b.segments)-1]
+		if tail.size >= b.segSize {
+			if err := b.roll(); err != nil {
+				return err
+			}
+			tail = b.segments[len(b.segments)-1]
+		}
+
+		if _, err := b.bw.Write(header[:]); err != nil {
+			return err
+		}
+		if _, err := b.bw.Write(payload); err != nil {
+			return err
+		}
+		n := int64(recordHeaderSize + len(payload))
+		tail.size += n
+		tail.count++
+		b.size += n
+		b.len++
+
+		b.enforceMaxSize()
+	}
+
+	if err := b.bw.Flush(); err != nil {
+		return err
+	}
+	if b.fsync == FsyncAlways {
+		return b.w.Sync()
+	}
+	return nil
+}
+
+// Peek returns up to batchSize metrics from the front of the buffer without
+// removing them.
+func (b *DiskBuffer) Peek(batchSize int) ([]telegraf.Metric, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.peeked = b.peeked[:0]
+	out := make([]telegraf.Metric, 0, min(b.len, batchSize))
+	pos := b.cursor
+	for _, seg := range b.segments {
+		if len(out) == batchSize {
+			break
+		}
+		if seg.seq < pos.seq {
+			continue
+		}
+		if seg.seq > pos.seq {
+			pos = position{seq: seg.seq}
+		}
+		if pos.off >= seg.size {
+			continue
+		}
+
+		f, err := os.Open(b.segmentPath(seg.seq))
+		if err != nil {
+			return out, err
+		}
+		if _, err := f.Seek(pos.off, io.SeekStart); err != nil {
+			f.Close()
+			return out, err
+		}
+		r := bufio.NewReader(io.LimitReader(f, seg.size-pos.off))
+		// records of the segment before pos
+		before := 0
+		if seg.seq == b.cursor.seq {
+			before = b.consumed
+		}
+		skipped := 0
+		for len(out) < batchSize && pos.off < seg.size {
+			m, n, err := readRecord(r)
+			if err != nil {
+				// the records cannot be delimited past this one, so the
+				// segment is truncated for the metrics after it to be
+				// delivered.
+				if err := b.truncateSegment(seg, pos.off, before, err); err != nil {
+					f.Close()
+					return out, err
+				}
+				break
+			}
+			pos.off += n
+			before++
+			if m == nil {
+				log.Printf("E! Dropping corrupt or unparseable metric from disk buffer %s",
+					b.dir)
+				MetricsDropped.Incr(1)
+				if len(out) == 0 && pos.seq == b.cursor.seq {
+					// nothing depends on it, so consume it right away.
+					b.cursor = pos
+					b.consumed++
+					b.len--
+					b.size -= n
+				} else {
+					skipped++
+				}
+				continue
+			}
+			out = append(out, m)
+			b.peeked = append(b.peeked, peekEntry{pos: pos, records: skipped + 1})
+			skipped = 0
+		}
+		f.Close()
+	}
+	return out, nil
+}
+
+// truncateSegment removes the records of seg from offset off, count being
+// the number of records before it.
+func (b *DiskBuffer) truncateSegment(seg *segment, off int64, count int, cause error) error {
+	dropped := seg.count - count
+	path := b.segmentPath(seg.seq)
+	log.Printf("E! Truncating disk buffer segment %s at offset %d, dropping %d metrics: %s",
+		path, off, dropped, cause)
+	if err := os.Truncate(path, off); err != nil {
+		return err
+	}
+	MetricsDropped.Incr(int64(dropped))
+	b.len -= dropped
+	b.size -= seg.size - off
+	seg.size = off
+	seg.count = count
+	return nil
+}
+
+// Remove removes the first n metrics returned by the last call to Peek from
+// the buffer.
+func (b *DiskBuffer) Remove(n int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if n > len(b.peeked) {
+		n = len(b.peeked)
+	}
+	if n <= 0 {
+		return nil
+	}
+	next := b.peeked[n-1].pos
+
+	for len(b.segments) > 1 && b.segments[0].seq < next.seq {
+		head := b.segments[0]
+		b.len -= head.count - b.consumed
+		b.size -= head.size - b.cursor.off
+		b.consumed = 0
+		b.cursor = position{seq: b.segments[1].seq}
+		if err := os.Remove(b.segmentPath(head.seq)); err != nil {
+			return err
+		}
+		b.segments = b.segments[1:]
+	}
+
+	removed := 0
+	for _, e := range b.peeked[:n] {
+		if e.pos.seq == next.seq {
+			removed += e.records
+		}
+	}
+	b.len -= removed
+	b.consumed += removed
+	b.size -= next.off - b.cursor.off
+	b.cursor = next
+	b.peeked = b.peeked[:0]
+	return b.writeCursor()
+}
+
+// Sync flushes the active segment file to stable storage, unless the
+// buffer's FsyncPolicy is FsyncNever.
+func (b *DiskBuffer) Sync() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.fsync == FsyncNever {
+		return nil
+	}
+	return b.w.Sync()
+}
+
+// Close flushes and closes the buffer. Metrics remaining in the buffer will
+// be available when it is opened again.
+func (b *DiskBuffer) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.bw.Flush(); err != nil {
+		b.w.Close()
+		return err
+	}
+	if b.fsync != FsyncNever {
+		if err := b.w.Sync(); err != nil {
+			b.w.Close()
+			return err
+		}
+	}
+	return b.w.Close()
+}
+
+// enforceMaxSize drops the oldest segments until the buffer fits in maxSize.
+// The active segment is never dropped.
+func (b *DiskBuffer) enforceMaxSize() {
+	for b.size > b.maxSize && len(b.segments) > 1 {
+		head := b.segments[0]
+		dropped := head.count - b.consumed
+		MetricsDropped.Incr(int64(dropped))
+		log.Printf("W! Disk buffer %s is full, dropping %d metrics",
+			b.dir, dropped)
+
+		b.len -= dropped
+		b.size -= head.size - b.cursor.off
+		if err := os.Remove(b.segmentPath(head.seq)); err != nil {
+			log.Printf("E! Could not remove disk buffer segment: %s", err)
+		}
+		b.segments = b.segments[1:]
+		b.cursor = position{seq: b.segments[0].seq}
+		b.consumed = 0
+		b.peeked = b.peeked[:0]
+		if err := b.writeCursor(); err != nil {
+			log.Printf("E! Could not write disk buffer cursor: %s", err)
+		}
+	}
+}
+
+// roll closes the active segment and starts a new one.
+func (b *DiskBuffer) roll() error {
+	if err := b.bw.Flush(); err != nil {
+		return err
+	}
+	if b.fsync != FsyncNever {
+		if err := b.w.Sync(); err != nil {
+			return err
+		}
+	}
+	if err := b.w.Close(); err != nil {
+		return err
+	}
+	seq := b.segments[len(b.segments)-1].seq + 1
+	return b.openSegment(seq)
+}
+
+func (b *DiskBuffer) openSegment(seq uint64) error {
+	f, err := os.OpenFile(b.segmentPath(seq),
+		os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0640)
+	if err != nil {
+		return err
+	}
+	b.w = f
+	b.bw = bufio.NewWriter(f)
+	if len(b.segments) == 0 || b.segments[len(b.segments)-1].seq != seq {
+		b.segments = append(b.segments, &segment{seq: seq})
+	}
+	return nil
+}
+
+// open loads the existing segments and read cursor from disk, discarding
+// anything that was partially written when telegraf last stopped.
+func (b *DiskBuffer) open() error {
+	files, err := ioutil.ReadDir(b.dir)
+	if err != nil {
+		return err
+	}
+	var seqs []uint64
+	for _, fi := range files {
+		name := fi.Name()
+		if fi.IsDir() || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+
+	cursor, err := b.readCursor()
+	if err != nil {
+		return err
+	}
+
+	for _, seq := range seqs {
+		if seq < cursor.seq {
+			// fully consumed, but not cleaned up before exiting.
+			if err := os.Remove(b.segmentPath(seq)); err != nil {
+				return err
+			}
+			continue
+		}
+		seg, err := b.scanSegment(seq)
+		if err != nil {
+			return err
+		}
+		b.segments = append(b.segments, seg)
+		b.size += seg.size
+		b.len += seg.count
+	}
+
+	if len(b.segments) == 0 {
+		b.cursor = position{seq: cursor.seq}
+		if err := b.openSegment(cursor.seq); err != nil {
+			return err
+		}
+		return b.writeCursor()
+	}
+
+	head := b.segments[0]
+	if head.seq != cursor.seq || cursor.off > head.size {
+		cursor = position{seq: head.seq}
+	}
+	b.consumed, err = b.countRecords(head.seq, 0, cursor.off)
+	if err != nil {
+		return err
+	}
+	b.cursor = cursor
+	b.len -= b.consumed
+	b.size -= cursor.off
+
+	return b.openSegment(b.segments[len(b.segments)-1].seq)
+}
+
+// scanSegment counts the valid records in a segment file, truncating the
+// file after the last one.
+func (b *DiskBuffer) scanSegment(seq uint64) (*segment, error) {
+	path := b.segmentPath(seq)
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	seg := &segment{seq: seq}
+	r := bufio.NewReader(f)
+	for {
+		n, err := skipRecord(r)
+		if err == io.EOF {
+			return seg, nil
+		}
+		if err != nil {
+			log.Printf("W! Truncating disk buffer segment %s at offset %d: %s",
+				path, seg.size, err)
+			return seg, os.Truncate(path, seg.size)
+		}
+		seg.size += n
+		seg.count++
+	}
+}
+
+// countRecords returns the number of records between two offsets of a
+// segment.
+func (b *DiskBuffer) countRecords(seq uint64, from, to int64) (int, error) {
+	if from >= to {
+		return 0, nil
+	}
+	f, err := os.Open(b.segmentPath(seq))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(from, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	r := bufio.NewReader(io.LimitReader(f, to-from))
+	count := 0
+	for {
+		_, err := skipRecord(r)
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+}
+
+func (b *DiskBuffer) segmentPath(seq uint64) string {
+	return filepath.Join(b.dir, fmt.Sprintf("%020d%s", seq, segmentExt))
+}
+
+func (b *DiskBuffer) readCursor() (position, error) {
+	var pos position
+	buf, err := ioutil.ReadFile(filepath.Join(b.dir, cursorFile))
+	if os.IsNotExist(err) {
+		return pos, nil
+	}
+	if err != nil {
+		return pos, err
+	}
+	if _, err := fmt.Sscanf(string(buf), "%d %d", &pos.seq, &pos.off); err != nil {
+		log.Printf("W! Ignoring invalid disk buffer cursor in %s: %s", b.dir, err)
+		return position{}, nil
+	}
+	return pos, nil
+}
+
+// writeCursor atomically replaces the cursor file with the current read
+// position.
+func (b *DiskBuffer) writeCursor() error {
+	path := filepath.Join(b.dir, cursorFile)
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0640)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(f, "%d %d\n", b.cursor.seq, b.cursor.off); err != nil {
+		f.Close()
+		return err
+	}
+	if b.fsync == FsyncAlways {
+		if err := f.Sync(); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// readRecord reads a single record, returning the decoded metric and the
+// number of bytes read. The returned metric is nil if the payload of the
+// record does not match its checksum or could not be parsed.
+func readRecord(r *bufio.Reader) (telegraf.Metric, int64, error) {
+	payload, err := readPayload(r)
+	if err == errChecksum {
+		return nil, int64(recordHeaderSize + len(payload)), nil
+	}
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, 0, err
+	}
+	n := int64(recordHeaderSize + len(payload))
+
+	if len(payload) < 2 {
+		return nil, n, nil
+	}
+	metrics, err := metric.Parse(payload[1:])
+	if err != nil || len(metrics) != 1 {
+		return nil, n, nil
+	}
+	m := metrics[0]
+	if t := telegraf.ValueType(payload[0]); t != m.Type() {
+		m, err = metric.New(m.Name(), m.Tags(), m.Fields(), m.Time(), t)
+		if err != nil {
+			return nil, n, nil
+		}
+	}
+	return m, n, nil
+}
+
+// skipRecord validates a single record and returns its size. A record whose
+// payload does not match its checksum is dropped when it is read.
+func skipRecord(r *bufio.Reader) (int64, error) {
+	payload, err := readPayload(r)
+	if err != nil && err != errChecksum {
+		return 0, err
+	}
+	return int64(recordHeaderSize + len(payload)), nil
+}
+
+// readPayload reads the payload of a single record. The payload is returned
+// along with errChecksum if it does not match the checksum of the record.
+func readPayload(r *bufio.Reader) ([]byte, error) {
+	var header [recordHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, errCorrupt
+		}
+		return nil, err
+	}
+	length := binary.BigEndian.Uint32(header[0:4])
+	if length > maxSegmentSize {
+		return nil, errCorrupt
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, errCorrupt
+	}
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:8]) {
+		return payload, errChecksum
+	}
+	return payload, nil
+}
@@ -0,0 +1,252 @@
+package buffer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tempDiskBuffer(t *testing.T, maxSize int64) (*DiskBuffer, string) {
+	dir, err := ioutil.TempDir("", "telegraf-disk-buffer")
+	require.NoError(t, err)
+	b, err := NewDiskBuffer(dir, maxSize, FsyncFlush)
+	require.NoError(t, err)
+	return b, dir
+}
+
+func metricStrings(metrics []telegraf.Metric) []string {
+	var out []string
+	for _, m := range metrics {
+		out = append(out, m.String())
+	}
+	return out
+}
+
+func TestDiskBufferAddPeekRemove(t *testing.T) {
+	b, dir := tempDiskBuffer(t, 0)
+	defer os.RemoveAll(dir)
+	defer b.Close()
+
+	assert.True(t, b.IsEmpty())
+	require.NoError(t, b.Add(metricList...))
+	assert.Equal(t, 5, b.Len())
+	assert.True(t, b.Size() > 0)
+
+	batch, err := b.Peek(3)
+	require.NoError(t, err)
+	assert.Equal(t, metricStrings(metricList[:3]), metricStrings(batch))
+
+	// peeking again returns the same metrics
+	batch, err = b.Peek(3)
+	require.NoError(t, err)
+	assert.Equal(t, metricStrings(metricList[:3]), metricStrings(batch))
+	assert.Equal(t, 5, b.Len())
+
+	require.NoError(t, b.Remove(len(batch)))
+	assert.Equal(t, 2, b.Len())
+
+	batch, err = b.Peek(10)
+	require.NoError(t, err)
+	assert.Equal(t, metricStrings(metricList[3:]), metricStrings(batch))
+	require.NoError(t, b.Remove(len(batch)))
+	assert.True(t, b.IsEmpty())
+	assert.Equal(t, int64(0), b.Size())
+}
+
+func TestDiskBufferPreservesType(t *testing.T) {
+	b, dir := tempDiskBuffer(t, 0)
+	defer os.RemoveAll(dir)
+	defer b.Close()
+
+	m, err := metric.New("cpu", map[string]string{"host": "a"},
+		map[string]interface{}{"value": 42.0}, testutil.TestMetric(1).Time(),
+		telegraf.Counter)
+	require.NoError(t, err)
+	require.NoError(t, b.Add(m))
+
+	batch, err := b.Peek(1)
+	require.NoError(t, err)
+	require.Len(t, batch, 1)
+	assert.Equal(t, telegraf.Counter, batch[0].Type())
+	assert.Equal(t, m.String(), batch[0].String())
+}
+
+func TestDiskBufferReopen(t *testing.T) {
+	b, dir := tempDiskBuffer(t, 0)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, b.Add(metricList...))
+	batch, err := b.Peek(2)
+	require.NoError(t, err)
+	require.NoError(t, b.Remove(len(batch)))
+	require.NoError(t, b.Close())
+
+	b, err = NewDiskBuffer(dir, 0, FsyncFlush)
+	require.NoError(t, err)
+	defer b.Close()
+	assert.Equal(t, 3, b.Len())
+
+	require.NoError(t, b.Add(metricList[0]))
+	batch, err = b.Peek(10)
+	require.NoError(t, err)
+	expected := append(metricStrings(metricList[2:]), metricList[0].String())
+	assert.Equal(t, expected, metricStrings(batch))
+}
+
+func TestDiskBufferTruncatesPartialRecord(t *testing.T) {
+	b, dir := tempDiskBuffer(t, 0)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, b.Add(metricList...))
+	require.NoError(t, b.Close())
+
+	// simulate a crash in the middle of writing a record
+	path := filepath.Join(dir, "00000000000000000000.seg")
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0640)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{0, 0, 0, 42, 1, 2})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	b, err = NewDiskBuffer(dir, 0, FsyncFlush)
+	require.NoError(t, err)
+	defer b.Close()
+	assert.Equal(t, 5, b.Len())
+
+	require.NoError(t, b.Add(metricList[0]))
+	batch, err := b.Peek(10)
+	require.NoError(t, err)
+	assert.Len(t, batch, 6)
+}
+
+// recordOffset returns the offset of the record of metricList[i] in a
+// segment holding metricList.
+func recordOffset(i int) int64 {
+	var off int64
+	for _, m := range metricList[:i] {
+		off += int64(recordHeaderSize + 1 + len(m.Serialize()))
+	}
+	return off
+}
+
+// corruptSegment overwrites the segment at off with data.
+func corruptSegment(t *testing.T, path string, off int64, data []byte) {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0640)
+	require.NoError(t, err)
+	_, err = f.WriteAt(data, off)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+}
+
+func TestDiskBufferSkipsChecksumMismatch(t *testing.T) {
+	b, dir := tempDiskBuffer(t, 0)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, b.Add(metricList...))
+	require.NoError(t, b.Close())
+
+	// flip a byte of the payload of the third record
+	path := filepath.Join(dir, "00000000000000000000.seg")
+	corruptSegment(t, path, recordOffset(2)+recordHeaderSize+1, []byte{0xff})
+
+	b, err := NewDiskBuffer(dir, 0, FsyncFlush)
+	require.NoError(t, err)
+	defer b.Close()
+	assert.Equal(t, 5, b.Len())
+
+	batch, err := b.Peek(10)
+	require.NoError(t, err)
+	expected := append(metricStrings(metricList[:2]), metricStrings(metricList[3:])...)
+	assert.Equal(t, expected, metricStrings(batch))
+	require.NoError(t, b.Remove(len(batch)))
+	assert.True(t, b.IsEmpty())
+
+	require.NoError(t, b.Add(metricList[0]))
+	batch, err = b.Peek(10)
+	require.NoError(t, err)
+	assert.Equal(t, metricStrings(metricList[:1]), metricStrings(batch))
+}
+
+func TestDiskBufferTruncatesCorruptRecord(t *testing.T) {
+	b, dir := tempDiskBuffer(t, 0)
+	defer os.RemoveAll(dir)
+	defer b.Close()
+
+	require.NoError(t, b.Add(metricList...))
+
+	// the length of the third record is garbage, so the records after it
+	// cannot be read.
+	path := filepath.Join(dir, "00000000000000000000.seg")
+	corruptSegment(t, path, recordOffset(2), []byte{0xff, 0xff, 0xff, 0xff})
+
+	batch, err := b.Peek(10)
+	require.NoError(t, err)
+	assert.Equal(t, metricStrings(metricList[:2]), metricStrings(batch))
+	assert.Equal(t, 2, b.Len())
+	assert.Equal(t, recordOffset(2), b.Size())
+	require.NoError(t, b.Remove(len(batch)))
+	assert.True(t, b.IsEmpty())
+
+	// delivery goes on with the metrics added after
+	require.NoError(t, b.Add(metricList[3:]...))
+	batch, err = b.Peek(10)
+	require.NoError(t, err)
+	assert.Equal(t, metricStrings(metricList[3:]), metricStrings(batch))
+}
+
+func TestDiskBufferSegmentsAndMaxSize(t *testing.T) {
+	MetricsDropped.Set(0)
+	b, dir := tempDiskBuffer(t, 4096)
+	defer os.RemoveAll(dir)
+	defer b.Close()
+
+	m := testutil.TestMetric(1, "mymetric")
+	for i := 0; i < 200; i++ {
+		require.NoError(t, b.Add(m))
+	}
+
+	assert.True(t, b.Size() <= 4096)
+	assert.True(t, MetricsDropped.Get() > 0)
+	assert.Equal(t, 200, b.Len()+int(MetricsDropped.Get()))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.seg"))
+	require.NoError(t, err)
+	assert.True(t, len(files) > 1)
+
+	// drain across segment boundaries
+	total := 0
+	for !b.IsEmpty() {
+		batch, err := b.Peek(7)
+		require.NoError(t, err)
+		require.NotEmpty(t, batch)
+		require.NoError(t, b.Remove(len(batch)))
+		total += len(batch)
+	}
+	assert.Equal(t, 200-int(MetricsDropped.Get()), total)
+	assert.Equal(t, int64(0), b.Size())
+
+	files, err = filepath.Glob(filepath.Join(dir, "*.seg"))
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestParseFsyncPolicy(t *testing.T) {
+	p, err := ParseFsyncPolicy("")
+	require.NoError(t, err)
+	assert.Equal(t, FsyncFlush, p)
+
+	p, err = ParseFsyncPolicy("always")
+	require.NoError(t, err)
+	assert.Equal(t, FsyncAlways, p)
+
+	_, err = ParseFsyncPolicy("sometimes")
+	assert.Error(t, err)
+}
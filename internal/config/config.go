@@ -18,7 +18,9 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/buffer"
 	"github.com/influxdata/telegraf/internal/models"
+	"github.com/influxdata/telegraf/internal/retry"
 	"github.com/influxdata/telegraf/plugins/aggregators"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/outputs"
@@ -56,6 +58,21 @@ type Config struct {
 	Aggregators []*models.RunningAggregator
 	// Processors have a slice wrapper type because they need to be sorted
 	Processors models.RunningProcessors
+
+	// sources holds the TOML source of each plugin, used to find the
+	// plugins that are unchanged on reload.
+	sources map[interface{}]string
+
+	// secretStores holds the secret stores by id, to resolve the references
+	// to secrets in the config.
+	secretStores map[string]telegraf.SecretStore
+
+	// problems holds the mistakes found in the config that did not prevent
+	// it from loading.
+	problems []Problem
+	// validating is set by Validate, to collect the errors of the plugins
+	// as problems instead of failing.
+	validating bool
 }
 
 func NewConfig() *Config {
@@ -65,6 +82,9 @@ func NewConfig() *Config {
 			Interval:      internal.Duration{Duration: 10 * time.Second},
 			RoundInterval: true,
 			FlushInterval: internal.Duration{Duration: 10 * time.Second},
+
+			MetricChannelSize: 100,
+			ProcessorWorkers:  1,
 		},
 
 		Tags:          make(map[string]string),
@@ -73,6 +93,8 @@ func NewConfig() *Config {
 		Processors:    make([]*models.RunningProcessor, 0),
 		InputFilters:  make([]string, 0),
 		OutputFilters: make([]string, 0),
+		sources:       make(map[interface{}]string),
+		secretStores:  make(map[string]telegraf.SecretStore),
 	}
 	return c
 }
@@ -119,6 +141,16 @@ type AgentConfig struct {
 	// not be less than 2 times MetricBatchSize.
 	MetricBufferLimit int
 
+	// MetricChannelSize is the number of metrics that can be queued between
+	// each stage of the agent, from the inputs through the processors to the
+	// outputs, before the previous stage blocks.
+	MetricChannelSize int
+
+	// ProcessorWorkers is the number of goroutines that apply consecutive
+	// stateless processors. The metrics of a series are always processed by
+	// the same worker, so that their order is kept.
+	ProcessorWorkers int
+
 	// FlushBufferWhenFull tells Telegraf to flush the metric buffer whenever
 	// it fills up, regardless of FlushInterval. Setting this option to true
 	// does _not_ deactivate FlushInterval.
@@ -139,6 +171,23 @@ type AgentConfig struct {
 	Quiet        bool
 	Hostname     string
 	OmitHostname bool
+
+	// HealthServiceAddress is the address the HTTP health API listens on,
+	// ie ":8888". It is disabled when empty.
+	HealthServiceAddress string
+
+	// HealthMaxGatherAge is how long an input can go without gathering
+	// successfully before it is reported unhealthy. Zero disables the check.
+	HealthMaxGatherAge internal.Duration
+
+	// HealthMaxWriteAge is how long an output can go without writing its
+	// buffered metrics before it is reported unhealthy. Zero disables the
+	// check.
+	HealthMaxWriteAge internal.Duration
+
+	// HealthMaxBufferFullness is the fraction of its buffer, between 0 and 1,
+	// above which an output is reported unhealthy. Zero disables the check.
+	HealthMaxBufferFullness float64
 }
 
 // Inputs returns a list of strings of the configured inputs.
@@ -216,6 +265,13 @@ var header = `# Telegraf Configuration
   ## This buffer only fills when writes fail to output plugin(s).
   metric_buffer_limit = 10000
 
+  ## Number of metrics queued between the inputs, each stage of processors
+  ## and the outputs.
+  # metric_channel_size = 100
+  ## Number of workers applying the stateless processors, such as converter,
+  ## regex and rename. The metrics of a series keep their order.
+  # processor_workers = 1
+
   ## Collection jitter is used to jitter the collection by a random amount.
   ## Each plugin will sleep for a random time within jitter before collecting.
   ## This can be used to avoid many plugins querying things like sysfs at the
@@ -252,6 +308,18 @@ var header = `# Telegraf Configuration
   ## If set to true, do no set the "host" tag in the telegraf agent.
   omit_hostname = false
 
+  ## Address of the HTTP health API, which reports the state of inputs and
+  ## outputs on /health. It is disabled when empty.
+  # health_service_address = ":8888"
+  ## /health responds with 503 when an input has not gathered without error,
+  ## or an output has not written its metrics, for longer than these
+  ## durations. "0s" disables the checks.
+  # health_max_gather_age = "0s"
+  # health_max_write_age = "0s"
+  ## /health responds with 503 when an output buffer is fuller than this
+  ## fraction, between 0 and 1. 0 disables the check.
+  # health_max_buffer_fullness = 0.0
+
 
 ###############################################################################
 #                            OUTPUT PLUGINS                                   #
@@ -565,18 +633,55 @@ func (c *Config) LoadConfig(path string) error {
 	if err != nil {
 		return fmt.Errorf("Error parsing %s, %s", path, err)
 	}
+	start := len(c.problems)
+	defer func() {
+		for i := start; i < len(c.problems); i++ {
+			c.problems[i].File = path
+		}
+	}()
+
+	// Parse secret stores first, as the rest of the config can refer to
+	// their secrets:
+	if val, ok := tbl.Fields["secretstores"]; ok {
+		subTable, ok := val.(*ast.Table)
+		if !ok {
+			return fmt.Errorf("%s: invalid configuration", path)
+		}
+		for storeName, storeVal := range subTable.Fields {
+			switch storeSubTable := storeVal.(type) {
+			case []*ast.Table:
+				for _, t := range storeSubTable {
+					if err = c.addSecretStore(storeName, t); err != nil {
+						err = c.pluginError(path, "secretstores."+storeName, t, err)
+						if err != nil {
+							return err
+						}
+					}
+				}
+			default:
+				return fmt.Errorf("Unsupported config format: %s, file %s",
+					storeName, path)
+			}
+		}
+	}
 
-	// Parse tags tables first:
+	// Parse tags tables next:
 	for _, tableName := range []string{"tags", "global_tags"} {
 		if val, ok := tbl.Fields[tableName]; ok {
 			subTable, ok := val.(*ast.Table)
 			if !ok {
 				return fmt.Errorf("%s: invalid configuration", path)
 			}
-			if err = toml.UnmarshalTable(subTable, c.Tags); err != nil {
-				log.Printf("E! Could not parse [global_tags] config\n")
+			if _, err = c.resolveSecrets(subTable); err != nil {
 				return fmt.Errorf("Error parsing %s, %s", path, err)
 			}
+			c.checkKeys(tableName, subTable, c.Tags)
+			if err = toml.UnmarshalTable(subTable, c.Tags); err != nil {
+				if err = c.pluginError(path, tableName, subTable, err); err != nil {
+					log.Printf("E! Could not parse [global_tags] config\n")
+					return err
+				}
+			}
 		}
 	}
 
@@ -586,10 +691,16 @@ func (c *Config) LoadConfig(path string) error {
 		if !ok {
 			return fmt.Errorf("%s: invalid configuration", path)
 		}
-		if err = toml.UnmarshalTable(subTable, c.Agent); err != nil {
-			log.Printf("E! Could not parse [agent] config\n")
+		if _, err = c.resolveSecrets(subTable); err != nil {
 			return fmt.Errorf("Error parsing %s, %s", path, err)
 		}
+		c.checkKeys("agent", subTable, c.Agent)
+		if err = toml.UnmarshalTable(subTable, c.Agent); err != nil {
+			if err = c.pluginError(path, "agent", subTable, err); err != nil {
+				log.Printf("E! Could not parse [agent] config\n")
+				return err
+			}
+		}
 	}
 
 	// Parse all the rest of the plugins:
@@ -600,19 +711,25 @@ func (c *Config) LoadConfig(path string) error {
 		}
 
 		switch name {
-		case "agent", "global_tags", "tags":
+		case "agent", "global_tags", "tags", "secretstores":
 		case "outputs":
 			for pluginName, pluginVal := range subTable.Fields {
 				switch pluginSubTable := pluginVal.(type) {
 				// legacy [outputs.influxdb] support
 				case *ast.Table:
 					if err = c.addOutput(pluginName, pluginSubTable); err != nil {
-						return fmt.Errorf("Error parsing %s, %s", path, err)
+						err = c.pluginError(path, "outputs."+pluginName, pluginSubTable, err)
+						if err != nil {
+							return err
+						}
 					}
 				case []*ast.Table:
 					for _, t := range pluginSubTable {
 						if err = c.addOutput(pluginName, t); err != nil {
-							return fmt.Errorf("Error parsing %s, %s", path, err)
+							err = c.pluginError(path, "outputs."+pluginName, t, err)
+							if err != nil {
+								return err
+							}
 						}
 					}
 				default:
@@ -626,12 +743,18 @@ func (c *Config) LoadConfig(path string) error {
 				// legacy [inputs.cpu] support
 				case *ast.Table:
 					if err = c.addInput(pluginName, pluginSubTable); err != nil {
-						return fmt.Errorf("Error parsing %s, %s", path, err)
+						err = c.pluginError(path, "inputs."+pluginName, pluginSubTable, err)
+						if err != nil {
+							return err
+						}
 					}
 				case []*ast.Table:
 					for _, t := range pluginSubTable {
 						if err = c.addInput(pluginName, t); err != nil {
-							return fmt.Errorf("Error parsing %s, %s", path, err)
+							err = c.pluginError(path, "inputs."+pluginName, t, err)
+							if err != nil {
+								return err
+							}
 						}
 					}
 				default:
@@ -645,7 +768,10 @@ func (c *Config) LoadConfig(path string) error {
 				case []*ast.Table:
 					for _, t := range pluginSubTable {
 						if err = c.addProcessor(pluginName, t); err != nil {
-							return fmt.Errorf("Error parsing %s, %s", path, err)
+							err = c.pluginError(path, "processors."+pluginName, t, err)
+							if err != nil {
+								return err
+							}
 						}
 					}
 				default:
@@ -659,7 +785,10 @@ func (c *Config) LoadConfig(path string) error {
 				case []*ast.Table:
 					for _, t := range pluginSubTable {
 						if err = c.addAggregator(pluginName, t); err != nil {
-							return fmt.Errorf("Error parsing %s, %s", path, err)
+							err = c.pluginError(path, "aggregators."+pluginName, t, err)
+							if err != nil {
+								return err
+							}
 						}
 					}
 				default:
@@ -671,7 +800,10 @@ func (c *Config) LoadConfig(path string) error {
 		// identifiers are present
 		default:
 			if err = c.addInput(name, subTable); err != nil {
-				return fmt.Errorf("Error parsing %s, %s", path, err)
+				err = c.pluginError(path, "inputs."+name, subTable, err)
+				if err != nil {
+					return err
+				}
 			}
 		}
 	}
@@ -717,17 +849,29 @@ func (c *Config) addAggregator(name string, table *ast.Table) error {
 		return fmt.Errorf("Undefined but requested aggregator: %s", name)
 	}
 	aggregator := creator()
+	source := tableSource(name, table)
+	secrets, err := c.resolveSecrets(table)
+	if err != nil {
+		return err
+	}
+	source += secrets
 
 	conf, err := buildAggregator(name, table)
 	if err != nil {
 		return err
 	}
 
+	c.checkKeys("aggregators."+name, table, aggregator)
 	if err := toml.UnmarshalTable(table, aggregator); err != nil {
 		return err
 	}
+	if err := initPlugin(aggregator); err != nil {
+		return err
+	}
 
-	c.Aggregators = append(c.Aggregators, models.NewRunningAggregator(aggregator, conf))
+	ra := models.NewRunningAggregator(aggregator, conf)
+	c.sources[ra] = source
+	c.Aggregators = append(c.Aggregators, ra)
 	return nil
 }
 
@@ -737,15 +881,25 @@ func (c *Config) addProcessor(name string, table *ast.Table) error {
 		return fmt.Errorf("Undefined but requested processor: %s", name)
 	}
 	processor := creator()
+	source := tableSource(name, table)
+	secrets, err := c.resolveSecrets(table)
+	if err != nil {
+		return err
+	}
+	source += secrets
 
 	processorConfig, err := buildProcessor(name, table)
 	if err != nil {
 		return err
 	}
 
+	c.checkKeys("processors."+name, table, processor)
 	if err := toml.UnmarshalTable(table, processor); err != nil {
 		return err
 	}
+	if err := initPlugin(processor); err != nil {
+		return err
+	}
 
 	rf := &models.RunningProcessor{
 		Name:      name,
@@ -753,6 +907,7 @@ func (c *Config) addProcessor(name string, table *ast.Table) error {
 		Config:    processorConfig,
 	}
 
+	c.sources[rf] = source
 	c.Processors = append(c.Processors, rf)
 	return nil
 }
@@ -766,6 +921,12 @@ func (c *Config) addOutput(name string, table *ast.Table) error {
 		return fmt.Errorf("Undefined but requested output: %s", name)
 	}
 	output := creator()
+	source := tableSource(name, table)
+	secrets, err := c.resolveSecrets(table)
+	if err != nil {
+		return err
+	}
+	source += secrets
 
 	// If the output has a SetSerializer function, then this means it can write
 	// arbitrary types of output, so build the serializer and set it.
@@ -783,12 +944,26 @@ func (c *Config) addOutput(name string, table *ast.Table) error {
 		return err
 	}
 
+	c.checkKeys("outputs."+name, table, output)
 	if err := toml.UnmarshalTable(table, output); err != nil {
 		return err
 	}
+	if err := initPlugin(output); err != nil {
+		return err
+	}
+
+	batchSize := c.Agent.MetricBatchSize
+	if outputConfig.MetricBatchSize > 0 {
+		batchSize = outputConfig.MetricBatchSize
+	}
+	bufferLimit := c.Agent.MetricBufferLimit
+	if outputConfig.MetricBufferLimit > 0 {
+		bufferLimit = outputConfig.MetricBufferLimit
+	}
 
 	ro := models.NewRunningOutput(name, output, outputConfig,
-		c.Agent.MetricBatchSize, c.Agent.MetricBufferLimit)
+		batchSize, bufferLimit)
+	c.sources[ro] = outputSource(source, batchSize, bufferLimit)
 	c.Outputs = append(c.Outputs, ro)
 	return nil
 }
@@ -807,10 +982,28 @@ func (c *Config) addInput(name string, table *ast.Table) error {
 		return fmt.Errorf("Undefined but requested input: %s", name)
 	}
 	input := creator()
+	source := tableSource(name, table)
+	secrets, err := c.resolveSecrets(table)
+	if err != nil {
+		return err
+	}
+	source += secrets
 
 	// If the input has a SetParser function, then this means it can accept
 	// arbitrary types of input, so build the parser and set it.
 	switch t := input.(type) {
+	case parsers.ParserFuncInput:
+		config, err := getParserConfig(name, table)
+		if err != nil {
+			return err
+		}
+		// create a parser now so that its errors are reported at load
+		if _, err := parsers.NewParser(config); err != nil {
+			return err
+		}
+		t.SetParserFunc(func() (parsers.Parser, error) {
+			return parsers.NewParser(config)
+		})
 	case parsers.ParserInput:
 		parser, err := buildParser(name, table)
 		if err != nil {
@@ -824,11 +1017,16 @@ func (c *Config) addInput(name string, table *ast.Table) error {
 		return err
 	}
 
+	c.checkKeys("inputs."+name, table, input)
 	if err := toml.UnmarshalTable(table, input); err != nil {
 		return err
 	}
+	if err := initPlugin(input); err != nil {
+		return err
+	}
 
 	rp := models.NewRunningInput(input, pluginConfig)
+	c.sources[rp] = source
 	c.Inputs = append(c.Inputs, rp)
 	return nil
 }
@@ -856,7 +1054,8 @@ func buildAggregator(name string, tbl *ast.Table) (*models.AggregatorConfig, err
 			if str, ok := kv.Value.(*ast.String); ok {
 				dur, err := time.ParseDuration(str.Value)
 				if err != nil {
-					return nil, err
+					return nil, fmt.Errorf("Error parsing period for %s: %s",
+						name, err)
 				}
 
 				conf.Period = dur
@@ -869,7 +1068,8 @@ func buildAggregator(name string, tbl *ast.Table) (*models.AggregatorConfig, err
 			if str, ok := kv.Value.(*ast.String); ok {
 				dur, err := time.ParseDuration(str.Value)
 				if err != nil {
-					return nil, err
+					return nil, fmt.Errorf("Error parsing delay for %s: %s",
+						name, err)
 				}
 
 				conf.Delay = dur
@@ -972,9 +1172,10 @@ func buildProcessor(name string, tbl *ast.Table) (*models.ProcessorConfig, error
 }
 
 // buildFilter builds a Filter
-// (tagpass/tagdrop/namepass/namedrop/fieldpass/fielddrop) to
+// (tagpass/tagdrop/namepass/namedrop/fieldpass/fielddrop/metricpass) to
 // be inserted into the models.OutputConfig/models.InputConfig
-// to be used for glob filtering on tags and measurements
+// to be used for glob filtering on tags and measurements, and for
+// expression filtering on whole metrics
 func buildFilter(tbl *ast.Table) (models.Filter, error) {
 	f := models.Filter{}
 
@@ -1091,8 +1292,16 @@ func buildFilter(tbl *ast.Table) (models.Filter, error) {
 			}
 		}
 	}
+
+	if node, ok := tbl.Fields["metricpass"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				f.MetricPass = str.Value
+			}
+		}
+	}
 	if err := f.Compile(); err != nil {
-		return f, err
+		return f, fmt.Errorf("Error compiling filters: %s", err)
 	}
 
 	delete(tbl.Fields, "namedrop")
@@ -1105,6 +1314,7 @@ func buildFilter(tbl *ast.Table) (models.Filter, error) {
 	delete(tbl.Fields, "tagpass")
 	delete(tbl.Fields, "tagexclude")
 	delete(tbl.Fields, "taginclude")
+	delete(tbl.Fields, "metricpass")
 	return f, nil
 }
 
@@ -1118,7 +1328,8 @@ func buildInput(name string, tbl *ast.Table) (*models.InputConfig, error) {
 			if str, ok := kv.Value.(*ast.String); ok {
 				dur, err := time.ParseDuration(str.Value)
 				if err != nil {
-					return nil, err
+					return nil, fmt.Errorf("Error parsing interval for %s: %s",
+						name, err)
 				}
 
 				cp.Interval = dur
@@ -1126,6 +1337,20 @@ func buildInput(name string, tbl *ast.Table) (*models.InputConfig, error) {
 		}
 	}
 
+	if node, ok := tbl.Fields["gather_timeout"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				dur, err := time.ParseDuration(str.Value)
+				if err != nil {
+					return nil, fmt.Errorf("Error parsing gather_timeout for %s: %s",
+						name, err)
+				}
+
+				cp.Timeout = dur
+			}
+		}
+	}
+
 	if node, ok := tbl.Fields["name_prefix"]; ok {
 		if kv, ok := node.(*ast.KeyValue); ok {
 			if str, ok := kv.Value.(*ast.String); ok {
@@ -1163,6 +1388,7 @@ func buildInput(name string, tbl *ast.Table) (*models.InputConfig, error) {
 	delete(tbl.Fields, "name_suffix")
 	delete(tbl.Fields, "name_override")
 	delete(tbl.Fields, "interval")
+	delete(tbl.Fields, "gather_timeout")
 	delete(tbl.Fields, "tags")
 	var err error
 	cp.Filter, err = buildFilter(tbl)
@@ -1176,6 +1402,16 @@ func buildInput(name string, tbl *ast.Table) (*models.InputConfig, error) {
 // a parsers.Parser object, and creates it, which can then be added onto
 // an Input object.
 func buildParser(name string, tbl *ast.Table) (parsers.Parser, error) {
+	config, err := getParserConfig(name, tbl)
+	if err != nil {
+		return nil, err
+	}
+	return parsers.NewParser(config)
+}
+
+// getParserConfig grabs the necessary entries from the ast.Table for
+// creating parsers.Parser objects.
+func getParserConfig(name string, tbl *ast.Table) (*parsers.Config, error) {
 	c := &parsers.Config{}
 
 	if node, ok := tbl.Fields["data_format"]; ok {
@@ -1225,6 +1461,63 @@ func buildParser(name string, tbl *ast.Table) (parsers.Parser, error) {
 		}
 	}
 
+	if node, ok := tbl.Fields["json_query"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.JSONQuery = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["json_queries"]; ok {
+		if subtbl, ok := node.(*ast.Table); ok {
+			c.JSONQueries = make(map[string]string)
+			for name, val := range subtbl.Fields {
+				if kv, ok := val.(*ast.KeyValue); ok {
+					if str, ok := kv.Value.(*ast.String); ok {
+						c.JSONQueries[name] = str.Value
+					}
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["json_name_key"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.JSONNameKey = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["json_time_key"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.JSONTimeKey = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["json_time_format"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.JSONTimeFormat = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["json_string_fields"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if ary, ok := kv.Value.(*ast.Array); ok {
+				for _, elem := range ary.Value {
+					if str, ok := elem.(*ast.String); ok {
+						c.JSONStringFields = append(c.JSONStringFields, str.Value)
+					}
+				}
+			}
+		}
+	}
+
 	if node, ok := tbl.Fields["data_type"]; ok {
 		if kv, ok := node.(*ast.KeyValue); ok {
 			if str, ok := kv.Value.(*ast.String); ok {
@@ -1261,18 +1554,204 @@ func buildParser(name string, tbl *ast.Table) (parsers.Parser, error) {
 		}
 	}
 
+	if node, ok := tbl.Fields["csv_header_row_count"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if integer, ok := kv.Value.(*ast.Integer); ok {
+				v, err := integer.Int()
+				if err != nil {
+					return nil, err
+				}
+				c.CSVHeaderRowCount = int(v)
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["csv_skip_rows"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if integer, ok := kv.Value.(*ast.Integer); ok {
+				v, err := integer.Int()
+				if err != nil {
+					return nil, err
+				}
+				c.CSVSkipRows = int(v)
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["csv_skip_columns"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if integer, ok := kv.Value.(*ast.Integer); ok {
+				v, err := integer.Int()
+				if err != nil {
+					return nil, err
+				}
+				c.CSVSkipColumns = int(v)
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["csv_delimiter"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.CSVDelimiter = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["csv_comment"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.CSVComment = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["csv_trim_space"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if b, ok := kv.Value.(*ast.Boolean); ok {
+				v, err := b.Boolean()
+				if err != nil {
+					return nil, err
+				}
+				c.CSVTrimSpace = v
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["csv_column_names"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if ary, ok := kv.Value.(*ast.Array); ok {
+				for _, elem := range ary.Value {
+					if str, ok := elem.(*ast.String); ok {
+						c.CSVColumnNames = append(c.CSVColumnNames, str.Value)
+					}
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["csv_column_types"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if ary, ok := kv.Value.(*ast.Array); ok {
+				for _, elem := range ary.Value {
+					if str, ok := elem.(*ast.String); ok {
+						c.CSVColumnTypes = append(c.CSVColumnTypes, str.Value)
+					}
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["csv_tag_columns"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if ary, ok := kv.Value.(*ast.Array); ok {
+				for _, elem := range ary.Value {
+					if str, ok := elem.(*ast.String); ok {
+						c.CSVTagColumns = append(c.CSVTagColumns, str.Value)
+					}
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["csv_measurement_column"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.CSVMeasurementColumn = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["csv_timestamp_column"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.CSVTimestampColumn = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["csv_timestamp_format"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.CSVTimestampFormat = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["grok_patterns"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if ary, ok := kv.Value.(*ast.Array); ok {
+				for _, elem := range ary.Value {
+					if str, ok := elem.(*ast.String); ok {
+						c.GrokPatterns = append(c.GrokPatterns, str.Value)
+					}
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["grok_custom_patterns"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.GrokCustomPatterns = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["grok_custom_pattern_files"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if ary, ok := kv.Value.(*ast.Array); ok {
+				for _, elem := range ary.Value {
+					if str, ok := elem.(*ast.String); ok {
+						c.GrokCustomPatternFiles = append(c.GrokCustomPatternFiles, str.Value)
+					}
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["grok_timezone"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.GrokTimezone = str.Value
+			}
+		}
+	}
+
 	c.MetricName = name
 
 	delete(tbl.Fields, "data_format")
 	delete(tbl.Fields, "separator")
 	delete(tbl.Fields, "templates")
 	delete(tbl.Fields, "tag_keys")
+	delete(tbl.Fields, "json_query")
+	delete(tbl.Fields, "json_queries")
+	delete(tbl.Fields, "json_name_key")
+	delete(tbl.Fields, "json_time_key")
+	delete(tbl.Fields, "json_time_format")
+	delete(tbl.Fields, "json_string_fields")
 	delete(tbl.Fields, "data_type")
 	delete(tbl.Fields, "collectd_auth_file")
 	delete(tbl.Fields, "collectd_security_level")
 	delete(tbl.Fields, "collectd_typesdb")
-
-	return parsers.NewParser(c)
+	delete(tbl.Fields, "csv_header_row_count")
+	delete(tbl.Fields, "csv_skip_rows")
+	delete(tbl.Fields, "csv_skip_columns")
+	delete(tbl.Fields, "csv_delimiter")
+	delete(tbl.Fields, "csv_comment")
+	delete(tbl.Fields, "csv_trim_space")
+	delete(tbl.Fields, "csv_column_names")
+	delete(tbl.Fields, "csv_column_types")
+	delete(tbl.Fields, "csv_tag_columns")
+	delete(tbl.Fields, "csv_measurement_column")
+	delete(tbl.Fields, "csv_timestamp_column")
+	delete(tbl.Fields, "csv_timestamp_format")
+	delete(tbl.Fields, "grok_patterns")
+	delete(tbl.Fields, "grok_custom_patterns")
+	delete(tbl.Fields, "grok_custom_pattern_files")
+	delete(tbl.Fields, "grok_timezone")
+
+	return c, nil
 }
 
 // buildSerializer grabs the necessary entries from the ast.Table for creating
@@ -1325,10 +1804,19 @@ func buildSerializer(name string, tbl *ast.Table) (serializers.Serializer, error
 		}
 	}
 
+	if node, ok := tbl.Fields["json_batch_format"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.JSONBatchFormat = str.Value
+			}
+		}
+	}
+
 	delete(tbl.Fields, "data_format")
 	delete(tbl.Fields, "prefix")
 	delete(tbl.Fields, "template")
 	delete(tbl.Fields, "json_timestamp_units")
+	delete(tbl.Fields, "json_batch_format")
 	return serializers.NewSerializer(c)
 }
 
@@ -1345,6 +1833,191 @@ func buildOutput(name string, tbl *ast.Table) (*models.OutputConfig, error) {
 		Name:   name,
 		Filter: filter,
 	}
+
+	if node, ok := tbl.Fields["buffer_directory"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				oc.BufferDirectory = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["buffer_max_size"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			switch v := kv.Value.(type) {
+			case *ast.Integer:
+				oc.BufferMaxSize, err = v.Int()
+			case *ast.String:
+				oc.BufferMaxSize, err = internal.ParseSize(v.Value)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("Error parsing buffer_max_size for %s: %s",
+					name, err)
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["buffer_fsync"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				oc.BufferFsync, err = buffer.ParseFsyncPolicy(str.Value)
+				if err != nil {
+					return nil, fmt.Errorf("Error parsing buffer_fsync for %s: %s",
+						name, err)
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["flush_interval"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				dur, err := time.ParseDuration(str.Value)
+				if err != nil {
+					return nil, fmt.Errorf("Error parsing flush_interval for %s: %s",
+						name, err)
+				}
+
+				oc.FlushInterval = dur
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["flush_jitter"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				dur, err := time.ParseDuration(str.Value)
+				if err != nil {
+					return nil, fmt.Errorf("Error parsing flush_jitter for %s: %s",
+						name, err)
+				}
+
+				oc.FlushJitter = dur
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["metric_batch_size"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if integer, ok := kv.Value.(*ast.Integer); ok {
+				v, err := integer.Int()
+				if err != nil {
+					return nil, err
+				}
+
+				oc.MetricBatchSize = int(v)
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["metric_buffer_limit"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if integer, ok := kv.Value.(*ast.Integer); ok {
+				v, err := integer.Int()
+				if err != nil {
+					return nil, err
+				}
+
+				oc.MetricBufferLimit = int(v)
+			}
+		}
+	}
+
+	oc.Retry = retry.DefaultPolicy()
+
+	if node, ok := tbl.Fields["retry_initial_delay"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				dur, err := time.ParseDuration(str.Value)
+				if err != nil {
+					return nil, fmt.Errorf("Error parsing retry_initial_delay for %s: %s",
+						name, err)
+				}
+
+				oc.Retry.InitialDelay = dur
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["retry_max_delay"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				dur, err := time.ParseDuration(str.Value)
+				if err != nil {
+					return nil, fmt.Errorf("Error parsing retry_max_delay for %s: %s",
+						name, err)
+				}
+
+				oc.Retry.MaxDelay = dur
+			}
+		}
+	} else if oc.Retry.MaxDelay < oc.Retry.InitialDelay {
+		// the default max delay does not prevent a longer initial delay
+		oc.Retry.MaxDelay = oc.Retry.InitialDelay
+	}
+
+	if node, ok := tbl.Fields["retry_jitter"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			switch v := kv.Value.(type) {
+			case *ast.Float:
+				f, err := v.Float()
+				if err != nil {
+					return nil, err
+				}
+
+				oc.Retry.Jitter = f
+			case *ast.Integer:
+				i, err := v.Int()
+				if err != nil {
+					return nil, err
+				}
+
+				oc.Retry.Jitter = float64(i)
+			default:
+				return nil, fmt.Errorf("Error parsing retry_jitter for %s: "+
+					"must be a number", name)
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["retry_max_attempts"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if integer, ok := kv.Value.(*ast.Integer); ok {
+				v, err := integer.Int()
+				if err != nil {
+					return nil, err
+				}
+
+				oc.Retry.MaxAttempts = int(v)
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["retry_give_up"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				oc.Retry.GiveUp = str.Value
+			}
+		}
+	}
+
+	if err := oc.Retry.Validate(); err != nil {
+		return nil, fmt.Errorf("Error parsing retry settings for %s: %s",
+			name, err)
+	}
+
+	delete(tbl.Fields, "flush_interval")
+	delete(tbl.Fields, "flush_jitter")
+	delete(tbl.Fields, "metric_batch_size")
+	delete(tbl.Fields, "metric_buffer_limit")
+	delete(tbl.Fields, "buffer_directory")
+	delete(tbl.Fields, "buffer_max_size")
+	delete(tbl.Fields, "buffer_fsync")
+	delete(tbl.Fields, "retry_initial_delay")
+	delete(tbl.Fields, "retry_max_delay")
+	delete(tbl.Fields, "retry_jitter")
+	delete(tbl.Fields, "retry_max_attempts")
+	delete(tbl.Fields, "retry_give_up")
 	// Outputs don't support FieldDrop/FieldPass, so set to NameDrop/NamePass
 	if len(oc.Filter.FieldDrop) > 0 {
 		oc.Filter.NameDrop = oc.Filter.FieldDrop
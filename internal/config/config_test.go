@@ -1,10 +1,12 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"testing"
 	"time"
 
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/internal/models"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/inputs/exec"
@@ -12,7 +14,9 @@ import (
 	"github.com/influxdata/telegraf/plugins/inputs/procstat"
 	"github.com/influxdata/telegraf/plugins/parsers"
 
+	"github.com/influxdata/toml"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestConfig_LoadSingleInputWithEnvVars(t *testing.T) {
@@ -88,6 +92,7 @@ func TestConfig_LoadSingleInput(t *testing.T) {
 		Name:     "memcached",
 		Filter:   filter,
 		Interval: 5 * time.Second,
+		Timeout:  3 * time.Second,
 	}
 	mConfig.Tags = make(map[string]string)
 
@@ -134,6 +139,7 @@ func TestConfig_LoadDirectory(t *testing.T) {
 		Name:     "memcached",
 		Filter:   filter,
 		Interval: 5 * time.Second,
+		Timeout:  3 * time.Second,
 	}
 	mConfig.Tags = make(map[string]string)
 
@@ -174,3 +180,110 @@ func TestConfig_LoadDirectory(t *testing.T) {
 	assert.Equal(t, pConfig, c.Inputs[3].Config,
 		"Merged Testdata did not produce correct procstat metadata.")
 }
+
+func TestConfig_Validate(t *testing.T) {
+	problems, err := Validate("./testdata/invalid.toml", "")
+	require.NoError(t, err)
+	require.Len(t, problems, 5)
+
+	path := "./testdata/invalid.toml"
+	assert.Equal(t, Problem{File: path, Line: 3, Plugin: "agent",
+		Err: errors.New(`unknown key "flush_intreval"`)}, problems[0])
+	assert.Equal(t, Problem{File: path, Line: 7, Plugin: "inputs.memcached",
+		Err: errors.New(`unknown key "metric_batchsize"`)}, problems[1])
+	assert.Equal(t, Problem{File: path, Line: 8, Plugin: "inputs.memcached",
+		Err: errors.New(`unknown key "tagpas"`)}, problems[2])
+
+	assert.Equal(t, 11, problems[3].Line)
+	assert.Contains(t, problems[3].Err.Error(), "Error parsing interval")
+	assert.Equal(t, 14, problems[4].Line)
+	assert.Contains(t, problems[4].Err.Error(), "Error compiling filters")
+	assert.Equal(t, "./testdata/invalid.toml:3: [agent] "+
+		`unknown key "flush_intreval"`, problems[0].String())
+
+	// loading stops at the first plugin that cannot be loaded
+	c := NewConfig()
+	assert.Error(t, c.LoadConfig(path))
+	c = NewConfig()
+	require.NoError(t, c.LoadConfig("./testdata/single_plugin.toml"))
+	assert.Len(t, c.Problems(), 0)
+}
+
+type embeddedOptions struct {
+	SSLCA string
+}
+
+type nestedOption struct {
+	Name string `toml:"name"`
+}
+
+type keysPlugin struct {
+	embeddedOptions
+	Timeout internal.Duration
+	Renamed string         `toml:"other_name"`
+	Fields  []nestedOption `toml:"field"`
+	Tags    map[string]string
+
+	unexported string
+}
+
+func TestConfig_CheckKeys(t *testing.T) {
+	tbl, err := toml.Parse([]byte(`
+ssl_ca = "ca.pem"
+timeout = "5s"
+other_name = "x"
+renamed = "y"
+unexported = "z"
+[tags]
+  anything = "goes"
+[[field]]
+  name = "a"
+  nmae = "b"
+`))
+	require.NoError(t, err)
+
+	c := NewConfig()
+	c.checkKeys("inputs.keys", tbl, &keysPlugin{})
+	var unknown []string
+	for _, p := range c.Problems() {
+		unknown = append(unknown, p.Err.Error())
+	}
+	assert.Equal(t, []string{
+		`unknown key "renamed"`,
+		`unknown key "unexported"`,
+		`unknown key "field.nmae"`,
+	}, unknown)
+
+	// the unknown keys are removed, so that the table can be unmarshalled
+	require.NoError(t, toml.UnmarshalTable(tbl, &keysPlugin{}))
+	assert.NotContains(t, tbl.Fields, "unexported")
+	assert.Contains(t, tbl.Fields, "other_name")
+}
+
+func TestConfig_RetrySettings(t *testing.T) {
+	tbl, err := toml.Parse([]byte(`
+retry_initial_delay = "2m"
+retry_jitter = 0
+`))
+	require.NoError(t, err)
+
+	oc, err := buildOutput("test", tbl)
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Minute, oc.Retry.InitialDelay)
+	// the default max delay is raised to the initial delay
+	assert.Equal(t, 2*time.Minute, oc.Retry.MaxDelay)
+	assert.Equal(t, 0.0, oc.Retry.Jitter)
+
+	tbl, err = toml.Parse([]byte(`
+retry_initial_delay = "2m"
+retry_max_delay = "1m"
+`))
+	require.NoError(t, err)
+	_, err = buildOutput("test", tbl)
+	assert.Error(t, err)
+
+	tbl, err = toml.Parse([]byte(`retry_jitter = "0.5"`))
+	require.NoError(t, err)
+	_, err = buildOutput("test", tbl)
+	assert.Error(t, err)
+}
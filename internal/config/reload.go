@@ -0,0 +1,189 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/influxdata/telegraf/internal/models"
+	"github.com/influxdata/toml/ast"
+)
+
+// Diff lists the plugins that differ between a running config and a newly
+// loaded one. Plugins that are not listed are unchanged, and keep running.
+type Diff struct {
+	AddedInputs        []*models.RunningInput
+	RemovedInputs      []*models.RunningInput
+	AddedOutputs       []*models.RunningOutput
+	RemovedOutputs     []*models.RunningOutput
+	AddedAggregators   []*models.RunningAggregator
+	RemovedAggregators []*models.RunningAggregator
+
+	// AgentChanged is true if the [agent] table changed, in which case the
+	// unchanged plugins need to be restarted to use the new settings.
+	AgentChanged bool
+
+	// ProcessorsChanged is true if processors were added, removed or
+	// reordered, in which case the processor pipeline needs to be rebuilt.
+	ProcessorsChanged bool
+}
+
+// IsEmpty returns true if nothing changed.
+func (d *Diff) IsEmpty() bool {
+	return len(d.AddedInputs) == 0 && len(d.RemovedInputs) == 0 &&
+		len(d.AddedOutputs) == 0 && len(d.RemovedOutputs) == 0 &&
+		len(d.AddedAggregators) == 0 && len(d.RemovedAggregators) == 0 &&
+		!d.AgentChanged && !d.ProcessorsChanged
+}
+
+// Merge compares c with next, a config loaded to replace it, and puts the
+// plugins of c into next wherever their configuration is unchanged, so that
+// they keep their state and buffered metrics. It returns the plugins that
+// were added and removed.
+//
+// Inputs are all considered changed if the global tags changed, as the tags
+// are set on them when they are started.
+func (c *Config) Merge(next *Config) *Diff {
+	d := &Diff{
+		AgentChanged: *c.Agent != *next.Agent,
+	}
+	tagsChanged := !reflect.DeepEqual(c.Tags, next.Tags)
+
+	// inputs
+	prevInputs := make(map[string][]*models.RunningInput)
+	if !tagsChanged {
+		for _, input := range c.Inputs {
+			src := c.sources[input]
+			prevInputs[src] = append(prevInputs[src], input)
+		}
+	}
+	keptInputs := make(map[*models.RunningInput]bool)
+	for i, input := range next.Inputs {
+		src := next.sources[input]
+		if prev := prevInputs[src]; len(prev) > 0 {
+			prevInputs[src] = prev[1:]
+			keptInputs[prev[0]] = true
+			next.Inputs[i] = prev[0]
+			delete(next.sources, input)
+			next.sources[prev[0]] = src
+			continue
+		}
+		d.AddedInputs = append(d.AddedInputs, input)
+	}
+	for _, input := range c.Inputs {
+		if !keptInputs[input] {
+			d.RemovedInputs = append(d.RemovedInputs, input)
+		}
+	}
+
+	// outputs
+	prevOutputs := make(map[string][]*models.RunningOutput)
+	for _, output := range c.Outputs {
+		src := c.sources[output]
+		prevOutputs[src] = append(prevOutputs[src], output)
+	}
+	keptOutputs := make(map[*models.RunningOutput]bool)
+	for i, output := range next.Outputs {
+		src := next.sources[output]
+		if prev := prevOutputs[src]; len(prev) > 0 {
+			prevOutputs[src] = prev[1:]
+			keptOutputs[prev[0]] = true
+			next.Outputs[i] = prev[0]
+			delete(next.sources, output)
+			next.sources[prev[0]] = src
+			continue
+		}
+		d.AddedOutputs = append(d.AddedOutputs, output)
+	}
+	for _, output := range c.Outputs {
+		if !keptOutputs[output] {
+			d.RemovedOutputs = append(d.RemovedOutputs, output)
+		}
+	}
+
+	// aggregators
+	prevAggregators := make(map[string][]*models.RunningAggregator)
+	for _, agg := range c.Aggregators {
+		src := c.sources[agg]
+		prevAggregators[src] = append(prevAggregators[src], agg)
+	}
+	keptAggregators := make(map[*models.RunningAggregator]bool)
+	for i, agg := range next.Aggregators {
+		src := next.sources[agg]
+		if prev := prevAggregators[src]; len(prev) > 0 {
+			prevAggregators[src] = prev[1:]
+			keptAggregators[prev[0]] = true
+			next.Aggregators[i] = prev[0]
+			delete(next.sources, agg)
+			next.sources[prev[0]] = src
+			continue
+		}
+		d.AddedAggregators = append(d.AddedAggregators, agg)
+	}
+	for _, agg := range c.Aggregators {
+		if !keptAggregators[agg] {
+			d.RemovedAggregators = append(d.RemovedAggregators, agg)
+		}
+	}
+
+	// processors are not started or stopped, but are kept for any state
+	// they might have.
+	prevProcessors := make(map[string][]*models.RunningProcessor)
+	for _, proc := range c.Processors {
+		src := c.sources[proc]
+		prevProcessors[src] = append(prevProcessors[src], proc)
+	}
+	for i, proc := range next.Processors {
+		src := next.sources[proc]
+		if prev := prevProcessors[src]; len(prev) > 0 {
+			prevProcessors[src] = prev[1:]
+			next.Processors[i] = prev[0]
+			delete(next.sources, proc)
+			next.sources[prev[0]] = src
+		}
+	}
+	d.ProcessorsChanged = len(c.Processors) != len(next.Processors)
+	for i := 0; i < len(c.Processors) && !d.ProcessorsChanged; i++ {
+		d.ProcessorsChanged = c.Processors[i] != next.Processors[i]
+	}
+
+	return d
+}
+
+// tableSource returns the TOML source of a plugin table, including its sub
+// tables, which identifies the plugin configuration across reloads. It must
+// be called before the table fields are consumed.
+func tableSource(name string, tbl *ast.Table) string {
+	var buf bytes.Buffer
+	buf.WriteString(name)
+	writeTableSource(&buf, tbl)
+	return buf.String()
+}
+
+func writeTableSource(buf *bytes.Buffer, tbl *ast.Table) {
+	buf.WriteString("\n")
+	buf.WriteString(string(tbl.Data))
+
+	keys := make([]string, 0, len(tbl.Fields))
+	for key := range tbl.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		switch sub := tbl.Fields[key].(type) {
+		case *ast.Table:
+			writeTableSource(buf, sub)
+		case []*ast.Table:
+			for _, t := range sub {
+				writeTableSource(buf, t)
+			}
+		}
+	}
+}
+
+// outputSource identifies an output by its table and by the buffer settings
+// it inherits from the [agent] table.
+func outputSource(src string, batchSize, bufferLimit int) string {
+	return fmt.Sprintf("%s\nbatch=%d buffer=%d", src, batchSize, bufferLimit)
+}
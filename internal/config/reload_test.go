@@ -0,0 +1,160 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	_ "github.com/influxdata/telegraf/plugins/aggregators/minmax"
+	_ "github.com/influxdata/telegraf/plugins/inputs/memcached"
+	_ "github.com/influxdata/telegraf/plugins/outputs/discard"
+	_ "github.com/influxdata/telegraf/plugins/processors/rename"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadTestConfig(t *testing.T, toml string) *Config {
+	f, err := ioutil.TempFile("", "telegraf-reload")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(toml)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	c := NewConfig()
+	require.NoError(t, c.LoadConfig(f.Name()))
+	return c
+}
+
+const reloadPlugins = `
+[[inputs.memcached]]
+  servers = ["localhost"]
+
+[[inputs.memcached]]
+  servers = ["remote"]
+  [inputs.memcached.tagpass]
+    cpu = ["cpu0"]
+
+[[outputs.discard]]
+
+[[aggregators.minmax]]
+  period = "30s"
+`
+
+const reloadConfig = `
+[agent]
+  interval = "10s"
+` + reloadPlugins
+
+func TestConfig_MergeUnchanged(t *testing.T) {
+	prev := loadTestConfig(t, reloadConfig)
+	next := loadTestConfig(t, reloadConfig)
+
+	d := prev.Merge(next)
+	assert.True(t, d.IsEmpty())
+	assert.Equal(t, prev.Inputs, next.Inputs)
+	assert.Equal(t, prev.Outputs, next.Outputs)
+	assert.Equal(t, prev.Aggregators, next.Aggregators)
+	for i := range prev.Inputs {
+		assert.True(t, prev.Inputs[i] == next.Inputs[i])
+	}
+	assert.True(t, prev.Outputs[0] == next.Outputs[0])
+	assert.True(t, prev.Aggregators[0] == next.Aggregators[0])
+}
+
+func TestConfig_MergeChanged(t *testing.T) {
+	prev := loadTestConfig(t, reloadConfig)
+	next := loadTestConfig(t, `
+[agent]
+  interval = "10s"
+
+[[inputs.memcached]]
+  servers = ["localhost"]
+
+[[inputs.memcached]]
+  servers = ["remote"]
+  [inputs.memcached.tagpass]
+    cpu = ["cpu1"]
+
+[[outputs.discard]]
+  metric_batch_size = 10
+
+[[aggregators.minmax]]
+  period = "30s"
+`)
+
+	d := prev.Merge(next)
+	assert.False(t, d.AgentChanged)
+
+	// the input with a different tagpass is replaced
+	require.Len(t, d.RemovedInputs, 1)
+	require.Len(t, d.AddedInputs, 1)
+	assert.True(t, d.RemovedInputs[0] == prev.Inputs[1])
+	assert.True(t, d.AddedInputs[0] == next.Inputs[1])
+	assert.True(t, prev.Inputs[0] == next.Inputs[0])
+
+	require.Len(t, d.RemovedOutputs, 1)
+	require.Len(t, d.AddedOutputs, 1)
+	assert.Equal(t, 10, d.AddedOutputs[0].MetricBatchSize)
+
+	assert.Empty(t, d.AddedAggregators)
+	assert.Empty(t, d.RemovedAggregators)
+	assert.True(t, prev.Aggregators[0] == next.Aggregators[0])
+}
+
+func TestConfig_MergeTagsChanged(t *testing.T) {
+	prev := loadTestConfig(t, reloadConfig)
+	next := loadTestConfig(t, `
+[global_tags]
+  dc = "us-east-1"
+`+reloadConfig)
+
+	d := prev.Merge(next)
+	assert.Len(t, d.RemovedInputs, 2)
+	assert.Len(t, d.AddedInputs, 2)
+	assert.Empty(t, d.AddedOutputs)
+	assert.Empty(t, d.RemovedOutputs)
+}
+
+func TestConfig_MergeAgentChanged(t *testing.T) {
+	prev := loadTestConfig(t, reloadConfig)
+	next := loadTestConfig(t, `
+[agent]
+  interval = "10s"
+  flush_interval = "5s"
+`+reloadPlugins)
+
+	d := prev.Merge(next)
+	assert.True(t, d.AgentChanged)
+	assert.False(t, d.IsEmpty())
+	assert.Empty(t, d.AddedInputs)
+	assert.Empty(t, d.RemovedInputs)
+}
+
+func TestConfig_MergeProcessorsChanged(t *testing.T) {
+	prev := loadTestConfig(t, reloadConfig+`
+[[processors.rename]]
+  order = 1
+`)
+	next := loadTestConfig(t, reloadConfig+`
+[[processors.rename]]
+  order = 1
+`)
+	d := prev.Merge(next)
+	assert.False(t, d.ProcessorsChanged)
+	assert.True(t, d.IsEmpty())
+	assert.True(t, prev.Processors[0] == next.Processors[0])
+
+	next = loadTestConfig(t, reloadConfig+`
+[[processors.rename]]
+  order = 1
+
+[[processors.rename]]
+  order = 2
+`)
+	d = prev.Merge(next)
+	assert.True(t, d.ProcessorsChanged)
+	assert.False(t, d.IsEmpty())
+	assert.True(t, prev.Processors[0] == next.Processors[0])
+}
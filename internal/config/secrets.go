@@ -0,0 +1,152 @@
+package config
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"regexp"
+	"sort"
+
+	"github.com/influxdata/telegraf/plugins/secretstores"
+
+	"github.com/influxdata/toml"
+	"github.com/influxdata/toml/ast"
+)
+
+var (
+	// secretRe is a regex to find the references to secrets in the config
+	// values, ie @{store_id:key}
+	secretRe = regexp.MustCompile(`@\{(\w+):([^{}]+)\}`)
+
+	// storeIDRe matches the valid secret store ids
+	storeIDRe = regexp.MustCompile(`^\w+$`)
+)
+
+func (c *Config) addSecretStore(name string, table *ast.Table) error {
+	creator, ok := secretstores.SecretStores[name]
+	if !ok {
+		return fmt.Errorf("Undefined but requested secret store: %s", name)
+	}
+	store := creator()
+
+	var id string
+	if node, ok := table.Fields["id"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				id = str.Value
+			}
+		}
+	}
+	if !storeIDRe.MatchString(id) {
+		return fmt.Errorf("secret store %s requires an id made of letters, "+
+			"digits and underscores", name)
+	}
+	if _, ok := c.secretStores[id]; ok {
+		return fmt.Errorf("duplicate secret store id: %s", id)
+	}
+	delete(table.Fields, "id")
+
+	c.checkKeys("secretstores."+name, table, store)
+	if err := toml.UnmarshalTable(table, store); err != nil {
+		return err
+	}
+	if err := initPlugin(store); err != nil {
+		return err
+	}
+
+	c.secretStores[id] = store
+	return nil
+}
+
+// resolveSecrets replaces the references to secrets in the string values of
+// tbl, and of its sub tables, with the secrets they refer to. It returns a
+// digest of the secrets, empty if there are none, to be added to the source
+// of the plugin so that it is restarted when a secret changes on reload.
+func (c *Config) resolveSecrets(tbl *ast.Table) (string, error) {
+	h := sha256.New()
+	n, err := c.resolveTable(tbl, h)
+	if err != nil || n == 0 {
+		return "", err
+	}
+	return fmt.Sprintf("\nsecrets=%x", h.Sum(nil)), nil
+}
+
+func (c *Config) resolveTable(tbl *ast.Table, h hash.Hash) (int, error) {
+	// fields are walked in order, for the digest to be stable
+	keys := make([]string, 0, len(tbl.Fields))
+	for key := range tbl.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	count := 0
+	for _, key := range keys {
+		var n int
+		var err error
+		switch node := tbl.Fields[key].(type) {
+		case *ast.KeyValue:
+			n, err = c.resolveValue(node.Value, h)
+		case *ast.Table:
+			n, err = c.resolveTable(node, h)
+		case []*ast.Table:
+			for _, t := range node {
+				var tn int
+				if tn, err = c.resolveTable(t, h); err != nil {
+					break
+				}
+				n += tn
+			}
+		}
+		if err != nil {
+			return 0, err
+		}
+		count += n
+	}
+	return count, nil
+}
+
+func (c *Config) resolveValue(v ast.Value, h hash.Hash) (int, error) {
+	switch v := v.(type) {
+	case *ast.String:
+		return c.resolveString(v, h)
+	case *ast.Array:
+		count := 0
+		for _, elem := range v.Value {
+			n, err := c.resolveValue(elem, h)
+			if err != nil {
+				return 0, err
+			}
+			count += n
+		}
+		return count, nil
+	}
+	return 0, nil
+}
+
+func (c *Config) resolveString(s *ast.String, h hash.Hash) (int, error) {
+	var err error
+	count := 0
+	s.Value = secretRe.ReplaceAllStringFunc(s.Value, func(ref string) string {
+		if err != nil {
+			return ref
+		}
+		m := secretRe.FindStringSubmatch(ref)
+		store, ok := c.secretStores[m[1]]
+		if !ok {
+			err = fmt.Errorf("unknown secret store %q in %s", m[1], ref)
+			return ref
+		}
+		secret, gerr := store.Get(m[2])
+		if gerr != nil {
+			err = fmt.Errorf("could not resolve %s: %s", ref, gerr)
+			return ref
+		}
+		count++
+		h.Write([]byte(ref))
+		h.Write([]byte{0})
+		h.Write([]byte(secret))
+		h.Write([]byte{0})
+		return secret
+	})
+	return count, err
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/influxdata/telegraf/plugins/inputs/memcached"
+	_ "github.com/influxdata/telegraf/plugins/secretstores/directory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSecretsDir(t *testing.T, secrets map[string]string) string {
+	dir, err := ioutil.TempDir("", "telegraf-secrets")
+	require.NoError(t, err)
+	writeSecrets(t, dir, secrets)
+	return dir
+}
+
+func writeSecrets(t *testing.T, dir string, secrets map[string]string) {
+	for key, secret := range secrets {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, key),
+			[]byte(secret), 0600))
+	}
+}
+
+func secretsConfig(dir string) string {
+	return `
+[[secretstores.directory]]
+  id = "local"
+  path = "` + dir + `"
+
+[global_tags]
+  dc = "@{local:dc}"
+
+[[inputs.memcached]]
+  servers = ["localhost", "user:@{local:password}@remote"]
+`
+}
+
+func TestConfig_Secrets(t *testing.T) {
+	dir := newSecretsDir(t, map[string]string{
+		"dc":       "us-east-1\n",
+		"password": "s3cr3t",
+	})
+	defer os.RemoveAll(dir)
+
+	c := loadTestConfig(t, secretsConfig(dir))
+	assert.Equal(t, map[string]string{"dc": "us-east-1"}, c.Tags)
+	require.Len(t, c.Inputs, 1)
+	assert.Equal(t, []string{"localhost", "user:s3cr3t@remote"},
+		c.Inputs[0].Input.(*memcached.Memcached).Servers)
+}
+
+func TestConfig_SecretsErrors(t *testing.T) {
+	dir := newSecretsDir(t, map[string]string{"password": "s3cr3t"})
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name string
+		toml string
+	}{
+		{
+			name: "missing secret",
+			toml: secretsConfig(dir),
+		},
+		{
+			name: "unknown store",
+			toml: `
+[[inputs.memcached]]
+  servers = ["user:@{local:password}@remote"]
+`,
+		},
+		{
+			name: "missing id",
+			toml: `
+[[secretstores.directory]]
+  path = "` + dir + `"
+`,
+		},
+		{
+			name: "duplicate id",
+			toml: `
+[[secretstores.directory]]
+  id = "local"
+  path = "` + dir + `"
+
+[[secretstores.directory]]
+  id = "local"
+  path = "` + dir + `"
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := ioutil.TempFile("", "telegraf-secrets")
+			require.NoError(t, err)
+			defer os.Remove(f.Name())
+			_, err = f.WriteString(tt.toml)
+			require.NoError(t, err)
+			require.NoError(t, f.Close())
+
+			c := NewConfig()
+			assert.Error(t, c.LoadConfig(f.Name()))
+		})
+	}
+}
+
+func TestConfig_MergeSecretChanged(t *testing.T) {
+	dir := newSecretsDir(t, map[string]string{
+		"dc":       "us-east-1",
+		"password": "s3cr3t",
+	})
+	defer os.RemoveAll(dir)
+
+	prev := loadTestConfig(t, secretsConfig(dir))
+	next := loadTestConfig(t, secretsConfig(dir))
+	assert.True(t, prev.Merge(next).IsEmpty())
+
+	// the input is restarted to use the new secret
+	writeSecrets(t, dir, map[string]string{"password": "n3w"})
+	prev = next
+	next = loadTestConfig(t, secretsConfig(dir))
+	d := prev.Merge(next)
+	require.Len(t, d.RemovedInputs, 1)
+	require.Len(t, d.AddedInputs, 1)
+	assert.Equal(t, []string{"localhost", "user:n3w@remote"},
+		d.AddedInputs[0].Input.(*memcached.Memcached).Servers)
+}
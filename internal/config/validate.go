@@ -0,0 +1,202 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+
+	"github.com/influxdata/toml/ast"
+)
+
+// Problem is a mistake found in the configuration.
+type Problem struct {
+	File string
+	// Line is the line of the setting in the file, or of the table of the
+	// plugin when the mistake is not about a single setting.
+	Line int
+	// Plugin is the name of the table the problem was found in, ie,
+	// inputs.cpu or agent.
+	Plugin string
+	Err    error
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s:%d: [%s] %s", p.File, p.Line, p.Plugin, p.Err)
+}
+
+// Problems returns the problems found while loading the configuration that
+// did not prevent it from loading, such as unknown keys. When validating, it
+// also has the errors of the plugins that could not be loaded.
+func (c *Config) Problems() []Problem {
+	return c.problems
+}
+
+// Validate loads the config file, and the *.conf files of the directory if it
+// is not empty, like LoadConfig and LoadDirectory. Instead of stopping at the
+// first plugin that cannot be loaded, it returns the problems of every
+// plugin. No plugin is started. The error is only set if a file cannot be
+// read or parsed.
+func Validate(path string, directory string) ([]Problem, error) {
+	c := NewConfig()
+	c.validating = true
+	if err := c.LoadConfig(path); err != nil {
+		return c.problems, err
+	}
+	if directory != "" {
+		if err := c.LoadDirectory(directory); err != nil {
+			return c.problems, err
+		}
+	}
+	return c.problems, nil
+}
+
+// pluginError returns the error of a plugin that could not be loaded, or
+// records it as a problem and returns nil when the config is validated, so
+// that the other plugins are checked as well.
+func (c *Config) pluginError(path string, plugin string, tbl *ast.Table, err error) error {
+	if !c.validating {
+		return fmt.Errorf("Error parsing %s, %s", path, err)
+	}
+	c.problems = append(c.problems, Problem{
+		File:   path,
+		Line:   tbl.Line,
+		Plugin: plugin,
+		Err:    err,
+	})
+	return nil
+}
+
+// checkKeys records a problem for each key of tbl that does not match any
+// field of v, and removes the key from tbl, as toml.UnmarshalTable fails on
+// unknown keys. It must be called once the settings common to all plugins
+// have been removed from tbl.
+func (c *Config) checkKeys(plugin string, tbl *ast.Table, v interface{}) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return
+	}
+
+	problems := unknownKeys(tbl, t, "")
+	sort.Slice(problems, func(i, j int) bool {
+		return problems[i].Line < problems[j].Line
+	})
+	for _, p := range problems {
+		p.Plugin = plugin
+		c.problems = append(c.problems, p)
+	}
+}
+
+// unknownKeys removes the keys of tbl, and of its sub tables, that do not
+// match a field of the struct type t, and returns them.
+func unknownKeys(tbl *ast.Table, t reflect.Type, prefix string) []Problem {
+	var problems []Problem
+	for key, val := range tbl.Fields {
+		field, ok := findField(t, key)
+		if !ok {
+			line := tbl.Line
+			switch v := val.(type) {
+			case *ast.KeyValue:
+				line = v.Line
+			case *ast.Table:
+				line = v.Line
+			case []*ast.Table:
+				if len(v) > 0 {
+					line = v[0].Line
+				}
+			}
+			problems = append(problems, Problem{
+				Line: line,
+				Err:  fmt.Errorf("unknown key %q", prefix+key),
+			})
+			delete(tbl.Fields, key)
+			continue
+		}
+
+		switch v := val.(type) {
+		case *ast.Table:
+			if ft, ok := structType(field.Type); ok {
+				problems = append(problems, unknownKeys(v, ft, prefix+key+".")...)
+			}
+		case []*ast.Table:
+			ft := field.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() != reflect.Slice {
+				continue
+			}
+			if et, ok := structType(ft.Elem()); ok {
+				for _, sub := range v {
+					problems = append(problems, unknownKeys(sub, et, prefix+key+".")...)
+				}
+			}
+		}
+	}
+	return problems
+}
+
+// structType returns the struct type t points to, unless the struct decodes
+// itself from TOML, in which case its keys are not checked.
+func structType(t reflect.Type) (reflect.Type, bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, false
+	}
+	if _, ok := reflect.PtrTo(t).MethodByName("UnmarshalTOML"); ok {
+		return nil, false
+	}
+	return t, true
+}
+
+// findField returns the exported field of the struct type t that the key is
+// decoded into, the same way as toml.UnmarshalTable: the field with a toml
+// tag equal to the key, or without a toml tag and whose name matches the key
+// ignoring case and underscores. The fields of embedded structs without a
+// toml tag are searched as well.
+func findField(t reflect.Type, key string) (reflect.StructField, bool) {
+	norm := normalizeKey(key)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := strings.TrimSpace(strings.Split(f.Tag.Get("toml"), ",")[0])
+		if f.Anonymous && f.Type.Kind() == reflect.Struct && tag == "" {
+			if sf, ok := findField(f.Type, key); ok {
+				return sf, true
+			}
+			continue
+		}
+		if f.PkgPath != "" || tag == "-" {
+			continue
+		}
+		if tag != "" {
+			if tag == key {
+				return f, true
+			}
+			continue
+		}
+		if normalizeKey(f.Name) == norm {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func normalizeKey(key string) string {
+	return strings.ToLower(strings.Replace(key, "_", "", -1))
+}
+
+// initPlugin calls the Init function of the plugin, if it has one, once it
+// has been configured.
+func initPlugin(plugin interface{}) error {
+	if p, ok := plugin.(telegraf.Initializer); ok {
+		return p.Init()
+	}
+	return nil
+}
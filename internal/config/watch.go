@@ -0,0 +1,91 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Watcher polls configuration files for changes, so that the configuration
+// can be reloaded when they are edited.
+type Watcher struct {
+	// C receives a value when a file was added, removed or modified since
+	// the previous poll.
+	C chan struct{}
+
+	files     []string
+	directory string
+	interval  time.Duration
+}
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewWatcher returns a Watcher for the given config files and the *.conf
+// files of directory, if it is not empty, polled every interval.
+func NewWatcher(files []string, directory string, interval time.Duration) *Watcher {
+	return &Watcher{
+		C:         make(chan struct{}, 1),
+		files:     files,
+		directory: directory,
+		interval:  interval,
+	}
+}
+
+// Run polls the files until shutdown is closed.
+func (w *Watcher) Run(shutdown chan struct{}) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	prev := w.snapshot()
+	for {
+		select {
+		case <-shutdown:
+			return
+		case <-ticker.C:
+			cur := w.snapshot()
+			if changed(prev, cur) {
+				select {
+				case w.C <- struct{}{}:
+				default:
+				}
+			}
+			prev = cur
+		}
+	}
+}
+
+// snapshot returns the state of the watched files. Files that cannot be read
+// are left out, so that their removal is also seen as a change.
+func (w *Watcher) snapshot() map[string]fileState {
+	files := make(map[string]fileState)
+	for _, path := range w.files {
+		if info, err := os.Stat(path); err == nil {
+			files[path] = fileState{info.ModTime(), info.Size()}
+		}
+	}
+	if w.directory != "" {
+		filepath.Walk(w.directory, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() || filepath.Ext(path) != ".conf" {
+				return nil
+			}
+			files[path] = fileState{info.ModTime(), info.Size()}
+			return nil
+		})
+	}
+	return files
+}
+
+func changed(prev, cur map[string]fileState) bool {
+	if len(prev) != len(cur) {
+		return true
+	}
+	for path, state := range cur {
+		if p, ok := prev[path]; !ok || p != state {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "telegraf-watch")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "telegraf.conf")
+	require.NoError(t, ioutil.WriteFile(file, []byte("[agent]\n"), 0644))
+	confDir := filepath.Join(dir, "telegraf.d")
+	require.NoError(t, os.Mkdir(confDir, 0755))
+
+	w := NewWatcher([]string{file}, confDir, 10*time.Millisecond)
+	shutdown := make(chan struct{})
+	defer close(shutdown)
+	go w.Run(shutdown)
+
+	changed := func() bool {
+		select {
+		case <-w.C:
+			return true
+		case <-time.After(200 * time.Millisecond):
+			return false
+		}
+	}
+
+	assert.False(t, changed())
+
+	// files that are not *.conf are ignored
+	require.NoError(t, ioutil.WriteFile(filepath.Join(confDir, "notes.txt"),
+		[]byte("notes"), 0644))
+	assert.False(t, changed())
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(confDir, "cpu.conf"),
+		[]byte("[[inputs.cpu]]\n"), 0644))
+	assert.True(t, changed())
+
+	require.NoError(t, ioutil.WriteFile(file, []byte("[agent]\n  debug = true\n"), 0644))
+	assert.True(t, changed())
+
+	require.NoError(t, os.Remove(filepath.Join(confDir, "cpu.conf")))
+	assert.True(t, changed())
+}
@@ -4,11 +4,8 @@ import (
 	"bufio"
 	"bytes"
 	"crypto/rand"
-	"crypto/tls"
-	"crypto/x509"
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"log"
 	"math/big"
 	"os"
@@ -67,6 +64,148 @@ func (d *Duration) UnmarshalTOML(b []byte) error {
 	return nil
 }
 
+// ParseSize parses a size in bytes, with an optional unit suffix, ie, "512",
+// "64kB", "10MB" or "1GiB". Decimal (kB, MB, GB) and binary (KiB, MiB, GiB)
+// units are supported.
+func ParseSize(s string) (int64, error) {
+	units := []struct {
+		suffix string
+		mult   int64
+	}{
+		{"KiB", 1 << 10}, {"MiB", 1 << 20}, {"GiB", 1 << 30}, {"TiB", 1 << 40},
+		{"kB", 1e3}, {"KB", 1e3}, {"MB", 1e6}, {"GB", 1e9}, {"TB", 1e12},
+		{"B", 1},
+	}
+
+	s = strings.TrimSpace(s)
+	mult := int64(1)
+	for _, u := range units {
+		if strings.HasSuffix(s, u.suffix) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, u.suffix))
+			mult = u.mult
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size: %q", s)
+	}
+	return n * mult, nil
+}
+
+// timeLayouts are the named layouts of the time package that are accepted
+// as a timestamp format.
+var timeLayouts = map[string]string{
+	"ANSIC":       time.ANSIC,
+	"UnixDate":    time.UnixDate,
+	"RubyDate":    time.RubyDate,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Stamp":       time.Stamp,
+	"StampMilli":  time.StampMilli,
+	"StampMicro":  time.StampMicro,
+	"StampNano":   time.StampNano,
+}
+
+// ParseTimestamp parses a timestamp with the given format, one of "unix",
+// "unix_ms", "unix_us" or "unix_ns" for a number of seconds, milliseconds,
+// microseconds or nanoseconds since the epoch, the name of a layout of the
+// time package, ie "RFC3339", or a Go reference time layout, ie
+// "2006-01-02 15:04:05". Timestamps without a time zone are in UTC.
+func ParseTimestamp(format string, timestamp interface{}) (time.Time, error) {
+	switch format {
+	case "unix", "unix_ms", "unix_us", "unix_ns":
+		return parseUnixTimestamp(format, timestamp)
+	case "":
+		return time.Time{}, errors.New("no timestamp format given")
+	}
+
+	str, ok := timestamp.(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf(
+			"unsupported type %T for timestamp with format %q", timestamp, format)
+	}
+	layout := format
+	if l, ok := timeLayouts[format]; ok {
+		layout = l
+	}
+	return time.Parse(layout, str)
+}
+
+func parseUnixTimestamp(format string, timestamp interface{}) (time.Time, error) {
+	var str string
+	switch v := timestamp.(type) {
+	case string:
+		str = strings.TrimSpace(v)
+	case float64:
+		str = strconv.FormatFloat(v, 'f', -1, 64)
+	case int64:
+		str = strconv.FormatInt(v, 10)
+	case int:
+		str = strconv.Itoa(v)
+	default:
+		return time.Time{}, fmt.Errorf(
+			"unsupported type %T for timestamp with format %q", timestamp, format)
+	}
+
+	// unit is the duration of the timestamp unit, and digits the number of
+	// fractional digits of the unit that are at least a nanosecond
+	var unit int64
+	var digits int
+	switch format {
+	case "unix":
+		unit, digits = int64(time.Second), 9
+	case "unix_ms":
+		unit, digits = int64(time.Millisecond), 6
+	case "unix_us":
+		unit, digits = int64(time.Microsecond), 3
+	default:
+		unit, digits = int64(time.Nanosecond), 0
+	}
+
+	// the integer and fractional parts are parsed as integers separately, so
+	// nanosecond timestamps don't lose precision
+	integer, fraction := str, ""
+	if i := strings.IndexByte(str, '.'); i >= 0 {
+		integer, fraction = str[:i], str[i+1:]
+	}
+	n, err := strconv.ParseInt(integer, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s timestamp: %q", format, str)
+	}
+	ns := n * unit
+	if fraction != "" {
+		for _, c := range fraction {
+			if c < '0' || c > '9' {
+				return time.Time{}, fmt.Errorf("invalid %s timestamp: %q", format, str)
+			}
+		}
+		// the digits below a nanosecond are truncated
+		if len(fraction) > digits {
+			fraction = fraction[:digits]
+		}
+		fraction += strings.Repeat("0", digits-len(fraction))
+		if fraction != "" {
+			f, err := strconv.ParseInt(fraction, 10, 64)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("invalid %s timestamp: %q", format, str)
+			}
+			if strings.HasPrefix(integer, "-") {
+				ns -= f
+			} else {
+				ns += f
+			}
+		}
+	}
+	return time.Unix(0, ns).UTC(), nil
+}
+
 // ReadLines reads contents from a file and splits them by new lines.
 // A convenience wrapper to ReadLinesOffsetN(filename, 0, -1).
 func ReadLines(filename string) ([]string, error) {
@@ -112,49 +251,6 @@ func RandomString(n int) string {
 	return string(bytes)
 }
 
-// GetTLSConfig gets a tls.Config object from the given certs, key, and CA files.
-// you must give the full path to the files.
-// If all files are blank and InsecureSkipVerify=false, returns a nil pointer.
-func GetTLSConfig(
-	SSLCert, SSLKey, SSLCA string,
-	InsecureSkipVerify bool,
-) (*tls.Config, error) {
-	if SSLCert == "" && SSLKey == "" && SSLCA == "" && !InsecureSkipVerify {
-		return nil, nil
-	}
-
-	t := &tls.Config{
-		InsecureSkipVerify: InsecureSkipVerify,
-	}
-
-	if SSLCA != "" {
-		caCert, err := ioutil.ReadFile(SSLCA)
-		if err != nil {
-			return nil, errors.New(fmt.Sprintf("Could not load TLS CA: %s",
-				err))
-		}
-
-		caCertPool := x509.NewCertPool()
-		caCertPool.AppendCertsFromPEM(caCert)
-		t.RootCAs = caCertPool
-	}
-
-	if SSLCert != "" && SSLKey != "" {
-		cert, err := tls.LoadX509KeyPair(SSLCert, SSLKey)
-		if err != nil {
-			return nil, errors.New(fmt.Sprintf(
-				"Could not load TLS client key/certificate from %s:%s: %s",
-				SSLKey, SSLCert, err))
-		}
-
-		t.Certificates = []tls.Certificate{cert}
-		t.BuildNameToCertificate()
-	}
-
-	// will be nil by default if nothing is provided
-	return t, nil
-}
-
 // SnakeCase converts the given string to snake case following the Golang format:
 // acronyms are converted to lower-case and preceded by an underscore.
 func SnakeCase(in string) string {
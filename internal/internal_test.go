@@ -155,3 +155,61 @@ func TestDuration(t *testing.T) {
 	d.UnmarshalTOML([]byte(`1.5`))
 	assert.Equal(t, time.Second, d.Duration)
 }
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"0", 0},
+		{"512", 512},
+		{"512B", 512},
+		{"64kB", 64000},
+		{"10 MB", 10000000},
+		{"1GiB", 1 << 30},
+	}
+	for _, test := range tests {
+		got, err := ParseSize(test.in)
+		assert.NoError(t, err, test.in)
+		assert.Equal(t, test.want, got, test.in)
+	}
+
+	_, err := ParseSize("ten MB")
+	assert.Error(t, err)
+	_, err = ParseSize("-1")
+	assert.Error(t, err)
+}
+
+func TestParseTimestamp(t *testing.T) {
+	tests := []struct {
+		format    string
+		timestamp interface{}
+		want      time.Time
+	}{
+		{"unix", float64(1500000000), time.Unix(1500000000, 0)},
+		{"unix", "1500000000.5", time.Unix(1500000000, 5e8)},
+		{"unix", "1500000000.000000015", time.Unix(1500000000, 15)},
+		{"unix", "1500000000.1234567891", time.Unix(1500000000, 123456789)},
+		{"unix", "-1.5", time.Unix(-2, 5e8)},
+		{"unix_ms", "1500000000123.4567", time.Unix(1500000000, 123456700)},
+		{"unix_ms", float64(1500000000123), time.Unix(1500000000, 123e6)},
+		{"unix_us", int64(1500000000123456), time.Unix(1500000000, 123456e3)},
+		{"unix_ns", "1500000000123456789", time.Unix(1500000000, 123456789)},
+		{"RFC3339", "2017-07-14T02:40:00Z", time.Unix(1500000000, 0)},
+		{"2006-01-02 15:04:05", "2017-07-14 02:40:00", time.Unix(1500000000, 0)},
+	}
+	for _, test := range tests {
+		got, err := ParseTimestamp(test.format, test.timestamp)
+		assert.NoError(t, err, test.format)
+		assert.True(t, test.want.Equal(got), "%s: %v != %v", test.format, test.want, got)
+	}
+
+	_, err := ParseTimestamp("unix", "yesterday")
+	assert.Error(t, err)
+	_, err = ParseTimestamp("unix", "1500000000.5e3")
+	assert.Error(t, err)
+	_, err = ParseTimestamp("RFC3339", float64(1500000000))
+	assert.Error(t, err)
+	_, err = ParseTimestamp("", "2017-07-14T02:40:00Z")
+	assert.Error(t, err)
+}
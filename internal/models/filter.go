@@ -2,6 +2,7 @@ package models
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/influxdata/telegraf/filter"
 )
@@ -33,6 +34,11 @@ type Filter struct {
 	TagInclude []string
 	tagInclude filter.Filter
 
+	// MetricPass is an expression that metrics must satisfy to pass, see
+	// filter.Expression.
+	MetricPass string
+	metricPass *filter.Expression
+
 	isActive bool
 }
 
@@ -45,7 +51,8 @@ func (f *Filter) Compile() error {
 		len(f.TagInclude) == 0 &&
 		len(f.TagExclude) == 0 &&
 		len(f.TagPass) == 0 &&
-		len(f.TagDrop) == 0 {
+		len(f.TagDrop) == 0 &&
+		f.MetricPass == "" {
 		return nil
 	}
 
@@ -90,17 +97,25 @@ func (f *Filter) Compile() error {
 			return fmt.Errorf("Error compiling 'tagpass', %s", err)
 		}
 	}
+
+	if f.MetricPass != "" {
+		f.metricPass, err = filter.CompileExpression(f.MetricPass)
+		if err != nil {
+			return fmt.Errorf("Error compiling 'metricpass', %s", err)
+		}
+	}
 	return nil
 }
 
-// Apply applies the filter to the given measurement name, fields map, and
-// tags map. It will return false if the metric should be "filtered out", and
-// true if the metric should "pass".
+// Apply applies the filter to the given measurement name, fields map, tags
+// map and time. It will return false if the metric should be "filtered out",
+// and true if the metric should "pass".
 // It will modify tags & fields in-place if they need to be deleted.
 func (f *Filter) Apply(
 	measurement string,
 	fields map[string]interface{},
 	tags map[string]string,
+	t time.Time,
 ) bool {
 	if !f.isActive {
 		return true
@@ -116,6 +131,12 @@ func (f *Filter) Apply(
 		return false
 	}
 
+	// check if the metric satisfies the expression, before any field or tag
+	// is removed
+	if f.metricPass != nil && !f.metricPass.Match(measurement, tags, fields, t) {
+		return false
+	}
+
 	// filter fields
 	for fieldkey, _ := range fields {
 		if !f.shouldFieldPass(fieldkey) {
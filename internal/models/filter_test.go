@@ -2,6 +2,7 @@ package models
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -12,7 +13,7 @@ func TestFilter_ApplyEmpty(t *testing.T) {
 	require.NoError(t, f.Compile())
 	assert.False(t, f.IsActive())
 
-	assert.True(t, f.Apply("m", map[string]interface{}{"value": int64(1)}, map[string]string{}))
+	assert.True(t, f.Apply("m", map[string]interface{}{"value": int64(1)}, map[string]string{}, time.Now()))
 }
 
 func TestFilter_ApplyTagsDontPass(t *testing.T) {
@@ -31,7 +32,7 @@ func TestFilter_ApplyTagsDontPass(t *testing.T) {
 
 	assert.False(t, f.Apply("m",
 		map[string]interface{}{"value": int64(1)},
-		map[string]string{"cpu": "cpu-total"}))
+		map[string]string{"cpu": "cpu-total"}, time.Now()))
 }
 
 func TestFilter_ApplyDeleteFields(t *testing.T) {
@@ -43,7 +44,7 @@ func TestFilter_ApplyDeleteFields(t *testing.T) {
 	assert.True(t, f.IsActive())
 
 	fields := map[string]interface{}{"value": int64(1), "value2": int64(2)}
-	assert.True(t, f.Apply("m", fields, nil))
+	assert.True(t, f.Apply("m", fields, nil, time.Now()))
 	assert.Equal(t, map[string]interface{}{"value2": int64(2)}, fields)
 }
 
@@ -56,7 +57,37 @@ func TestFilter_ApplyDeleteAllFields(t *testing.T) {
 	assert.True(t, f.IsActive())
 
 	fields := map[string]interface{}{"value": int64(1), "value2": int64(2)}
-	assert.False(t, f.Apply("m", fields, nil))
+	assert.False(t, f.Apply("m", fields, nil, time.Now()))
+}
+
+func TestFilter_ApplyMetricPass(t *testing.T) {
+	f := Filter{
+		MetricPass: `usage_idle < 50 and tags.cpu != "cpu-total"`,
+		FieldDrop:  []string{"usage_idle"},
+	}
+	require.NoError(t, f.Compile())
+	assert.True(t, f.IsActive())
+
+	now := time.Now()
+	fields := map[string]interface{}{"usage_idle": 20.0, "usage_user": 80.0}
+	assert.True(t, f.Apply("cpu", fields, map[string]string{"cpu": "cpu0"}, now))
+	assert.Equal(t, map[string]interface{}{"usage_user": 80.0}, fields)
+
+	fields = map[string]interface{}{"usage_idle": 90.0}
+	assert.False(t, f.Apply("cpu", fields, map[string]string{"cpu": "cpu0"}, now))
+
+	fields = map[string]interface{}{"usage_idle": 20.0}
+	assert.False(t, f.Apply("cpu", fields, map[string]string{"cpu": "cpu-total"}, now))
+
+	fields = map[string]interface{}{"usage_user": 80.0}
+	assert.False(t, f.Apply("cpu", fields, map[string]string{"cpu": "cpu0"}, now))
+}
+
+func TestFilter_MetricPassError(t *testing.T) {
+	f := Filter{
+		MetricPass: "usage_idle <",
+	}
+	require.Error(t, f.Compile())
 }
 
 func TestFilter_Empty(t *testing.T) {
@@ -80,6 +80,7 @@ func (r *RunningAggregator) MakeMetric(
 // Add applies the given metric to the aggregator.
 // Before applying to the plugin, it will run any defined filters on the metric.
 // Apply returns true if the original metric should be dropped.
+// The metric is dropped once it has been aggregated, or filtered out.
 func (r *RunningAggregator) Add(in telegraf.Metric) bool {
 	if r.Config.Filter.IsActive() {
 		// check if the aggregator should apply this metric
@@ -87,11 +88,13 @@ func (r *RunningAggregator) Add(in telegraf.Metric) bool {
 		fields := in.Fields()
 		tags := in.Tags()
 		t := in.Time()
-		if ok := r.Config.Filter.Apply(name, fields, tags); !ok {
+		if ok := r.Config.Filter.Apply(name, fields, tags, t); !ok {
 			// aggregator should not apply this metric
+			in.Drop()
 			return false
 		}
 
+		in.Drop()
 		in, _ = metric.New(name, tags, fields, t)
 	}
 
@@ -106,6 +109,13 @@ func (r *RunningAggregator) push(acc telegraf.Accumulator) {
 	r.a.Push(acc)
 }
 
+// Push pushes the metrics of the current period, even if it is not over, and
+// resets the aggregator. It must not be called while the aggregator runs.
+func (r *RunningAggregator) Push(acc telegraf.Accumulator) {
+	r.push(acc)
+	r.reset()
+}
+
 func (r *RunningAggregator) reset() {
 	r.a.Reset()
 }
@@ -153,9 +163,11 @@ func (r *RunningAggregator) Run(
 				m.Time().After(r.periodEnd.Add(truncation).Add(r.Config.Delay)) {
 				// the metric is outside the current aggregation period, so
 				// skip it.
+				m.Drop()
 				continue
 			}
 			r.add(m)
+			m.Drop()
 		case <-periodT.C:
 			r.periodStart = r.periodEnd
 			r.periodEnd = r.periodStart.Add(r.Config.Period)
@@ -2,6 +2,7 @@ package models
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -18,6 +19,23 @@ type RunningInput struct {
 	defaultTags map[string]string
 
 	MetricsGathered selfstat.Stat
+	GatherTimeouts  selfstat.Stat
+
+	statusMu sync.Mutex
+	status   InputStatus
+}
+
+// InputStatus is the state of an input, as reported by the health API.
+type InputStatus struct {
+	// Started is when the input was started.
+	Started time.Time
+	// LastGather is when the last gather completed.
+	LastGather time.Time
+	// LastSuccess is when the last gather without error completed.
+	LastSuccess time.Time
+	// LastError is the last error reported by the input.
+	LastError     error
+	LastErrorTime time.Time
 }
 
 func NewRunningInput(
@@ -32,6 +50,11 @@ func NewRunningInput(
 			"metrics_gathered",
 			map[string]string{"input": config.Name},
 		),
+		GatherTimeouts: selfstat.Register(
+			"gather",
+			"gather_timeouts",
+			map[string]string{"input": config.Name},
+		),
 	}
 }
 
@@ -44,6 +67,10 @@ type InputConfig struct {
 	Tags              map[string]string
 	Filter            Filter
 	Interval          time.Duration
+
+	// Timeout is how long a gather can take before it is abandoned, set by
+	// gather_timeout as plugins have timeout options of their own.
+	Timeout time.Duration
 }
 
 func (r *RunningInput) Name() string {
@@ -94,3 +121,37 @@ func (r *RunningInput) SetTrace(trace bool) {
 func (r *RunningInput) SetDefaultTags(tags map[string]string) {
 	r.defaultTags = tags
 }
+
+// SetStarted records the time the input was started, and clears its status.
+func (r *RunningInput) SetStarted(t time.Time) {
+	r.statusMu.Lock()
+	defer r.statusMu.Unlock()
+	r.status = InputStatus{Started: t}
+}
+
+// RecordGather records that a gather which started at start has completed.
+// It is successful if no error was recorded since it started.
+func (r *RunningInput) RecordGather(start time.Time) {
+	r.statusMu.Lock()
+	defer r.statusMu.Unlock()
+	now := time.Now()
+	r.status.LastGather = now
+	if r.status.LastErrorTime.Before(start) {
+		r.status.LastSuccess = now
+	}
+}
+
+// RecordError records an error reported by the input.
+func (r *RunningInput) RecordError(err error) {
+	r.statusMu.Lock()
+	defer r.statusMu.Unlock()
+	r.status.LastError = err
+	r.status.LastErrorTime = time.Now()
+}
+
+// Status returns the current status of the input.
+func (r *RunningInput) Status() InputStatus {
+	r.statusMu.Lock()
+	defer r.statusMu.Unlock()
+	return r.status
+}
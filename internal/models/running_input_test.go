@@ -12,6 +12,34 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+func TestRunningInputStatus(t *testing.T) {
+	ri := NewRunningInput(&testInput{}, &InputConfig{
+		Name: "TestRunningInput",
+	})
+	ri.SetStarted(time.Now())
+	assert.True(t, ri.Status().LastGather.IsZero())
+
+	start := time.Now()
+	ri.RecordGather(start)
+	status := ri.Status()
+	assert.False(t, status.LastGather.IsZero())
+	assert.Equal(t, status.LastGather, status.LastSuccess)
+
+	// a gather reporting an error is not successful
+	start = time.Now()
+	ri.RecordError(fmt.Errorf("gather failed"))
+	ri.RecordGather(start)
+	status = ri.Status()
+	assert.True(t, status.LastSuccess.Before(status.LastGather))
+	assert.EqualError(t, status.LastError, "gather failed")
+
+	// errors reported before a gather do not affect it
+	time.Sleep(time.Millisecond)
+	ri.RecordGather(time.Now())
+	status = ri.Status()
+	assert.Equal(t, status.LastGather, status.LastSuccess)
+}
+
 func TestMakeMetricNoFields(t *testing.T) {
 	now := time.Now()
 	ri := NewRunningInput(&testInput{}, &InputConfig{
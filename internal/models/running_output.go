@@ -1,12 +1,14 @@
 package models
 
 import (
+	"fmt"
 	"log"
 	"sync"
 	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal/buffer"
+	"github.com/influxdata/telegraf/internal/retry"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/selfstat"
 )
@@ -27,15 +29,35 @@ type RunningOutput struct {
 	MetricBufferLimit int
 	MetricBatchSize   int
 
+	// BatchReady receives a value each time a full batch of metrics has
+	// been buffered, so that it can be written before the next flush.
+	BatchReady chan struct{}
+
 	MetricsFiltered selfstat.Stat
 	MetricsWritten  selfstat.Stat
 	BufferSize      selfstat.Stat
 	BufferLimit     selfstat.Stat
+	DiskBufferBytes selfstat.Stat
 	WriteTime       selfstat.Stat
 
 	metrics     *buffer.Buffer
 	failMetrics *buffer.Buffer
 
+	// disk replaces both in-memory buffers when the output has a
+	// buffer_directory configured.
+	disk *buffer.DiskBuffer
+
+	// backoff delays connection and write attempts after failures.
+	backoff *retry.Backoff
+
+	stateMu       sync.Mutex
+	connected     bool
+	disabled      bool
+	started       time.Time
+	lastWrite     time.Time
+	lastError     error
+	lastErrorTime time.Time
+
 	// Guards against concurrent calls to the Output as described in #3009
 	sync.Mutex
 }
@@ -55,12 +77,14 @@ func NewRunningOutput(
 	}
 	ro := &RunningOutput{
 		Name:              name,
-		metrics:           buffer.NewBuffer(batchSize),
+		metrics:           buffer.NewBuffer(bufferLimit),
 		failMetrics:       buffer.NewBuffer(bufferLimit),
 		Output:            output,
 		Config:            conf,
 		MetricBufferLimit: bufferLimit,
 		MetricBatchSize:   batchSize,
+		BatchReady:        make(chan struct{}, 1),
+		backoff:           retry.NewBackoff(conf.Retry),
 		MetricsWritten: selfstat.Register(
 			"write",
 			"metrics_written",
@@ -81,6 +105,11 @@ func NewRunningOutput(
 			"buffer_limit",
 			map[string]string{"output": name},
 		),
+		DiskBufferBytes: selfstat.Register(
+			"write",
+			"disk_buffer_bytes",
+			map[string]string{"output": name},
+		),
 		WriteTime: selfstat.RegisterTiming(
 			"write",
 			"write_time_ns",
@@ -91,12 +120,81 @@ func NewRunningOutput(
 	return ro
 }
 
-// AddMetric adds a metric to the output. This function can also write cached
-// points if FlushBufferWhenFull is true.
+// OpenBuffer opens the on-disk buffer of the output, if it has a
+// buffer_directory configured. Metrics left in the buffer by a previous run
+// will be written, in order, before any new metrics.
+func (ro *RunningOutput) OpenBuffer() error {
+	if ro.Config.BufferDirectory == "" || ro.disk != nil {
+		return nil
+	}
+	disk, err := buffer.NewDiskBuffer(ro.Config.BufferDirectory,
+		ro.Config.BufferMaxSize, ro.Config.BufferFsync)
+	if err != nil {
+		return err
+	}
+	ro.disk = disk
+	ro.BufferSize.Set(int64(disk.Len()))
+	ro.DiskBufferBytes.Set(disk.Size())
+	return nil
+}
+
+// Connect connects to the output. On failure, further attempts are delayed
+// according to the output's retry policy.
+func (ro *RunningOutput) Connect() error {
+	log.Printf("D! Attempting connection to output: %s\n", ro.Name)
+	if err := ro.Output.Connect(); err != nil {
+		return err
+	}
+	log.Printf("D! Successfully connected to output: %s\n", ro.Name)
+	ro.stateMu.Lock()
+	ro.connected = true
+	ro.stateMu.Unlock()
+	return nil
+}
+
+// Connected returns true once the output has successfully connected.
+func (ro *RunningOutput) Connected() bool {
+	ro.stateMu.Lock()
+	defer ro.stateMu.Unlock()
+	return ro.connected
+}
+
+// Disabled returns true if the output failed to connect and its retry policy
+// gave up on it.
+func (ro *RunningOutput) Disabled() bool {
+	ro.stateMu.Lock()
+	defer ro.stateMu.Unlock()
+	return ro.disabled
+}
+
+// RetryFailed records a failed connection attempt made outside of Write, so
+// that the next one is delayed.
+func (ro *RunningOutput) RetryFailed(err error) error {
+	return ro.retryFailed("connect", err)
+}
+
+// BufferLen returns the number of metrics waiting to be written.
+func (ro *RunningOutput) BufferLen() int {
+	if ro.disk != nil {
+		return ro.disk.Len()
+	}
+	return ro.failMetrics.Len() + ro.metrics.Len()
+}
+
+// AddMetric adds a metric to the output. It never writes to the output
+// itself, but signals BatchReady each time a full batch has been buffered.
+//
+// The metric is accepted once it has been written, or once it is safe in the
+// disk buffer. It is rejected if it is dropped from a full buffer, if the
+// output gives up on writing it, or if the output is disabled.
 func (ro *RunningOutput) AddMetric(m telegraf.Metric) {
 	if m == nil {
 		return
 	}
+	if ro.Disabled() {
+		m.Reject()
+		return
+	}
 	// Filter any tagexclude/taginclude parameters before adding metric
 	if ro.Config.Filter.IsActive() {
 		// In order to filter out tags, we need to create a new metric, since
@@ -105,26 +203,246 @@ func (ro *RunningOutput) AddMetric(m telegraf.Metric) {
 		tags := m.Tags()
 		fields := m.Fields()
 		t := m.Time()
-		if ok := ro.Config.Filter.Apply(name, fields, tags); !ok {
+		if ok := ro.Config.Filter.Apply(name, fields, tags, t); !ok {
 			ro.MetricsFiltered.Incr(1)
+			m.Drop()
 			return
 		}
 		// error is not possible if creating from another metric, so ignore.
-		m, _ = metric.New(name, tags, fields, t)
+		filtered, _ := metric.New(name, tags, fields, t)
+		filtered = metric.WithTrackingOf(filtered, m)
+		m.Drop()
+		m = filtered
 	}
 
-	ro.metrics.Add(m)
-	if ro.metrics.Len() == ro.MetricBatchSize {
-		batch := ro.metrics.Batch(ro.MetricBatchSize)
-		err := ro.write(batch)
-		if err != nil {
-			ro.failMetrics.Add(batch...)
+	if ro.disk != nil {
+		if err := ro.disk.Add(m); err != nil {
+			log.Printf("E! Output [%s] could not buffer metric to disk: %s",
+				ro.Name, err)
+			m.Reject()
+		} else {
+			m.Accept()
+		}
+		if ro.disk.Len()%ro.MetricBatchSize == 0 {
+			ro.batchReady()
 		}
+		return
+	}
+
+	ro.metrics.Add(m)
+	ro.trimMemory()
+	if ro.metrics.Len()%ro.MetricBatchSize == 0 {
+		ro.batchReady()
+	}
+}
+
+// trimMemory drops the oldest metrics, starting with the failed writes, once
+// the in-memory buffers together hold more than MetricBufferLimit metrics.
+func (ro *RunningOutput) trimMemory() {
+	excess := ro.failMetrics.Len() + ro.metrics.Len() - ro.MetricBufferLimit
+	if excess <= 0 {
+		return
+	}
+	dropped := ro.failMetrics.Batch(excess)
+	if len(dropped) < excess {
+		dropped = append(dropped, ro.metrics.Batch(excess-len(dropped))...)
 	}
+	for _, m := range dropped {
+		m.Reject()
+	}
+	buffer.MetricsDropped.Incr(int64(len(dropped)))
 }
 
-// Write writes all cached points to this output.
+func (ro *RunningOutput) batchReady() {
+	select {
+	case ro.BatchReady <- struct{}{}:
+	default:
+		// a write is already pending
+	}
+}
+
+// Write writes all cached points to this output, connecting to it first if
+// needed. Nothing is done while the retry policy is delaying the next attempt
+// after a failure.
 func (ro *RunningOutput) Write() error {
+	if ro.Disabled() {
+		return nil
+	}
+	if !ro.backoff.Ready() {
+		log.Printf("D! Output [%s] delaying write until %s after %d failures",
+			ro.Name, ro.backoff.Next().Format(time.RFC3339), ro.backoff.Failures())
+		return nil
+	}
+
+	if !ro.Connected() {
+		if err := ro.Connect(); err != nil {
+			ro.recordError(err)
+			return ro.retryFailed("connect", err)
+		}
+	}
+
+	var err error
+	if ro.disk != nil {
+		err = ro.writeDisk()
+	} else {
+		err = ro.writeMemory()
+	}
+	if err != nil {
+		ro.recordError(err)
+		return ro.retryFailed("write", err)
+	}
+	ro.backoff.Reset()
+	ro.stateMu.Lock()
+	ro.lastWrite = time.Now()
+	ro.stateMu.Unlock()
+	return nil
+}
+
+// WriteAll writes all cached points to this output, retrying failed attempts
+// once the retry policy allows it, until nothing is left or the policy gives
+// up. maxAttempts limits the number of failed attempts when it is not 0, so
+// that a policy that never gives up does not retry forever. It returns early
+// if shutdown is closed.
+func (ro *RunningOutput) WriteAll(shutdown chan struct{}, maxAttempts int) error {
+	var attempts int
+	for {
+		if ro.Disabled() {
+			return fmt.Errorf("output %s is disabled", ro.Name)
+		}
+		if wait := ro.backoff.Next().Sub(time.Now()); wait > 0 {
+			select {
+			case <-shutdown:
+				return fmt.Errorf("stopped with %d metrics left to write",
+					ro.BufferLen())
+			case <-time.After(wait):
+			}
+		}
+
+		err := ro.Write()
+		if err == nil {
+			if ro.BufferLen() == 0 {
+				return nil
+			}
+			continue
+		}
+		attempts++
+		if _, ok := err.(*GiveUpError); ok {
+			return err
+		}
+		// the failures are reset when the policy gives up
+		if ro.backoff.Failures() == 0 || (maxAttempts > 0 && attempts >= maxAttempts) {
+			return err
+		}
+	}
+}
+
+func (ro *RunningOutput) recordError(err error) {
+	ro.stateMu.Lock()
+	defer ro.stateMu.Unlock()
+	ro.lastError = err
+	ro.lastErrorTime = time.Now()
+}
+
+// OutputStatus is the state of an output, as reported by the health API.
+type OutputStatus struct {
+	Connected bool
+	Disabled  bool
+	// BufferSize is the number of metrics waiting to be written.
+	BufferSize int
+	// BufferFullness is the fraction of the buffer in use, between 0 and 1.
+	// It is always 0 for a disk buffer without a maximum size.
+	BufferFullness float64
+	// Started is when the output's writer was started.
+	Started time.Time
+	// LastWrite is when the output last wrote all its buffered metrics.
+	LastWrite     time.Time
+	LastError     error
+	LastErrorTime time.Time
+}
+
+// SetStarted records the time the output's writer was started.
+func (ro *RunningOutput) SetStarted(t time.Time) {
+	ro.stateMu.Lock()
+	defer ro.stateMu.Unlock()
+	ro.started = t
+}
+
+// Status returns the current status of the output.
+func (ro *RunningOutput) Status() OutputStatus {
+	status := OutputStatus{
+		BufferSize: ro.BufferLen(),
+	}
+	if ro.disk != nil {
+		if ro.Config.BufferMaxSize > 0 {
+			status.BufferFullness = float64(ro.disk.Size()) /
+				float64(ro.Config.BufferMaxSize)
+		}
+	} else {
+		status.BufferFullness = float64(status.BufferSize) /
+			float64(ro.MetricBufferLimit)
+	}
+	if status.BufferFullness > 1 {
+		status.BufferFullness = 1
+	}
+
+	ro.stateMu.Lock()
+	defer ro.stateMu.Unlock()
+	status.Connected = ro.connected
+	status.Disabled = ro.disabled
+	status.Started = ro.started
+	status.LastWrite = ro.lastWrite
+	status.LastError = ro.lastError
+	status.LastErrorTime = ro.lastErrorTime
+	return status
+}
+
+// retryFailed records a failed connection or write attempt, and applies the
+// give up action of the retry policy once it has been reached.
+func (ro *RunningOutput) retryFailed(op string, err error) error {
+	if !ro.backoff.Fail() {
+		return err
+	}
+
+	if ro.Config.Retry.GiveUp == retry.GiveUpExit {
+		return &GiveUpError{Output: ro.Name, Err: err}
+	}
+
+	if op == "connect" {
+		log.Printf("E! Output [%s] giving up on connecting after %d attempts, "+
+			"disabling it: %s", ro.Name, ro.Config.Retry.MaxAttempts, err)
+		ro.stateMu.Lock()
+		ro.disabled = true
+		ro.stateMu.Unlock()
+		return err
+	}
+
+	var dropped int
+	if ro.disk != nil {
+		batch, perr := ro.disk.Peek(ro.MetricBatchSize)
+		if perr == nil {
+			perr = ro.disk.Remove(len(batch))
+		}
+		if perr != nil {
+			log.Printf("E! Output [%s] could not drop batch from disk buffer: %s",
+				ro.Name, perr)
+		}
+		dropped = len(batch)
+	} else {
+		batch := ro.failMetrics.Batch(ro.MetricBatchSize)
+		for _, m := range batch {
+			m.Reject()
+		}
+		dropped = len(batch)
+	}
+	buffer.MetricsDropped.Incr(int64(dropped))
+	log.Printf("E! Output [%s] giving up on writing after %d attempts, "+
+		"dropped %d metrics: %s", ro.Name, ro.Config.Retry.MaxAttempts,
+		dropped, err)
+	return err
+}
+
+// writeMemory writes the metrics held in the in-memory buffers.
+func (ro *RunningOutput) writeMemory() error {
 	nFails, nMetrics := ro.failMetrics.Len(), ro.metrics.Len()
 	ro.BufferSize.Set(int64(nFails + nMetrics))
 	log.Printf("D! Output [%s] buffer fullness: %d / %d metrics. ",
@@ -154,20 +472,81 @@ func (ro *RunningOutput) Write() error {
 		}
 	}
 
-	batch := ro.metrics.Batch(ro.MetricBatchSize)
-	// see comment above about not trying to write to an already failed output.
-	// if ro.failMetrics is empty then err will always be nil at this point.
-	if err == nil {
-		err = ro.write(batch)
+	// write the metrics buffered since the last flush, in batches. Metrics
+	// added while writing are left for the next flush.
+	for nMetrics > 0 {
+		batch := ro.metrics.Batch(min(nMetrics, ro.MetricBatchSize))
+		nMetrics -= len(batch)
+		if len(batch) == 0 {
+			break
+		}
+		// see comment above about not trying to write to an already failed
+		// output. if ro.failMetrics is empty then err will always be nil at
+		// this point.
+		if err == nil {
+			err = ro.write(batch)
+		}
+		if err != nil {
+			ro.failMetrics.Add(batch...)
+		}
 	}
+	return err
+}
 
-	if err != nil {
-		ro.failMetrics.Add(batch...)
-		return err
+func min(a, b int) int {
+	if b < a {
+		return b
+	}
+	return a
+}
+
+// writeDisk writes the metrics present in the disk buffer when it is called,
+// removing each batch from the buffer once it has been written.
+func (ro *RunningOutput) writeDisk() error {
+	nMetrics := ro.disk.Len()
+	ro.BufferSize.Set(int64(nMetrics))
+	ro.DiskBufferBytes.Set(ro.disk.Size())
+	log.Printf("D! Output [%s] disk buffer fullness: %d metrics, %d bytes",
+		ro.Name, nMetrics, ro.disk.Size())
+
+	if err := ro.disk.Sync(); err != nil {
+		log.Printf("E! Output [%s] could not sync disk buffer: %s", ro.Name, err)
+	}
+
+	nBatches := (nMetrics + ro.MetricBatchSize - 1) / ro.MetricBatchSize
+	for i := 0; i < nBatches; i++ {
+		batch, err := ro.disk.Peek(ro.MetricBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			break
+		}
+		if err := ro.write(batch); err != nil {
+			return err
+		}
+		if err := ro.disk.Remove(len(batch)); err != nil {
+			return err
+		}
 	}
+
+	ro.BufferSize.Set(int64(ro.disk.Len()))
+	ro.DiskBufferBytes.Set(ro.disk.Size())
 	return nil
 }
 
+// Close closes the output and its on-disk buffer.
+func (ro *RunningOutput) Close() error {
+	err := ro.Output.Close()
+	if ro.disk != nil {
+		if derr := ro.disk.Close(); derr != nil && err == nil {
+			err = derr
+		}
+		ro.disk = nil
+	}
+	return err
+}
+
 func (ro *RunningOutput) write(metrics []telegraf.Metric) error {
 	nMetrics := len(metrics)
 	if nMetrics == 0 {
@@ -183,12 +562,41 @@ func (ro *RunningOutput) write(metrics []telegraf.Metric) error {
 			ro.Name, nMetrics, elapsed)
 		ro.MetricsWritten.Incr(int64(nMetrics))
 		ro.WriteTime.Incr(elapsed.Nanoseconds())
+		for _, m := range metrics {
+			m.Accept()
+		}
 	}
 	return err
 }
 
+// GiveUpError is returned by RunningOutput.Write when the retry policy of an
+// output gives up, and requires telegraf to exit.
+type GiveUpError struct {
+	Output string
+	Err    error
+}
+
+func (e *GiveUpError) Error() string {
+	return fmt.Sprintf("giving up on output %s: %s", e.Output, e.Err)
+}
+
 // OutputConfig containing name and filter
 type OutputConfig struct {
 	Name   string
 	Filter Filter
+
+	// BufferDirectory enables the on-disk buffer when not empty.
+	BufferDirectory string
+	BufferMaxSize   int64
+	BufferFsync     buffer.FsyncPolicy
+
+	// Per-output flush and batch settings, overriding the agent settings
+	// when non-zero.
+	FlushInterval     time.Duration
+	FlushJitter       time.Duration
+	MetricBatchSize   int
+	MetricBufferLimit int
+
+	// Retry is the policy used when connecting or writing fails.
+	Retry retry.Policy
 }
@@ -2,10 +2,15 @@ package models
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/retry"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 
 	"github.com/stretchr/testify/assert"
@@ -248,9 +253,17 @@ func TestRunningOutputDefault(t *testing.T) {
 	assert.Len(t, m.Metrics(), 10)
 }
 
-// Test that running output doesn't flush until it's full when
-// FlushBufferWhenFull is set.
-func TestRunningOutputFlushWhenFull(t *testing.T) {
+func batchReady(ro *RunningOutput) bool {
+	select {
+	case <-ro.BatchReady:
+		return true
+	default:
+		return false
+	}
+}
+
+// Test that running output signals a full batch, but never writes by itself.
+func TestRunningOutputBatchReady(t *testing.T) {
 	conf := &OutputConfig{
 		Filter: Filter{},
 	}
@@ -258,28 +271,28 @@ func TestRunningOutputFlushWhenFull(t *testing.T) {
 	m := &mockOutput{}
 	ro := NewRunningOutput("test", m, conf, 6, 10)
 
-	// Fill buffer to 1 under limit
+	// Fill buffer to 1 under batch size
 	for _, metric := range first5 {
 		ro.AddMetric(metric)
 	}
-	// no flush yet
-	assert.Len(t, m.Metrics(), 0)
+	assert.False(t, batchReady(ro))
 
 	// add one more metric
 	ro.AddMetric(next5[0])
-	// now it flushed
-	assert.Len(t, m.Metrics(), 6)
+	assert.True(t, batchReady(ro))
+	// no flush yet
+	assert.Len(t, m.Metrics(), 0)
 
-	// add one more metric and write it manually
+	// add one more metric and write everything
 	ro.AddMetric(next5[1])
+	assert.False(t, batchReady(ro))
 	err := ro.Write()
 	assert.NoError(t, err)
 	assert.Len(t, m.Metrics(), 7)
 }
 
-// Test that running output doesn't flush until it's full when
-// FlushBufferWhenFull is set, twice.
-func TestRunningOutputMultiFlushWhenFull(t *testing.T) {
+// Test that running output signals once for each full batch.
+func TestRunningOutputMultiBatchReady(t *testing.T) {
 	conf := &OutputConfig{
 		Filter: Filter{},
 	}
@@ -287,15 +300,21 @@ func TestRunningOutputMultiFlushWhenFull(t *testing.T) {
 	m := &mockOutput{}
 	ro := NewRunningOutput("test", m, conf, 4, 12)
 
-	// Fill buffer past limit twive
-	for _, metric := range first5 {
+	for _, metric := range first5[:4] {
 		ro.AddMetric(metric)
 	}
-	for _, metric := range next5 {
+	assert.True(t, batchReady(ro))
+	ro.AddMetric(first5[4])
+	assert.False(t, batchReady(ro))
+	for _, metric := range next5[:3] {
 		ro.AddMetric(metric)
 	}
-	// flushed twice
-	assert.Len(t, m.Metrics(), 8)
+	assert.True(t, batchReady(ro))
+
+	// batches are written in order and without losing the remainder.
+	err := ro.Write()
+	assert.NoError(t, err)
+	assert.Equal(t, append(first5, next5[:3]...), m.Metrics())
 }
 
 func TestRunningOutputWriteFail(t *testing.T) {
@@ -330,6 +349,33 @@ func TestRunningOutputWriteFail(t *testing.T) {
 	assert.Len(t, m.Metrics(), 10)
 }
 
+// Test that the failed writes and the new metrics together are limited to
+// the buffer limit, dropping the oldest metrics first.
+func TestRunningOutputBufferLimit(t *testing.T) {
+	conf := &OutputConfig{
+		Filter: Filter{},
+	}
+
+	m := &mockOutput{}
+	m.failWrite = true
+	ro := NewRunningOutput("test", m, conf, 2, 6)
+
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+	require.Error(t, ro.Write())
+	assert.Equal(t, 5, ro.BufferLen())
+
+	for _, metric := range next5[:3] {
+		ro.AddMetric(metric)
+	}
+	assert.Equal(t, 6, ro.BufferLen())
+
+	m.failWrite = false
+	require.NoError(t, ro.Write())
+	assert.Equal(t, append(first5[2:], next5[:3]...), m.Metrics())
+}
+
 // Verify that the order of points is preserved during a write failure.
 func TestRunningOutputWriteFailOrder(t *testing.T) {
 	conf := &OutputConfig{
@@ -479,6 +525,271 @@ func TestRunningOutputWriteFailOrder3(t *testing.T) {
 	assert.Equal(t, expected, m.Metrics())
 }
 
+// Verify that metrics are kept on disk while writes fail, and survive the
+// output being closed and opened again.
+func TestRunningOutputDiskBuffer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "telegraf-running-output")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	conf := &OutputConfig{
+		Filter:          Filter{},
+		BufferDirectory: dir,
+	}
+
+	m := &mockOutput{}
+	m.failWrite = true
+	ro := NewRunningOutput("test", m, conf, 3, 1000)
+	require.NoError(t, ro.OpenBuffer())
+
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+	require.Error(t, ro.Write())
+	assert.Equal(t, 5, ro.BufferLen())
+	require.NoError(t, ro.Close())
+
+	m = &mockOutput{}
+	ro = NewRunningOutput("test", m, conf, 3, 1000)
+	require.NoError(t, ro.OpenBuffer())
+	defer ro.Close()
+	assert.Equal(t, 5, ro.BufferLen())
+
+	for _, metric := range next5 {
+		ro.AddMetric(metric)
+	}
+	require.NoError(t, ro.Write())
+	assert.Equal(t, 0, ro.BufferLen())
+
+	var expected, actual []string
+	for _, metric := range append(first5, next5...) {
+		expected = append(expected, metric.String())
+	}
+	for _, metric := range m.Metrics() {
+		actual = append(actual, metric.String())
+	}
+	assert.Equal(t, expected, actual)
+}
+
+func TestRunningOutputRetryBackoff(t *testing.T) {
+	conf := &OutputConfig{
+		Filter: Filter{},
+		Retry: retry.Policy{
+			InitialDelay: time.Hour,
+		},
+	}
+
+	m := &mockOutput{}
+	m.failWrite = true
+	ro := NewRunningOutput("test", m, conf, 4, 12)
+
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+	require.Error(t, ro.Write())
+
+	// the next attempt is delayed
+	m.failWrite = false
+	require.NoError(t, ro.Write())
+	assert.Len(t, m.Metrics(), 0)
+	assert.Equal(t, 5, ro.BufferLen())
+}
+
+func TestRunningOutputRetryGiveUpDrop(t *testing.T) {
+	conf := &OutputConfig{
+		Filter: Filter{},
+		Retry: retry.Policy{
+			MaxAttempts: 2,
+			GiveUp:      retry.GiveUpDrop,
+		},
+	}
+
+	m := &mockOutput{}
+	m.failWrite = true
+	ro := NewRunningOutput("test", m, conf, 4, 12)
+
+	for _, metric := range append(first5, next5...) {
+		ro.AddMetric(metric)
+	}
+	require.Error(t, ro.Write())
+	// gives up on the second attempt, dropping the oldest batch
+	require.Error(t, ro.Write())
+	assert.Equal(t, 6, ro.BufferLen())
+
+	m.failWrite = false
+	require.NoError(t, ro.Write())
+	require.Len(t, m.Metrics(), 6)
+	assert.Equal(t, first5[4].String(), m.Metrics()[0].String())
+}
+
+// Tracked metrics are accepted once written, and rejected when dropped from
+// a full buffer or when the output gives up on them.
+func TestRunningOutputTracking(t *testing.T) {
+	conf := &OutputConfig{
+		Filter: Filter{},
+		Retry: retry.Policy{
+			MaxAttempts: 1,
+			GiveUp:      retry.GiveUpDrop,
+		},
+	}
+
+	delivered := make(map[telegraf.TrackingID]bool)
+	notify := func(info telegraf.DeliveryInfo) {
+		delivered[info.ID()] = info.Delivered()
+	}
+
+	m := &mockOutput{}
+	ro := NewRunningOutput("test", m, conf, 2, 2)
+
+	var ids []telegraf.TrackingID
+	for _, untracked := range first5[:3] {
+		tracked, id := metric.WithTracking(untracked.Copy(), notify)
+		ids = append(ids, id)
+		ro.AddMetric(tracked)
+	}
+	// the oldest metric was dropped from the full buffer
+	assert.Equal(t, map[telegraf.TrackingID]bool{ids[0]: false}, delivered)
+
+	require.NoError(t, ro.Write())
+	assert.Equal(t, map[telegraf.TrackingID]bool{
+		ids[0]: false, ids[1]: true, ids[2]: true}, delivered)
+
+	m.failWrite = true
+	tracked, id := metric.WithTracking(first5[3].Copy(), notify)
+	ro.AddMetric(tracked)
+	require.Error(t, ro.Write())
+	assert.False(t, delivered[id])
+	assert.Len(t, delivered, 4)
+}
+
+func TestRunningOutputRetryGiveUpExit(t *testing.T) {
+	conf := &OutputConfig{
+		Filter: Filter{},
+		Retry: retry.Policy{
+			MaxAttempts: 1,
+			GiveUp:      retry.GiveUpExit,
+		},
+	}
+
+	m := &mockOutput{}
+	m.failWrite = true
+	ro := NewRunningOutput("test", m, conf, 4, 12)
+
+	ro.AddMetric(first5[0])
+	err := ro.Write()
+	require.Error(t, err)
+	assert.IsType(t, &GiveUpError{}, err)
+}
+
+func TestRunningOutputRetryConnect(t *testing.T) {
+	conf := &OutputConfig{
+		Filter: Filter{},
+		Retry: retry.Policy{
+			MaxAttempts: 2,
+			GiveUp:      retry.GiveUpDrop,
+		},
+	}
+
+	m := &mockOutput{}
+	m.failConnect = true
+	ro := NewRunningOutput("test", m, conf, 4, 12)
+	err := ro.Connect()
+	require.Error(t, err)
+	require.Error(t, ro.RetryFailed(err))
+	assert.False(t, ro.Connected())
+
+	ro.AddMetric(first5[0])
+	// gives up on the second attempt and disables the output
+	require.Error(t, ro.Write())
+	assert.True(t, ro.Disabled())
+
+	m.failConnect = false
+	ro.AddMetric(first5[1])
+	require.NoError(t, ro.Write())
+	assert.Len(t, m.Metrics(), 0)
+}
+
+func TestRunningOutputWriteAll(t *testing.T) {
+	conf := &OutputConfig{
+		Filter: Filter{},
+	}
+
+	m := &mockOutput{}
+	m.failWrite = true
+	ro := NewRunningOutput("test", m, conf, 4, 12)
+
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+	// the policy never gives up, the attempts are limited instead
+	require.Error(t, ro.WriteAll(nil, 2))
+	assert.Equal(t, 2, ro.backoff.Failures())
+	assert.Equal(t, 5, ro.BufferLen())
+
+	m.failWrite = false
+	require.NoError(t, ro.WriteAll(nil, 2))
+	assert.Len(t, m.Metrics(), 5)
+	assert.Equal(t, 0, ro.BufferLen())
+}
+
+func TestRunningOutputWriteAllShutdown(t *testing.T) {
+	conf := &OutputConfig{
+		Filter: Filter{},
+		Retry: retry.Policy{
+			InitialDelay: time.Hour,
+		},
+	}
+
+	m := &mockOutput{}
+	m.failWrite = true
+	ro := NewRunningOutput("test", m, conf, 4, 12)
+
+	ro.AddMetric(first5[0])
+	require.Error(t, ro.Write())
+
+	// the delayed attempt is not waited for once shutdown is closed
+	shutdown := make(chan struct{})
+	close(shutdown)
+	m.failWrite = false
+	assert.EqualError(t, ro.WriteAll(shutdown, 0),
+		"stopped with 1 metrics left to write")
+	assert.Len(t, m.Metrics(), 0)
+}
+
+func TestRunningOutputStatus(t *testing.T) {
+	conf := &OutputConfig{
+		Filter: Filter{},
+	}
+
+	m := &mockOutput{}
+	m.failWrite = true
+	ro := NewRunningOutput("test", m, conf, 4, 10)
+	ro.SetStarted(time.Now())
+
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+	status := ro.Status()
+	assert.Equal(t, 5, status.BufferSize)
+	assert.Equal(t, 0.5, status.BufferFullness)
+	assert.False(t, status.Connected)
+	assert.False(t, status.Started.IsZero())
+
+	require.Error(t, ro.Write())
+	status = ro.Status()
+	assert.True(t, status.Connected)
+	assert.True(t, status.LastWrite.IsZero())
+	assert.EqualError(t, status.LastError, "Failed Write!")
+	assert.False(t, status.LastErrorTime.IsZero())
+
+	m.failWrite = false
+	require.NoError(t, ro.Write())
+	status = ro.Status()
+	assert.Equal(t, 0, status.BufferSize)
+	assert.Equal(t, 0.0, status.BufferFullness)
+	assert.False(t, status.LastWrite.IsZero())
+}
+
 type mockOutput struct {
 	sync.Mutex
 
@@ -486,9 +797,14 @@ type mockOutput struct {
 
 	// if true, mock a write failure
 	failWrite bool
+	// if true, mock a connect failure
+	failConnect bool
 }
 
 func (m *mockOutput) Connect() error {
+	if m.failConnect {
+		return fmt.Errorf("Failed Connect!")
+	}
 	return nil
 }
 
@@ -4,6 +4,7 @@ import (
 	"sync"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 )
 
 type RunningProcessor struct {
@@ -27,25 +28,53 @@ type ProcessorConfig struct {
 	Filter Filter
 }
 
+// Stateless returns true if the processor can be applied concurrently, as a
+// telegraf.StatelessProcessor.
+func (rp *RunningProcessor) Stateless() bool {
+	p, ok := rp.Processor.(telegraf.StatelessProcessor)
+	return ok && p.Stateless()
+}
+
+// Apply applies the processor to the metrics that pass its filter. Calls are
+// serialized unless the processor is stateless.
 func (rp *RunningProcessor) Apply(in ...telegraf.Metric) []telegraf.Metric {
-	rp.Lock()
-	defer rp.Unlock()
+	if !rp.Stateless() {
+		rp.Lock()
+		defer rp.Unlock()
+	}
 
 	ret := []telegraf.Metric{}
 
-	for _, metric := range in {
+	for _, m := range in {
 		if rp.Config.Filter.IsActive() {
 			// check if the filter should be applied to this metric
-			if ok := rp.Config.Filter.Apply(metric.Name(), metric.Fields(), metric.Tags()); !ok {
+			if ok := rp.Config.Filter.Apply(m.Name(), m.Fields(), m.Tags(), m.Time()); !ok {
 				// this means filter should not be applied
-				ret = append(ret, metric)
+				ret = append(ret, m)
 				continue
 			}
 		}
 		// This metric should pass through the filter, so call the filter Apply
 		// function and append results to the output slice.
-		ret = append(ret, rp.Processor.Apply(metric)...)
+		ret = append(ret, track(m, rp.Processor.Apply(m))...)
 	}
 
 	return ret
 }
+
+// track tracks the metrics a processor returned for a tracked metric in the
+// same group, and drops the metric if the processor replaced or removed it.
+func track(in telegraf.Metric, out []telegraf.Metric) []telegraf.Metric {
+	var kept bool
+	for i, m := range out {
+		if m == in {
+			kept = true
+			continue
+		}
+		out[i] = metric.WithTrackingOf(m, in)
+	}
+	if !kept {
+		in.Drop()
+	}
+	return out
+}
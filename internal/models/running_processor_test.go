@@ -4,9 +4,11 @@ import (
 	"testing"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type TestProcessor struct {
@@ -115,3 +117,56 @@ func TestRunningProcessor_DroppedMetric(t *testing.T) {
 	}
 	assert.Equal(t, expectedNames, actualNames)
 }
+
+type statelessProcessor struct {
+	TestProcessor
+	stateless bool
+}
+
+func (p *statelessProcessor) Stateless() bool { return p.stateless }
+
+func TestRunningProcessor_Stateless(t *testing.T) {
+	rp := NewTestRunningProcessor()
+	assert.False(t, rp.Stateless())
+
+	rp.Processor = &statelessProcessor{}
+	assert.False(t, rp.Stateless())
+
+	rp.Processor = &statelessProcessor{stateless: true}
+	assert.True(t, rp.Stateless())
+}
+
+// The metrics a processor derives from a tracked metric are tracked with it.
+func TestRunningProcessor_Tracking(t *testing.T) {
+	var infos []telegraf.DeliveryInfo
+	notify := func(info telegraf.DeliveryInfo) {
+		infos = append(infos, info)
+	}
+	rp := NewTestRunningProcessor()
+
+	// replaced
+	in, _ := metric.WithTracking(testutil.TestMetric(1, "foo"), notify)
+	out := rp.Apply(in)
+	require.Len(t, out, 1)
+	assert.Equal(t, "fuz", out[0].Name())
+	assert.Empty(t, infos)
+	out[0].Accept()
+	require.Len(t, infos, 1)
+	assert.True(t, infos[0].Delivered())
+
+	// dropped
+	in, _ = metric.WithTracking(testutil.TestMetric(1, "dropme"), notify)
+	assert.Empty(t, rp.Apply(in))
+	require.Len(t, infos, 2)
+	assert.True(t, infos[1].Delivered())
+
+	// passed through
+	in, _ = metric.WithTracking(testutil.TestMetric(1, "baz"), notify)
+	out = rp.Apply(in)
+	require.Len(t, out, 1)
+	assert.True(t, in == out[0])
+	assert.Len(t, infos, 2)
+	out[0].Reject()
+	require.Len(t, infos, 3)
+	assert.False(t, infos[2].Delivered())
+}
@@ -0,0 +1,315 @@
+// Package prompb implements the protocol buffer messages of the Prometheus
+// remote write protocol.
+//
+// The messages are encoded by hand, as they are few and simple, rather than
+// depending on the generated code of the Prometheus server.
+package prompb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// MetricType is the type of a metric family, as sent in its metadata.
+type MetricType int32
+
+const (
+	MetricTypeUnknown        MetricType = 0
+	MetricTypeCounter        MetricType = 1
+	MetricTypeGauge          MetricType = 2
+	MetricTypeHistogram      MetricType = 3
+	MetricTypeGaugeHistogram MetricType = 4
+	MetricTypeSummary        MetricType = 5
+	MetricTypeInfo           MetricType = 6
+	MetricTypeStateset       MetricType = 7
+)
+
+// WriteRequest is the body of a remote write request, once decompressed.
+type WriteRequest struct {
+	Timeseries []TimeSeries
+	Metadata   []MetricMetadata
+}
+
+// TimeSeries is a series identified by its labels, including the metric name
+// as the "__name__" label, and its samples in time order.
+type TimeSeries struct {
+	Labels  []Label
+	Samples []Sample
+}
+
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Sample is a value at a timestamp in milliseconds.
+type Sample struct {
+	Value     float64
+	Timestamp int64
+}
+
+// MetricMetadata describes a metric family.
+type MetricMetadata struct {
+	Type             MetricType
+	MetricFamilyName string
+	Help             string
+	Unit             string
+}
+
+// protocol buffer wire types
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errTruncated = errors.New("prompb: truncated message")
+
+// Marshal encodes the request to the protocol buffer format.
+func (r *WriteRequest) Marshal() ([]byte, error) {
+	var buf, msg []byte
+	for i := range r.Timeseries {
+		msg = r.Timeseries[i].marshal(msg[:0])
+		buf = appendBytes(buf, 1, msg)
+	}
+	for i := range r.Metadata {
+		msg = r.Metadata[i].marshal(msg[:0])
+		buf = appendBytes(buf, 3, msg)
+	}
+	return buf, nil
+}
+
+func (ts *TimeSeries) marshal(buf []byte) []byte {
+	var msg []byte
+	for _, l := range ts.Labels {
+		msg = appendString(msg[:0], 1, l.Name)
+		msg = appendString(msg, 2, l.Value)
+		buf = appendBytes(buf, 1, msg)
+	}
+	for _, s := range ts.Samples {
+		msg = appendTag(msg[:0], 1, wireFixed64)
+		msg = appendFixed64(msg, math.Float64bits(s.Value))
+		msg = appendTag(msg, 2, wireVarint)
+		msg = appendVarint(msg, uint64(s.Timestamp))
+		buf = appendBytes(buf, 2, msg)
+	}
+	return buf
+}
+
+func (m *MetricMetadata) marshal(buf []byte) []byte {
+	buf = appendTag(buf, 1, wireVarint)
+	buf = appendVarint(buf, uint64(m.Type))
+	buf = appendString(buf, 2, m.MetricFamilyName)
+	if m.Help != "" {
+		buf = appendString(buf, 4, m.Help)
+	}
+	if m.Unit != "" {
+		buf = appendString(buf, 5, m.Unit)
+	}
+	return buf
+}
+
+// Unmarshal decodes a request from the protocol buffer format. Unknown
+// fields are skipped.
+func (r *WriteRequest) Unmarshal(buf []byte) error {
+	*r = WriteRequest{}
+	return decodeFields(buf, func(field int, wire int, d *decoder) error {
+		switch {
+		case field == 1 && wire == wireBytes:
+			var ts TimeSeries
+			if err := ts.unmarshal(d.bytes()); err != nil {
+				return err
+			}
+			r.Timeseries = append(r.Timeseries, ts)
+		case field == 3 && wire == wireBytes:
+			var m MetricMetadata
+			if err := m.unmarshal(d.bytes()); err != nil {
+				return err
+			}
+			r.Metadata = append(r.Metadata, m)
+		default:
+			d.skip(wire)
+		}
+		return nil
+	})
+}
+
+func (ts *TimeSeries) unmarshal(buf []byte) error {
+	return decodeFields(buf, func(field int, wire int, d *decoder) error {
+		switch {
+		case field == 1 && wire == wireBytes:
+			var l Label
+			err := decodeFields(d.bytes(), func(field int, wire int, d *decoder) error {
+				switch {
+				case field == 1 && wire == wireBytes:
+					l.Name = string(d.bytes())
+				case field == 2 && wire == wireBytes:
+					l.Value = string(d.bytes())
+				default:
+					d.skip(wire)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			ts.Labels = append(ts.Labels, l)
+		case field == 2 && wire == wireBytes:
+			var s Sample
+			err := decodeFields(d.bytes(), func(field int, wire int, d *decoder) error {
+				switch {
+				case field == 1 && wire == wireFixed64:
+					s.Value = math.Float64frombits(d.fixed64())
+				case field == 2 && wire == wireVarint:
+					s.Timestamp = int64(d.varint())
+				default:
+					d.skip(wire)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			ts.Samples = append(ts.Samples, s)
+		default:
+			d.skip(wire)
+		}
+		return nil
+	})
+}
+
+func (m *MetricMetadata) unmarshal(buf []byte) error {
+	return decodeFields(buf, func(field int, wire int, d *decoder) error {
+		switch {
+		case field == 1 && wire == wireVarint:
+			m.Type = MetricType(d.varint())
+		case field == 2 && wire == wireBytes:
+			m.MetricFamilyName = string(d.bytes())
+		case field == 4 && wire == wireBytes:
+			m.Help = string(d.bytes())
+		case field == 5 && wire == wireBytes:
+			m.Unit = string(d.bytes())
+		default:
+			d.skip(wire)
+		}
+		return nil
+	})
+}
+
+func appendTag(buf []byte, field int, wire int) []byte {
+	return appendVarint(buf, uint64(field)<<3|uint64(wire))
+}
+
+func appendVarint(buf []byte, v uint64) []byte {
+	for v >= 0x80 {
+		buf = append(buf, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(buf, byte(v))
+}
+
+func appendFixed64(buf []byte, v uint64) []byte {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], v)
+	return append(buf, b[:]...)
+}
+
+func appendBytes(buf []byte, field int, b []byte) []byte {
+	buf = appendTag(buf, field, wireBytes)
+	buf = appendVarint(buf, uint64(len(b)))
+	return append(buf, b...)
+}
+
+func appendString(buf []byte, field int, s string) []byte {
+	buf = appendTag(buf, field, wireBytes)
+	buf = appendVarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+// decoder reads the values of a message. Reading past the end of the
+// message sets err, after which all reads return zero values.
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) varint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		d.err = errTruncated
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return v
+}
+
+func (d *decoder) fixed64() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	if len(d.buf) < 8 {
+		d.err = errTruncated
+		return 0
+	}
+	v := binary.LittleEndian.Uint64(d.buf)
+	d.buf = d.buf[8:]
+	return v
+}
+
+func (d *decoder) bytes() []byte {
+	n := d.varint()
+	if d.err != nil {
+		return nil
+	}
+	if uint64(len(d.buf)) < n {
+		d.err = errTruncated
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *decoder) skip(wire int) {
+	switch wire {
+	case wireVarint:
+		d.varint()
+	case wireFixed64:
+		d.fixed64()
+	case wireBytes:
+		d.bytes()
+	case wireFixed32:
+		if len(d.buf) < 4 {
+			d.err = errTruncated
+			return
+		}
+		d.buf = d.buf[4:]
+	default:
+		d.err = fmt.Errorf("prompb: unsupported wire type %d", wire)
+	}
+}
+
+// decodeFields calls fn for each field of the message in buf, which must
+// read or skip the field value from the decoder.
+func decodeFields(buf []byte, fn func(field int, wire int, d *decoder) error) error {
+	d := &decoder{buf: buf}
+	for len(d.buf) > 0 {
+		tag := d.varint()
+		if d.err != nil {
+			return d.err
+		}
+		if err := fn(int(tag>>3), int(tag&7), d); err != nil {
+			return err
+		}
+		if d.err != nil {
+			return d.err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package prompb
+
+import (
+	"encoding/hex"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshal(t *testing.T) {
+	r := &WriteRequest{
+		Timeseries: []TimeSeries{
+			{
+				Labels:  []Label{{Name: "a", Value: "b"}},
+				Samples: []Sample{{Value: 1, Timestamp: 2}},
+			},
+		},
+		Metadata: []MetricMetadata{
+			{Type: MetricTypeCounter, MetricFamilyName: "c"},
+		},
+	}
+	buf, err := r.Marshal()
+	require.NoError(t, err)
+	assert.Equal(t,
+		"0a15"+ // timeseries
+			"0a06"+"0a0161"+"120162"+ // label
+			"120b"+"09000000000000f03f"+"1002"+ // sample
+			"1a05"+"0801"+"120163", // metadata
+		hex.EncodeToString(buf))
+}
+
+func TestMarshalUnmarshal(t *testing.T) {
+	r := &WriteRequest{
+		Timeseries: []TimeSeries{
+			{
+				Labels: []Label{
+					{Name: "__name__", Value: "cpu_usage_idle"},
+					{Name: "host", Value: "localhost"},
+				},
+				Samples: []Sample{
+					{Value: 42.5, Timestamp: 1500000000000},
+					{Value: math.Inf(1), Timestamp: -1},
+				},
+			},
+			{
+				Labels:  []Label{{Name: "__name__", Value: "up"}},
+				Samples: []Sample{{Value: 1, Timestamp: 1500000000000}},
+			},
+		},
+		Metadata: []MetricMetadata{
+			{
+				Type:             MetricTypeHistogram,
+				MetricFamilyName: "http_request_duration_seconds",
+				Help:             "Request duration",
+				Unit:             "seconds",
+			},
+		},
+	}
+	buf, err := r.Marshal()
+	require.NoError(t, err)
+
+	var decoded WriteRequest
+	require.NoError(t, decoded.Unmarshal(buf))
+	assert.Equal(t, *r, decoded)
+}
+
+func TestUnmarshalUnknownFields(t *testing.T) {
+	// a timeseries with an exemplar (field 3) and a fixed32 field 15
+	buf, err := hex.DecodeString("0a0f" + "0a06" + "0a0161" + "120162" + "1a00" + "7d01020304")
+	require.NoError(t, err)
+
+	var r WriteRequest
+	require.NoError(t, r.Unmarshal(buf))
+	require.Len(t, r.Timeseries, 1)
+	assert.Equal(t, []Label{{Name: "a", Value: "b"}}, r.Timeseries[0].Labels)
+}
+
+func TestUnmarshalTruncated(t *testing.T) {
+	buf, err := (&WriteRequest{
+		Timeseries: []TimeSeries{
+			{Labels: []Label{{Name: "a", Value: "b"}}},
+		},
+	}).Marshal()
+	require.NoError(t, err)
+
+	var r WriteRequest
+	assert.Error(t, r.Unmarshal(buf[:len(buf)-1]))
+}
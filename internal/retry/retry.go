@@ -0,0 +1,160 @@
+package retry
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	// GiveUpDrop drops whatever could not be delivered and carries on.
+	GiveUpDrop = "drop"
+	// GiveUpExit stops telegraf.
+	GiveUpExit = "exit"
+)
+
+// Policy describes how failed operations are retried.
+type Policy struct {
+	// InitialDelay is the delay before the first retry. It doubles after
+	// each consecutive failure, up to MaxDelay.
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+
+	// Jitter randomly shortens each delay by up to this fraction of it, ie,
+	// 0.2 means delays will be between 80% and 100% of their nominal value.
+	Jitter float64
+
+	// MaxAttempts is the number of consecutive failures after which the
+	// policy gives up. 0 means never give up.
+	MaxAttempts int
+
+	// GiveUp is the action to take once MaxAttempts is reached, either
+	// GiveUpDrop or GiveUpExit.
+	GiveUp string
+}
+
+// DefaultPolicy returns the policy used when none is configured.
+func DefaultPolicy() Policy {
+	return Policy{
+		InitialDelay: time.Second,
+		MaxDelay:     time.Minute,
+		Jitter:       0.1,
+		GiveUp:       GiveUpDrop,
+	}
+}
+
+// Validate checks that the policy settings are consistent.
+func (p *Policy) Validate() error {
+	if p.InitialDelay < 0 || p.MaxDelay < 0 {
+		return fmt.Errorf("retry delays must not be negative")
+	}
+	if p.MaxDelay != 0 && p.MaxDelay < p.InitialDelay {
+		return fmt.Errorf("retry max delay (%s) must not be less than "+
+			"initial delay (%s)", p.MaxDelay, p.InitialDelay)
+	}
+	if p.Jitter < 0 || p.Jitter > 1 {
+		return fmt.Errorf("retry jitter must be between 0 and 1, found %v",
+			p.Jitter)
+	}
+	if p.MaxAttempts < 0 {
+		return fmt.Errorf("retry max attempts must not be negative")
+	}
+	switch p.GiveUp {
+	case "", GiveUpDrop, GiveUpExit:
+	default:
+		return fmt.Errorf("invalid give up action %q, must be %q or %q",
+			p.GiveUp, GiveUpDrop, GiveUpExit)
+	}
+	return nil
+}
+
+// Delay returns the nominal delay after the given number of consecutive
+// failures, without jitter.
+func (p *Policy) Delay(failures int) time.Duration {
+	if failures <= 0 || p.InitialDelay == 0 {
+		return 0
+	}
+	delay := p.InitialDelay
+	for i := 1; i < failures; i++ {
+		delay *= 2
+		if p.MaxDelay != 0 && delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	if p.MaxDelay != 0 && delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
+}
+
+// Backoff tracks consecutive failures of an operation against a Policy.
+// It is safe for concurrent use.
+type Backoff struct {
+	policy Policy
+
+	mu       sync.Mutex
+	failures int
+	next     time.Time
+
+	// now is replaced in tests.
+	now func() time.Time
+}
+
+// NewBackoff returns a Backoff using the given policy.
+func NewBackoff(policy Policy) *Backoff {
+	return &Backoff{
+		policy: policy,
+		now:    time.Now,
+	}
+}
+
+// Ready returns true if the operation can be attempted now.
+func (b *Backoff) Ready() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.now().Before(b.next)
+}
+
+// Fail records a failed attempt and schedules the next one. It returns true
+// if the policy gives up, in which case the failure count is reset.
+func (b *Backoff) Fail() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.policy.MaxAttempts > 0 && b.failures >= b.policy.MaxAttempts {
+		b.failures = 0
+		b.next = time.Time{}
+		return true
+	}
+
+	delay := b.policy.Delay(b.failures)
+	if b.policy.Jitter > 0 && delay > 0 {
+		delay -= time.Duration(rand.Float64() * b.policy.Jitter * float64(delay))
+	}
+	b.next = b.now().Add(delay)
+	return false
+}
+
+// Reset clears the failure count after a successful attempt.
+func (b *Backoff) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.next = time.Time{}
+}
+
+// Failures returns the number of consecutive failures.
+func (b *Backoff) Failures() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failures
+}
+
+// Next returns the time of the next allowed attempt.
+func (b *Backoff) Next() time.Time {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.next
+}
@@ -0,0 +1,95 @@
+package retry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicyDelay(t *testing.T) {
+	p := Policy{
+		InitialDelay: time.Second,
+		MaxDelay:     10 * time.Second,
+	}
+	assert.Equal(t, time.Duration(0), p.Delay(0))
+	assert.Equal(t, time.Second, p.Delay(1))
+	assert.Equal(t, 2*time.Second, p.Delay(2))
+	assert.Equal(t, 8*time.Second, p.Delay(4))
+	assert.Equal(t, 10*time.Second, p.Delay(5))
+	assert.Equal(t, 10*time.Second, p.Delay(1000))
+}
+
+func TestPolicyValidate(t *testing.T) {
+	p := DefaultPolicy()
+	assert.NoError(t, p.Validate())
+
+	p = DefaultPolicy()
+	p.MaxDelay = time.Millisecond
+	assert.Error(t, p.Validate())
+
+	p = DefaultPolicy()
+	p.Jitter = 1.5
+	assert.Error(t, p.Validate())
+
+	p = DefaultPolicy()
+	p.GiveUp = "panic"
+	assert.Error(t, p.Validate())
+}
+
+func TestBackoff(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := NewBackoff(Policy{
+		InitialDelay: time.Second,
+		MaxDelay:     4 * time.Second,
+		MaxAttempts:  4,
+	})
+	b.now = func() time.Time { return now }
+
+	assert.True(t, b.Ready())
+	assert.False(t, b.Fail())
+	assert.False(t, b.Ready())
+	assert.Equal(t, now.Add(time.Second), b.Next())
+
+	now = now.Add(time.Second)
+	assert.True(t, b.Ready())
+	assert.False(t, b.Fail())
+	assert.Equal(t, now.Add(2*time.Second), b.Next())
+	assert.False(t, b.Fail())
+	assert.Equal(t, 3, b.Failures())
+
+	// gives up on the 4th failure, and starts over
+	assert.True(t, b.Fail())
+	assert.Equal(t, 0, b.Failures())
+	assert.True(t, b.Ready())
+
+	assert.False(t, b.Fail())
+	b.Reset()
+	assert.True(t, b.Ready())
+	assert.Equal(t, 0, b.Failures())
+}
+
+func TestBackoffJitter(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := NewBackoff(Policy{
+		InitialDelay: 10 * time.Second,
+		Jitter:       0.5,
+	})
+	b.now = func() time.Time { return now }
+
+	for i := 0; i < 100; i++ {
+		b.Reset()
+		b.Fail()
+		delay := b.Next().Sub(now)
+		assert.True(t, delay >= 5*time.Second && delay <= 10*time.Second,
+			"delay out of range: %s", delay)
+	}
+}
+
+func TestBackoffZeroPolicy(t *testing.T) {
+	b := NewBackoff(Policy{})
+	for i := 0; i < 10; i++ {
+		assert.False(t, b.Fail())
+		assert.True(t, b.Ready())
+	}
+}
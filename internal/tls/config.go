@@ -0,0 +1,216 @@
+package tls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// ClientConfig represents the standard client TLS config, to be embedded in
+// plugins connecting to a server.
+type ClientConfig struct {
+	TLSCA              string   `toml:"tls_ca"`
+	TLSCert            string   `toml:"tls_cert"`
+	TLSKey             string   `toml:"tls_key"`
+	TLSServerName      string   `toml:"tls_server_name"`
+	TLSMinVersion      string   `toml:"tls_min_version"`
+	TLSCipherSuites    []string `toml:"tls_cipher_suites"`
+	InsecureSkipVerify bool     `toml:"insecure_skip_verify"`
+
+	// Deprecated: use the tls_* options above.
+	SSLCA   string `toml:"ssl_ca"`
+	SSLCert string `toml:"ssl_cert"`
+	SSLKey  string `toml:"ssl_key"`
+}
+
+// ServerConfig represents the standard server TLS config, to be embedded in
+// plugins listening for connections.
+type ServerConfig struct {
+	TLSCert         string   `toml:"tls_cert"`
+	TLSKey          string   `toml:"tls_key"`
+	TLSMinVersion   string   `toml:"tls_min_version"`
+	TLSCipherSuites []string `toml:"tls_cipher_suites"`
+
+	// TLSAllowedCACerts enables mutual TLS: clients must present a
+	// certificate signed by one of these CAs.
+	TLSAllowedCACerts []string `toml:"tls_allowed_cacerts"`
+	// TLSAllowedClientCNs restricts the client certificates accepted to those
+	// with one of these common names.
+	TLSAllowedClientCNs []string `toml:"tls_allowed_client_cns"`
+}
+
+var versions = map[string]uint16{
+	"TLS10": tls.VersionTLS10,
+	"TLS11": tls.VersionTLS11,
+	"TLS12": tls.VersionTLS12,
+}
+
+var cipherSuites = map[string]uint16{
+	"TLS_RSA_WITH_RC4_128_SHA":                tls.TLS_RSA_WITH_RC4_128_SHA,
+	"TLS_RSA_WITH_3DES_EDE_CBC_SHA":           tls.TLS_RSA_WITH_3DES_EDE_CBC_SHA,
+	"TLS_RSA_WITH_AES_128_CBC_SHA":            tls.TLS_RSA_WITH_AES_128_CBC_SHA,
+	"TLS_RSA_WITH_AES_256_CBC_SHA":            tls.TLS_RSA_WITH_AES_256_CBC_SHA,
+	"TLS_RSA_WITH_AES_128_CBC_SHA256":         tls.TLS_RSA_WITH_AES_128_CBC_SHA256,
+	"TLS_RSA_WITH_AES_128_GCM_SHA256":         tls.TLS_RSA_WITH_AES_128_GCM_SHA256,
+	"TLS_RSA_WITH_AES_256_GCM_SHA384":         tls.TLS_RSA_WITH_AES_256_GCM_SHA384,
+	"TLS_ECDHE_ECDSA_WITH_RC4_128_SHA":        tls.TLS_ECDHE_ECDSA_WITH_RC4_128_SHA,
+	"TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA":    tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA,
+	"TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA":    tls.TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA,
+	"TLS_ECDHE_RSA_WITH_RC4_128_SHA":          tls.TLS_ECDHE_RSA_WITH_RC4_128_SHA,
+	"TLS_ECDHE_RSA_WITH_3DES_EDE_CBC_SHA":     tls.TLS_ECDHE_RSA_WITH_3DES_EDE_CBC_SHA,
+	"TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA":      tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA,
+	"TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA":      tls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA,
+	"TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA256": tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA256,
+	"TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA256":   tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA256,
+	"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256":   tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256": tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384":   tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384": tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	"TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305":    tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305,
+	"TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305":  tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305,
+}
+
+// TLSConfig returns a tls.Config, or nil if TLS is not configured.
+func (c *ClientConfig) TLSConfig() (*tls.Config, error) {
+	// the deprecated options are used when the new ones are not set
+	ca, cert, key := c.TLSCA, c.TLSCert, c.TLSKey
+	if ca == "" {
+		ca = c.SSLCA
+	}
+	if cert == "" {
+		cert = c.SSLCert
+	}
+	if key == "" {
+		key = c.SSLKey
+	}
+
+	if ca == "" && cert == "" && key == "" && c.TLSServerName == "" &&
+		c.TLSMinVersion == "" && len(c.TLSCipherSuites) == 0 &&
+		!c.InsecureSkipVerify {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: c.InsecureSkipVerify,
+		ServerName:         c.TLSServerName,
+	}
+
+	if ca != "" {
+		pool, err := makeCertPool([]string{ca})
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cert != "" && key != "" {
+		if err := loadCertificate(tlsConfig, cert, key); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := setProtocol(tlsConfig, c.TLSMinVersion, c.TLSCipherSuites); err != nil {
+		return nil, err
+	}
+	return tlsConfig, nil
+}
+
+// TLSConfig returns a tls.Config, or nil if TLS is not configured.
+func (c *ServerConfig) TLSConfig() (*tls.Config, error) {
+	if c.TLSCert == "" && c.TLSKey == "" && len(c.TLSAllowedCACerts) == 0 &&
+		len(c.TLSAllowedClientCNs) == 0 {
+		return nil, nil
+	}
+	if c.TLSCert == "" || c.TLSKey == "" {
+		return nil, fmt.Errorf("tls_cert and tls_key are required to enable TLS")
+	}
+
+	tlsConfig := &tls.Config{}
+	if err := loadCertificate(tlsConfig, c.TLSCert, c.TLSKey); err != nil {
+		return nil, err
+	}
+
+	if len(c.TLSAllowedCACerts) != 0 {
+		pool, err := makeCertPool(c.TLSAllowedCACerts)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	if len(c.TLSAllowedClientCNs) != 0 {
+		if len(c.TLSAllowedCACerts) == 0 {
+			return nil, fmt.Errorf("tls_allowed_cacerts is required to " +
+				"verify tls_allowed_client_cns")
+		}
+		allowed := make(map[string]bool, len(c.TLSAllowedClientCNs))
+		for _, cn := range c.TLSAllowedClientCNs {
+			allowed[cn] = true
+		}
+		tlsConfig.VerifyPeerCertificate = func(_ [][]byte, chains [][]*x509.Certificate) error {
+			// the certificate chains have already been verified against
+			// the allowed CAs.
+			for _, chain := range chains {
+				if len(chain) > 0 && allowed[chain[0].Subject.CommonName] {
+					return nil
+				}
+			}
+			return fmt.Errorf("client certificate common name is not allowed")
+		}
+	}
+
+	if err := setProtocol(tlsConfig, c.TLSMinVersion, c.TLSCipherSuites); err != nil {
+		return nil, err
+	}
+	return tlsConfig, nil
+}
+
+func makeCertPool(certFiles []string) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	for _, certFile := range certFiles {
+		pem, err := ioutil.ReadFile(certFile)
+		if err != nil {
+			return nil, fmt.Errorf("Could not load TLS CA: %s", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("Could not parse any PEM certificates %s",
+				certFile)
+		}
+	}
+	return pool, nil
+}
+
+func loadCertificate(config *tls.Config, certFile, keyFile string) error {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return fmt.Errorf("Could not load TLS key/certificate from %s:%s: %s",
+			keyFile, certFile, err)
+	}
+
+	config.Certificates = []tls.Certificate{cert}
+	config.BuildNameToCertificate()
+	return nil
+}
+
+func setProtocol(config *tls.Config, minVersion string, suites []string) error {
+	if minVersion != "" {
+		version, ok := versions[strings.ToUpper(minVersion)]
+		if !ok {
+			return fmt.Errorf("unsupported TLS version %q, must be one of "+
+				"TLS10, TLS11 or TLS12", minVersion)
+		}
+		config.MinVersion = version
+	}
+
+	for _, name := range suites {
+		suite, ok := cipherSuites[strings.ToUpper(name)]
+		if !ok {
+			return fmt.Errorf("unsupported cipher suite %q", name)
+		}
+		config.CipherSuites = append(config.CipherSuites, suite)
+	}
+	return nil
+}
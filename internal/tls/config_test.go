@@ -0,0 +1,255 @@
+package tls_test
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	tlsint "github.com/influxdata/telegraf/internal/tls"
+	"github.com/influxdata/telegraf/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var pki = testutil.NewPKI("../../testutil/pki")
+
+func TestClientConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		client tlsint.ClientConfig
+		expNil bool
+		expErr bool
+	}{
+		{
+			name:   "unset",
+			client: tlsint.ClientConfig{},
+			expNil: true,
+		},
+		{
+			name: "success",
+			client: tlsint.ClientConfig{
+				TLSCA:   pki.CACertPath(),
+				TLSCert: pki.ClientCertPath(),
+				TLSKey:  pki.ClientKeyPath(),
+			},
+		},
+		{
+			name: "deprecated ssl options",
+			client: tlsint.ClientConfig{
+				SSLCA:   pki.CACertPath(),
+				SSLCert: pki.ClientCertPath(),
+				SSLKey:  pki.ClientKeyPath(),
+			},
+		},
+		{
+			name: "insecure skip verify",
+			client: tlsint.ClientConfig{
+				InsecureSkipVerify: true,
+			},
+		},
+		{
+			name: "protocol settings",
+			client: tlsint.ClientConfig{
+				TLSServerName:   "example.org",
+				TLSMinVersion:   "tls12",
+				TLSCipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+			},
+		},
+		{
+			name: "missing ca",
+			client: tlsint.ClientConfig{
+				TLSCA: "/nonexistent",
+			},
+			expErr: true,
+		},
+		{
+			name: "invalid ca",
+			client: tlsint.ClientConfig{
+				TLSCA: pki.ClientKeyPath(),
+			},
+			expErr: true,
+		},
+		{
+			name: "invalid key",
+			client: tlsint.ClientConfig{
+				TLSCert: pki.ClientCertPath(),
+				TLSKey:  pki.ServerKeyPath(),
+			},
+			expErr: true,
+		},
+		{
+			name: "invalid min version",
+			client: tlsint.ClientConfig{
+				TLSMinVersion: "SSL3",
+			},
+			expErr: true,
+		},
+		{
+			name: "invalid cipher suite",
+			client: tlsint.ClientConfig{
+				TLSCipherSuites: []string{"TLS_NULL"},
+			},
+			expErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsConfig, err := tt.client.TLSConfig()
+			if tt.expErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tt.expNil {
+				require.Nil(t, tlsConfig)
+			} else {
+				require.NotNil(t, tlsConfig)
+			}
+		})
+	}
+}
+
+func TestClientConfigProtocol(t *testing.T) {
+	client := tlsint.ClientConfig{
+		TLSServerName:   "example.org",
+		TLSMinVersion:   "TLS11",
+		TLSCipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+	}
+	tlsConfig, err := client.TLSConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "example.org", tlsConfig.ServerName)
+	assert.Equal(t, uint16(tls.VersionTLS11), tlsConfig.MinVersion)
+	assert.Equal(t, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256},
+		tlsConfig.CipherSuites)
+}
+
+func TestServerConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		server tlsint.ServerConfig
+		expNil bool
+		expErr bool
+	}{
+		{
+			name:   "unset",
+			server: tlsint.ServerConfig{},
+			expNil: true,
+		},
+		{
+			name: "success",
+			server: tlsint.ServerConfig{
+				TLSCert:             pki.ServerCertPath(),
+				TLSKey:              pki.ServerKeyPath(),
+				TLSAllowedCACerts:   []string{pki.CACertPath()},
+				TLSAllowedClientCNs: []string{"telegraf-client"},
+			},
+		},
+		{
+			name: "missing key",
+			server: tlsint.ServerConfig{
+				TLSCert: pki.ServerCertPath(),
+			},
+			expErr: true,
+		},
+		{
+			name: "missing allowed ca",
+			server: tlsint.ServerConfig{
+				TLSCert:           pki.ServerCertPath(),
+				TLSKey:            pki.ServerKeyPath(),
+				TLSAllowedCACerts: []string{"/nonexistent"},
+			},
+			expErr: true,
+		},
+		{
+			name: "client cns without allowed ca",
+			server: tlsint.ServerConfig{
+				TLSCert:             pki.ServerCertPath(),
+				TLSKey:              pki.ServerKeyPath(),
+				TLSAllowedClientCNs: []string{"telegraf-client"},
+			},
+			expErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsConfig, err := tt.server.TLSConfig()
+			if tt.expErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tt.expNil {
+				require.Nil(t, tlsConfig)
+			} else {
+				require.NotNil(t, tlsConfig)
+			}
+		})
+	}
+}
+
+func TestConnect(t *testing.T) {
+	tests := []struct {
+		name      string
+		client    *tlsint.ClientConfig
+		allowedCN []string
+		expErr    bool
+	}{
+		{
+			name:   "mutual tls",
+			client: pki.TLSClientConfig(),
+		},
+		{
+			name:      "allowed client cn",
+			client:    pki.TLSClientConfig(),
+			allowedCN: []string{"telegraf-client"},
+		},
+		{
+			name:      "client cn not allowed",
+			client:    pki.TLSClientConfig(),
+			allowedCN: []string{"other-client"},
+			expErr:    true,
+		},
+		{
+			name: "no client certificate",
+			client: &tlsint.ClientConfig{
+				TLSCA: pki.CACertPath(),
+			},
+			expErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serverConfig := pki.TLSServerConfig()
+			serverConfig.TLSAllowedClientCNs = tt.allowedCN
+			serverTLSConfig, err := serverConfig.TLSConfig()
+			require.NoError(t, err)
+
+			ts := httptest.NewUnstartedServer(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusOK)
+				}))
+			ts.TLS = serverTLSConfig
+			ts.StartTLS()
+			defer ts.Close()
+
+			clientTLSConfig, err := tt.client.TLSConfig()
+			require.NoError(t, err)
+			client := http.Client{
+				Transport: &http.Transport{
+					TLSClientConfig: clientTLSConfig,
+				},
+			}
+
+			resp, err := client.Get(ts.URL)
+			if tt.expErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		})
+	}
+}
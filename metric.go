@@ -61,4 +61,13 @@ type Metric interface {
 	// aggregator things:
 	SetAggregate(bool)
 	IsAggregate() bool
+
+	// Accept marks the metric as written by an output, Reject as
+	// permanently rejected by an output, and Drop as discarded without
+	// being written, ie by a filter or an aggregator. One of them is called
+	// once the metric is no longer needed, and they are no-ops unless the
+	// metric is tracked.
+	Accept()
+	Reject()
+	Drop()
 }
@@ -31,6 +31,10 @@ var (
 )
 
 func escape(s string, t string) string {
+	// most strings don't need to be escaped, avoid the replacer for them.
+	if !needsEscape(s) {
+		return s
+	}
 	switch t {
 	case "fieldkey", "tagkey", "tagval":
 		return escaper.Replace(s)
@@ -43,6 +47,9 @@ func escape(s string, t string) string {
 }
 
 func unescape(s string, t string) string {
+	if strings.IndexByte(s, '\\') == -1 {
+		return s
+	}
 	switch t {
 	case "fieldkey", "tagkey", "tagval":
 		return unEscaper.Replace(s)
@@ -53,3 +60,15 @@ func unescape(s string, t string) string {
 	}
 	return s
 }
+
+// needsEscape reports whether s contains any of the characters escaped by
+// escape.
+func needsEscape(s string) bool {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case ',', '"', ' ', '=', '\\':
+			return true
+		}
+	}
+	return false
+}
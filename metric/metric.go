@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"fmt"
 	"hash/fnv"
+	"math"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -39,14 +41,13 @@ func New(
 	}
 
 	m := &metric{
-		name:  []byte(escape(name, "name")),
-		t:     []byte(fmt.Sprint(t.UnixNano())),
-		nsec:  t.UnixNano(),
-		mType: thisType,
+		name:   name,
+		tags:   make([]tag, 0, len(tags)),
+		fields: make([]field, 0, len(fields)),
+		nsec:   t.UnixNano(),
+		mType:  thisType,
 	}
 
-	// pre-allocate exact size of the tags slice
-	taglen := 0
 	for k, v := range tags {
 		if strings.HasSuffix(k, `\`) {
 			return nil, fmt.Errorf("%s: tag key cannot end with a backslash: %s", name, k)
@@ -58,45 +59,23 @@ func New(
 		if len(k) == 0 || len(v) == 0 {
 			continue
 		}
-		taglen += 2 + len(escape(k, "tagkey")) + len(escape(v, "tagval"))
+		m.tags = append(m.tags, tag{key: k, value: v})
 	}
-	m.tags = make([]byte, taglen)
+	sort.Sort(tagsByKey(m.tags))
 
-	i := 0
-	for k, v := range tags {
-		if len(k) == 0 || len(v) == 0 {
-			continue
-		}
-		m.tags[i] = ','
-		i++
-		i += copy(m.tags[i:], escape(k, "tagkey"))
-		m.tags[i] = '='
-		i++
-		i += copy(m.tags[i:], escape(v, "tagval"))
-	}
-
-	// pre-allocate capacity of the fields slice
-	fieldlen := 0
-	for k, _ := range fields {
+	for k, v := range fields {
 		if strings.HasSuffix(k, `\`) {
 			return nil, fmt.Errorf("%s: field key cannot end with a backslash: %s", name, k)
 		}
 
-		// 10 bytes is completely arbitrary, but will at least prevent some
-		// amount of allocations. There's a small possibility this will create
-		// slightly more allocations for a metric that has many short fields.
-		fieldlen += len(k) + 10
-	}
-	m.fields = make([]byte, 0, fieldlen)
-
-	i = 0
-	for k, v := range fields {
-		if i != 0 {
-			m.fields = append(m.fields, ',')
+		v = convertField(v)
+		if v == nil {
+			continue
 		}
-		m.fields = appendField(m.fields, k, v)
-		i++
+		m.fields = append(m.fields, field{key: k, value: v})
 	}
+	// the fields are sorted so that the serialization is deterministic.
+	sort.Sort(fieldsByKey(m.fields))
 
 	return m, nil
 }
@@ -163,22 +142,98 @@ func countBackslashes(buf []byte, index int) int {
 	return count
 }
 
+type tag struct {
+	key   string
+	value string
+}
+
+type field struct {
+	key   string
+	value interface{}
+}
+
+type tagsByKey []tag
+
+func (t tagsByKey) Len() int           { return len(t) }
+func (t tagsByKey) Less(i, j int) bool { return t[i].key < t[j].key }
+func (t tagsByKey) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
+
+type fieldsByKey []field
+
+func (f fieldsByKey) Len() int           { return len(f) }
+func (f fieldsByKey) Less(i, j int) bool { return f[i].key < f[j].key }
+func (f fieldsByKey) Swap(i, j int)      { f[i], f[j] = f[j], f[i] }
+
+// metric holds the tags, sorted by key, and the fields of a point.
+//
+// Copies share the tags and fields of the metric they are copied from until
+// either is modified, so that a metric can be sent to several outputs
+// without copying it for each of them.
 type metric struct {
-	name   []byte
-	tags   []byte
-	fields []byte
-	t      []byte
+	name   string
+	tags   []tag
+	fields []field
+	nsec   int64
 
 	mType     telegraf.ValueType
 	aggregate bool
 
-	// cached values for reuse in "get" functions
+	// shared is set when the tags and fields slices may be referenced by
+	// another metric, and must be copied before being modified.
+	shared bool
+
+	// cached values for reuse in "get" functions, reset by the setters.
 	hashID uint64
-	nsec   int64
+	// line is the serialized line protocol of the metric, built on first
+	// use. It is never modified in place, so it can be shared by copies.
+	line []byte
+	// cache is set by Copy when the line has not been built yet, so that
+	// it is built once for the metric and its unmodified copies, which
+	// may be serialized concurrently by different outputs.
+	cache *lineCache
+
+	// tagsEncoded and fieldsEncoded are set when the tags or fields have
+	// not been decoded yet from the line the metric was parsed from, of
+	// which the first keyLen bytes are the name and tags, followed by a
+	// space and fieldsLen bytes of fields.
+	tagsEncoded   bool
+	fieldsEncoded bool
+	keyLen        int
+	fieldsLen     int
+}
+
+type lineCache struct {
+	once sync.Once
+	line []byte
+}
+
+// decode decodes the tags and fields of a parsed metric on first use.
+func (m *metric) decode() {
+	m.decodeTags()
+	m.decodeFields()
+}
+
+func (m *metric) decodeTags() {
+	if !m.tagsEncoded {
+		return
+	}
+	key := m.line[:m.keyLen]
+	if namei := indexUnescapedByte(key, ','); namei >= 1 {
+		m.tags = parseTags(key[namei:])
+	}
+	m.tagsEncoded = false
+}
+
+func (m *metric) decodeFields() {
+	if !m.fieldsEncoded {
+		return
+	}
+	m.fields = parseFields(m.line[m.keyLen+1 : m.keyLen+1+m.fieldsLen])
+	m.fieldsEncoded = false
 }
 
 func (m *metric) String() string {
-	return string(m.name) + string(m.tags) + " " + string(m.fields) + " " + string(m.t) + "\n"
+	return string(m.serialize())
 }
 
 func (m *metric) SetAggregate(b bool) {
@@ -189,435 +244,397 @@ func (m *metric) IsAggregate() bool {
 	return m.aggregate
 }
 
+// Accept, Reject and Drop do nothing, as the metric is not tracked. See
+// WithTracking.
+func (m *metric) Accept() {}
+func (m *metric) Reject() {}
+func (m *metric) Drop()   {}
+
 func (m *metric) Type() telegraf.ValueType {
 	return m.mType
 }
 
 func (m *metric) Len() int {
-	// 3 is for 2 spaces surrounding the fields array + newline at the end.
-	return len(m.name) + len(m.tags) + len(m.fields) + len(m.t) + 3
+	return len(m.serialize())
 }
 
 func (m *metric) Serialize() []byte {
-	tmp := make([]byte, m.Len())
-	i := 0
-	i += copy(tmp[i:], m.name)
-	i += copy(tmp[i:], m.tags)
-	tmp[i] = ' '
-	i++
-	i += copy(tmp[i:], m.fields)
-	tmp[i] = ' '
-	i++
-	i += copy(tmp[i:], m.t)
-	tmp[i] = '\n'
+	line := m.serialize()
+	tmp := make([]byte, len(line))
+	copy(tmp, line)
 	return tmp
 }
 
 func (m *metric) SerializeTo(dst []byte) int {
-	i := 0
-	if i >= len(dst) {
-		return i
-	}
-
-	i += copy(dst[i:], m.name)
-	if i >= len(dst) {
-		return i
-	}
-
-	i += copy(dst[i:], m.tags)
-	if i >= len(dst) {
-		return i
-	}
+	return copy(dst, m.serialize())
+}
 
-	dst[i] = ' '
-	i++
-	if i >= len(dst) {
-		return i
+// serialize returns the line protocol of the metric, including the newline
+// at the end. The returned slice must not be modified.
+func (m *metric) serialize() []byte {
+	if m.line == nil {
+		if c := m.cache; c != nil {
+			c.once.Do(func() { c.line = m.appendLine(nil) })
+			m.line = c.line
+		} else {
+			m.line = m.appendLine(nil)
+		}
 	}
+	return m.line
+}
 
-	i += copy(dst[i:], m.fields)
-	if i >= len(dst) {
-		return i
+// appendLine appends the line protocol of the metric.
+func (m *metric) appendLine(line []byte) []byte {
+	if line == nil {
+		// estimate the size of the line, assuming fields values are at most
+		// 20 bytes long.
+		size := len(m.name) + 24
+		for _, t := range m.tags {
+			size += len(t.key) + len(t.value) + 2
+		}
+		for _, f := range m.fields {
+			size += len(f.key) + 22
+		}
+		line = make([]byte, 0, size)
 	}
-
-	dst[i] = ' '
-	i++
-	if i >= len(dst) {
-		return i
+	line = m.appendKey(line)
+	line = append(line, ' ')
+	for i, f := range m.fields {
+		if i != 0 {
+			line = append(line, ',')
+		}
+		line = appendField(line, f.key, f.value)
 	}
+	line = append(line, ' ')
+	line = strconv.AppendInt(line, m.nsec, 10)
+	return append(line, '\n')
+}
 
-	i += copy(dst[i:], m.t)
-	if i >= len(dst) {
-		return i
+// appendKey appends the escaped measurement name and tags.
+func (m *metric) appendKey(b []byte) []byte {
+	b = append(b, escape(m.name, "name")...)
+	for _, t := range m.tags {
+		b = append(b, ',')
+		b = append(b, escape(t.key, "tagkey")...)
+		b = append(b, '=')
+		b = append(b, escape(t.value, "tagval")...)
 	}
-	dst[i] = '\n'
-
-	return i + 1
+	return b
 }
 
 func (m *metric) Split(maxSize int) []telegraf.Metric {
 	if m.Len() <= maxSize {
 		return []telegraf.Metric{m}
 	}
+	m.decode()
 	var out []telegraf.Metric
 
 	// constant number of bytes for each metric (in addition to field bytes)
-	constant := len(m.name) + len(m.tags) + len(m.t) + 3
-	// currently selected fields
-	fields := make([]byte, 0, maxSize)
-
-	i := 0
-	for {
-		if i >= len(m.fields) {
-			// hit the end of the field byte slice
-			if len(fields) > 0 {
-				out = append(out, copyWith(m.name, m.tags, fields, m.t))
-			}
-			break
-		}
-
-		// find the end of the next field
-		j := indexUnescapedByte(m.fields[i:], ',')
-		if j == -1 {
-			j = len(m.fields)
-		} else {
-			j += i
-		}
-
+	buf := m.appendKey(nil)
+	buf = strconv.AppendInt(buf, m.nsec, 10)
+	constant := len(buf) + 3
+	// length of the serialization of each field
+	lengths := make([]int, len(m.fields))
+	for i, f := range m.fields {
+		buf = appendField(buf[:0], f.key, f.value)
+		lengths[i] = len(buf)
+	}
+	// currently selected fields, and the length of their serialization
+	var fields []field
+	size := 0
+
+	for i, f := range m.fields {
 		// if true, then we need to create a metric _not_ including the currently
 		// selected field
-		if len(m.fields[i:j])+len(fields)+constant >= maxSize {
+		if lengths[i]+size+constant >= maxSize {
 			// if false, then we'll create a metric including the currently
 			// selected field anyways. This means that the given maxSize is too
 			// small for a single field to fit.
 			if len(fields) > 0 {
-				out = append(out, copyWith(m.name, m.tags, fields, m.t))
+				out = append(out, m.withFields(fields))
 			}
-
-			fields = make([]byte, 0, maxSize)
+			fields = nil
+			size = 0
 		}
 		if len(fields) > 0 {
-			fields = append(fields, ',')
+			size++
 		}
-		fields = append(fields, m.fields[i:j]...)
-
-		i = j + 1
+		fields = append(fields, f)
+		size += lengths[i]
+	}
+	if len(fields) > 0 {
+		out = append(out, m.withFields(fields))
 	}
 	return out
 }
 
-func (m *metric) Fields() map[string]interface{} {
-	fieldMap := map[string]interface{}{}
-	i := 0
-	for {
-		if i >= len(m.fields) {
-			break
-		}
-		// end index of field key
-		i1 := indexUnescapedByte(m.fields[i:], '=')
-		if i1 == -1 {
-			break
-		}
-		// start index of field value
-		i2 := i1 + 1
-
-		// end index of field value
-		var i3 int
-		if m.fields[i:][i2] == '"' {
-			i3 = indexUnescapedByteBackslashEscaping(m.fields[i:][i2+1:], '"')
-			if i3 == -1 {
-				i3 = len(m.fields[i:])
-			}
-			i3 += i2 + 2 // increment index to the comma
-		} else {
-			i3 = indexUnescapedByte(m.fields[i:], ',')
-			if i3 == -1 {
-				i3 = len(m.fields[i:])
-			}
-		}
-
-		switch m.fields[i:][i2] {
-		case '"':
-			// string field
-			fieldMap[unescape(string(m.fields[i:][0:i1]), "fieldkey")] = unescape(string(m.fields[i:][i2+1:i3-1]), "fieldval")
-		case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
-			// number field
-			switch m.fields[i:][i3-1] {
-			case 'i':
-				// integer field
-				n, err := parseIntBytes(m.fields[i:][i2:i3-1], 10, 64)
-				if err == nil {
-					fieldMap[unescape(string(m.fields[i:][0:i1]), "fieldkey")] = n
-				} else {
-					// TODO handle error or just ignore field silently?
-				}
-			default:
-				// float field
-				n, err := parseFloatBytes(m.fields[i:][i2:i3], 64)
-				if err == nil {
-					fieldMap[unescape(string(m.fields[i:][0:i1]), "fieldkey")] = n
-				} else {
-					// TODO handle error or just ignore field silently?
-				}
-			}
-		case 'T', 't':
-			fieldMap[unescape(string(m.fields[i:][0:i1]), "fieldkey")] = true
-		case 'F', 'f':
-			fieldMap[unescape(string(m.fields[i:][0:i1]), "fieldkey")] = false
-		default:
-			// TODO handle unsupported field type
-		}
-
-		i += i3 + 1
+// withFields returns a metric with the name, tags and time of m, and the
+// given fields.
+func (m *metric) withFields(fields []field) telegraf.Metric {
+	m.shared = true
+	return &metric{
+		name:      m.name,
+		tags:      m.tags,
+		fields:    fields,
+		nsec:      m.nsec,
+		mType:     m.mType,
+		aggregate: m.aggregate,
+		shared:    true,
+		hashID:    m.hashID,
 	}
+}
 
+func (m *metric) Fields() map[string]interface{} {
+	m.decodeFields()
+	fieldMap := make(map[string]interface{}, len(m.fields))
+	for _, f := range m.fields {
+		fieldMap[f.key] = f.value
+	}
 	return fieldMap
 }
 
 func (m *metric) Tags() map[string]string {
-	tagMap := map[string]string{}
-	if len(m.tags) == 0 {
-		return tagMap
-	}
-
-	i := 0
-	for {
-		// start index of tag key
-		i0 := indexUnescapedByte(m.tags[i:], ',') + 1
-		if i0 == 0 {
-			// didn't find a tag start
-			break
-		}
-		// end index of tag key
-		i1 := indexUnescapedByte(m.tags[i:], '=')
-		// start index of tag value
-		i2 := i1 + 1
-		// end index of tag value (starting from i2)
-		i3 := indexUnescapedByte(m.tags[i+i2:], ',')
-		if i3 == -1 {
-			tagMap[unescape(string(m.tags[i:][i0:i1]), "tagkey")] = unescape(string(m.tags[i:][i2:]), "tagval")
-			break
-		}
-		tagMap[unescape(string(m.tags[i:][i0:i1]), "tagkey")] = unescape(string(m.tags[i:][i2:i2+i3]), "tagval")
-		// increment start index for the next tag
-		i += i2 + i3
+	m.decodeTags()
+	tagMap := make(map[string]string, len(m.tags))
+	for _, t := range m.tags {
+		tagMap[t.key] = t.value
 	}
-
 	return tagMap
 }
 
 func (m *metric) Name() string {
-	return unescape(string(m.name), "name")
+	return m.name
 }
 
 func (m *metric) Time() time.Time {
-	// assume metric has been verified already and ignore error:
-	if m.nsec == 0 {
-		m.nsec, _ = parseIntBytes(m.t, 10, 64)
-	}
 	return time.Unix(0, m.nsec)
 }
 
 func (m *metric) UnixNano() int64 {
-	// assume metric has been verified already and ignore error:
-	if m.nsec == 0 {
-		m.nsec, _ = parseIntBytes(m.t, 10, 64)
-	}
 	return m.nsec
 }
 
 func (m *metric) SetName(name string) {
+	m.decode()
 	m.hashID = 0
-	m.name = []byte(nameEscaper.Replace(name))
+	m.line, m.cache = nil, nil
+	m.name = name
 }
 
 func (m *metric) SetPrefix(prefix string) {
-	m.hashID = 0
-	m.name = append([]byte(nameEscaper.Replace(prefix)), m.name...)
+	m.SetName(prefix + m.name)
 }
 
 func (m *metric) SetSuffix(suffix string) {
-	m.hashID = 0
-	m.name = append(m.name, []byte(nameEscaper.Replace(suffix))...)
+	m.SetName(m.name + suffix)
+}
+
+// modify prepares the metric for a change of its tags or fields.
+func (m *metric) modify() {
+	m.decode()
+	if m.shared {
+		m.tags = append([]tag(nil), m.tags...)
+		m.fields = append([]field(nil), m.fields...)
+		m.shared = false
+	}
+	m.line, m.cache = nil, nil
+}
+
+// tagIndex returns the index of the tag with the given key, or where it
+// would be inserted.
+func (m *metric) tagIndex(key string) (int, bool) {
+	m.decodeTags()
+	i := sort.Search(len(m.tags), func(i int) bool {
+		return m.tags[i].key >= key
+	})
+	return i, i < len(m.tags) && m.tags[i].key == key
 }
 
 func (m *metric) AddTag(key, value string) {
-	m.RemoveTag(key)
-	m.tags = append(m.tags, []byte(","+escape(key, "tagkey")+"="+escape(value, "tagval"))...)
+	if len(key) == 0 || len(value) == 0 {
+		return
+	}
+	m.modify()
+	m.hashID = 0
+
+	i, ok := m.tagIndex(key)
+	if ok {
+		m.tags[i].value = value
+		return
+	}
+	m.tags = append(m.tags, tag{})
+	copy(m.tags[i+1:], m.tags[i:])
+	m.tags[i] = tag{key: key, value: value}
 }
 
 func (m *metric) HasTag(key string) bool {
-	i := bytes.Index(m.tags, []byte(escape(key, "tagkey")+"="))
-	if i == -1 {
-		return false
-	}
-	return true
+	_, ok := m.tagIndex(key)
+	return ok
 }
 
 func (m *metric) RemoveTag(key string) {
-	m.hashID = 0
-
-	i := bytes.Index(m.tags, []byte(escape(key, "tagkey")+"="))
-	if i == -1 {
+	i, ok := m.tagIndex(key)
+	if !ok {
 		return
 	}
+	m.modify()
+	m.hashID = 0
+	m.tags = append(m.tags[:i], m.tags[i+1:]...)
+}
 
-	tmp := m.tags[0 : i-1]
-	j := indexUnescapedByte(m.tags[i:], ',')
-	if j != -1 {
-		tmp = append(tmp, m.tags[i+j:]...)
+func (m *metric) fieldIndex(key string) int {
+	m.decodeFields()
+	for i, f := range m.fields {
+		if f.key == key {
+			return i
+		}
 	}
-	m.tags = tmp
-	return
+	return -1
 }
 
 func (m *metric) AddField(key string, value interface{}) {
-	m.fields = append(m.fields, ',')
-	m.fields = appendField(m.fields, key, value)
+	value = convertField(value)
+	if value == nil {
+		return
+	}
+	m.modify()
+
+	if i := m.fieldIndex(key); i != -1 {
+		m.fields[i].value = value
+		return
+	}
+	m.fields = append(m.fields, field{key: key, value: value})
 }
 
 func (m *metric) HasField(key string) bool {
-	i := bytes.Index(m.fields, []byte(escape(key, "tagkey")+"="))
-	if i == -1 {
-		return false
-	}
-	return true
+	return m.fieldIndex(key) != -1
 }
 
 func (m *metric) RemoveField(key string) error {
-	i := bytes.Index(m.fields, []byte(escape(key, "tagkey")+"="))
+	i := m.fieldIndex(key)
 	if i == -1 {
 		return nil
 	}
-
-	var tmp []byte
-	if i != 0 {
-		tmp = m.fields[0 : i-1]
-	}
-	j := indexUnescapedByte(m.fields[i:], ',')
-	if j != -1 {
-		tmp = append(tmp, m.fields[i+j:]...)
-	}
-
-	if len(tmp) == 0 {
-		return fmt.Errorf("Metric cannot remove final field: %s", m.fields)
+	if len(m.fields) == 1 {
+		return fmt.Errorf("Metric cannot remove final field: %s", key)
 	}
 
-	m.fields = tmp
+	m.modify()
+	m.fields = append(m.fields[:i], m.fields[i+1:]...)
 	return nil
 }
 
+// Copy returns a copy of the metric, sharing its tags and fields until
+// either of them is modified.
 func (m *metric) Copy() telegraf.Metric {
-	return copyWith(m.name, m.tags, m.fields, m.t)
-}
-
-func copyWith(name, tags, fields, t []byte) telegraf.Metric {
-	out := metric{
-		name:   make([]byte, len(name)),
-		tags:   make([]byte, len(tags)),
-		fields: make([]byte, len(fields)),
-		t:      make([]byte, len(t)),
+	if m.line == nil && m.cache == nil {
+		m.cache = &lineCache{}
 	}
-	copy(out.name, name)
-	copy(out.tags, tags)
-	copy(out.fields, fields)
-	copy(out.t, t)
+	m.shared = true
+	out := *m
 	return &out
 }
 
 func (m *metric) HashID() uint64 {
 	if m.hashID == 0 {
+		m.decodeTags()
 		h := fnv.New64a()
-		h.Write(m.name)
-
-		tags := m.Tags()
-		tmp := make([]string, len(tags))
-		i := 0
-		for k, v := range tags {
-			tmp[i] = k + v
-			i++
+		h.Write([]byte(m.name))
+		h.Write([]byte("\n"))
+		for _, t := range m.tags {
+			h.Write([]byte(t.key))
+			h.Write([]byte("\n"))
+			h.Write([]byte(t.value))
+			h.Write([]byte("\n"))
 		}
-		sort.Strings(tmp)
-
-		for _, s := range tmp {
-			h.Write([]byte(s))
-		}
-
 		m.hashID = h.Sum64()
 	}
 	return m.hashID
 }
 
-func appendField(b []byte, k string, v interface{}) []byte {
-	if v == nil {
-		return b
-	}
-	b = append(b, []byte(escape(k, "tagkey")+"=")...)
-
+// convertField converts a field value to one of the types of the line
+// protocol: float64, int64, string or bool. It returns nil if the value
+// can't be represented.
+func convertField(v interface{}) interface{} {
 	// check popular types first
 	switch v := v.(type) {
 	case float64:
-		b = strconv.AppendFloat(b, v, 'f', -1, 64)
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil
+		}
+		return v
 	case int64:
-		b = strconv.AppendInt(b, v, 10)
-		b = append(b, 'i')
+		return v
 	case string:
-		b = append(b, '"')
-		b = append(b, []byte(escape(v, "fieldval"))...)
-		b = append(b, '"')
+		return v
 	case bool:
-		b = strconv.AppendBool(b, v)
+		return v
 	case int32:
-		b = strconv.AppendInt(b, int64(v), 10)
-		b = append(b, 'i')
+		return int64(v)
 	case int16:
-		b = strconv.AppendInt(b, int64(v), 10)
-		b = append(b, 'i')
+		return int64(v)
 	case int8:
-		b = strconv.AppendInt(b, int64(v), 10)
-		b = append(b, 'i')
+		return int64(v)
 	case int:
-		b = strconv.AppendInt(b, int64(v), 10)
-		b = append(b, 'i')
+		return int64(v)
 	case uint64:
 		// Cap uints above the maximum int value
-		var intv int64
 		if v <= uint64(MaxInt) {
-			intv = int64(v)
-		} else {
-			intv = int64(MaxInt)
+			return int64(v)
 		}
-		b = strconv.AppendInt(b, intv, 10)
-		b = append(b, 'i')
+		return int64(MaxInt)
 	case uint32:
-		b = strconv.AppendInt(b, int64(v), 10)
-		b = append(b, 'i')
+		return int64(v)
 	case uint16:
-		b = strconv.AppendInt(b, int64(v), 10)
-		b = append(b, 'i')
+		return int64(v)
 	case uint8:
-		b = strconv.AppendInt(b, int64(v), 10)
-		b = append(b, 'i')
+		return int64(v)
 	case uint:
 		// Cap uints above the maximum int value
-		var intv int64
 		if v <= uint(MaxInt) {
-			intv = int64(v)
-		} else {
-			intv = int64(MaxInt)
+			return int64(v)
 		}
-		b = strconv.AppendInt(b, intv, 10)
-		b = append(b, 'i')
+		return int64(MaxInt)
 	case float32:
-		b = strconv.AppendFloat(b, float64(v), 'f', -1, 32)
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			return nil
+		}
+		// use the shortest decimal representation of the float32, rather
+		// than its exact float64 value.
+		f, _ := strconv.ParseFloat(strconv.FormatFloat(float64(v), 'f', -1, 32), 64)
+		return f
 	case []byte:
-		b = append(b, v...)
+		// bytes are the line protocol representation of the value
+		if value, ok := parseValue(v); ok {
+			return value
+		}
+		return string(v)
+	case nil:
+		return nil
 	default:
 		// Can't determine the type, so convert to string
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// appendField appends the line protocol of a field, whose value must have
+// been converted by convertField.
+func appendField(b []byte, k string, v interface{}) []byte {
+	b = append(b, escape(k, "fieldkey")...)
+	b = append(b, '=')
+
+	switch v := v.(type) {
+	case float64:
+		b = strconv.AppendFloat(b, v, 'f', -1, 64)
+	case int64:
+		b = strconv.AppendInt(b, v, 10)
+		b = append(b, 'i')
+	case string:
 		b = append(b, '"')
-		b = append(b, []byte(escape(fmt.Sprintf("%v", v), "fieldval"))...)
+		b = append(b, escape(v, "fieldval")...)
 		b = append(b, '"')
+	case bool:
+		b = strconv.AppendBool(b, v)
 	}
-
 	return b
 }
@@ -37,13 +37,11 @@ func BenchmarkNewMetric(b *testing.B) {
 }
 
 func BenchmarkAddTag(b *testing.B) {
-	var mt telegraf.Metric
-	mt = &metric{
-		name:   []byte("cpu"),
-		tags:   []byte(",host=localhost"),
-		fields: []byte("a=101"),
-		t:      []byte("1480614053000000000"),
-	}
+	mt, _ := New("cpu",
+		map[string]string{"host": "localhost"},
+		map[string]interface{}{"a": int64(101)},
+		time.Unix(0, 1480614053000000000),
+	)
 	for n := 0; n < b.N; n++ {
 		mt.AddTag("foo", "bar")
 	}
@@ -51,13 +49,17 @@ func BenchmarkAddTag(b *testing.B) {
 }
 
 func BenchmarkSplit(b *testing.B) {
-	var mt telegraf.Metric
-	mt = &metric{
-		name:   []byte("cpu"),
-		tags:   []byte(",host=localhost"),
-		fields: []byte("a=101,b=10i,c=10101,d=101010,e=42"),
-		t:      []byte("1480614053000000000"),
-	}
+	mt, _ := New("cpu",
+		map[string]string{"host": "localhost"},
+		map[string]interface{}{
+			"a": float64(101),
+			"b": int64(10),
+			"c": float64(10101),
+			"d": float64(101010),
+			"e": float64(42),
+		},
+		time.Unix(0, 1480614053000000000),
+	)
 	var metrics []telegraf.Metric
 	for n := 0; n < b.N; n++ {
 		metrics = mt.Split(60)
@@ -146,3 +148,112 @@ func BenchmarkSerialize(b *testing.B) {
 	}
 	s = string(B)
 }
+
+func newBenchmarkMetric() telegraf.Metric {
+	mt, _ := New("test_metric",
+		map[string]string{
+			"test_tag_1": "tag_value_1",
+			"test_tag_2": "tag_value_2",
+			"test_tag_3": "tag_value_3",
+		},
+		map[string]interface{}{
+			"string_field": "string",
+			"int_field":    int64(1000),
+			"float_field":  float64(2.1),
+		},
+		time.Now(),
+	)
+	return mt
+}
+
+func BenchmarkTagsOnly(b *testing.B) {
+	mt := newBenchmarkMetric()
+	for n := 0; n < b.N; n++ {
+		tags = mt.Tags()
+	}
+	s = fmt.Sprint(tags)
+}
+
+func BenchmarkFieldsOnly(b *testing.B) {
+	mt := newBenchmarkMetric()
+	for n := 0; n < b.N; n++ {
+		fields = mt.Fields()
+	}
+	s = fmt.Sprint(fields)
+}
+
+func BenchmarkHasTag(b *testing.B) {
+	mt := newBenchmarkMetric()
+	var ok bool
+	for n := 0; n < b.N; n++ {
+		ok = mt.HasTag("test_tag_2")
+	}
+	s = fmt.Sprint(ok)
+}
+
+func BenchmarkHashID(b *testing.B) {
+	var h uint64
+	for n := 0; n < b.N; n++ {
+		// a new metric, as the hash is cached
+		mt := newBenchmarkMetric()
+		h = mt.HashID()
+	}
+	s = fmt.Sprint(h)
+}
+
+func BenchmarkCopy(b *testing.B) {
+	mt := newBenchmarkMetric()
+	var c telegraf.Metric
+	for n := 0; n < b.N; n++ {
+		c = mt.Copy()
+	}
+	s = c.Name()
+}
+
+// BenchmarkCopyPerOutput copies a metric for each of 4 outputs, as the
+// agent does, where one of the outputs modifies its copy and all serialize
+// it.
+func BenchmarkCopyPerOutput(b *testing.B) {
+	mt := newBenchmarkMetric()
+	for n := 0; n < b.N; n++ {
+		for i := 0; i < 4; i++ {
+			c := mt.Copy()
+			if i == 0 {
+				c.AddTag("output", "first")
+			}
+			s = c.String()
+		}
+	}
+}
+
+func BenchmarkCopyAddTag(b *testing.B) {
+	mt := newBenchmarkMetric()
+	for n := 0; n < b.N; n++ {
+		c := mt.Copy()
+		c.AddTag("foo", "bar")
+		tags = c.Tags()
+	}
+	s = fmt.Sprint(tags)
+}
+
+func BenchmarkParse(b *testing.B) {
+	line := []byte("test_metric,test_tag_1=tag_value_1,test_tag_2=tag_value_2,test_tag_3=tag_value_3 " +
+		"string_field=\"string\",int_field=1000i,float_field=2.1 1480614053000000000\n")
+	var metrics []telegraf.Metric
+	for n := 0; n < b.N; n++ {
+		metrics, _ = Parse(line)
+	}
+	s = metrics[0].Name()
+}
+
+// BenchmarkParseFields parses a line and reads its fields, as processors
+// and non-influx serializers do.
+func BenchmarkParseFields(b *testing.B) {
+	line := []byte("test_metric,test_tag_1=tag_value_1,test_tag_2=tag_value_2,test_tag_3=tag_value_3 " +
+		"string_field=\"string\",int_field=1000i,float_field=2.1 1480614053000000000\n")
+	for n := 0; n < b.N; n++ {
+		metrics, _ := Parse(line)
+		fields = metrics[0].Fields()
+	}
+	s = fmt.Sprint(fields)
+}
@@ -735,3 +735,65 @@ func TestNewMetric_TrailingSlash(t *testing.T) {
 		assert.Error(t, err)
 	}
 }
+
+func TestNewMetric_CopyOnWrite(t *testing.T) {
+	now := time.Unix(0, 1480940990034083306)
+	m, err := New("cpu",
+		map[string]string{"host": "localhost"},
+		map[string]interface{}{"value": float64(1)},
+		now, telegraf.Gauge)
+	require.NoError(t, err)
+
+	c1 := m.Copy()
+	c2 := m.Copy()
+	assert.Equal(t, telegraf.Gauge, c1.Type())
+
+	c1.AddTag("dc", "us-east-1")
+	c1.AddField("count", int64(2))
+	assert.NoError(t, c2.RemoveField("missing"))
+	c2.RemoveTag("host")
+	c2.SetName("mem")
+
+	assert.Equal(t, "cpu,host=localhost value=1 1480940990034083306\n", m.String())
+	assert.Equal(t, "cpu,dc=us-east-1,host=localhost value=1,count=2i 1480940990034083306\n", c1.String())
+	assert.Equal(t, "mem value=1 1480940990034083306\n", c2.String())
+
+	// modifying the original doesn't change the copies either
+	m.AddTag("host", "remote")
+	assert.Equal(t, map[string]string{"dc": "us-east-1", "host": "localhost"}, c1.Tags())
+	assert.Equal(t, map[string]string{}, c2.Tags())
+}
+
+func TestParse_Modify(t *testing.T) {
+	metrics, err := Parse([]byte("cpu,host=a,dc=b value=1.0,str=\"x y\" 1480940990034083306\n"))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	m := metrics[0]
+
+	// the line is kept as received until the metric is modified
+	assert.Equal(t, "cpu,host=a,dc=b value=1.0,str=\"x y\" 1480940990034083306\n", m.String())
+	assert.Equal(t, map[string]string{"host": "a", "dc": "b"}, m.Tags())
+	assert.Equal(t, map[string]interface{}{"value": float64(1), "str": "x y"}, m.Fields())
+
+	c := m.Copy()
+	c.RemoveTag("host")
+	assert.Equal(t, "cpu,dc=b value=1,str=\"x y\" 1480940990034083306\n", c.String())
+	assert.Equal(t, "cpu,host=a,dc=b value=1.0,str=\"x y\" 1480940990034083306\n", m.String())
+}
+
+func TestNewMetric_BytesField(t *testing.T) {
+	m, err := New("cpu", nil,
+		map[string]interface{}{
+			"int":    []byte("42i"),
+			"float":  []byte("4.2"),
+			"string": []byte("foo"),
+		},
+		time.Now())
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"int":    int64(42),
+		"float":  4.2,
+		"string": "foo",
+	}, m.Fields())
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"time"
 
@@ -93,7 +94,6 @@ func parseMetric(buf []byte,
 	defaultTime time.Time,
 	precision string,
 ) (telegraf.Metric, error) {
-	var dTime string
 	// scan the first block which is measurement[,tag1=value1,tag2=value=2...]
 	pos, key, err := scanKey(buf, 0)
 	if err != nil {
@@ -128,46 +128,183 @@ func parseMetric(buf []byte,
 
 	// apply precision multiplier
 	var nsec int64
-	multiplier := getPrecisionMultiplier(precision)
-	if len(ts) > 0 && multiplier > 1 {
-		tsint, err := parseIntBytes(ts, 10, 64)
+	if len(ts) > 0 {
+		nsec, err = parseIntBytes(ts, 10, 64)
 		if err != nil {
 			return nil, err
 		}
-
-		nsec := multiplier * tsint
-		ts = []byte(strconv.FormatInt(nsec, 10))
+		multiplier := getPrecisionMultiplier(precision)
+		if multiplier > 1 {
+			nsec = multiplier * nsec
+			ts = nil
+		}
+	} else {
+		nsec = defaultTime.UnixNano()
 	}
 
+	// parse out the measurement name, the tags and fields are decoded on
+	// first use.
+	// namei is the index at which the "name" ends
+	name := key
+	if namei := indexUnescapedByte(key, ','); namei >= 1 {
+		name = key[0:namei]
+	}
 	m := &metric{
-		fields: fields,
-		t:      ts,
-		nsec:   nsec,
+		name:      unescape(string(name), "name"),
+		nsec:      nsec,
+		mType:     telegraf.Untyped,
+		keyLen:    len(key),
+		fieldsLen: len(fields),
+
+		tagsEncoded:   true,
+		fieldsEncoded: true,
+	}
+
+	// the line is kept as it was received, unless the timestamp was
+	// changed, so that it doesn't need to be serialized again.
+	if len(ts) == 0 {
+		ts = strconv.AppendInt(nil, nsec, 10)
+	}
+	line := make([]byte, 0, len(key)+len(fields)+len(ts)+3)
+	line = append(line, key...)
+	line = append(line, ' ')
+	line = append(line, fields...)
+	line = append(line, ' ')
+	line = append(line, ts...)
+	m.line = append(line, '\n')
+
+	return m, nil
+}
+
+// parseTags parses the tags section of a line, including its leading comma.
+// The tags are returned sorted by key.
+func parseTags(buf []byte) []tag {
+	tags := make([]tag, 0, bytes.Count(buf, []byte(",")))
+	i := 0
+	for {
+		// start index of tag key
+		i0 := indexUnescapedByte(buf[i:], ',') + 1
+		if i0 == 0 {
+			// didn't find a tag start
+			break
+		}
+		// end index of tag key
+		i1 := indexUnescapedByte(buf[i:], '=')
+		// start index of tag value
+		i2 := i1 + 1
+		// end index of tag value (starting from i2)
+		i3 := indexUnescapedByte(buf[i+i2:], ',')
+		var value []byte
+		if i3 == -1 {
+			value = buf[i:][i2:]
+		} else {
+			value = buf[i:][i2 : i2+i3]
+		}
+		tags = append(tags, tag{
+			key:   unescape(string(buf[i:][i0:i1]), "tagkey"),
+			value: unescape(string(value), "tagval"),
+		})
+		if i3 == -1 {
+			break
+		}
+		// increment start index for the next tag
+		i += i2 + i3
 	}
 
-	// parse out the measurement name
-	// namei is the index at which the "name" ends
-	namei := indexUnescapedByte(key, ',')
-	if namei < 1 {
-		// no tags
-		m.name = key
-	} else {
-		m.name = key[0:namei]
-		m.tags = key[namei:]
+	// the last value of a duplicated key is kept
+	sort.Stable(tagsByKey(tags))
+	out := tags[:0]
+	for i, t := range tags {
+		if i+1 < len(tags) && tags[i+1].key == t.key {
+			continue
+		}
+		out = append(out, t)
 	}
+	return out
+}
 
-	if len(m.t) == 0 {
-		if len(dTime) == 0 {
-			dTime = fmt.Sprint(defaultTime.UnixNano())
+// parseFields parses the fields section of a line. Values which can't be
+// parsed are skipped.
+func parseFields(buf []byte) []field {
+	fields := make([]field, 0, bytes.Count(buf, []byte(","))+1)
+	add := func(key []byte, value interface{}) {
+		k := unescape(string(key), "fieldkey")
+		for i := range fields {
+			if fields[i].key == k {
+				fields[i].value = value
+				return
+			}
 		}
-		// use default time
-		m.t = []byte(dTime)
+		fields = append(fields, field{key: k, value: value})
 	}
 
-	// here we copy on return because this allows us to later call
-	// AddTag, AddField, RemoveTag, RemoveField, etc. without worrying about
-	// modifying 'tag' bytes having an affect on 'field' bytes, for example.
-	return m.Copy(), nil
+	i := 0
+	for {
+		if i >= len(buf) {
+			break
+		}
+		// end index of field key
+		i1 := indexUnescapedByte(buf[i:], '=')
+		if i1 == -1 {
+			break
+		}
+		// start index of field value
+		i2 := i1 + 1
+
+		// end index of field value
+		var i3 int
+		if buf[i:][i2] == '"' {
+			i3 = indexUnescapedByteBackslashEscaping(buf[i:][i2+1:], '"')
+			if i3 == -1 {
+				i3 = len(buf[i:])
+			}
+			i3 += i2 + 2 // increment index to the comma
+		} else {
+			i3 = indexUnescapedByte(buf[i:], ',')
+			if i3 == -1 {
+				i3 = len(buf[i:])
+			}
+		}
+
+		if v, ok := parseValue(buf[i:][i2:i3]); ok {
+			add(buf[i:][0:i1], v)
+		}
+
+		i += i3 + 1
+	}
+	return fields
+}
+
+// parseValue parses a field value.
+func parseValue(buf []byte) (interface{}, bool) {
+	if len(buf) == 0 {
+		return nil, false
+	}
+	switch buf[0] {
+	case '"':
+		// string field
+		if len(buf) < 2 {
+			return nil, false
+		}
+		return unescape(string(buf[1:len(buf)-1]), "fieldval"), true
+	case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		// number field
+		switch buf[len(buf)-1] {
+		case 'i':
+			// integer field
+			n, err := parseIntBytes(buf[:len(buf)-1], 10, 64)
+			return n, err == nil
+		default:
+			// float field
+			n, err := parseFloatBytes(buf, 64)
+			return n, err == nil
+		}
+	case 'T', 't', 'F', 'f':
+		// boolean field
+		b, err := parseBoolBytes(buf)
+		return b, err == nil
+	}
+	return nil, false
 }
 
 // scanKey scans buf starting at i for the measurement and tag portion of the point.
@@ -0,0 +1,136 @@
+package metric
+
+import (
+	"sync/atomic"
+
+	"github.com/influxdata/telegraf"
+)
+
+// NotifyFunc is called once all the metrics of a tracked group have been
+// accepted, rejected or dropped.
+type NotifyFunc func(telegraf.DeliveryInfo)
+
+var lastTrackingID uint64
+
+func newTrackingID() telegraf.TrackingID {
+	return telegraf.TrackingID(atomic.AddUint64(&lastTrackingID, 1))
+}
+
+// trackingData is shared by the metrics of a tracked group, and by their
+// copies. It counts the metrics that are yet to be accepted, rejected or
+// dropped.
+type trackingData struct {
+	id       telegraf.TrackingID
+	count    int32
+	rejected int32
+	notify   NotifyFunc
+}
+
+func (d *trackingData) incr() {
+	atomic.AddInt32(&d.count, 1)
+}
+
+func (d *trackingData) decr() {
+	if atomic.AddInt32(&d.count, -1) == 0 {
+		d.notify(&deliveryInfo{
+			id:        d.id,
+			delivered: atomic.LoadInt32(&d.rejected) == 0,
+		})
+	}
+}
+
+type deliveryInfo struct {
+	id        telegraf.TrackingID
+	delivered bool
+}
+
+func (i *deliveryInfo) ID() telegraf.TrackingID {
+	return i.id
+}
+
+func (i *deliveryInfo) Delivered() bool {
+	return i.delivered
+}
+
+// trackingMetric is a metric of a tracked group. Each copy of it must be
+// accepted, rejected or dropped, and only the first of these calls counts.
+type trackingMetric struct {
+	telegraf.Metric
+	d        *trackingData
+	released int32
+}
+
+// WithTracking tracks the delivery of a metric, notify is called once it and
+// all its copies have been accepted, rejected or dropped.
+func WithTracking(m telegraf.Metric, notify NotifyFunc) (telegraf.Metric, telegraf.TrackingID) {
+	group, id := WithGroupTracking([]telegraf.Metric{m}, notify)
+	return group[0], id
+}
+
+// WithGroupTracking tracks the delivery of a group of metrics, notify is called
+// once all of them and their copies have been accepted, rejected or dropped.
+// It is called immediately for an empty group.
+func WithGroupTracking(group []telegraf.Metric, notify NotifyFunc) ([]telegraf.Metric, telegraf.TrackingID) {
+	d := &trackingData{
+		id:     newTrackingID(),
+		count:  int32(len(group)),
+		notify: notify,
+	}
+	if len(group) == 0 {
+		notify(&deliveryInfo{id: d.id, delivered: true})
+		return group, d.id
+	}
+
+	tracked := make([]telegraf.Metric, len(group))
+	for i, m := range group {
+		tracked[i] = &trackingMetric{Metric: m, d: d}
+	}
+	return tracked, d.id
+}
+
+// WithTrackingOf returns m tracked in the same group as from, if from is
+// tracked and m is not, so that a metric derived from a tracked metric, ie
+// by a processor, is delivered before the group is. Otherwise m is returned
+// unchanged: a metric already tracked, even in another group, is only
+// released by its own group.
+func WithTrackingOf(m telegraf.Metric, from telegraf.Metric) telegraf.Metric {
+	tf, ok := from.(*trackingMetric)
+	if !ok {
+		return m
+	}
+	if _, ok := m.(*trackingMetric); ok {
+		return m
+	}
+	tf.d.incr()
+	return &trackingMetric{Metric: m, d: tf.d}
+}
+
+// Copy returns a copy of the metric, tracked in the same group.
+func (m *trackingMetric) Copy() telegraf.Metric {
+	m.d.incr()
+	return &trackingMetric{Metric: m.Metric.Copy(), d: m.d}
+}
+
+func (m *trackingMetric) Accept() {
+	if m.release() {
+		m.d.decr()
+	}
+}
+
+func (m *trackingMetric) Reject() {
+	if m.release() {
+		atomic.StoreInt32(&m.d.rejected, 1)
+		m.d.decr()
+	}
+}
+
+func (m *trackingMetric) Drop() {
+	if m.release() {
+		m.d.decr()
+	}
+}
+
+// release returns true the first time it is called.
+func (m *trackingMetric) release() bool {
+	return atomic.CompareAndSwapInt32(&m.released, 0, 1)
+}
@@ -0,0 +1,102 @@
+package metric
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type deliveries struct {
+	infos []telegraf.DeliveryInfo
+}
+
+func (d *deliveries) notify(info telegraf.DeliveryInfo) {
+	d.infos = append(d.infos, info)
+}
+
+func newTestMetric(t *testing.T) telegraf.Metric {
+	m, err := New("cpu",
+		map[string]string{"host": "localhost"},
+		map[string]interface{}{"value": 42.0},
+		time.Unix(0, 0))
+	require.NoError(t, err)
+	return m
+}
+
+func TestTracking_Accept(t *testing.T) {
+	d := &deliveries{}
+	m, id := WithTracking(newTestMetric(t), d.notify)
+	assert.Equal(t, "cpu", m.Name())
+
+	m.Accept()
+	require.Len(t, d.infos, 1)
+	assert.Equal(t, id, d.infos[0].ID())
+	assert.True(t, d.infos[0].Delivered())
+
+	// only the first call counts
+	m.Reject()
+	assert.Len(t, d.infos, 1)
+}
+
+func TestTracking_GroupWithCopies(t *testing.T) {
+	d := &deliveries{}
+	group, id := WithGroupTracking(
+		[]telegraf.Metric{newTestMetric(t), newTestMetric(t)}, d.notify)
+	require.Len(t, group, 2)
+
+	c := group[0].Copy()
+	group[0].Accept()
+	group[1].Drop()
+	assert.Empty(t, d.infos)
+
+	c.Reject()
+	require.Len(t, d.infos, 1)
+	assert.Equal(t, id, d.infos[0].ID())
+	assert.False(t, d.infos[0].Delivered())
+}
+
+func TestTracking_EmptyGroup(t *testing.T) {
+	d := &deliveries{}
+	group, id := WithGroupTracking(nil, d.notify)
+	assert.Empty(t, group)
+	require.Len(t, d.infos, 1)
+	assert.Equal(t, id, d.infos[0].ID())
+	assert.True(t, d.infos[0].Delivered())
+}
+
+func TestTracking_WithTrackingOf(t *testing.T) {
+	d := &deliveries{}
+	m, _ := WithTracking(newTestMetric(t), d.notify)
+
+	untracked := newTestMetric(t)
+	assert.True(t, untracked == WithTrackingOf(untracked, newTestMetric(t)))
+	assert.True(t, m == WithTrackingOf(m, m))
+
+	derived := WithTrackingOf(untracked, m)
+	m.Drop()
+	assert.Empty(t, d.infos)
+	derived.Accept()
+	require.Len(t, d.infos, 1)
+	assert.True(t, d.infos[0].Delivered())
+}
+
+func TestTracking_WithTrackingOfOtherGroup(t *testing.T) {
+	d := &deliveries{}
+	m, id := WithTracking(newTestMetric(t), d.notify)
+	other, otherID := WithTracking(newTestMetric(t), d.notify)
+
+	// a metric tracked in another group is not tracked again
+	assert.True(t, other == WithTrackingOf(other, m))
+
+	m.Accept()
+	require.Len(t, d.infos, 1)
+	assert.Equal(t, id, d.infos[0].ID())
+
+	other.Accept()
+	require.Len(t, d.infos, 2)
+	assert.Equal(t, otherID, d.infos[1].ID())
+}
@@ -0,0 +1,10 @@
+package telegraf
+
+// Initializer is a plugin of any type that checks and prepares its
+// configuration before it is used. Init is called once the plugin has been
+// configured, before it is started; an error means the configuration is
+// invalid and telegraf does not start.
+type Initializer interface {
+	// Init checks the configuration of the plugin and prepares it.
+	Init() error
+}
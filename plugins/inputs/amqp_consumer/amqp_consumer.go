@@ -10,7 +10,7 @@ import (
 	"github.com/streadway/amqp"
 
 	"github.com/influxdata/telegraf"
-	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers"
 )
@@ -29,16 +29,13 @@ type AMQPConsumer struct {
 	// for consumers before receiving delivery acks.
 	PrefetchCount int
 
+	// Maximum number of messages read but not yet acknowledged because their
+	// metrics are not yet written by the outputs.
+	MaxUndeliveredMessages int `toml:"max_undelivered_messages"`
+
 	// AMQP Auth method
 	AuthMethod string
-	// Path to CA file
-	SSLCA string `toml:"ssl_ca"`
-	// Path to host cert file
-	SSLCert string `toml:"ssl_cert"`
-	// Path to cert key file
-	SSLKey string `toml:"ssl_key"`
-	// Use SSL but skip chain & host verification
-	InsecureSkipVerify bool
+	tls.ClientConfig
 
 	parser parsers.Parser
 	conn   *amqp.Connection
@@ -57,6 +54,8 @@ func (a *externalAuth) Response() string {
 const (
 	DefaultAuthMethod    = "PLAIN"
 	DefaultPrefetchCount = 50
+
+	DefaultMaxUndeliveredMessages = 1000
 )
 
 func (a *AMQPConsumer) SampleConfig() string {
@@ -73,16 +72,21 @@ func (a *AMQPConsumer) SampleConfig() string {
   ## Maximum number of messages server should give to the worker.
   prefetch_count = 50
 
+  ## Maximum number of messages read but not yet written by the outputs. A
+  ## message is only acknowledged once its metrics are written, the server
+  ## does not send more than prefetch_count unacknowledged messages either.
+  # max_undelivered_messages = 1000
+
   ## Auth method. PLAIN and EXTERNAL are supported
   ## Using EXTERNAL requires enabling the rabbitmq_auth_mechanism_ssl plugin as
   ## described here: https://www.rabbitmq.com/plugins.html
   # auth_method = "PLAIN"
 
-  ## Optional SSL Config
-  # ssl_ca = "/etc/telegraf/ca.pem"
-  # ssl_cert = "/etc/telegraf/cert.pem"
-  # ssl_key = "/etc/telegraf/key.pem"
-  ## Use SSL but skip chain & host verification
+  ## Optional TLS Config
+  # tls_ca = "/etc/telegraf/ca.pem"
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+  ## Use TLS but skip chain & host verification
   # insecure_skip_verify = false
 
   ## Data format to consume.
@@ -108,8 +112,7 @@ func (a *AMQPConsumer) Gather(_ telegraf.Accumulator) error {
 
 func (a *AMQPConsumer) createConfig() (*amqp.Config, error) {
 	// make new tls config
-	tls, err := internal.GetTLSConfig(
-		a.SSLCert, a.SSLKey, a.SSLCA, a.InsecureSkipVerify)
+	tls, err := a.ClientConfig.TLSConfig()
 	if err != nil {
 		return nil, err
 	}
@@ -140,9 +143,14 @@ func (a *AMQPConsumer) Start(acc telegraf.Accumulator) error {
 		return err
 	}
 
+	if a.MaxUndeliveredMessages <= 0 {
+		a.MaxUndeliveredMessages = DefaultMaxUndeliveredMessages
+	}
+	tacc := acc.WithTracking(a.MaxUndeliveredMessages)
+
 	a.wg = &sync.WaitGroup{}
 	a.wg.Add(1)
-	go a.process(msgs, acc)
+	go a.process(msgs, tacc)
 
 	go func() {
 		err := <-a.conn.NotifyClose(make(chan *amqp.Error))
@@ -160,7 +168,7 @@ func (a *AMQPConsumer) Start(acc telegraf.Accumulator) error {
 			}
 
 			a.wg.Add(1)
-			go a.process(msgs, acc)
+			go a.process(msgs, tacc)
 			break
 		}
 	}()
@@ -242,22 +250,55 @@ func (a *AMQPConsumer) connect(amqpConf *amqp.Config) (<-chan amqp.Delivery, err
 	return msgs, err
 }
 
-// Read messages from queue and add them to the Accumulator
-func (a *AMQPConsumer) process(msgs <-chan amqp.Delivery, acc telegraf.Accumulator) {
+// Read messages from queue and add them to the Accumulator, a message is
+// acknowledged once its metrics are delivered, or rejected without requeueing
+// if an output could not write them.
+func (a *AMQPConsumer) process(msgs <-chan amqp.Delivery, acc telegraf.TrackingAccumulator) {
 	defer a.wg.Done()
-	for d := range msgs {
-		metrics, err := a.parser.Parse(d.Body)
-		if err != nil {
-			log.Printf("E! %v: error parsing metric - %v", err, string(d.Body))
-		} else {
-			for _, m := range metrics {
-				acc.AddFields(m.Name(), m.Fields(), m.Tags(), m.Time())
-			}
+	undelivered := make(map[telegraf.TrackingID]amqp.Delivery)
+	for {
+		in := msgs
+		if len(undelivered) >= a.MaxUndeliveredMessages {
+			// wait for messages to be delivered before reading more
+			in = nil
 		}
 
-		d.Ack(false)
+		select {
+		case info := <-acc.Delivered():
+			d, ok := undelivered[info.ID()]
+			if !ok {
+				// a message read on a previous connection
+				continue
+			}
+			delete(undelivered, info.ID())
+			if info.Delivered() {
+				err := d.Ack(false)
+				if err != nil {
+					log.Printf("E! Error acknowledging AMQP message: %s", err)
+				}
+			} else {
+				log.Printf("W! AMQP message was not delivered to all outputs, rejecting it")
+				err := d.Reject(false)
+				if err != nil {
+					log.Printf("E! Error rejecting AMQP message: %s", err)
+				}
+			}
+		case d, ok := <-in:
+			if !ok {
+				log.Printf("I! AMQP consumer queue closed")
+				return
+			}
+
+			metrics, err := a.parser.Parse(d.Body)
+			if err != nil {
+				log.Printf("E! %v: error parsing metric - %v", err, string(d.Body))
+				metrics = nil
+			}
+			// a message without metrics is delivered right away
+			id := acc.AddTrackingMetricGroup(metrics)
+			undelivered[id] = d
+		}
 	}
-	log.Printf("I! AMQP consumer queue closed")
 }
 
 func (a *AMQPConsumer) Stop() {
@@ -273,8 +314,9 @@ func (a *AMQPConsumer) Stop() {
 func init() {
 	inputs.Add("amqp_consumer", func() telegraf.Input {
 		return &AMQPConsumer{
-			AuthMethod:    DefaultAuthMethod,
-			PrefetchCount: DefaultPrefetchCount,
+			AuthMethod:             DefaultAuthMethod,
+			PrefetchCount:          DefaultPrefetchCount,
+			MaxUndeliveredMessages: DefaultMaxUndeliveredMessages,
 		}
 	})
 }
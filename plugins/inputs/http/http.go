@@ -0,0 +1,242 @@
+package http
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/tls"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/plugins/parsers"
+)
+
+type HTTP struct {
+	URLs               []string          `toml:"urls"`
+	Method             string            `toml:"method"`
+	Body               string            `toml:"body"`
+	Headers            map[string]string `toml:"headers"`
+	Username           string            `toml:"username"`
+	Password           string            `toml:"password"`
+	BearerToken        string            `toml:"bearer_token"`
+	SuccessStatusCodes []int             `toml:"success_status_codes"`
+	Timeout            internal.Duration `toml:"timeout"`
+	tls.ClientConfig
+
+	client *http.Client
+	parser parsers.Parser
+}
+
+var sampleConfig = `
+  ## One or more URLs from which to read formatted metrics
+  urls = [
+    "http://localhost/metrics"
+  ]
+
+  ## HTTP method
+  # method = "GET"
+
+  ## Optional HTTP headers
+  # [inputs.http.headers]
+  #   X-Special-Header = "Special-Value"
+
+  ## Optional HTTP request body
+  # body = '''
+  # {"query": "select * from metrics"}
+  # '''
+
+  ## Optional HTTP basic auth credentials, or bearer token.
+  # username = "username"
+  # password = "pa$$word"
+  # bearer_token = "token"
+
+  ## Status codes of successful responses, the metrics of other responses
+  ## are not parsed and an error is reported.
+  # success_status_codes = [200]
+
+  ## Amount of time allowed to complete the HTTP request
+  # timeout = "5s"
+
+  ## Optional TLS Config
+  # tls_ca = "/etc/telegraf/ca.pem"
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+  ## Use TLS but skip chain & host verification
+  # insecure_skip_verify = false
+
+  ## Data format to consume.
+  ## Each data format has its own unique set of configuration options, read
+  ## more about them here:
+  ## https://github.com/influxdata/telegraf/blob/master/docs/DATA_FORMATS_INPUT.md
+  data_format = "influx"
+`
+
+func (h *HTTP) SampleConfig() string {
+	return sampleConfig
+}
+
+func (h *HTTP) Description() string {
+	return "Read formatted metrics from one or more HTTP endpoints"
+}
+
+func (h *HTTP) SetParser(parser parsers.Parser) {
+	h.parser = parser
+}
+
+// Gather requests all URLs concurrently, and parses the responses one after
+// the other, as parsers are not safe for concurrent use.
+func (h *HTTP) Gather(acc telegraf.Accumulator) error {
+	if h.parser == nil {
+		return fmt.Errorf("no parser configured")
+	}
+
+	if h.client == nil {
+		tlsCfg, err := h.ClientConfig.TLSConfig()
+		if err != nil {
+			return err
+		}
+		h.client = &http.Client{
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: tlsCfg,
+			},
+			Timeout: h.Timeout.Duration,
+		}
+	}
+
+	bodies := make([][]byte, len(h.URLs))
+	var wg sync.WaitGroup
+	for i, u := range h.URLs {
+		wg.Add(1)
+		go func(i int, url string) {
+			defer wg.Done()
+			b, err := h.fetch(url)
+			if err != nil {
+				acc.AddError(fmt.Errorf("[url=%s]: %s", url, err))
+				return
+			}
+			bodies[i] = b
+		}(i, u)
+	}
+	wg.Wait()
+
+	for i, b := range bodies {
+		if b == nil {
+			continue
+		}
+		if err := h.parse(acc, h.URLs[i], b); err != nil {
+			acc.AddError(fmt.Errorf("[url=%s]: %s", h.URLs[i], err))
+		}
+	}
+	return nil
+}
+
+// fetch requests url and returns the body of the response.
+func (h *HTTP) fetch(url string) ([]byte, error) {
+	var body io.Reader
+	if h.Body != "" {
+		body = strings.NewReader(h.Body)
+	}
+	req, err := http.NewRequest(h.Method, url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", "Telegraf")
+	for k, v := range h.Headers {
+		if strings.ToLower(k) == "host" {
+			req.Host = v
+		} else {
+			req.Header.Set(k, v)
+		}
+	}
+	if h.Username != "" || h.Password != "" {
+		req.SetBasicAuth(h.Username, h.Password)
+	} else if h.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+h.BearerToken)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if !h.isSuccess(resp.StatusCode) {
+		// drain the body so the connection can be reused
+		io.Copy(ioutil.Discard, resp.Body)
+		return nil, fmt.Errorf("received status code %d (%s), expected any value out of %v",
+			resp.StatusCode, http.StatusText(resp.StatusCode), h.successStatusCodes())
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		b = []byte{}
+	}
+	return b, nil
+}
+
+// parse adds the metrics parsed from the body of the response of url, tagged
+// with the url.
+func (h *HTTP) parse(acc telegraf.Accumulator, url string, b []byte) error {
+	metrics, err := h.parser.Parse(b)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range metrics {
+		tags := m.Tags()
+		if _, ok := tags["url"]; !ok {
+			tags["url"] = url
+		}
+
+		switch m.Type() {
+		case telegraf.Counter:
+			acc.AddCounter(m.Name(), m.Fields(), tags, m.Time())
+		case telegraf.Gauge:
+			acc.AddGauge(m.Name(), m.Fields(), tags, m.Time())
+		case telegraf.Summary:
+			acc.AddSummary(m.Name(), m.Fields(), tags, m.Time())
+		case telegraf.Histogram:
+			acc.AddHistogram(m.Name(), m.Fields(), tags, m.Time())
+		default:
+			acc.AddFields(m.Name(), m.Fields(), tags, m.Time())
+		}
+	}
+	return nil
+}
+
+func (h *HTTP) successStatusCodes() []int {
+	if len(h.SuccessStatusCodes) == 0 {
+		return []int{http.StatusOK}
+	}
+	return h.SuccessStatusCodes
+}
+
+func (h *HTTP) isSuccess(code int) bool {
+	for _, c := range h.successStatusCodes() {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	inputs.Add("http", func() telegraf.Input {
+		return &HTTP{
+			Method: "GET",
+			Timeout: internal.Duration{
+				Duration: 5 * time.Second,
+			},
+		}
+	})
+}
@@ -0,0 +1,245 @@
+package http
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/parsers"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPWithInfluxFormat(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/metrics", r.URL.Path)
+		w.Write([]byte("cpu,host=a usage_idle=98.5 1500000000000000000\n" +
+			"cpu,host=b,url=other usage_idle=50 1500000000000000000\n"))
+	}))
+	defer ts.Close()
+
+	parser, err := parsers.NewInfluxParser()
+	require.NoError(t, err)
+
+	url := ts.URL + "/metrics"
+	h := &HTTP{URLs: []string{url}, Method: "GET"}
+	h.SetParser(parser)
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(h.Gather))
+
+	acc.AssertContainsTaggedFields(t, "cpu",
+		map[string]interface{}{"usage_idle": 98.5},
+		map[string]string{"host": "a", "url": url})
+	// the url tag of the metrics is kept
+	acc.AssertContainsTaggedFields(t, "cpu",
+		map[string]interface{}{"usage_idle": float64(50)},
+		map[string]string{"host": "b", "url": "other"})
+}
+
+func TestHTTPWithJSONFormat(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"a": 5, "b": {"c": 6}}`))
+	}))
+	defer ts.Close()
+
+	parser, err := parsers.NewParser(&parsers.Config{
+		DataFormat: "json",
+		MetricName: "metric",
+	})
+	require.NoError(t, err)
+
+	h := &HTTP{URLs: []string{ts.URL}, Method: "GET"}
+	h.SetParser(parser)
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(h.Gather))
+	acc.AssertContainsTaggedFields(t, "metric",
+		map[string]interface{}{"a": float64(5), "b_c": float64(6)},
+		map[string]string{"url": ts.URL})
+}
+
+// typedParser parses a counter and a gauge.
+type typedParser struct {
+	parsers.Parser
+}
+
+func (p *typedParser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	counter, err := metric.New("requests", nil,
+		map[string]interface{}{"count": 5}, time.Now(), telegraf.Counter)
+	if err != nil {
+		return nil, err
+	}
+	gauge, err := metric.New("memory", nil,
+		map[string]interface{}{"used": 7}, time.Now(), telegraf.Gauge)
+	if err != nil {
+		return nil, err
+	}
+	return []telegraf.Metric{counter, gauge}, nil
+}
+
+// typedAccumulator records the type of the added metrics by name.
+type typedAccumulator struct {
+	testutil.Accumulator
+	types map[string]telegraf.ValueType
+}
+
+func (a *typedAccumulator) AddCounter(measurement string, fields map[string]interface{},
+	tags map[string]string, t ...time.Time) {
+	a.types[measurement] = telegraf.Counter
+	a.Accumulator.AddCounter(measurement, fields, tags, t...)
+}
+
+func (a *typedAccumulator) AddGauge(measurement string, fields map[string]interface{},
+	tags map[string]string, t ...time.Time) {
+	a.types[measurement] = telegraf.Gauge
+	a.Accumulator.AddGauge(measurement, fields, tags, t...)
+}
+
+func TestHTTPKeepsMetricTypes(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("metrics"))
+	}))
+	defer ts.Close()
+
+	h := &HTTP{URLs: []string{ts.URL}, Method: "GET"}
+	h.SetParser(&typedParser{})
+
+	acc := &typedAccumulator{types: make(map[string]telegraf.ValueType)}
+	require.NoError(t, h.Gather(acc))
+	require.Empty(t, acc.Errors)
+
+	assert.Equal(t, map[string]telegraf.ValueType{
+		"requests": telegraf.Counter,
+		"memory":   telegraf.Gauge,
+	}, acc.types)
+	acc.AssertContainsTaggedFields(t, "requests",
+		map[string]interface{}{"count": int64(5)},
+		map[string]string{"url": ts.URL})
+}
+
+func TestHTTPRequest(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "value", r.Header.Get("X-Special-Header"))
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, "select", string(body))
+		w.Write([]byte("cpu value=1\n"))
+	}))
+	defer ts.Close()
+
+	parser, err := parsers.NewInfluxParser()
+	require.NoError(t, err)
+
+	h := &HTTP{
+		URLs:        []string{ts.URL},
+		Method:      "POST",
+		Body:        "select",
+		Headers:     map[string]string{"X-Special-Header": "value"},
+		BearerToken: "token",
+	}
+	h.SetParser(parser)
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(h.Gather))
+	assert.Equal(t, uint64(1), acc.NMetrics())
+}
+
+func TestHTTPBasicAuth(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "user" || pass != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("cpu value=1\n"))
+	}))
+	defer ts.Close()
+
+	parser, err := parsers.NewInfluxParser()
+	require.NoError(t, err)
+
+	h := &HTTP{URLs: []string{ts.URL}, Method: "GET"}
+	h.SetParser(parser)
+
+	var acc testutil.Accumulator
+	require.Error(t, acc.GatherError(h.Gather))
+	assert.Equal(t, uint64(0), acc.NMetrics())
+
+	h.Username = "user"
+	h.Password = "pass"
+	acc = testutil.Accumulator{}
+	require.NoError(t, acc.GatherError(h.Gather))
+	assert.Equal(t, uint64(1), acc.NMetrics())
+}
+
+func TestHTTPSuccessStatusCodes(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("cpu value=1\n"))
+	}))
+	defer ts.Close()
+
+	parser, err := parsers.NewInfluxParser()
+	require.NoError(t, err)
+
+	h := &HTTP{URLs: []string{ts.URL}, Method: "GET"}
+	h.SetParser(parser)
+
+	var acc testutil.Accumulator
+	require.Error(t, acc.GatherError(h.Gather))
+
+	h.SuccessStatusCodes = []int{200, 202}
+	acc = testutil.Accumulator{}
+	require.NoError(t, acc.GatherError(h.Gather))
+	assert.Equal(t, uint64(1), acc.NMetrics())
+}
+
+// serialParser fails if Parse is called concurrently.
+type serialParser struct {
+	parsers.Parser
+	active int32
+}
+
+func (p *serialParser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	if !atomic.CompareAndSwapInt32(&p.active, 0, 1) {
+		panic("concurrent Parse")
+	}
+	defer atomic.StoreInt32(&p.active, 0)
+	return p.Parser.Parse(buf)
+}
+
+func TestHTTPMultipleURLsParseSerially(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("cpu value=1\n"))
+	}))
+	defer ts.Close()
+
+	parser, err := parsers.NewInfluxParser()
+	require.NoError(t, err)
+
+	var urls []string
+	for i := 0; i < 10; i++ {
+		urls = append(urls, ts.URL+"/"+string('a'+rune(i)))
+	}
+	h := &HTTP{URLs: append(urls, "http://127.0.0.1:0/"), Method: "GET"}
+	h.SetParser(&serialParser{Parser: parser})
+
+	var acc testutil.Accumulator
+	require.NoError(t, h.Gather(&acc))
+	assert.Equal(t, uint64(10), acc.NMetrics())
+	assert.Len(t, acc.Errors, 1)
+	for _, url := range urls {
+		acc.AssertContainsTaggedFields(t, "cpu",
+			map[string]interface{}{"value": 1.0},
+			map[string]string{"url": url})
+	}
+}
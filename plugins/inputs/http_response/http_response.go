@@ -1,6 +1,7 @@
 package http_response
 
 import (
+	"context"
 	"errors"
 	"io"
 	"io/ioutil"
@@ -14,6 +15,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
@@ -27,14 +29,7 @@ type HTTPResponse struct {
 	FollowRedirects     bool
 	ResponseStringMatch string
 
-	// Path to CA file
-	SSLCA string `toml:"ssl_ca"`
-	// Path to host cert file
-	SSLCert string `toml:"ssl_cert"`
-	// Path to cert key file
-	SSLKey string `toml:"ssl_key"`
-	// Use SSL but skip chain & host verification
-	InsecureSkipVerify bool
+	tls.ClientConfig
 
 	compiledStringMatch *regexp.Regexp
 	client              *http.Client
@@ -68,11 +63,11 @@ var sampleConfig = `
   # response_string_match = "ok"
   # response_string_match = "\".*_status\".?:.?\"up\""
 
-  ## Optional SSL Config
-  # ssl_ca = "/etc/telegraf/ca.pem"
-  # ssl_cert = "/etc/telegraf/cert.pem"
-  # ssl_key = "/etc/telegraf/key.pem"
-  ## Use SSL but skip chain & host verification
+  ## Optional TLS Config
+  # tls_ca = "/etc/telegraf/ca.pem"
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+  ## Use TLS but skip chain & host verification
   # insecure_skip_verify = false
 
   ## HTTP Request Headers (all values must be strings)
@@ -91,8 +86,7 @@ var ErrRedirectAttempted = errors.New("redirect")
 // CreateHttpClient creates an http client which will timeout at the specified
 // timeout period and can follow redirects if specified
 func (h *HTTPResponse) createHttpClient() (*http.Client, error) {
-	tlsCfg, err := internal.GetTLSConfig(
-		h.SSLCert, h.SSLKey, h.SSLCA, h.InsecureSkipVerify)
+	tlsCfg, err := h.ClientConfig.TLSConfig()
 	if err != nil {
 		return nil, err
 	}
@@ -114,7 +108,7 @@ func (h *HTTPResponse) createHttpClient() (*http.Client, error) {
 }
 
 // HTTPGather gathers all fields and returns any errors it encounters
-func (h *HTTPResponse) httpGather() (map[string]interface{}, error) {
+func (h *HTTPResponse) httpGather(ctx context.Context) (map[string]interface{}, error) {
 	// Prepare fields
 	fields := make(map[string]interface{})
 
@@ -126,6 +120,7 @@ func (h *HTTPResponse) httpGather() (map[string]interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
+	request = request.WithContext(ctx)
 
 	for key, val := range h.Headers {
 		request.Header.Add(key, val)
@@ -139,6 +134,10 @@ func (h *HTTPResponse) httpGather() (map[string]interface{}, error) {
 	resp, err := h.client.Do(request)
 
 	if err != nil {
+		if ctx.Err() != nil {
+			// the gather was abandoned
+			return nil, ctx.Err()
+		}
 		if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
 			fields["result_type"] = "timeout"
 			return fields, nil
@@ -199,6 +198,11 @@ func (h *HTTPResponse) httpGather() (map[string]interface{}, error) {
 
 // Gather gets all metric fields and tags and returns any errors it encounters
 func (h *HTTPResponse) Gather(acc telegraf.Accumulator) error {
+	return h.GatherContext(context.Background(), acc)
+}
+
+// GatherContext is Gather, the request is cancelled when the context is done.
+func (h *HTTPResponse) GatherContext(ctx context.Context, acc telegraf.Accumulator) error {
 	// Set default values
 	if h.ResponseTimeout.Duration < time.Second {
 		h.ResponseTimeout.Duration = time.Second * 5
@@ -230,7 +234,7 @@ func (h *HTTPResponse) Gather(acc telegraf.Accumulator) error {
 	}
 
 	// Gather data
-	fields, err = h.httpGather()
+	fields, err = h.httpGather(ctx)
 	if err != nil {
 		return err
 	}
@@ -1,6 +1,7 @@
 package http_response
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -388,3 +389,22 @@ func TestTimeout(t *testing.T) {
 	_, ok = acc.FloatField("http_response", "response_time")
 	require.False(t, ok)
 }
+
+func TestContextDone(t *testing.T) {
+	mux := setUpTestMux()
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	h := &HTTPResponse{
+		Address:         ts.URL + "/twosecondnap",
+		Method:          "GET",
+		ResponseTimeout: internal.Duration{Duration: time.Second * 5},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	var acc testutil.Accumulator
+	err := h.GatherContext(ctx, &acc)
+	require.Error(t, err)
+	assert.Equal(t, 0, len(acc.Metrics))
+}
@@ -7,7 +7,7 @@ import (
 	"sync"
 
 	"github.com/influxdata/telegraf"
-	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers"
 
@@ -15,22 +15,21 @@ import (
 	cluster "github.com/bsm/sarama-cluster"
 )
 
+const defaultMaxUndeliveredMessages = 1000
+
 type Kafka struct {
 	ConsumerGroup string
 	Topics        []string
 	Brokers       []string
 	MaxMessageLen int
 
+	// MaxUndeliveredMessages is the number of messages that can be read
+	// before the outputs have written their metrics.
+	MaxUndeliveredMessages int `toml:"max_undelivered_messages"`
+
 	Cluster *cluster.Consumer
 
-	// Verify Kafka SSL Certificate
-	InsecureSkipVerify bool
-	// Path to CA file
-	SSLCA string `toml:"ssl_ca"`
-	// Path to host cert file
-	SSLCert string `toml:"ssl_cert"`
-	// Path to cert key file
-	SSLKey string `toml:"ssl_key"`
+	tls.ClientConfig
 
 	// SASL Username
 	SASLUsername string `toml:"sasl_username"`
@@ -54,24 +53,41 @@ type Kafka struct {
 	done chan struct{}
 
 	// keep the accumulator internally:
-	acc telegraf.Accumulator
+	acc telegraf.TrackingAccumulator
+
+	// undelivered maps the tracking ids of the messages to them until their
+	// metrics are delivered, and pending lists the messages of each partition
+	// in the order they were read. The offset of a message is committed once
+	// it and all the previous messages of its partition are delivered.
+	undelivered map[telegraf.TrackingID]*message
+	pending     map[topicPartition][]*message
 
 	// doNotCommitMsgs tells the parser not to call CommitUpTo on the consumer
 	// this is mostly for test purposes, but there may be a use-case for it later.
 	doNotCommitMsgs bool
 }
 
+type topicPartition struct {
+	topic     string
+	partition int32
+}
+
+type message struct {
+	msg       *sarama.ConsumerMessage
+	delivered bool
+}
+
 var sampleConfig = `
   ## kafka servers
   brokers = ["localhost:9092"]
   ## topic(s) to consume
   topics = ["telegraf"]
 
-  ## Optional SSL Config
-  # ssl_ca = "/etc/telegraf/ca.pem"
-  # ssl_cert = "/etc/telegraf/cert.pem"
-  # ssl_key = "/etc/telegraf/key.pem"
-  ## Use SSL but skip chain & host verification
+  ## Optional TLS Config
+  # tls_ca = "/etc/telegraf/ca.pem"
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+  ## Use TLS but skip chain & host verification
   # insecure_skip_verify = false
 
   ## Optional SASL Config
@@ -92,6 +108,12 @@ var sampleConfig = `
   ## Maximum length of a message to consume, in bytes (default 0/unlimited);
   ## larger messages are dropped
   max_message_len = 65536
+
+  ## Maximum number of messages read but not yet written by the outputs.
+  ## The offset of a message is only committed once its metrics are written,
+  ## and no more messages are read while this many are in flight. It should
+  ## allow enough metrics for a full metric_batch_size of the outputs.
+  # max_undelivered_messages = 1000
 `
 
 func (k *Kafka) SampleConfig() string {
@@ -111,13 +133,17 @@ func (k *Kafka) Start(acc telegraf.Accumulator) error {
 	defer k.Unlock()
 	var clusterErr error
 
-	k.acc = acc
+	if k.MaxUndeliveredMessages <= 0 {
+		k.MaxUndeliveredMessages = defaultMaxUndeliveredMessages
+	}
+	k.acc = acc.WithTracking(k.MaxUndeliveredMessages)
+	k.undelivered = make(map[telegraf.TrackingID]*message)
+	k.pending = make(map[topicPartition][]*message)
 
 	config := cluster.NewConfig()
 	config.Consumer.Return.Errors = true
 
-	tlsConfig, err := internal.GetTLSConfig(
-		k.SSLCert, k.SSLKey, k.SSLCA, k.InsecureSkipVerify)
+	tlsConfig, err := k.ClientConfig.TLSConfig()
 	if err != nil {
 		return err
 	}
@@ -177,6 +203,12 @@ func (k *Kafka) Start(acc telegraf.Accumulator) error {
 // influxdb metric points.
 func (k *Kafka) receiver() {
 	for {
+		in := k.in
+		if len(k.undelivered) >= k.MaxUndeliveredMessages {
+			// wait for messages to be delivered before reading more
+			in = nil
+		}
+
 		select {
 		case <-k.done:
 			return
@@ -184,30 +216,74 @@ func (k *Kafka) receiver() {
 			if err != nil {
 				k.acc.AddError(fmt.Errorf("Consumer Error: %s\n", err))
 			}
-		case msg := <-k.in:
-			if k.MaxMessageLen != 0 && len(msg.Value) > k.MaxMessageLen {
-				k.acc.AddError(fmt.Errorf("Message longer than max_message_len (%d > %d)",
-					len(msg.Value), k.MaxMessageLen))
-			} else {
-				metrics, err := k.parser.Parse(msg.Value)
-				if err != nil {
-					k.acc.AddError(fmt.Errorf("Message Parse Error\nmessage: %s\nerror: %s",
-						string(msg.Value), err.Error()))
-				}
-				for _, metric := range metrics {
-					k.acc.AddFields(metric.Name(), metric.Fields(), metric.Tags(), metric.Time())
-				}
-			}
+		case info := <-k.acc.Delivered():
+			k.onDelivery(info)
+		case msg := <-in:
+			k.onMessage(msg)
+		}
+	}
+}
 
-			if !k.doNotCommitMsgs {
-				// TODO(cam) this locking can be removed if this PR gets merged:
-				// https://github.com/wvanbergen/kafka/pull/84
-				k.Lock()
-				k.Cluster.MarkOffset(msg, "")
-				k.Unlock()
-			}
+// onMessage adds the metrics of a message as a tracked group. A message
+// without valid metrics is tracked too, so that its offset is committed in
+// order.
+func (k *Kafka) onMessage(msg *sarama.ConsumerMessage) {
+	var metrics []telegraf.Metric
+	if k.MaxMessageLen != 0 && len(msg.Value) > k.MaxMessageLen {
+		k.acc.AddError(fmt.Errorf("Message longer than max_message_len (%d > %d)",
+			len(msg.Value), k.MaxMessageLen))
+	} else {
+		var err error
+		metrics, err = k.parser.Parse(msg.Value)
+		if err != nil {
+			k.acc.AddError(fmt.Errorf("Message Parse Error\nmessage: %s\nerror: %s",
+				string(msg.Value), err.Error()))
 		}
 	}
+
+	m := &message{msg: msg}
+	tp := topicPartition{topic: msg.Topic, partition: msg.Partition}
+	k.pending[tp] = append(k.pending[tp], m)
+	// the delivery is received by this goroutine, after the message is
+	// registered, even if it is already sent.
+	id := k.acc.AddTrackingMetricGroup(metrics)
+	k.undelivered[id] = m
+}
+
+// onDelivery commits the offset of the delivered messages that no longer
+// follow an undelivered message of their partition.
+func (k *Kafka) onDelivery(info telegraf.DeliveryInfo) {
+	m, ok := k.undelivered[info.ID()]
+	if !ok {
+		return
+	}
+	delete(k.undelivered, info.ID())
+	m.delivered = true
+	if !info.Delivered() {
+		log.Printf("W! Kafka message at offset %d of %s/%d was not delivered "+
+			"to all outputs", m.msg.Offset, m.msg.Topic, m.msg.Partition)
+	}
+
+	tp := topicPartition{topic: m.msg.Topic, partition: m.msg.Partition}
+	queue := k.pending[tp]
+	var last *sarama.ConsumerMessage
+	for len(queue) > 0 && queue[0].delivered {
+		last = queue[0].msg
+		queue = queue[1:]
+	}
+	if len(queue) == 0 {
+		delete(k.pending, tp)
+	} else {
+		k.pending[tp] = queue
+	}
+
+	if last != nil && !k.doNotCommitMsgs {
+		// TODO(cam) this locking can be removed if this PR gets merged:
+		// https://github.com/wvanbergen/kafka/pull/84
+		k.Lock()
+		k.Cluster.MarkOffset(last, "")
+		k.Unlock()
+	}
 }
 
 func (k *Kafka) Stop() {
@@ -225,6 +301,8 @@ func (k *Kafka) Gather(acc telegraf.Accumulator) error {
 
 func init() {
 	inputs.Add("kafka_consumer", func() telegraf.Input {
-		return &Kafka{}
+		return &Kafka{
+			MaxUndeliveredMessages: defaultMaxUndeliveredMessages,
+		}
 	})
 }
@@ -4,11 +4,13 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/plugins/parsers"
 	"github.com/influxdata/telegraf/testutil"
 
 	"github.com/Shopify/sarama"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -29,6 +31,10 @@ func newTestKafka() (*Kafka, chan *sarama.ConsumerMessage) {
 		doNotCommitMsgs: true,
 		errs:            make(chan error, 1000),
 		done:            make(chan struct{}),
+
+		MaxUndeliveredMessages: defaultMaxUndeliveredMessages,
+		undelivered:            make(map[telegraf.TrackingID]*message),
+		pending:                make(map[topicPartition][]*message),
 	}
 	return &k, in
 }
@@ -140,6 +146,45 @@ func TestRunParserAndGatherJSON(t *testing.T) {
 		})
 }
 
+type deliveryInfo struct {
+	id        telegraf.TrackingID
+	delivered bool
+}
+
+func (d *deliveryInfo) ID() telegraf.TrackingID { return d.id }
+func (d *deliveryInfo) Delivered() bool         { return d.delivered }
+
+// Test that messages are only done once the previous messages of their
+// partition are delivered
+func TestDeliveryInOrder(t *testing.T) {
+	k, _ := newTestKafka()
+	// the metrics are never delivered by this accumulator
+	acc := testutil.Accumulator{}
+	k.acc = &acc
+	k.parser, _ = parsers.NewInfluxParser()
+
+	ids := make(map[int64]telegraf.TrackingID)
+	for offset := int64(0); offset < 3; offset++ {
+		msg := saramaMsg(testMsg)
+		msg.Offset = offset
+		k.onMessage(msg)
+	}
+	for id, m := range k.undelivered {
+		ids[m.msg.Offset] = id
+	}
+	require.Len(t, ids, 3)
+	tp := topicPartition{partition: 0}
+
+	k.onDelivery(&deliveryInfo{id: ids[1], delivered: true})
+	assert.Len(t, k.pending[tp], 3)
+	k.onDelivery(&deliveryInfo{id: ids[0], delivered: true})
+	require.Len(t, k.pending[tp], 1)
+	assert.Equal(t, int64(2), k.pending[tp][0].msg.Offset)
+	k.onDelivery(&deliveryInfo{id: ids[2], delivered: false})
+	assert.Empty(t, k.pending)
+	assert.Empty(t, k.undelivered)
+}
+
 func saramaMsg(val string) *sarama.ConsumerMessage {
 	return &sarama.ConsumerMessage{
 		Key:       nil,
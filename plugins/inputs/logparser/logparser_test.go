@@ -3,21 +3,19 @@ package logparser
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"runtime"
-	"strings"
 	"testing"
 
 	"github.com/influxdata/telegraf/testutil"
 
-	"github.com/influxdata/telegraf/plugins/inputs/logparser/grok"
-
 	"github.com/stretchr/testify/assert"
 )
 
 func TestStartNoParsers(t *testing.T) {
 	logparser := &LogParserPlugin{
 		FromBeginning: true,
-		Files:         []string{"grok/testdata/*.log"},
+		Files:         []string{"../../parsers/grok/testdata/*.log"},
 	}
 
 	acc := testutil.Accumulator{}
@@ -25,16 +23,16 @@ func TestStartNoParsers(t *testing.T) {
 }
 
 func TestGrokParseLogFilesNonExistPattern(t *testing.T) {
-	thisdir := getCurrentDir()
-	p := &grok.Parser{
+	testdata := getTestdataDir()
+	p := GrokConfig{
 		Patterns:           []string{"%{FOOBAR}"},
-		CustomPatternFiles: []string{thisdir + "grok/testdata/test-patterns"},
+		CustomPatternFiles: []string{testdata + "/test-patterns"},
 	}
 
 	logparser := &LogParserPlugin{
 		FromBeginning: true,
-		Files:         []string{thisdir + "grok/testdata/*.log"},
-		GrokParser:    p,
+		Files:         []string{testdata + "/*.log"},
+		GrokConfig:    p,
 	}
 
 	acc := testutil.Accumulator{}
@@ -43,16 +41,16 @@ func TestGrokParseLogFilesNonExistPattern(t *testing.T) {
 }
 
 func TestGrokParseLogFiles(t *testing.T) {
-	thisdir := getCurrentDir()
-	p := &grok.Parser{
+	testdata := getTestdataDir()
+	p := GrokConfig{
 		Patterns:           []string{"%{TEST_LOG_A}", "%{TEST_LOG_B}"},
-		CustomPatternFiles: []string{thisdir + "grok/testdata/test-patterns"},
+		CustomPatternFiles: []string{testdata + "/test-patterns"},
 	}
 
 	logparser := &LogParserPlugin{
 		FromBeginning: true,
-		Files:         []string{thisdir + "grok/testdata/*.log"},
-		GrokParser:    p,
+		Files:         []string{testdata + "/*.log"},
+		GrokConfig:    p,
 	}
 
 	acc := testutil.Accumulator{}
@@ -71,7 +69,7 @@ func TestGrokParseLogFiles(t *testing.T) {
 		},
 		map[string]string{
 			"response_code": "200",
-			"path":          thisdir + "grok/testdata/test_a.log",
+			"path":          testdata + "/test_a.log",
 		})
 
 	acc.AssertContainsTaggedFields(t, "logparser_grok",
@@ -81,7 +79,7 @@ func TestGrokParseLogFiles(t *testing.T) {
 			"nomodifier": "nomodifier",
 		},
 		map[string]string{
-			"path": thisdir + "grok/testdata/test_b.log",
+			"path": testdata + "/test_b.log",
 		})
 }
 
@@ -90,16 +88,16 @@ func TestGrokParseLogFilesAppearLater(t *testing.T) {
 	defer os.RemoveAll(emptydir)
 	assert.NoError(t, err)
 
-	thisdir := getCurrentDir()
-	p := &grok.Parser{
+	testdata := getTestdataDir()
+	p := GrokConfig{
 		Patterns:           []string{"%{TEST_LOG_A}", "%{TEST_LOG_B}"},
-		CustomPatternFiles: []string{thisdir + "grok/testdata/test-patterns"},
+		CustomPatternFiles: []string{testdata + "/test-patterns"},
 	}
 
 	logparser := &LogParserPlugin{
 		FromBeginning: true,
 		Files:         []string{emptydir + "/*.log"},
-		GrokParser:    p,
+		GrokConfig:    p,
 	}
 
 	acc := testutil.Accumulator{}
@@ -107,7 +105,7 @@ func TestGrokParseLogFilesAppearLater(t *testing.T) {
 
 	assert.Equal(t, acc.NFields(), 0)
 
-	_ = os.Symlink(thisdir+"grok/testdata/test_a.log", emptydir+"/test_a.log")
+	_ = os.Symlink(testdata+"/test_a.log", emptydir+"/test_a.log")
 	assert.NoError(t, acc.GatherError(logparser.Gather))
 	acc.Wait(1)
 
@@ -129,17 +127,16 @@ func TestGrokParseLogFilesAppearLater(t *testing.T) {
 // Test that test_a.log line gets parsed even though we don't have the correct
 // pattern available for test_b.log
 func TestGrokParseLogFilesOneBad(t *testing.T) {
-	thisdir := getCurrentDir()
-	p := &grok.Parser{
+	testdata := getTestdataDir()
+	p := GrokConfig{
 		Patterns:           []string{"%{TEST_LOG_A}", "%{TEST_LOG_BAD}"},
-		CustomPatternFiles: []string{thisdir + "grok/testdata/test-patterns"},
+		CustomPatternFiles: []string{testdata + "/test-patterns"},
 	}
-	assert.NoError(t, p.Compile())
 
 	logparser := &LogParserPlugin{
 		FromBeginning: true,
-		Files:         []string{thisdir + "grok/testdata/test_a.log"},
-		GrokParser:    p,
+		Files:         []string{testdata + "/test_a.log"},
+		GrokConfig:    p,
 	}
 
 	acc := testutil.Accumulator{}
@@ -158,11 +155,12 @@ func TestGrokParseLogFilesOneBad(t *testing.T) {
 		},
 		map[string]string{
 			"response_code": "200",
-			"path":          thisdir + "grok/testdata/test_a.log",
+			"path":          testdata + "/test_a.log",
 		})
 }
 
-func getCurrentDir() string {
-	_, filename, _, _ := runtime.Caller(1)
-	return strings.Replace(filename, "logparser_test.go", "", 1)
+// getTestdataDir returns the testdata directory of the grok parser.
+func getTestdataDir() string {
+	_, filename, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(filename), "..", "..", "parsers", "grok", "testdata")
 }
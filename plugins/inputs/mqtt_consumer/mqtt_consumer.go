@@ -9,6 +9,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers"
 
@@ -18,6 +19,8 @@ import (
 // 30 Seconds is the default used by paho.mqtt.golang
 var defaultConnectionTimeout = internal.Duration{Duration: 30 * time.Second}
 
+const defaultMaxUndeliveredMessages = 1000
+
 type MQTTConsumer struct {
 	Servers           []string
 	Topics            []string
@@ -26,6 +29,10 @@ type MQTTConsumer struct {
 	QoS               int               `toml:"qos"`
 	ConnectionTimeout internal.Duration `toml:"connection_timeout"`
 
+	// MaxUndeliveredMessages is the number of messages that can be read
+	// before the outputs have written their metrics.
+	MaxUndeliveredMessages int `toml:"max_undelivered_messages"`
+
 	parser parsers.Parser
 
 	// Legacy metric buffer support
@@ -34,14 +41,7 @@ type MQTTConsumer struct {
 	PersistentSession bool
 	ClientID          string `toml:"client_id"`
 
-	// Path to CA file
-	SSLCA string `toml:"ssl_ca"`
-	// Path to host cert file
-	SSLCert string `toml:"ssl_cert"`
-	// Path to cert key file
-	SSLKey string `toml:"ssl_key"`
-	// Use SSL but skip chain & host verification
-	InsecureSkipVerify bool
+	tls.ClientConfig
 
 	sync.Mutex
 	client mqtt.Client
@@ -50,7 +50,11 @@ type MQTTConsumer struct {
 	done chan struct{}
 
 	// keep the accumulator internally:
-	acc telegraf.Accumulator
+	acc telegraf.TrackingAccumulator
+
+	// undelivered holds the tracking ids of the messages whose metrics are
+	// not yet delivered.
+	undelivered map[telegraf.TrackingID]bool
 
 	connected bool
 }
@@ -65,6 +69,11 @@ var sampleConfig = `
   ## Connection timeout for initial connection in seconds
   connection_timeout = "30s"
 
+  ## Maximum number of messages read but not yet written by the outputs. No
+  ## more messages are read while this many are in flight, messages are still
+  ## acknowledged to the broker as soon as they are received.
+  # max_undelivered_messages = 1000
+
   ## Topics to subscribe to
   topics = [
     "telegraf/host01/cpu",
@@ -83,11 +92,11 @@ var sampleConfig = `
   # username = "telegraf"
   # password = "metricsmetricsmetricsmetrics"
 
-  ## Optional SSL Config
-  # ssl_ca = "/etc/telegraf/ca.pem"
-  # ssl_cert = "/etc/telegraf/cert.pem"
-  # ssl_key = "/etc/telegraf/key.pem"
-  ## Use SSL but skip chain & host verification
+  ## Optional TLS Config
+  # tls_ca = "/etc/telegraf/ca.pem"
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+  ## Use TLS but skip chain & host verification
   # insecure_skip_verify = false
 
   ## Data format to consume.
@@ -119,7 +128,11 @@ func (m *MQTTConsumer) Start(acc telegraf.Accumulator) error {
 			" = true, you MUST also set client_id")
 	}
 
-	m.acc = acc
+	if m.MaxUndeliveredMessages <= 0 {
+		m.MaxUndeliveredMessages = defaultMaxUndeliveredMessages
+	}
+	m.acc = acc.WithTracking(m.MaxUndeliveredMessages)
+	m.undelivered = make(map[telegraf.TrackingID]bool)
 	if m.QoS > 2 || m.QoS < 0 {
 		return fmt.Errorf("MQTT Consumer, invalid QoS value: %d", m.QoS)
 	}
@@ -179,13 +192,22 @@ func (m *MQTTConsumer) onConnectionLost(c mqtt.Client, err error) {
 }
 
 // receiver() reads all incoming messages from the consumer, and parses them into
-// influxdb metric points.
+// influxdb metric points. The client acknowledges the messages once received,
+// their delivery is tracked only to bound the messages in flight.
 func (m *MQTTConsumer) receiver() {
 	for {
+		in := m.in
+		if len(m.undelivered) >= m.MaxUndeliveredMessages {
+			// wait for messages to be delivered before reading more
+			in = nil
+		}
+
 		select {
 		case <-m.done:
 			return
-		case msg := <-m.in:
+		case info := <-m.acc.Delivered():
+			delete(m.undelivered, info.ID())
+		case msg := <-in:
 			topic := msg.Topic()
 			metrics, err := m.parser.Parse(msg.Payload())
 			if err != nil {
@@ -194,10 +216,10 @@ func (m *MQTTConsumer) receiver() {
 			}
 
 			for _, metric := range metrics {
-				tags := metric.Tags()
-				tags["topic"] = topic
-				m.acc.AddFields(metric.Name(), metric.Fields(), tags, metric.Time())
+				metric.AddTag("topic", topic)
 			}
+			id := m.acc.AddTrackingMetricGroup(metrics)
+			m.undelivered[id] = true
 		}
 	}
 }
@@ -236,8 +258,7 @@ func (m *MQTTConsumer) createOpts() (*mqtt.ClientOptions, error) {
 		opts.SetClientID(m.ClientID)
 	}
 
-	tlsCfg, err := internal.GetTLSConfig(
-		m.SSLCert, m.SSLKey, m.SSLCA, m.InsecureSkipVerify)
+	tlsCfg, err := m.ClientConfig.TLSConfig()
 	if err != nil {
 		return nil, err
 	}
@@ -284,7 +305,8 @@ func (m *MQTTConsumer) createOpts() (*mqtt.ClientOptions, error) {
 func init() {
 	inputs.Add("mqtt_consumer", func() telegraf.Input {
 		return &MQTTConsumer{
-			ConnectionTimeout: defaultConnectionTimeout,
+			ConnectionTimeout:      defaultConnectionTimeout,
+			MaxUndeliveredMessages: defaultMaxUndeliveredMessages,
 		}
 	})
 }
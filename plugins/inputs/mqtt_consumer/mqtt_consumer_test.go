@@ -3,6 +3,7 @@ package mqtt_consumer
 import (
 	"testing"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/plugins/parsers"
 	"github.com/influxdata/telegraf/testutil"
 
@@ -27,6 +28,9 @@ func newTestMQTTConsumer() (*MQTTConsumer, chan mqtt.Message) {
 		in:        in,
 		done:      make(chan struct{}),
 		connected: true,
+
+		MaxUndeliveredMessages: defaultMaxUndeliveredMessages,
+		undelivered:            make(map[telegraf.TrackingID]bool),
 	}
 
 	return n, in
@@ -110,6 +114,24 @@ func TestRunParserNegativeNumber(t *testing.T) {
 	}
 }
 
+// Test that messages are read again once the previous ones are delivered
+func TestRunParserUndeliveredLimit(t *testing.T) {
+	n, in := newTestMQTTConsumer()
+	n.MaxUndeliveredMessages = 1
+	acc := testutil.Accumulator{}
+	n.acc = acc.WithTracking(n.MaxUndeliveredMessages)
+	defer close(n.done)
+
+	n.parser, _ = parsers.NewInfluxParser()
+	go n.receiver()
+	for i := 0; i < 3; i++ {
+		in <- mqttMsg(testMsg)
+	}
+	acc.Wait(3)
+
+	assert.Equal(t, "telegraf/unit_test", acc.Metrics[0].Tags["topic"])
+}
+
 // Test that the parser ignores invalid messages
 func TestRunParserInvalidMsg(t *testing.T) {
 	n, in := newTestMQTTConsumer()
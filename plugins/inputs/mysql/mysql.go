@@ -2,6 +2,7 @@ package mysql
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
@@ -11,7 +12,7 @@ import (
 	"time"
 
 	"github.com/influxdata/telegraf"
-	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 
 	"github.com/go-sql-driver/mysql"
@@ -37,9 +38,7 @@ type Mysql struct {
 	GatherFileEventsStats               bool     `toml:"gather_file_events_stats"`
 	GatherPerfEventsStatements          bool     `toml:"gather_perf_events_statements"`
 	IntervalSlow                        string   `toml:"interval_slow"`
-	SSLCA                               string   `toml:"ssl_ca"`
-	SSLCert                             string   `toml:"ssl_cert"`
-	SSLKey                              string   `toml:"ssl_key"`
+	tls.ClientConfig
 }
 
 var sampleConfig = `
@@ -102,10 +101,12 @@ var sampleConfig = `
   ## Some queries we may want to run less often (such as SHOW GLOBAL VARIABLES)
   interval_slow                   = "30m"
 
-  ## Optional SSL Config (will be used if tls=custom parameter specified in server uri)
-  ssl_ca = "/etc/telegraf/ca.pem"
-  ssl_cert = "/etc/telegraf/cert.pem"
-  ssl_key = "/etc/telegraf/key.pem"
+  ## Optional TLS Config (will be used if tls=custom parameter specified in server uri)
+  # tls_ca = "/etc/telegraf/ca.pem"
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+  ## Use TLS but skip chain & host verification
+  # insecure_skip_verify = false
 `
 
 var defaultTimeout = time.Second * time.Duration(5)
@@ -136,16 +137,21 @@ func (m *Mysql) InitMysql() {
 }
 
 func (m *Mysql) Gather(acc telegraf.Accumulator) error {
+	return m.GatherContext(context.Background(), acc)
+}
+
+// GatherContext is Gather, the queries are cancelled when the context is done.
+func (m *Mysql) GatherContext(ctx context.Context, acc telegraf.Accumulator) error {
 	if len(m.Servers) == 0 {
 		// default to localhost if nothing specified.
-		return m.gatherServer(localhost, acc)
+		return m.gatherServer(ctx, localhost, acc)
 	}
 	// Initialise additional query intervals
 	if !initDone {
 		m.InitMysql()
 	}
 
-	tlsConfig, err := internal.GetTLSConfig(m.SSLCert, m.SSLKey, m.SSLCA, false)
+	tlsConfig, err := m.ClientConfig.TLSConfig()
 	if err != nil {
 		log.Printf("E! MySQL Error registering TLS config: %s", err)
 	}
@@ -161,7 +167,7 @@ func (m *Mysql) Gather(acc telegraf.Accumulator) error {
 		wg.Add(1)
 		go func(s string) {
 			defer wg.Done()
-			acc.AddError(m.gatherServer(s, acc))
+			acc.AddError(m.gatherServer(ctx, s, acc))
 		}(server)
 	}
 
@@ -187,10 +193,10 @@ var (
 		"deleting":                  uint32(0),
 		"executing":                 uint32(0),
 		"execution of init_command": uint32(0),
-		"end":                     uint32(0),
-		"freeing items":           uint32(0),
-		"flushing tables":         uint32(0),
-		"fulltext initialization": uint32(0),
+		"end":                       uint32(0),
+		"freeing items":             uint32(0),
+		"flushing tables":           uint32(0),
+		"fulltext initialization":   uint32(0),
 		"idle":                      uint32(0),
 		"init":                      uint32(0),
 		"killed":                    uint32(0),
@@ -226,8 +232,8 @@ var (
 	}
 	// plaintext statuses
 	stateStatusMappings = map[string]string{
-		"user sleep":                               "idle",
-		"creating index":                           "altering table",
+		"user sleep":     "idle",
+		"creating index": "altering table",
 		"committing alter table to storage engine": "altering table",
 		"discard or import tablespace":             "altering table",
 		"rename":                                   "altering table",
@@ -392,7 +398,7 @@ const (
 	`
 )
 
-func (m *Mysql) gatherServer(serv string, acc telegraf.Accumulator) error {
+func (m *Mysql) gatherServer(ctx context.Context, serv string, acc telegraf.Accumulator) error {
 	serv, err := dsnAddTimeout(serv)
 	if err != nil {
 		return err
@@ -405,7 +411,7 @@ func (m *Mysql) gatherServer(serv string, acc telegraf.Accumulator) error {
 
 	defer db.Close()
 
-	err = m.gatherGlobalStatuses(db, serv, acc)
+	err = m.gatherGlobalStatuses(ctx, db, serv, acc)
 	if err != nil {
 		return err
 	}
@@ -413,7 +419,7 @@ func (m *Mysql) gatherServer(serv string, acc telegraf.Accumulator) error {
 	// Global Variables may be gathered less often
 	if len(m.IntervalSlow) > 0 {
 		if uint32(time.Since(lastT).Seconds()) >= scanIntervalSlow {
-			err = m.gatherGlobalVariables(db, serv, acc)
+			err = m.gatherGlobalVariables(ctx, db, serv, acc)
 			if err != nil {
 				return err
 			}
@@ -422,91 +428,91 @@ func (m *Mysql) gatherServer(serv string, acc telegraf.Accumulator) error {
 	}
 
 	if m.GatherBinaryLogs {
-		err = m.gatherBinaryLogs(db, serv, acc)
+		err = m.gatherBinaryLogs(ctx, db, serv, acc)
 		if err != nil {
 			return err
 		}
 	}
 
 	if m.GatherProcessList {
-		err = m.GatherProcessListStatuses(db, serv, acc)
+		err = m.GatherProcessListStatuses(ctx, db, serv, acc)
 		if err != nil {
 			return err
 		}
 	}
 
 	if m.GatherUserStatistics {
-		err = m.GatherUserStatisticsStatuses(db, serv, acc)
+		err = m.GatherUserStatisticsStatuses(ctx, db, serv, acc)
 		if err != nil {
 			return err
 		}
 	}
 
 	if m.GatherSlaveStatus {
-		err = m.gatherSlaveStatuses(db, serv, acc)
+		err = m.gatherSlaveStatuses(ctx, db, serv, acc)
 		if err != nil {
 			return err
 		}
 	}
 
 	if m.GatherInfoSchemaAutoInc {
-		err = m.gatherInfoSchemaAutoIncStatuses(db, serv, acc)
+		err = m.gatherInfoSchemaAutoIncStatuses(ctx, db, serv, acc)
 		if err != nil {
 			return err
 		}
 	}
 
 	if m.GatherInnoDBMetrics {
-		err = m.gatherInnoDBMetrics(db, serv, acc)
+		err = m.gatherInnoDBMetrics(ctx, db, serv, acc)
 		if err != nil {
 			return err
 		}
 	}
 
 	if m.GatherTableIOWaits {
-		err = m.gatherPerfTableIOWaits(db, serv, acc)
+		err = m.gatherPerfTableIOWaits(ctx, db, serv, acc)
 		if err != nil {
 			return err
 		}
 	}
 
 	if m.GatherIndexIOWaits {
-		err = m.gatherPerfIndexIOWaits(db, serv, acc)
+		err = m.gatherPerfIndexIOWaits(ctx, db, serv, acc)
 		if err != nil {
 			return err
 		}
 	}
 
 	if m.GatherTableLockWaits {
-		err = m.gatherPerfTableLockWaits(db, serv, acc)
+		err = m.gatherPerfTableLockWaits(ctx, db, serv, acc)
 		if err != nil {
 			return err
 		}
 	}
 
 	if m.GatherEventWaits {
-		err = m.gatherPerfEventWaits(db, serv, acc)
+		err = m.gatherPerfEventWaits(ctx, db, serv, acc)
 		if err != nil {
 			return err
 		}
 	}
 
 	if m.GatherFileEventsStats {
-		err = m.gatherPerfFileEventsStatuses(db, serv, acc)
+		err = m.gatherPerfFileEventsStatuses(ctx, db, serv, acc)
 		if err != nil {
 			return err
 		}
 	}
 
 	if m.GatherPerfEventsStatements {
-		err = m.gatherPerfEventsStatements(db, serv, acc)
+		err = m.gatherPerfEventsStatements(ctx, db, serv, acc)
 		if err != nil {
 			return err
 		}
 	}
 
 	if m.GatherTableSchema {
-		err = m.gatherTableSchema(db, serv, acc)
+		err = m.gatherTableSchema(ctx, db, serv, acc)
 		if err != nil {
 			return err
 		}
@@ -516,9 +522,9 @@ func (m *Mysql) gatherServer(serv string, acc telegraf.Accumulator) error {
 
 // gatherGlobalVariables can be used to fetch all global variables from
 // MySQL environment.
-func (m *Mysql) gatherGlobalVariables(db *sql.DB, serv string, acc telegraf.Accumulator) error {
+func (m *Mysql) gatherGlobalVariables(ctx context.Context, db *sql.DB, serv string, acc telegraf.Accumulator) error {
 	// run query
-	rows, err := db.Query(globalVariablesQuery)
+	rows, err := db.QueryContext(ctx, globalVariablesQuery)
 	if err != nil {
 		return err
 	}
@@ -561,9 +567,9 @@ func (m *Mysql) gatherGlobalVariables(db *sql.DB, serv string, acc telegraf.Accu
 // When the server is slave, then it returns only one row.
 // If the multi-source replication is set, then everything works differently
 // This code does not work with multi-source replication.
-func (m *Mysql) gatherSlaveStatuses(db *sql.DB, serv string, acc telegraf.Accumulator) error {
+func (m *Mysql) gatherSlaveStatuses(ctx context.Context, db *sql.DB, serv string, acc telegraf.Accumulator) error {
 	// run query
-	rows, err := db.Query(slaveStatusQuery)
+	rows, err := db.QueryContext(ctx, slaveStatusQuery)
 	if err != nil {
 		return err
 	}
@@ -605,9 +611,9 @@ func (m *Mysql) gatherSlaveStatuses(db *sql.DB, serv string, acc telegraf.Accumu
 
 // gatherBinaryLogs can be used to collect size and count of all binary files
 // binlogs metric requires the MySQL server to turn it on in configuration
-func (m *Mysql) gatherBinaryLogs(db *sql.DB, serv string, acc telegraf.Accumulator) error {
+func (m *Mysql) gatherBinaryLogs(ctx context.Context, db *sql.DB, serv string, acc telegraf.Accumulator) error {
 	// run query
-	rows, err := db.Query(binaryLogsQuery)
+	rows, err := db.QueryContext(ctx, binaryLogsQuery)
 	if err != nil {
 		return err
 	}
@@ -642,9 +648,9 @@ func (m *Mysql) gatherBinaryLogs(db *sql.DB, serv string, acc telegraf.Accumulat
 // gatherGlobalStatuses can be used to get MySQL status metrics
 // the mappings of actual names and names of each status to be exported
 // to output is provided on mappings variable
-func (m *Mysql) gatherGlobalStatuses(db *sql.DB, serv string, acc telegraf.Accumulator) error {
+func (m *Mysql) gatherGlobalStatuses(ctx context.Context, db *sql.DB, serv string, acc telegraf.Accumulator) error {
 	// run query
-	rows, err := db.Query(globalStatusQuery)
+	rows, err := db.QueryContext(ctx, globalStatusQuery)
 	if err != nil {
 		return err
 	}
@@ -680,7 +686,7 @@ func (m *Mysql) gatherGlobalStatuses(db *sql.DB, serv string, acc telegraf.Accum
 	}
 	// gather connection metrics from processlist for each user
 	if m.GatherProcessList {
-		conn_rows, err := db.Query("SELECT user, sum(1) FROM INFORMATION_SCHEMA.PROCESSLIST GROUP BY user")
+		conn_rows, err := db.QueryContext(ctx, "SELECT user, sum(1) FROM INFORMATION_SCHEMA.PROCESSLIST GROUP BY user")
 		if err != nil {
 			log.Printf("E! MySQL Error gathering process list: %s", err)
 		} else {
@@ -707,7 +713,7 @@ func (m *Mysql) gatherGlobalStatuses(db *sql.DB, serv string, acc telegraf.Accum
 
 	// gather connection metrics from user_statistics for each user
 	if m.GatherUserStatistics {
-		conn_rows, err := db.Query("select user, total_connections, concurrent_connections, connected_time, busy_time, cpu_time, bytes_received, bytes_sent, binlog_bytes_written, rows_fetched, rows_updated, table_rows_read, select_commands, update_commands, other_commands, commit_transactions, rollback_transactions, denied_connections, lost_connections, access_denied, empty_queries, total_ssl_connections FROM INFORMATION_SCHEMA.USER_STATISTICS GROUP BY user")
+		conn_rows, err := db.QueryContext(ctx, "select user, total_connections, concurrent_connections, connected_time, busy_time, cpu_time, bytes_received, bytes_sent, binlog_bytes_written, rows_fetched, rows_updated, table_rows_read, select_commands, update_commands, other_commands, commit_transactions, rollback_transactions, denied_connections, lost_connections, access_denied, empty_queries, total_ssl_connections FROM INFORMATION_SCHEMA.USER_STATISTICS GROUP BY user")
 		if err != nil {
 			log.Printf("E! MySQL Error gathering user stats: %s", err)
 		} else {
@@ -781,9 +787,9 @@ func (m *Mysql) gatherGlobalStatuses(db *sql.DB, serv string, acc telegraf.Accum
 
 // GatherProcessList can be used to collect metrics on each running command
 // and its state with its running count
-func (m *Mysql) GatherProcessListStatuses(db *sql.DB, serv string, acc telegraf.Accumulator) error {
+func (m *Mysql) GatherProcessListStatuses(ctx context.Context, db *sql.DB, serv string, acc telegraf.Accumulator) error {
 	// run query
-	rows, err := db.Query(infoSchemaProcessListQuery)
+	rows, err := db.QueryContext(ctx, infoSchemaProcessListQuery)
 	if err != nil {
 		return err
 	}
@@ -826,9 +832,9 @@ func (m *Mysql) GatherProcessListStatuses(db *sql.DB, serv string, acc telegraf.
 
 // GatherUserStatistics can be used to collect metrics on each running command
 // and its state with its running count
-func (m *Mysql) GatherUserStatisticsStatuses(db *sql.DB, serv string, acc telegraf.Accumulator) error {
+func (m *Mysql) GatherUserStatisticsStatuses(ctx context.Context, db *sql.DB, serv string, acc telegraf.Accumulator) error {
 	// run query
-	rows, err := db.Query(infoSchemaUserStatisticsQuery)
+	rows, err := db.QueryContext(ctx, infoSchemaUserStatisticsQuery)
 	if err != nil {
 		return err
 	}
@@ -903,8 +909,8 @@ func (m *Mysql) GatherUserStatisticsStatuses(db *sql.DB, serv string, acc telegr
 
 // gatherPerfTableIOWaits can be used to get total count and time
 // of I/O wait event for each table and process
-func (m *Mysql) gatherPerfTableIOWaits(db *sql.DB, serv string, acc telegraf.Accumulator) error {
-	rows, err := db.Query(perfTableIOWaitsQuery)
+func (m *Mysql) gatherPerfTableIOWaits(ctx context.Context, db *sql.DB, serv string, acc telegraf.Accumulator) error {
+	rows, err := db.QueryContext(ctx, perfTableIOWaitsQuery)
 	if err != nil {
 		return err
 	}
@@ -952,8 +958,8 @@ func (m *Mysql) gatherPerfTableIOWaits(db *sql.DB, serv string, acc telegraf.Acc
 
 // gatherPerfIndexIOWaits can be used to get total count and time
 // of I/O wait event for each index and process
-func (m *Mysql) gatherPerfIndexIOWaits(db *sql.DB, serv string, acc telegraf.Accumulator) error {
-	rows, err := db.Query(perfIndexIOWaitsQuery)
+func (m *Mysql) gatherPerfIndexIOWaits(ctx context.Context, db *sql.DB, serv string, acc telegraf.Accumulator) error {
+	rows, err := db.QueryContext(ctx, perfIndexIOWaitsQuery)
 	if err != nil {
 		return err
 	}
@@ -1005,8 +1011,8 @@ func (m *Mysql) gatherPerfIndexIOWaits(db *sql.DB, serv string, acc telegraf.Acc
 }
 
 // gatherInfoSchemaAutoIncStatuses can be used to get auto incremented values of the column
-func (m *Mysql) gatherInfoSchemaAutoIncStatuses(db *sql.DB, serv string, acc telegraf.Accumulator) error {
-	rows, err := db.Query(infoSchemaAutoIncQuery)
+func (m *Mysql) gatherInfoSchemaAutoIncStatuses(ctx context.Context, db *sql.DB, serv string, acc telegraf.Accumulator) error {
+	rows, err := db.QueryContext(ctx, infoSchemaAutoIncQuery)
 	if err != nil {
 		return err
 	}
@@ -1040,9 +1046,9 @@ func (m *Mysql) gatherInfoSchemaAutoIncStatuses(db *sql.DB, serv string, acc tel
 
 // gatherInnoDBMetrics can be used to fetch enabled metrics from
 // information_schema.INNODB_METRICS
-func (m *Mysql) gatherInnoDBMetrics(db *sql.DB, serv string, acc telegraf.Accumulator) error {
+func (m *Mysql) gatherInnoDBMetrics(ctx context.Context, db *sql.DB, serv string, acc telegraf.Accumulator) error {
 	// run query
-	rows, err := db.Query(innoDBMetricsQuery)
+	rows, err := db.QueryContext(ctx, innoDBMetricsQuery)
 	if err != nil {
 		return err
 	}
@@ -1079,12 +1085,12 @@ func (m *Mysql) gatherInnoDBMetrics(db *sql.DB, serv string, acc telegraf.Accumu
 // the total number and time for SQL and external lock wait events
 // for each table and operation
 // requires the MySQL server to be enabled to save this metric
-func (m *Mysql) gatherPerfTableLockWaits(db *sql.DB, serv string, acc telegraf.Accumulator) error {
+func (m *Mysql) gatherPerfTableLockWaits(ctx context.Context, db *sql.DB, serv string, acc telegraf.Accumulator) error {
 	// check if table exists,
 	// if performance_schema is not enabled, tables do not exist
 	// then there is no need to scan them
 	var tableName string
-	err := db.QueryRow(perfSchemaTablesQuery, "table_lock_waits_summary_by_table").Scan(&tableName)
+	err := db.QueryRowContext(ctx, perfSchemaTablesQuery, "table_lock_waits_summary_by_table").Scan(&tableName)
 	switch {
 	case err == sql.ErrNoRows:
 		return nil
@@ -1092,7 +1098,7 @@ func (m *Mysql) gatherPerfTableLockWaits(db *sql.DB, serv string, acc telegraf.A
 		return err
 	}
 
-	rows, err := db.Query(perfTableLockWaitsQuery)
+	rows, err := db.QueryContext(ctx, perfTableLockWaitsQuery)
 	if err != nil {
 		return err
 	}
@@ -1208,8 +1214,8 @@ func (m *Mysql) gatherPerfTableLockWaits(db *sql.DB, serv string, acc telegraf.A
 }
 
 // gatherPerfEventWaits can be used to get total time and number of event waits
-func (m *Mysql) gatherPerfEventWaits(db *sql.DB, serv string, acc telegraf.Accumulator) error {
-	rows, err := db.Query(perfEventWaitsQuery)
+func (m *Mysql) gatherPerfEventWaits(ctx context.Context, db *sql.DB, serv string, acc telegraf.Accumulator) error {
+	rows, err := db.QueryContext(ctx, perfEventWaitsQuery)
 	if err != nil {
 		return err
 	}
@@ -1240,8 +1246,8 @@ func (m *Mysql) gatherPerfEventWaits(db *sql.DB, serv string, acc telegraf.Accum
 }
 
 // gatherPerfFileEvents can be used to get stats on file events
-func (m *Mysql) gatherPerfFileEventsStatuses(db *sql.DB, serv string, acc telegraf.Accumulator) error {
-	rows, err := db.Query(perfFileEventsQuery)
+func (m *Mysql) gatherPerfFileEventsStatuses(ctx context.Context, db *sql.DB, serv string, acc telegraf.Accumulator) error {
+	rows, err := db.QueryContext(ctx, perfFileEventsQuery)
 	if err != nil {
 		return err
 	}
@@ -1298,7 +1304,7 @@ func (m *Mysql) gatherPerfFileEventsStatuses(db *sql.DB, serv string, acc telegr
 }
 
 // gatherPerfEventsStatements can be used to get attributes of each event
-func (m *Mysql) gatherPerfEventsStatements(db *sql.DB, serv string, acc telegraf.Accumulator) error {
+func (m *Mysql) gatherPerfEventsStatements(ctx context.Context, db *sql.DB, serv string, acc telegraf.Accumulator) error {
 	query := fmt.Sprintf(
 		perfEventsStatementsQuery,
 		m.PerfEventsStatementsDigestTextLimit,
@@ -1306,7 +1312,7 @@ func (m *Mysql) gatherPerfEventsStatements(db *sql.DB, serv string, acc telegraf
 		m.PerfEventsStatementsLimit,
 	)
 
-	rows, err := db.Query(query)
+	rows, err := db.QueryContext(ctx, query)
 	if err != nil {
 		return err
 	}
@@ -1365,13 +1371,13 @@ func (m *Mysql) gatherPerfEventsStatements(db *sql.DB, serv string, acc telegraf
 }
 
 // gatherTableSchema can be used to gather stats on each schema
-func (m *Mysql) gatherTableSchema(db *sql.DB, serv string, acc telegraf.Accumulator) error {
+func (m *Mysql) gatherTableSchema(ctx context.Context, db *sql.DB, serv string, acc telegraf.Accumulator) error {
 	var dbList []string
 	servtag := getDSNTag(serv)
 
 	// if the list of databases if empty, then get all databases
 	if len(m.TableSchemaDatabases) == 0 {
-		rows, err := db.Query(dbListQuery)
+		rows, err := db.QueryContext(ctx, dbListQuery)
 		if err != nil {
 			return err
 		}
@@ -1391,7 +1397,7 @@ func (m *Mysql) gatherTableSchema(db *sql.DB, serv string, acc telegraf.Accumula
 	}
 
 	for _, database := range dbList {
-		rows, err := db.Query(fmt.Sprintf(tableSchemaQuery, database))
+		rows, err := db.QueryContext(ctx, fmt.Sprintf(tableSchemaQuery, database))
 		if err != nil {
 			return err
 		}
@@ -11,6 +11,8 @@ import (
 	"github.com/nats-io/nats"
 )
 
+const defaultMaxUndeliveredMessages = 1000
+
 type natsError struct {
 	conn *nats.Conn
 	sub  *nats.Subscription
@@ -32,6 +34,10 @@ type natsConsumer struct {
 	PendingMessageLimit int
 	PendingBytesLimit   int
 
+	// MaxUndeliveredMessages is the number of messages that can be read
+	// before the outputs have written their metrics.
+	MaxUndeliveredMessages int `toml:"max_undelivered_messages"`
+
 	// Legacy metric buffer support
 	MetricBuffer int
 
@@ -47,7 +53,11 @@ type natsConsumer struct {
 	// channel for all NATS read errors
 	errs chan error
 	done chan struct{}
-	acc  telegraf.Accumulator
+	acc  telegraf.TrackingAccumulator
+
+	// undelivered holds the tracking ids of the messages whose metrics are
+	// not yet delivered.
+	undelivered map[telegraf.TrackingID]bool
 }
 
 var sampleConfig = `
@@ -65,6 +75,11 @@ var sampleConfig = `
   # pending_message_limit = 65536
   # pending_bytes_limit = 67108864
 
+  ## Maximum number of messages read but not yet written by the outputs. No
+  ## more messages are read while this many are in flight, the pending limits
+  ## above then apply.
+  # max_undelivered_messages = 1000
+
   ## Data format to consume.
   ## Each data format has its own unique set of configuration options, read
   ## more about them here:
@@ -97,7 +112,11 @@ func (n *natsConsumer) Start(acc telegraf.Accumulator) error {
 	n.Lock()
 	defer n.Unlock()
 
-	n.acc = acc
+	if n.MaxUndeliveredMessages <= 0 {
+		n.MaxUndeliveredMessages = defaultMaxUndeliveredMessages
+	}
+	n.acc = acc.WithTracking(n.MaxUndeliveredMessages)
+	n.undelivered = make(map[telegraf.TrackingID]bool)
 
 	var connectErr error
 
@@ -158,20 +177,27 @@ func (n *natsConsumer) Start(acc telegraf.Accumulator) error {
 func (n *natsConsumer) receiver() {
 	defer n.wg.Done()
 	for {
+		in := n.in
+		if len(n.undelivered) >= n.MaxUndeliveredMessages {
+			// wait for messages to be delivered before reading more
+			in = nil
+		}
+
 		select {
 		case <-n.done:
 			return
 		case err := <-n.errs:
 			n.acc.AddError(fmt.Errorf("E! error reading from %s\n", err.Error()))
-		case msg := <-n.in:
+		case info := <-n.acc.Delivered():
+			delete(n.undelivered, info.ID())
+		case msg := <-in:
 			metrics, err := n.parser.Parse(msg.Data)
 			if err != nil {
 				n.acc.AddError(fmt.Errorf("E! subject: %s, error: %s", msg.Subject, err.Error()))
 			}
 
-			for _, metric := range metrics {
-				n.acc.AddFields(metric.Name(), metric.Fields(), metric.Tags(), metric.Time())
-			}
+			id := n.acc.AddTrackingMetricGroup(metrics)
+			n.undelivered[id] = true
 		}
 	}
 }
@@ -210,6 +236,8 @@ func init() {
 			QueueGroup:          "telegraf_consumers",
 			PendingBytesLimit:   nats.DefaultSubPendingBytesLimit,
 			PendingMessageLimit: nats.DefaultSubPendingMsgsLimit,
+
+			MaxUndeliveredMessages: defaultMaxUndeliveredMessages,
 		}
 	})
 }
@@ -3,6 +3,7 @@ package natsconsumer
 import (
 	"testing"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/plugins/parsers"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/nats-io/nats"
@@ -27,6 +28,9 @@ func newTestNatsConsumer() (*natsConsumer, chan *nats.Msg) {
 		in:         in,
 		errs:       make(chan error, metricBuffer),
 		done:       make(chan struct{}),
+
+		MaxUndeliveredMessages: defaultMaxUndeliveredMessages,
+		undelivered:            make(map[telegraf.TrackingID]bool),
 	}
 	return n, in
 }
@@ -46,6 +50,24 @@ func TestRunParser(t *testing.T) {
 	acc.Wait(1)
 }
 
+// Test that messages are read again once the previous ones are delivered
+func TestRunParserUndeliveredLimit(t *testing.T) {
+	n, in := newTestNatsConsumer()
+	n.MaxUndeliveredMessages = 1
+	acc := testutil.Accumulator{}
+	n.acc = acc.WithTracking(n.MaxUndeliveredMessages)
+	defer close(n.done)
+
+	n.parser, _ = parsers.NewInfluxParser()
+	n.wg.Add(1)
+	go n.receiver()
+	for i := 0; i < 3; i++ {
+		in <- natsMsg(testMsg)
+	}
+
+	acc.Wait(3)
+}
+
 // Test that the parser ignores invalid messages
 func TestRunParserInvalidMsg(t *testing.T) {
 	n, in := newTestNatsConsumer()
@@ -2,6 +2,8 @@ package nsq_consumer
 
 import (
 	"fmt"
+	"log"
+	"sync"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/plugins/inputs"
@@ -9,6 +11,8 @@ import (
 	nsq "github.com/nsqio/go-nsq"
 )
 
+const defaultMaxUndeliveredMessages = 1000
+
 //NSQConsumer represents the configuration of the plugin
 type NSQConsumer struct {
 	Server      string
@@ -17,9 +21,22 @@ type NSQConsumer struct {
 	Topic       string
 	Channel     string
 	MaxInFlight int
-	parser      parsers.Parser
-	consumer    *nsq.Consumer
-	acc         telegraf.Accumulator
+
+	// MaxUndeliveredMessages is the number of messages that can be read
+	// before the outputs have written their metrics.
+	MaxUndeliveredMessages int `toml:"max_undelivered_messages"`
+
+	parser   parsers.Parser
+	consumer *nsq.Consumer
+	acc      telegraf.TrackingAccumulator
+
+	// messages maps the tracking ids of the messages to them until their
+	// metrics are delivered and they are finished, sem limits their number.
+	mu       sync.Mutex
+	messages map[telegraf.TrackingID]*nsq.Message
+	sem      chan struct{}
+	wg       sync.WaitGroup
+	done     chan struct{}
 }
 
 var sampleConfig = `
@@ -33,6 +50,11 @@ var sampleConfig = `
   channel = "consumer"
   max_in_flight = 100
 
+  ## Maximum number of messages read but not yet written by the outputs. A
+  ## message is only finished once its metrics are written, nsqd does not
+  ## send more than max_in_flight unfinished messages either.
+  # max_undelivered_messages = 1000
+
   ## Data format to consume.
   ## Each data format has its own unique set of configuration options, read
   ## more about them here:
@@ -42,7 +64,9 @@ var sampleConfig = `
 
 func init() {
 	inputs.Add("nsq_consumer", func() telegraf.Input {
-		return &NSQConsumer{}
+		return &NSQConsumer{
+			MaxUndeliveredMessages: defaultMaxUndeliveredMessages,
+		}
 	})
 }
 
@@ -63,20 +87,22 @@ func (n *NSQConsumer) Description() string {
 
 // Start pulls data from nsq
 func (n *NSQConsumer) Start(acc telegraf.Accumulator) error {
-	n.acc = acc
+	if n.MaxUndeliveredMessages <= 0 {
+		n.MaxUndeliveredMessages = defaultMaxUndeliveredMessages
+	}
+	n.acc = acc.WithTracking(n.MaxUndeliveredMessages)
+	n.messages = make(map[telegraf.TrackingID]*nsq.Message)
+	n.sem = make(chan struct{}, n.MaxUndeliveredMessages)
+	n.done = make(chan struct{})
+
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		n.finishDelivered()
+	}()
+
 	n.connect()
-	n.consumer.AddConcurrentHandlers(nsq.HandlerFunc(func(message *nsq.Message) error {
-		metrics, err := n.parser.Parse(message.Body)
-		if err != nil {
-			acc.AddError(fmt.Errorf("E! NSQConsumer Parse Error\nmessage:%s\nerror:%s", string(message.Body), err.Error()))
-			return nil
-		}
-		for _, metric := range metrics {
-			n.acc.AddFields(metric.Name(), metric.Fields(), metric.Tags(), metric.Time())
-		}
-		message.Finish()
-		return nil
-	}), n.MaxInFlight)
+	n.consumer.AddConcurrentHandlers(nsq.HandlerFunc(n.onMessage), n.MaxInFlight)
 
 	if len(n.Nsqlookupd) > 0 {
 		n.consumer.ConnectToNSQLookupds(n.Nsqlookupd)
@@ -85,9 +111,62 @@ func (n *NSQConsumer) Start(acc telegraf.Accumulator) error {
 	return nil
 }
 
+// onMessage adds the metrics of a message as a tracked group, the message is
+// finished once they are delivered.
+func (n *NSQConsumer) onMessage(message *nsq.Message) error {
+	metrics, err := n.parser.Parse(message.Body)
+	if err != nil {
+		n.acc.AddError(fmt.Errorf("E! NSQConsumer Parse Error\nmessage:%s\nerror:%s", string(message.Body), err.Error()))
+		return nil
+	}
+
+	message.DisableAutoResponse()
+	select {
+	case n.sem <- struct{}{}:
+	case <-n.done:
+		message.Requeue(-1)
+		return nil
+	}
+
+	// the message is registered before its delivery is handled, even if the
+	// delivery is sent right away.
+	n.mu.Lock()
+	id := n.acc.AddTrackingMetricGroup(metrics)
+	n.messages[id] = message
+	n.mu.Unlock()
+	return nil
+}
+
+// finishDelivered finishes the messages whose metrics are delivered. A message
+// that an output could not write is finished too, as it would fail again.
+func (n *NSQConsumer) finishDelivered() {
+	for {
+		select {
+		case <-n.done:
+			return
+		case info := <-n.acc.Delivered():
+			n.mu.Lock()
+			message, ok := n.messages[info.ID()]
+			delete(n.messages, info.ID())
+			n.mu.Unlock()
+			if !ok {
+				continue
+			}
+
+			if !info.Delivered() {
+				log.Printf("W! NSQ message %s was not delivered to all outputs", message.ID)
+			}
+			message.Finish()
+			<-n.sem
+		}
+	}
+}
+
 // Stop processing messages
 func (n *NSQConsumer) Stop() {
+	close(n.done)
 	n.consumer.Stop()
+	n.wg.Wait()
 }
 
 // Gather is a noop
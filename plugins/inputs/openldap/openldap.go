@@ -8,18 +8,17 @@ import (
 	"gopkg.in/ldap.v2"
 
 	"github.com/influxdata/telegraf"
-	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
 type Openldap struct {
-	Host               string
-	Port               int
-	Ssl                string
-	InsecureSkipVerify bool
-	SslCa              string
-	BindDn             string
-	BindPassword       string
+	Host         string
+	Port         int
+	Ssl          string
+	BindDn       string
+	BindPassword string
+	tls.ClientConfig
 }
 
 const sampleConfig string = `
@@ -35,7 +34,7 @@ const sampleConfig string = `
   insecure_skip_verify = false
 
   # Path to PEM-encoded Root certificate to use to verify server certificate
-  ssl_ca = "/etc/ssl/certs.pem"
+  tls_ca = "/etc/ssl/certs.pem"
 
   # dn/password to bind with. If bind_dn is empty, an anonymous bind is performed.
   bind_dn = ""
@@ -62,13 +61,11 @@ func (o *Openldap) Description() string {
 // return an initialized Openldap
 func NewOpenldap() *Openldap {
 	return &Openldap{
-		Host:               "localhost",
-		Port:               389,
-		Ssl:                "",
-		InsecureSkipVerify: false,
-		SslCa:              "",
-		BindDn:             "",
-		BindPassword:       "",
+		Host:         "localhost",
+		Port:         389,
+		Ssl:          "",
+		BindDn:       "",
+		BindPassword: "",
 	}
 }
 
@@ -78,7 +75,7 @@ func (o *Openldap) Gather(acc telegraf.Accumulator) error {
 	var l *ldap.Conn
 	if o.Ssl != "" {
 		// build tls config
-		tlsConfig, err := internal.GetTLSConfig("", "", o.SslCa, o.InsecureSkipVerify)
+		tlsConfig, err := o.ClientConfig.TLSConfig()
 		if err != nil {
 			acc.AddError(err)
 			return nil
@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"testing"
 
+	"github.com/influxdata/telegraf/internal/tls"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -72,10 +73,12 @@ func TestOpenldapStartTLS(t *testing.T) {
 	}
 
 	o := &Openldap{
-		Host:               testutil.GetLocalHost(),
-		Port:               389,
-		Ssl:                "starttls",
-		InsecureSkipVerify: true,
+		Host: testutil.GetLocalHost(),
+		Port: 389,
+		Ssl:  "starttls",
+		ClientConfig: tls.ClientConfig{
+			InsecureSkipVerify: true,
+		},
 	}
 
 	var acc testutil.Accumulator
@@ -90,10 +93,12 @@ func TestOpenldapLDAPS(t *testing.T) {
 	}
 
 	o := &Openldap{
-		Host:               testutil.GetLocalHost(),
-		Port:               636,
-		Ssl:                "ldaps",
-		InsecureSkipVerify: true,
+		Host: testutil.GetLocalHost(),
+		Port: 636,
+		Ssl:  "ldaps",
+		ClientConfig: tls.ClientConfig{
+			InsecureSkipVerify: true,
+		},
 	}
 
 	var acc testutil.Accumulator
@@ -108,10 +113,12 @@ func TestOpenldapInvalidSSL(t *testing.T) {
 	}
 
 	o := &Openldap{
-		Host:               testutil.GetLocalHost(),
-		Port:               636,
-		Ssl:                "invalid",
-		InsecureSkipVerify: true,
+		Host: testutil.GetLocalHost(),
+		Port: 636,
+		Ssl:  "invalid",
+		ClientConfig: tls.ClientConfig{
+			InsecureSkipVerify: true,
+		},
 	}
 
 	var acc testutil.Accumulator
@@ -127,12 +134,14 @@ func TestOpenldapBind(t *testing.T) {
 	}
 
 	o := &Openldap{
-		Host:               testutil.GetLocalHost(),
-		Port:               389,
-		Ssl:                "",
-		InsecureSkipVerify: true,
-		BindDn:             "cn=manager,cn=config",
-		BindPassword:       "secret",
+		Host: testutil.GetLocalHost(),
+		Port: 389,
+		Ssl:  "",
+		ClientConfig: tls.ClientConfig{
+			InsecureSkipVerify: true,
+		},
+		BindDn:       "cn=manager,cn=config",
+		BindPassword: "secret",
 	}
 
 	var acc testutil.Accumulator
@@ -0,0 +1,409 @@
+package prometheus_remote_write
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/prompb"
+	tlsint "github.com/influxdata/telegraf/internal/tls"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+// defaultMaxBodySize is the default maximum request body size, in bytes,
+// of the compressed request.
+const defaultMaxBodySize = 32 * 1024 * 1024
+
+// defaultMaxDecodedRatio is the default maximum size of the decompressed
+// request, as a multiple of the maximum body size.
+const defaultMaxDecodedRatio = 10
+
+type PrometheusRemoteWrite struct {
+	ServiceAddress  string            `toml:"service_address"`
+	Path            string            `toml:"path"`
+	ReadTimeout     internal.Duration `toml:"read_timeout"`
+	WriteTimeout    internal.Duration `toml:"write_timeout"`
+	MaxBodySize     int64             `toml:"max_body_size"`
+	MaxDecodedRatio int64             `toml:"max_decoded_ratio"`
+	tlsint.ServerConfig
+
+	mu sync.Mutex
+	wg sync.WaitGroup
+
+	listener net.Listener
+	acc      telegraf.Accumulator
+}
+
+const sampleConfig = `
+  ## Address and port to listen on.
+  service_address = ":9201"
+
+  ## Path of the remote write endpoint.
+  path = "/write"
+
+  ## Maximum duration before timing out read of the request, and write of
+  ## the response.
+  # read_timeout = "10s"
+  # write_timeout = "10s"
+
+  ## Maximum allowed compressed request body size in bytes.
+  ## 0 means to use the default of 33,554,432 bytes (32 mebibytes)
+  # max_body_size = 0
+
+  ## Maximum allowed decompressed request size, as a multiple of
+  ## max_body_size.  0 means to use the default of 10.
+  # max_decoded_ratio = 0
+
+  ## Optional TLS Config
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+  ## Enables client authentication if set.
+  # tls_allowed_cacerts = ["/etc/telegraf/clientca.pem"]
+`
+
+func (p *PrometheusRemoteWrite) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *PrometheusRemoteWrite) Description() string {
+	return "Receive metrics sent with the Prometheus remote write protocol"
+}
+
+func (p *PrometheusRemoteWrite) Gather(_ telegraf.Accumulator) error {
+	return nil
+}
+
+// Start starts the remote write receiver.
+func (p *PrometheusRemoteWrite) Start(acc telegraf.Accumulator) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.Path == "" {
+		p.Path = "/write"
+	}
+	if p.MaxBodySize == 0 {
+		p.MaxBodySize = defaultMaxBodySize
+	}
+	if p.MaxDecodedRatio == 0 {
+		p.MaxDecodedRatio = defaultMaxDecodedRatio
+	}
+	if p.ReadTimeout.Duration < time.Second {
+		p.ReadTimeout.Duration = time.Second * 10
+	}
+	if p.WriteTimeout.Duration < time.Second {
+		p.WriteTimeout.Duration = time.Second * 10
+	}
+
+	tlsConfig, err := p.ServerConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+
+	p.acc = acc
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(p.Path, p.serveWrite)
+	server := &http.Server{
+		Handler:      mux,
+		ReadTimeout:  p.ReadTimeout.Duration,
+		WriteTimeout: p.WriteTimeout.Duration,
+	}
+
+	listener, err := net.Listen("tcp", p.ServiceAddress)
+	if err != nil {
+		return err
+	}
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+	p.listener = listener
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		server.Serve(p.listener)
+	}()
+
+	log.Printf("I! Started Prometheus remote write receiver on %s\n",
+		p.ServiceAddress)
+	return nil
+}
+
+// Stop stops the receiver.
+func (p *PrometheusRemoteWrite) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.listener.Close()
+	p.wg.Wait()
+
+	log.Printf("I! Stopped Prometheus remote write receiver on %s\n",
+		p.ServiceAddress)
+}
+
+func (p *PrometheusRemoteWrite) serveWrite(res http.ResponseWriter, req *http.Request) {
+	if req.Method != "POST" {
+		res.Header().Set("Allow", "POST")
+		http.Error(res, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if req.ContentLength > p.MaxBodySize {
+		http.Error(res, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(res, req.Body, p.MaxBodySize))
+	if err != nil {
+		log.Printf("E! Error reading remote write request: %s\n", err)
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// the decoded length is read from the header, check it before
+	// snappy allocates the buffer
+	n, err := snappy.DecodedLen(body)
+	if err != nil {
+		log.Printf("E! Error decoding remote write request: %s\n", err)
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if int64(n) > p.MaxBodySize*p.MaxDecodedRatio {
+		http.Error(res, "decoded request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	data, err := snappy.Decode(nil, body)
+	if err != nil {
+		log.Printf("E! Error decoding remote write request: %s\n", err)
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var wr prompb.WriteRequest
+	if err := wr.Unmarshal(data); err != nil {
+		log.Printf("E! Error decoding remote write request: %s\n", err)
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	for _, m := range convert(&wr) {
+		switch m.Type() {
+		case telegraf.Counter:
+			p.acc.AddCounter(m.Name(), m.Fields(), m.Tags(), m.Time())
+		case telegraf.Gauge:
+			p.acc.AddGauge(m.Name(), m.Fields(), m.Tags(), m.Time())
+		case telegraf.Summary:
+			p.acc.AddSummary(m.Name(), m.Fields(), m.Tags(), m.Time())
+		case telegraf.Histogram:
+			p.acc.AddHistogram(m.Name(), m.Fields(), m.Tags(), m.Time())
+		default:
+			p.acc.AddFields(m.Name(), m.Fields(), m.Tags(), m.Time())
+		}
+	}
+	res.WriteHeader(http.StatusNoContent)
+}
+
+// group collects the series of a summary or histogram with the same labels
+// and timestamp into one metric.
+type group struct {
+	name   string
+	tags   map[string]string
+	fields map[string]interface{}
+	tm     int64
+	tp     telegraf.ValueType
+}
+
+// convert converts the series of a request into metrics, the same way as
+// the prometheus input: summaries and histograms are a metric with a field
+// for each quantile or bucket, and the sum and count fields.
+func convert(wr *prompb.WriteRequest) []telegraf.Metric {
+	types := make(map[string]prompb.MetricType)
+	for _, md := range wr.Metadata {
+		types[md.MetricFamilyName] = md.Type
+	}
+	// the series of summaries and histograms are recognized from their
+	// labels when there is no metadata.
+	for _, ts := range wr.Timeseries {
+		name, labels := splitLabels(ts.Labels)
+		if _, ok := types[name]; ok {
+			continue
+		}
+		if _, ok := labels["quantile"]; ok {
+			types[name] = prompb.MetricTypeSummary
+		} else if _, ok := labels["le"]; ok && strings.HasSuffix(name, "_bucket") {
+			types[strings.TrimSuffix(name, "_bucket")] = prompb.MetricTypeHistogram
+		}
+	}
+
+	var groups []*group
+	byKey := make(map[string]*group)
+	getGroup := func(
+		name string,
+		tags map[string]string,
+		tm int64,
+		tp telegraf.ValueType,
+	) *group {
+		key := groupKey(name, tags, tm)
+		g, ok := byKey[key]
+		if !ok {
+			g = &group{
+				name:   name,
+				tags:   tags,
+				fields: make(map[string]interface{}),
+				tm:     tm,
+				tp:     tp,
+			}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		return g
+	}
+
+	for _, ts := range wr.Timeseries {
+		name, labels := splitLabels(ts.Labels)
+		family, field, tp := classify(name, labels, types)
+
+		for _, s := range ts.Samples {
+			switch tp {
+			case telegraf.Summary, telegraf.Histogram:
+				if field == "" {
+					continue
+				}
+				tags := make(map[string]string, len(labels))
+				for k, v := range labels {
+					if k != "quantile" && k != "le" {
+						tags[k] = v
+					}
+				}
+				g := getGroup(family, tags, s.Timestamp, tp)
+				g.fields[field] = s.Value
+			default:
+				var f string
+				switch tp {
+				case telegraf.Counter:
+					f = "counter"
+				case telegraf.Gauge:
+					f = "gauge"
+				default:
+					f = "value"
+				}
+				g := &group{
+					name:   name,
+					tags:   labels,
+					fields: map[string]interface{}{f: s.Value},
+					tm:     s.Timestamp,
+					tp:     tp,
+				}
+				groups = append(groups, g)
+			}
+		}
+	}
+
+	metrics := make([]telegraf.Metric, 0, len(groups))
+	for _, g := range groups {
+		m, err := metric.New(g.name, g.tags, g.fields,
+			time.Unix(0, g.tm*int64(time.Millisecond)), g.tp)
+		if err != nil {
+			log.Printf("E! Error creating metric %s: %s\n", g.name, err)
+			continue
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics
+}
+
+// classify returns the family of a series, the field of its value in the
+// metric of the family for summaries and histograms, and its type.
+func classify(
+	name string,
+	labels map[string]string,
+	types map[string]prompb.MetricType,
+) (string, string, telegraf.ValueType) {
+	for _, suffix := range []string{"_sum", "_count"} {
+		if !strings.HasSuffix(name, suffix) {
+			continue
+		}
+		family := strings.TrimSuffix(name, suffix)
+		switch types[family] {
+		case prompb.MetricTypeSummary:
+			return family, suffix[1:], telegraf.Summary
+		case prompb.MetricTypeHistogram, prompb.MetricTypeGaugeHistogram:
+			return family, suffix[1:], telegraf.Histogram
+		}
+	}
+
+	if strings.HasSuffix(name, "_bucket") {
+		family := strings.TrimSuffix(name, "_bucket")
+		switch types[family] {
+		case prompb.MetricTypeHistogram, prompb.MetricTypeGaugeHistogram:
+			return family, boundField(labels["le"]), telegraf.Histogram
+		}
+	}
+
+	switch types[name] {
+	case prompb.MetricTypeCounter:
+		return name, "", telegraf.Counter
+	case prompb.MetricTypeGauge:
+		return name, "", telegraf.Gauge
+	case prompb.MetricTypeSummary:
+		return name, boundField(labels["quantile"]), telegraf.Summary
+	}
+	return name, "", telegraf.Untyped
+}
+
+// boundField returns the field name of a quantile or bucket bound, or an
+// empty string if it is not a number.
+func boundField(bound string) string {
+	f, err := strconv.ParseFloat(bound, 64)
+	if err != nil {
+		return ""
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// splitLabels returns the metric name of a series and its other labels.
+func splitLabels(labels []prompb.Label) (string, map[string]string) {
+	var name string
+	tags := make(map[string]string, len(labels))
+	for _, l := range labels {
+		if l.Name == "__name__" {
+			name = l.Value
+			continue
+		}
+		tags[l.Name] = l.Value
+	}
+	return name, tags
+}
+
+func groupKey(name string, tags map[string]string, tm int64) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(tags)+2)
+	parts = append(parts, name)
+	for _, k := range keys {
+		parts = append(parts, k+"="+tags[k])
+	}
+	parts = append(parts, strconv.FormatInt(tm, 10))
+	return strings.Join(parts, "\x00")
+}
+
+func init() {
+	inputs.Add("prometheus_remote_write", func() telegraf.Input {
+		return &PrometheusRemoteWrite{
+			ServiceAddress: ":9201",
+			Path:           "/write",
+		}
+	})
+}
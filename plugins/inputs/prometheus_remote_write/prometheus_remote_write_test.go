@@ -0,0 +1,182 @@
+package prometheus_remote_write
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/prompb"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func series(name string, value float64, ts int64, kv ...string) prompb.TimeSeries {
+	labels := []prompb.Label{{Name: "__name__", Value: name}}
+	for i := 0; i < len(kv); i += 2 {
+		labels = append(labels, prompb.Label{Name: kv[i], Value: kv[i+1]})
+	}
+	return prompb.TimeSeries{
+		Labels:  labels,
+		Samples: []prompb.Sample{{Value: value, Timestamp: ts}},
+	}
+}
+
+func TestConvert_Untyped(t *testing.T) {
+	metrics := convert(&prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{
+			series("cpu_usage_idle", 98.5, 1500000000000, "host", "a"),
+		},
+	})
+
+	require.Len(t, metrics, 1)
+	m := metrics[0]
+	assert.Equal(t, "cpu_usage_idle", m.Name())
+	assert.Equal(t, map[string]string{"host": "a"}, m.Tags())
+	assert.Equal(t, map[string]interface{}{"value": 98.5}, m.Fields())
+	assert.Equal(t, time.Unix(1500000000, 0), m.Time())
+	assert.Equal(t, telegraf.Untyped, m.Type())
+}
+
+func TestConvert_CounterGauge(t *testing.T) {
+	metrics := convert(&prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{
+			series("requests_total", 7, 0),
+			series("temperature", 20.5, 0),
+		},
+		Metadata: []prompb.MetricMetadata{
+			{Type: prompb.MetricTypeCounter, MetricFamilyName: "requests_total"},
+			{Type: prompb.MetricTypeGauge, MetricFamilyName: "temperature"},
+		},
+	})
+
+	require.Len(t, metrics, 2)
+	assert.Equal(t, telegraf.Counter, metrics[0].Type())
+	assert.Equal(t, map[string]interface{}{"counter": 7.0}, metrics[0].Fields())
+	assert.Equal(t, telegraf.Gauge, metrics[1].Type())
+	assert.Equal(t, map[string]interface{}{"gauge": 20.5}, metrics[1].Fields())
+}
+
+func TestConvert_Summary(t *testing.T) {
+	// without metadata, the summary is recognized from the quantile label
+	metrics := convert(&prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{
+			series("rpc_seconds_sum", 10, 0, "service", "a"),
+			series("rpc_seconds", 1, 0, "service", "a", "quantile", "0.5"),
+			series("rpc_seconds", 3, 0, "service", "a", "quantile", "0.90"),
+			series("rpc_seconds_count", 4, 0, "service", "a"),
+		},
+	})
+
+	require.Len(t, metrics, 1)
+	m := metrics[0]
+	assert.Equal(t, "rpc_seconds", m.Name())
+	assert.Equal(t, telegraf.Summary, m.Type())
+	assert.Equal(t, map[string]string{"service": "a"}, m.Tags())
+	assert.Equal(t, map[string]interface{}{
+		"0.5":   1.0,
+		"0.9":   3.0,
+		"sum":   10.0,
+		"count": 4.0,
+	}, m.Fields())
+}
+
+func TestConvert_Histogram(t *testing.T) {
+	metrics := convert(&prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{
+			series("latency_bucket", 1, 0, "le", "0.1"),
+			series("latency_bucket", 3, 0, "le", "1"),
+			series("latency_bucket", 5, 0, "le", "+Inf"),
+			series("latency_sum", 2.5, 0),
+			series("latency_count", 5, 0),
+			// another timestamp is another metric
+			series("latency_count", 6, 1000),
+		},
+		Metadata: []prompb.MetricMetadata{
+			{Type: prompb.MetricTypeHistogram, MetricFamilyName: "latency"},
+		},
+	})
+
+	require.Len(t, metrics, 2)
+	assert.Equal(t, telegraf.Histogram, metrics[0].Type())
+	assert.Equal(t, map[string]interface{}{
+		"0.1":   1.0,
+		"1":     3.0,
+		"+Inf":  5.0,
+		"sum":   2.5,
+		"count": 5.0,
+	}, metrics[0].Fields())
+	assert.Equal(t, map[string]interface{}{"count": 6.0}, metrics[1].Fields())
+	assert.Equal(t, time.Unix(1, 0), metrics[1].Time())
+}
+
+func TestServeWrite(t *testing.T) {
+	p := &PrometheusRemoteWrite{ServiceAddress: "localhost:0"}
+	acc := &testutil.Accumulator{}
+	require.NoError(t, p.Start(acc))
+	defer p.Stop()
+
+	url := "http://" + p.listener.Addr().String() + "/write"
+
+	wr := &prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{
+			series("cpu_usage_idle", 98.5, 1500000000000, "host", "a"),
+		},
+	}
+	data, err := wr.Marshal()
+	require.NoError(t, err)
+
+	resp, err := http.Post(url, "application/x-protobuf",
+		bytes.NewReader(snappy.Encode(nil, data)))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	acc.AssertContainsTaggedFields(t, "cpu_usage_idle",
+		map[string]interface{}{"value": 98.5},
+		map[string]string{"host": "a"})
+
+	// the body must be snappy compressed
+	resp, err = http.Post(url, "application/x-protobuf", bytes.NewReader(data))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = http.Get(url)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestServeWrite_DecodedTooLarge(t *testing.T) {
+	p := &PrometheusRemoteWrite{
+		ServiceAddress:  "localhost:0",
+		MaxBodySize:     1024,
+		MaxDecodedRatio: 2,
+	}
+	acc := &testutil.Accumulator{}
+	require.NoError(t, p.Start(acc))
+	defer p.Stop()
+
+	url := "http://" + p.listener.Addr().String() + "/write"
+
+	// the snappy header is the decoded length as a uvarint, here about
+	// 4 GiB, followed by a single literal byte
+	body := []byte{0xff, 0xff, 0xff, 0xff, 0x0f, 0x00, 'a'}
+	resp, err := http.Post(url, "application/x-protobuf", bytes.NewReader(body))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+
+	// within the compressed limit, but over twice of it once decoded
+	data := snappy.Encode(nil, make([]byte, 4096))
+	require.True(t, len(data) < 1024)
+	resp, err = http.Post(url, "application/x-protobuf", bytes.NewReader(data))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	assert.Equal(t, 0, len(acc.Metrics))
+}
@@ -2,6 +2,7 @@ package redis
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"fmt"
 	"net"
@@ -55,12 +56,18 @@ const defaultPort = "6379"
 // Reads stats from all configured servers accumulates stats.
 // Returns one of the errors encountered while gather stats (if any).
 func (r *Redis) Gather(acc telegraf.Accumulator) error {
+	return r.GatherContext(context.Background(), acc)
+}
+
+// GatherContext is Gather, the connections to the servers are closed when the
+// context is done.
+func (r *Redis) GatherContext(ctx context.Context, acc telegraf.Accumulator) error {
 	if len(r.Servers) == 0 {
 		url := &url.URL{
 			Scheme: "tcp",
 			Host:   ":6379",
 		}
-		r.gatherServer(url, acc)
+		r.gatherServer(ctx, url, acc)
 		return nil
 	}
 
@@ -90,7 +97,7 @@ func (r *Redis) Gather(acc telegraf.Accumulator) error {
 		wg.Add(1)
 		go func(serv string) {
 			defer wg.Done()
-			acc.AddError(r.gatherServer(u, acc))
+			acc.AddError(r.gatherServer(ctx, u, acc))
 		}(serv)
 	}
 
@@ -98,7 +105,7 @@ func (r *Redis) Gather(acc telegraf.Accumulator) error {
 	return nil
 }
 
-func (r *Redis) gatherServer(addr *url.URL, acc telegraf.Accumulator) error {
+func (r *Redis) gatherServer(ctx context.Context, addr *url.URL, acc telegraf.Accumulator) error {
 	var address string
 
 	if addr.Scheme == "unix" {
@@ -106,12 +113,24 @@ func (r *Redis) gatherServer(addr *url.URL, acc telegraf.Accumulator) error {
 	} else {
 		address = addr.Host
 	}
-	c, err := net.DialTimeout(addr.Scheme, address, defaultTimeout)
+	dialer := net.Dialer{Timeout: defaultTimeout}
+	c, err := dialer.DialContext(ctx, addr.Scheme, address)
 	if err != nil {
 		return fmt.Errorf("Unable to connect to redis server '%s': %s", address, err)
 	}
 	defer c.Close()
 
+	// Abort the reads and writes when the context is done
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			c.Close()
+		case <-stop:
+		}
+	}()
+
 	// Extend connection
 	c.SetDeadline(time.Now().Add(defaultTimeout))
 
@@ -147,7 +166,11 @@ func (r *Redis) gatherServer(addr *url.URL, acc telegraf.Accumulator) error {
 		host, port, _ = net.SplitHostPort(addr.Host)
 		tags = map[string]string{"server": host, "port": port}
 	}
-	return gatherInfoOutput(rdr, acc, tags)
+	err = gatherInfoOutput(rdr, acc, tags)
+	if ctx.Err() != nil {
+		return fmt.Errorf("Gathering from redis server '%s' was cancelled: %s", address, ctx.Err())
+	}
+	return err
 }
 
 // gatherInfoOutput gathers
@@ -239,6 +262,9 @@ func gatherInfoOutput(
 
 		fields[metric] = val
 	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
 	var keyspace_hitrate float64 = 0.0
 	if keyspace_hits != 0 || keyspace_misses != 0 {
 		keyspace_hitrate = float64(keyspace_hits) / float64(keyspace_hits+keyspace_misses)
@@ -2,7 +2,9 @@ package redis
 
 import (
 	"bufio"
+	"context"
 	"fmt"
+	"net"
 	"strings"
 	"testing"
 	"time"
@@ -29,6 +31,35 @@ func TestRedisConnect(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestRedis_ContextDone(t *testing.T) {
+	// a server that never answers
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer c.Close()
+		}
+	}()
+
+	r := &Redis{
+		Servers: []string{l.Addr().String()},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	var acc testutil.Accumulator
+	start := time.Now()
+	require.NoError(t, r.GatherContext(ctx, &acc))
+	assert.True(t, time.Since(start) < defaultTimeout)
+	assert.Len(t, acc.Errors, 1)
+	assert.Equal(t, 0, len(acc.Metrics))
+}
+
 func TestRedis_ParseMetrics(t *testing.T) {
 	var acc testutil.Accumulator
 	tags := map[string]string{"host": "redis.net"}
@@ -3,6 +3,7 @@ package snmp
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"fmt"
 	"math"
 	"net"
@@ -341,6 +342,12 @@ func (s *Snmp) Description() string {
 // Any error encountered does not halt the process. The errors are accumulated
 // and returned at the end.
 func (s *Snmp) Gather(acc telegraf.Accumulator) error {
+	return s.GatherContext(context.Background(), acc)
+}
+
+// GatherContext is Gather, no more requests are sent to the agents once the
+// context is done.
+func (s *Snmp) GatherContext(ctx context.Context, acc telegraf.Accumulator) error {
 	if err := s.init(); err != nil {
 		return err
 	}
@@ -350,11 +357,12 @@ func (s *Snmp) Gather(acc telegraf.Accumulator) error {
 		wg.Add(1)
 		go func(i int, agent string) {
 			defer wg.Done()
-			gs, err := s.getConnection(i)
+			conn, err := s.getConnection(i)
 			if err != nil {
 				acc.AddError(Errorf(err, "agent %s", agent))
 				return
 			}
+			gs := contextConnection{snmpConnection: conn, ctx: ctx}
 
 			// First is the top-level fields. We treat the fields as table prefixes with an empty index.
 			t := Table{
@@ -526,6 +534,35 @@ type snmpConnection interface {
 	Get(oids []string) (*gosnmp.SnmpPacket, error)
 }
 
+// contextConnection is a snmpConnection whose requests fail once the context
+// is done, so that a cancelled gather stops after the current request.
+type contextConnection struct {
+	snmpConnection
+	ctx context.Context
+}
+
+// Walk wraps the Walk of the connection, it is stopped once the context is
+// done.
+func (c contextConnection) Walk(oid string, fn gosnmp.WalkFunc) error {
+	if err := c.ctx.Err(); err != nil {
+		return err
+	}
+	return c.snmpConnection.Walk(oid, func(ent gosnmp.SnmpPDU) error {
+		if err := c.ctx.Err(); err != nil {
+			return err
+		}
+		return fn(ent)
+	})
+}
+
+// Get wraps the Get of the connection.
+func (c contextConnection) Get(oids []string) (*gosnmp.SnmpPacket, error) {
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.snmpConnection.Get(oids)
+}
+
 // gosnmpWrapper wraps a *gosnmp.GoSNMP object so we can use it as a snmpConnection.
 type gosnmpWrapper struct {
 	*gosnmp.GoSNMP
@@ -2,6 +2,7 @@
 package snmp
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"os/exec"
@@ -635,6 +636,33 @@ func TestGather_host(t *testing.T) {
 	assert.Equal(t, "baz", m.Tags["host"])
 }
 
+func TestGatherContextDone(t *testing.T) {
+	s := &Snmp{
+		Agents: []string{"TestGather"},
+		Name:   "mytable",
+		Fields: []Field{
+			{
+				Name: "myfield2",
+				Oid:  ".1.0.0.1.2",
+			},
+		},
+
+		connectionCache: []snmpConnection{
+			tsc,
+		},
+		initialized: true,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	acc := &testutil.Accumulator{}
+	s.GatherContext(ctx, acc)
+
+	assert.Len(t, acc.Metrics, 0)
+	require.Len(t, acc.Errors, 1)
+	assert.Contains(t, acc.Errors[0].Error(), context.Canceled.Error())
+}
+
 func TestFieldConvert(t *testing.T) {
 	testTable := []struct {
 		input    interface{}
@@ -2,6 +2,7 @@ package socket_listener
 
 import (
 	"bufio"
+	"crypto/tls"
 	"fmt"
 	"io"
 	"log"
@@ -14,6 +15,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
+	tlsint "github.com/influxdata/telegraf/internal/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers"
 )
@@ -26,6 +28,9 @@ type streamSocketListener struct {
 	net.Listener
 	*SocketListener
 
+	// tlsConfig is set when the connections are to be wrapped in TLS.
+	tlsConfig *tls.Config
+
 	connections    map[string]net.Conn
 	connectionsMtx sync.Mutex
 }
@@ -55,6 +60,10 @@ func (ssl *streamSocketListener) listen() {
 			ssl.AddError(fmt.Errorf("unable to configure keep alive (%s): %s", ssl.ServiceAddress, err))
 		}
 
+		if ssl.tlsConfig != nil {
+			c = tls.Server(c, ssl.tlsConfig)
+		}
+
 		go ssl.read(c)
 	}
 
@@ -154,6 +163,7 @@ type SocketListener struct {
 	ReadBufferSize  int
 	ReadTimeout     *internal.Duration
 	KeepAlivePeriod *internal.Duration
+	tlsint.ServerConfig
 
 	parsers.Parser
 	telegraf.Accumulator
@@ -200,6 +210,15 @@ func (sl *SocketListener) SampleConfig() string {
   ## Defaults to the OS configuration.
   # keep_alive_period = "5m"
 
+  ## Enables TLS on stream sockets.
+  ## Only applies to stream sockets (e.g. TCP).
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+  ## Enables client authentication if set.
+  # tls_allowed_cacerts = ["/etc/telegraf/clientca.pem"]
+  ## Only accept client certificates with one of these common names.
+  # tls_allowed_client_cns = ["telegraf"]
+
   ## Data format to consume.
   ## Each data format has its own unique set of configuration options, read
   ## more about them here:
@@ -223,6 +242,11 @@ func (sl *SocketListener) Start(acc telegraf.Accumulator) error {
 		return fmt.Errorf("invalid service address: %s", sl.ServiceAddress)
 	}
 
+	tlsCfg, err := sl.ServerConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+
 	if spl[0] == "unix" || spl[0] == "unixpacket" || spl[0] == "unixgram" {
 		// no good way of testing for "file does not exist".
 		// Instead just ignore error and blow up when we try to listen, which will
@@ -248,11 +272,16 @@ func (sl *SocketListener) Start(acc telegraf.Accumulator) error {
 		ssl := &streamSocketListener{
 			Listener:       l,
 			SocketListener: sl,
+			tlsConfig:      tlsCfg,
 		}
 
 		sl.Closer = ssl
 		go ssl.listen()
 	case "udp", "udp4", "udp6", "ip", "ip4", "ip6", "unixgram":
+		if tlsCfg != nil {
+			return fmt.Errorf("TLS is not supported on %s sockets", spl[0])
+		}
+
 		pc, err := net.ListenPacket(spl[0], spl[1])
 		if err != nil {
 			return err
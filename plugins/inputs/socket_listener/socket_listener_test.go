@@ -1,6 +1,7 @@
 package socket_listener
 
 import (
+	"crypto/tls"
 	"net"
 	"os"
 	"testing"
@@ -11,6 +12,8 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+var pki = testutil.NewPKI("../../../testutil/pki")
+
 func TestSocketListener_tcp(t *testing.T) {
 	sl := newSocketListener()
 	sl.ServiceAddress = "tcp://127.0.0.1:0"
@@ -26,6 +29,34 @@ func TestSocketListener_tcp(t *testing.T) {
 	testSocketListener(t, sl, client)
 }
 
+func TestSocketListener_tcp_tls(t *testing.T) {
+	sl := newSocketListener()
+	sl.ServiceAddress = "tcp://127.0.0.1:0"
+	sl.ServerConfig = *pki.TLSServerConfig()
+
+	acc := &testutil.Accumulator{}
+	err := sl.Start(acc)
+	require.NoError(t, err)
+	defer sl.Stop()
+
+	tlsCfg, err := pki.TLSClientConfig().TLSConfig()
+	require.NoError(t, err)
+
+	client, err := tls.Dial("tcp", sl.Closer.(net.Listener).Addr().String(), tlsCfg)
+	require.NoError(t, err)
+
+	testSocketListener(t, sl, client)
+}
+
+func TestSocketListener_udp_tls(t *testing.T) {
+	sl := newSocketListener()
+	sl.ServiceAddress = "udp://127.0.0.1:0"
+	sl.ServerConfig = *pki.TLSServerConfig()
+
+	err := sl.Start(&testutil.Accumulator{})
+	require.Error(t, err)
+}
+
 func TestSocketListener_udp(t *testing.T) {
 	sl := newSocketListener()
 	sl.ServiceAddress = "udp://127.0.0.1:0"
@@ -0,0 +1,267 @@
+package syslog
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// message is a parsed syslog message, the fields that are missing from the
+// message, or nil ("-") in RFC5424, are left empty.
+type message struct {
+	facility  int
+	severity  int
+	version   int
+	timestamp time.Time
+	hostname  string
+	appname   string
+	procid    string
+	msgid     string
+	// structuredData maps the SD-IDs to their parameters, in RFC5424 only.
+	structuredData map[string]map[string]string
+	message        string
+}
+
+const nilValue = "-"
+
+var facilities = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console",
+	"solaris-cron", "local0", "local1", "local2", "local3", "local4",
+	"local5", "local6", "local7",
+}
+
+var severities = []string{
+	"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug",
+}
+
+// bom is the UTF-8 byte order mark a RFC5424 message can start with.
+var bom = []byte("\xef\xbb\xbf")
+
+// parse parses a RFC5424 or a RFC3164 (BSD) syslog message, the format is
+// detected from the version that follows the priority in RFC5424. The
+// timestamp of a RFC3164 message has no year nor offset, it is in the year
+// and the location of now.
+func parse(buf []byte, now time.Time) (*message, error) {
+	buf = bytes.TrimRight(buf, "\r\n\x00")
+
+	m := &message{}
+	rest, err := m.parsePriority(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	// RFC5424 has a version of one to three digits followed by a space
+	if n := digits(rest); n > 0 && n <= 3 && n < len(rest) && rest[n] == ' ' {
+		err = m.parseRFC5424(rest)
+	} else {
+		err = m.parseRFC3164(rest, now)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// parsePriority parses the <PRI> of the message and returns the rest of it.
+func (m *message) parsePriority(buf []byte) ([]byte, error) {
+	if len(buf) == 0 || buf[0] != '<' {
+		return nil, fmt.Errorf("expecting a priority at the start of the message")
+	}
+	end := bytes.IndexByte(buf, '>')
+	if end < 2 || end > 4 {
+		return nil, fmt.Errorf("invalid priority in message")
+	}
+	pri, err := strconv.Atoi(string(buf[1:end]))
+	if err != nil || pri > 191 {
+		return nil, fmt.Errorf("invalid priority %q in message", buf[1:end])
+	}
+	m.facility = pri / 8
+	m.severity = pri % 8
+	return buf[end+1:], nil
+}
+
+// parseRFC5424 parses the header, structured data and message of a RFC5424
+// message: VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID SD [MSG]
+func (m *message) parseRFC5424(buf []byte) error {
+	var header [6]string
+	for i := range header {
+		var token []byte
+		token, buf = nextToken(buf)
+		if token == nil {
+			return fmt.Errorf("incomplete RFC5424 header")
+		}
+		header[i] = string(token)
+	}
+
+	var err error
+	if m.version, err = strconv.Atoi(header[0]); err != nil {
+		return fmt.Errorf("invalid version %q", header[0])
+	}
+	if header[1] != nilValue {
+		m.timestamp, err = time.Parse(time.RFC3339Nano, header[1])
+		if err != nil {
+			return fmt.Errorf("invalid timestamp %q", header[1])
+		}
+	}
+	m.hostname = optional(header[2])
+	m.appname = optional(header[3])
+	m.procid = optional(header[4])
+	m.msgid = optional(header[5])
+
+	if buf, err = m.parseStructuredData(buf); err != nil {
+		return err
+	}
+
+	if len(buf) > 0 {
+		if buf[0] != ' ' {
+			return fmt.Errorf("expecting a space after the structured data")
+		}
+		m.message = string(bytes.TrimPrefix(buf[1:], bom))
+	}
+	return nil
+}
+
+// parseStructuredData parses the SD-ELEMENTs, ie
+// [exampleSDID@32473 iut="3" eventSource="Application"], or the nil value.
+func (m *message) parseStructuredData(buf []byte) ([]byte, error) {
+	if len(buf) == 0 {
+		return nil, fmt.Errorf("missing structured data")
+	}
+	if buf[0] == '-' {
+		return buf[1:], nil
+	}
+
+	m.structuredData = make(map[string]map[string]string)
+	for len(buf) > 0 && buf[0] == '[' {
+		buf = buf[1:]
+		n := bytes.IndexAny(buf, " ]")
+		if n <= 0 {
+			return nil, fmt.Errorf("invalid structured data element")
+		}
+		params := make(map[string]string)
+		m.structuredData[string(buf[:n])] = params
+		buf = buf[n:]
+
+		for len(buf) > 0 && buf[0] == ' ' {
+			buf = buf[1:]
+			n = bytes.Index(buf, []byte(`="`))
+			if n <= 0 {
+				return nil, fmt.Errorf("invalid structured data parameter")
+			}
+			name := string(buf[:n])
+			buf = buf[n+2:]
+
+			var value []byte
+			for {
+				if len(buf) == 0 {
+					return nil, fmt.Errorf("unterminated structured data parameter %s", name)
+				}
+				c := buf[0]
+				buf = buf[1:]
+				if c == '"' {
+					break
+				}
+				// only '"', '\' and ']' are escaped, other backslashes are
+				// kept as is
+				if c == '\\' && len(buf) > 0 &&
+					(buf[0] == '"' || buf[0] == '\\' || buf[0] == ']') {
+					c = buf[0]
+					buf = buf[1:]
+				}
+				value = append(value, c)
+			}
+			params[name] = string(value)
+		}
+
+		if len(buf) == 0 || buf[0] != ']' {
+			return nil, fmt.Errorf("unterminated structured data element")
+		}
+		buf = buf[1:]
+	}
+	return buf, nil
+}
+
+// parseRFC3164 parses a BSD syslog message: TIMESTAMP HOSTNAME TAG[PID]: MSG
+// The message may be all that follows the priority.
+func (m *message) parseRFC3164(buf []byte, now time.Time) error {
+	// the timestamp is "Mmm dd hh:mm:ss", the day is padded with a space
+	if len(buf) > len(time.Stamp) && buf[len(time.Stamp)] == ' ' {
+		ts, err := time.ParseInLocation(time.Stamp, string(buf[:len(time.Stamp)]),
+			now.Location())
+		if err == nil {
+			ts = ts.AddDate(now.Year(), 0, 0)
+			// a message from the end of last year
+			if ts.After(now.AddDate(0, 0, 1)) {
+				ts = ts.AddDate(-1, 0, 0)
+			}
+			m.timestamp = ts
+			buf = buf[len(time.Stamp)+1:]
+
+			var hostname []byte
+			if hostname, buf = nextToken(buf); hostname != nil {
+				m.hostname = string(hostname)
+			}
+		}
+	}
+
+	// the tag is alphanumeric, it ends with the pid in brackets or a colon
+	n := 0
+	for n < len(buf) && n <= 32 && isAlphaNum(buf[n]) {
+		n++
+	}
+	if n > 0 && n < len(buf) && (buf[n] == '[' || buf[n] == ':') {
+		tag := string(buf[:n])
+		rest := buf[n:]
+		if rest[0] == '[' {
+			end := bytes.IndexByte(rest, ']')
+			if end > 0 {
+				m.procid = string(rest[1:end])
+				rest = rest[end+1:]
+			}
+		}
+		if len(rest) > 0 && rest[0] == ':' {
+			m.appname = tag
+			buf = bytes.TrimPrefix(rest[1:], []byte(" "))
+		} else {
+			m.procid = ""
+		}
+	}
+
+	m.message = string(buf)
+	return nil
+}
+
+// nextToken returns the bytes up to the next space and the bytes after it,
+// or a nil token at the end of the buffer.
+func nextToken(buf []byte) ([]byte, []byte) {
+	if len(buf) == 0 {
+		return nil, buf
+	}
+	n := bytes.IndexByte(buf, ' ')
+	if n < 0 {
+		return buf, nil
+	}
+	return buf[:n], buf[n+1:]
+}
+
+func optional(s string) string {
+	if s == nilValue {
+		return ""
+	}
+	return s
+}
+
+func digits(buf []byte) int {
+	n := 0
+	for n < len(buf) && buf[n] >= '0' && buf[n] <= '9' {
+		n++
+	}
+	return n
+}
+
+func isAlphaNum(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '-' || c == '_' || c == '.' || c == '/'
+}
@@ -0,0 +1,111 @@
+package syslog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2018, time.January, 10, 12, 0, 0, 0, time.UTC)
+
+func TestParseRFC5424(t *testing.T) {
+	m, err := parse([]byte(`<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut="3" eventSource="Application" eventID="1011"][examplePriority@32473 class="high"] `+"\xef\xbb\xbf"+`An application event log entry...`), now)
+	require.NoError(t, err)
+
+	assert.Equal(t, &message{
+		facility:  20,
+		severity:  5,
+		version:   1,
+		timestamp: time.Date(2003, time.October, 11, 22, 14, 15, 3000000, time.UTC),
+		hostname:  "mymachine.example.com",
+		appname:   "evntslog",
+		msgid:     "ID47",
+		structuredData: map[string]map[string]string{
+			"exampleSDID@32473": {
+				"iut":         "3",
+				"eventSource": "Application",
+				"eventID":     "1011",
+			},
+			"examplePriority@32473": {
+				"class": "high",
+			},
+		},
+		message: "An application event log entry...",
+	}, m)
+}
+
+func TestParseRFC5424NilValues(t *testing.T) {
+	m, err := parse([]byte("<34>1 - - - - - -\n"), now)
+	require.NoError(t, err)
+	assert.Equal(t, &message{facility: 4, severity: 2, version: 1}, m)
+}
+
+func TestParseRFC5424StructuredDataEscapes(t *testing.T) {
+	m, err := parse([]byte(`<14>1 2018-01-10T13:00:00+01:00 host app 123 - [meta a="x\"y\]z\\" b="c:\d"][empty] message`), now)
+	require.NoError(t, err)
+
+	assert.Equal(t, "123", m.procid)
+	assert.Equal(t, map[string]map[string]string{
+		"meta":  {"a": `x"y]z\`, "b": `c:\d`},
+		"empty": {},
+	}, m.structuredData)
+	assert.Equal(t, "message", m.message)
+	assert.True(t, now.Equal(m.timestamp))
+}
+
+func TestParseRFC5424Errors(t *testing.T) {
+	for _, msg := range []string{
+		"",
+		"no priority",
+		"<192>1 - - - - - -",
+		"<14>1 - - - -",
+		"<14>1 yesterday - - - - -",
+		`<14>1 - - - - - [id a="b"`,
+		`<14>1 - - - - - [id a="b]`,
+		`<14>1 - - - - - [id a]`,
+		`<14>1 - - - - - [id]message`,
+	} {
+		_, err := parse([]byte(msg), now)
+		assert.Error(t, err, msg)
+	}
+}
+
+func TestParseRFC3164(t *testing.T) {
+	m, err := parse([]byte("<34>Oct 11 22:14:15 mymachine su[1234]: 'su root' failed for lonvick on /dev/pts/8"), now)
+	require.NoError(t, err)
+
+	assert.Equal(t, &message{
+		facility:  4,
+		severity:  2,
+		timestamp: time.Date(2017, time.October, 11, 22, 14, 15, 0, time.UTC),
+		hostname:  "mymachine",
+		appname:   "su",
+		procid:    "1234",
+		message:   "'su root' failed for lonvick on /dev/pts/8",
+	}, m)
+}
+
+func TestParseRFC3164Variants(t *testing.T) {
+	m, err := parse([]byte("<13>Jan  9 08:30:00 host cron: job done"), now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2018, time.January, 9, 8, 30, 0, 0, time.UTC), m.timestamp)
+	assert.Equal(t, "host", m.hostname)
+	assert.Equal(t, "cron", m.appname)
+	assert.Equal(t, "", m.procid)
+	assert.Equal(t, "job done", m.message)
+
+	// only a message
+	m, err = parse([]byte("<13>just a message [with brackets]"), now)
+	require.NoError(t, err)
+	assert.Equal(t, &message{facility: 1, severity: 5, message: "just a message [with brackets]"}, m)
+}
+
+func TestParseRFC3164Location(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	m, err := parse([]byte("<13>Jan 10 13:00:00 host cron: job done"), now.In(loc))
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2018, time.January, 10, 13, 0, 0, 0, loc), m.timestamp)
+	assert.True(t, m.timestamp.Equal(now.Add(-time.Hour)))
+}
@@ -0,0 +1,500 @@
+package syslog
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	tlsint "github.com/influxdata/telegraf/internal/tls"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const (
+	defaultServiceAddress = "tcp://:6514"
+	// maxMessageLength is the largest message accepted from streams, and
+	// the largest datagram.
+	maxMessageLength = 64 * 1024
+	// maxLengthDigits is the number of digits of maxMessageLength, the
+	// longest octet counting frame length.
+	maxLengthDigits = 5
+)
+
+// Framing of the messages in stream sockets (RFC6587).
+const (
+	octetCounting  = "octet-counting"
+	nonTransparent = "non-transparent"
+)
+
+type Syslog struct {
+	ServiceAddress  string
+	MaxConnections  int
+	ReadTimeout     *internal.Duration
+	KeepAlivePeriod *internal.Duration
+	// Framing is the framing of the messages in stream sockets, one of
+	// octet-counting or non-transparent (newline delimited).
+	Framing string
+	// SDParamSeparator joins the SD-ID and the parameter names of the
+	// structured data into field names.
+	SDParamSeparator string `toml:"sdparam_separator"`
+	// Timezone is the location of the timestamps of RFC3164 messages, which
+	// have no offset.
+	Timezone string `toml:"timezone"`
+	tlsint.ServerConfig
+
+	acc telegraf.Accumulator
+	io.Closer
+	wg sync.WaitGroup
+
+	// now returns the receive time of the messages, it is replaced in tests.
+	now      func() time.Time
+	location *time.Location
+}
+
+var sampleConfig = `
+  ## URL to listen on
+  # service_address = "tcp://:6514"
+  # service_address = "tcp4://:6514"
+  # service_address = "udp://:6514"
+  # service_address = "unix:///tmp/telegraf-syslog.sock"
+  # service_address = "unixgram:///tmp/telegraf-syslog.sock"
+
+  ## Maximum number of concurrent connections.
+  ## Only applies to stream sockets (e.g. TCP).
+  ## 0 (default) is unlimited.
+  # max_connections = 1024
+
+  ## Read timeout.
+  ## Only applies to stream sockets (e.g. TCP).
+  ## 0 (default) is unlimited.
+  # read_timeout = "30s"
+
+  ## Period between keep alive probes.
+  ## Only applies to TCP sockets.
+  ## 0 disables keep alive probes.
+  ## Defaults to the OS configuration.
+  # keep_alive_period = "5m"
+
+  ## Framing of the messages in stream sockets (RFC6587), either
+  ## "octet-counting" (the length of each message precedes it) or
+  ## "non-transparent" (each message ends with a newline).
+  # framing = "octet-counting"
+
+  ## Enables TLS on stream sockets (RFC5425).
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+  ## Enables client authentication if set.
+  # tls_allowed_cacerts = ["/etc/telegraf/clientca.pem"]
+
+  ## Separator of the SD-ID and parameter name in the field names of the
+  ## structured data, ie "exampleSDID@32473_iut".
+  # sdparam_separator = "_"
+
+  ## Timezone of the timestamps of RFC3164 messages, which have no offset.
+  ## "Local" (default) is the timezone of the machine, other options are
+  ## "UTC" or a name of the tz database, ie "Europe/Paris".
+  # timezone = "Local"
+`
+
+func (s *Syslog) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *Syslog) Description() string {
+	return "Accepts syslog messages following RFC5424 or RFC3164 format"
+}
+
+func (s *Syslog) Gather(_ telegraf.Accumulator) error {
+	return nil
+}
+
+func (s *Syslog) Start(acc telegraf.Accumulator) error {
+	s.acc = acc
+	if s.now == nil {
+		s.now = time.Now
+	}
+
+	switch s.Framing {
+	case "":
+		s.Framing = octetCounting
+	case octetCounting, nonTransparent:
+	default:
+		return fmt.Errorf("invalid framing %q, must be one of: %s, %s",
+			s.Framing, octetCounting, nonTransparent)
+	}
+
+	s.location = time.Local
+	if s.Timezone != "" {
+		loc, err := time.LoadLocation(s.Timezone)
+		if err != nil {
+			return fmt.Errorf("invalid timezone %q: %s", s.Timezone, err)
+		}
+		s.location = loc
+	}
+
+	spl := strings.SplitN(s.ServiceAddress, "://", 2)
+	if len(spl) != 2 {
+		return fmt.Errorf("invalid service address: %s", s.ServiceAddress)
+	}
+
+	tlsCfg, err := s.ServerConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+
+	if spl[0] == "unix" || spl[0] == "unixgram" {
+		// ignore the error, listening fails if the file can't be removed
+		os.Remove(spl[1])
+	}
+
+	switch spl[0] {
+	case "tcp", "tcp4", "tcp6", "unix":
+		l, err := net.Listen(spl[0], spl[1])
+		if err != nil {
+			return err
+		}
+		sl := &streamListener{
+			Listener:    l,
+			Syslog:      s,
+			tlsConfig:   tlsCfg,
+			connections: make(map[net.Conn]struct{}),
+		}
+		s.Closer = sl
+		s.wg.Add(1)
+		go sl.listen()
+	case "udp", "udp4", "udp6", "unixgram":
+		if tlsCfg != nil {
+			return fmt.Errorf("TLS is not supported on %s sockets", spl[0])
+		}
+		pc, err := net.ListenPacket(spl[0], spl[1])
+		if err != nil {
+			return err
+		}
+		pl := &packetListener{
+			PacketConn: pc,
+			Syslog:     s,
+		}
+		s.Closer = pl
+		s.wg.Add(1)
+		go pl.listen()
+	default:
+		return fmt.Errorf("unknown protocol '%s' in '%s'", spl[0], s.ServiceAddress)
+	}
+
+	if spl[0] == "unix" || spl[0] == "unixgram" {
+		s.Closer = unixCloser{path: spl[1], closer: s.Closer}
+	}
+
+	return nil
+}
+
+func (s *Syslog) Stop() {
+	if s.Closer != nil {
+		s.Close()
+		s.Closer = nil
+	}
+	s.wg.Wait()
+}
+
+// addMessage parses a message and adds its metric.
+func (s *Syslog) addMessage(buf []byte, addr net.Addr) {
+	now := s.now().In(s.location)
+	m, err := parse(buf, now)
+	if err != nil {
+		s.acc.AddError(fmt.Errorf("unable to parse syslog message: %s", err))
+		return
+	}
+
+	tags := map[string]string{
+		"facility": facilityName(m.facility),
+		"severity": severities[m.severity],
+	}
+	if m.hostname != "" {
+		tags["hostname"] = m.hostname
+	}
+	if m.appname != "" {
+		tags["appname"] = m.appname
+	}
+	if source := sourceHost(addr); source != "" {
+		tags["source"] = source
+	}
+
+	fields := map[string]interface{}{
+		"facility_code": m.facility,
+		"severity_code": m.severity,
+	}
+	if m.version > 0 {
+		fields["version"] = m.version
+	}
+	if m.procid != "" {
+		fields["procid"] = m.procid
+	}
+	if m.msgid != "" {
+		fields["msgid"] = m.msgid
+	}
+	if m.message != "" {
+		fields["message"] = m.message
+	}
+	for id, params := range m.structuredData {
+		if len(params) == 0 {
+			fields[id] = true
+			continue
+		}
+		for name, value := range params {
+			fields[id+s.SDParamSeparator+name] = value
+		}
+	}
+
+	timestamp := m.timestamp
+	if timestamp.IsZero() {
+		timestamp = now
+	}
+	s.acc.AddFields("syslog", fields, tags, timestamp)
+}
+
+type streamListener struct {
+	net.Listener
+	*Syslog
+
+	// tlsConfig is set when the connections are to be wrapped in TLS.
+	tlsConfig *tls.Config
+
+	connections    map[net.Conn]struct{}
+	connectionsMtx sync.Mutex
+}
+
+func (sl *streamListener) listen() {
+	defer sl.wg.Done()
+
+	for {
+		c, err := sl.Accept()
+		if err != nil {
+			if !strings.HasSuffix(err.Error(), ": use of closed network connection") {
+				sl.acc.AddError(err)
+			}
+			break
+		}
+
+		sl.connectionsMtx.Lock()
+		if sl.MaxConnections > 0 && len(sl.connections) >= sl.MaxConnections {
+			sl.connectionsMtx.Unlock()
+			c.Close()
+			continue
+		}
+		sl.connections[c] = struct{}{}
+		sl.connectionsMtx.Unlock()
+
+		if err := sl.setKeepAlive(c); err != nil {
+			sl.acc.AddError(fmt.Errorf("unable to configure keep alive (%s): %s", sl.ServiceAddress, err))
+		}
+
+		sl.wg.Add(1)
+		go sl.read(c)
+	}
+
+	sl.connectionsMtx.Lock()
+	for c := range sl.connections {
+		c.Close()
+	}
+	sl.connectionsMtx.Unlock()
+}
+
+func (sl *streamListener) setKeepAlive(c net.Conn) error {
+	if sl.KeepAlivePeriod == nil {
+		return nil
+	}
+	tcpc, ok := c.(*net.TCPConn)
+	if !ok {
+		return fmt.Errorf("cannot set keep alive on a %s socket", strings.SplitN(sl.ServiceAddress, "://", 2)[0])
+	}
+	if sl.KeepAlivePeriod.Duration == 0 {
+		return tcpc.SetKeepAlive(false)
+	}
+	if err := tcpc.SetKeepAlive(true); err != nil {
+		return err
+	}
+	return tcpc.SetKeepAlivePeriod(sl.KeepAlivePeriod.Duration)
+}
+
+func (sl *streamListener) removeConnection(c net.Conn) {
+	sl.connectionsMtx.Lock()
+	delete(sl.connections, c)
+	sl.connectionsMtx.Unlock()
+}
+
+// read reads the messages of the connection c, as accepted by the listener.
+func (sl *streamListener) read(c net.Conn) {
+	defer sl.wg.Done()
+	defer sl.removeConnection(c)
+
+	if sl.tlsConfig != nil {
+		c = tls.Server(c, sl.tlsConfig)
+	}
+	defer c.Close()
+
+	var next func() ([]byte, error)
+	if sl.Framing == octetCounting {
+		r := bufio.NewReader(c)
+		next = func() ([]byte, error) {
+			return readOctetCounted(r)
+		}
+	} else {
+		next = nonTransparentReader(c)
+	}
+
+	for {
+		if sl.ReadTimeout != nil && sl.ReadTimeout.Duration > 0 {
+			c.SetReadDeadline(time.Now().Add(sl.ReadTimeout.Duration))
+		}
+
+		buf, err := next()
+		if len(buf) > 0 {
+			sl.addMessage(buf, c.RemoteAddr())
+		}
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			if nerr, ok := err.(net.Error); ok && nerr.Timeout() {
+				log.Printf("D! Timeout in plugin [input.syslog]: %s", err)
+			} else if !strings.HasSuffix(err.Error(), ": use of closed network connection") {
+				sl.acc.AddError(err)
+			}
+			break
+		}
+	}
+}
+
+// readOctetCounted reads a message preceded by its length and a space.
+func readOctetCounted(r *bufio.Reader) ([]byte, error) {
+	// the length is read digit by digit, so that a peer that never sends
+	// the space cannot make it grow
+	var length []byte
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			if err == io.EOF && len(length) > 0 {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		if b == ' ' {
+			break
+		}
+		length = append(length, b)
+		if b < '0' || b > '9' || len(length) > maxLengthDigits {
+			return nil, fmt.Errorf("invalid octet counting frame length %q", length)
+		}
+	}
+
+	n, err := strconv.Atoi(string(length))
+	if err != nil || n <= 0 {
+		return nil, fmt.Errorf("invalid octet counting frame length %q", length)
+	}
+	if n > maxMessageLength {
+		return nil, fmt.Errorf("message length %d exceeds the maximum of %d", n, maxMessageLength)
+	}
+
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return buf, nil
+}
+
+// nonTransparentReader returns a function reading the messages ending with a
+// newline, the message at the end of the stream may be without it. A message
+// longer than maxMessageLength is an error. The returned message is only
+// valid until the next call.
+func nonTransparentReader(r io.Reader) func() ([]byte, error) {
+	scnr := bufio.NewScanner(r)
+	scnr.Buffer(make([]byte, 4096), maxMessageLength)
+	return func() ([]byte, error) {
+		for scnr.Scan() {
+			buf := scnr.Bytes()
+			// skip empty lines
+			if strings.TrimSpace(string(buf)) == "" {
+				continue
+			}
+			return buf, nil
+		}
+		if err := scnr.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+}
+
+type packetListener struct {
+	net.PacketConn
+	*Syslog
+}
+
+// listen reads the datagrams, each of them is a message (RFC5426).
+func (pl *packetListener) listen() {
+	defer pl.wg.Done()
+
+	buf := make([]byte, maxMessageLength)
+	for {
+		n, addr, err := pl.ReadFrom(buf)
+		if err != nil {
+			if !strings.HasSuffix(err.Error(), ": use of closed network connection") {
+				pl.acc.AddError(err)
+			}
+			break
+		}
+		pl.addMessage(buf[:n], addr)
+	}
+}
+
+type unixCloser struct {
+	path   string
+	closer io.Closer
+}
+
+func (uc unixCloser) Close() error {
+	err := uc.closer.Close()
+	os.Remove(uc.path) // ignore error
+	return err
+}
+
+func facilityName(facility int) string {
+	if facility < len(facilities) {
+		return facilities[facility]
+	}
+	return strconv.Itoa(facility)
+}
+
+// sourceHost returns the host of the sender, there is none for unix sockets.
+func sourceHost(addr net.Addr) string {
+	if addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return ""
+	}
+	return host
+}
+
+func init() {
+	inputs.Add("syslog", func() telegraf.Input {
+		return &Syslog{
+			ServiceAddress:   defaultServiceAddress,
+			Framing:          octetCounting,
+			SDParamSeparator: "_",
+		}
+	})
+}
@@ -0,0 +1,280 @@
+package syslog
+
+import (
+	"bytes"
+	"crypto/tls"
+	"net"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var pki = testutil.NewPKI("../../../testutil/pki")
+
+const (
+	rfc5424Message = `<29>1 2018-01-10T11:00:00Z web1 nginx 42 access [origin ip="10.0.0.1"][reload] started`
+	rfc3164Message = `<13>Jan 10 11:00:01 web2 cron[7]: job done`
+)
+
+func newSyslog(address string) *Syslog {
+	return &Syslog{
+		ServiceAddress:   address,
+		SDParamSeparator: "_",
+		now:              func() time.Time { return now },
+	}
+}
+
+func octetCounted(msg string) string {
+	return strconv.Itoa(len(msg)) + " " + msg
+}
+
+func assertMetrics(t *testing.T, acc *testutil.Accumulator, source string) {
+	acc.Wait(2)
+
+	tags := map[string]string{
+		"facility": "daemon",
+		"severity": "notice",
+		"hostname": "web1",
+		"appname":  "nginx",
+	}
+	if source != "" {
+		tags["source"] = source
+	}
+	acc.AssertContainsTaggedFields(t, "syslog",
+		map[string]interface{}{
+			"facility_code": 3,
+			"severity_code": 5,
+			"version":       1,
+			"procid":        "42",
+			"msgid":         "access",
+			"origin_ip":     "10.0.0.1",
+			"reload":        true,
+			"message":       "started",
+		},
+		tags)
+	assert.True(t, acc.HasTimestamp("syslog", time.Date(2018, time.January, 10, 11, 0, 0, 0, time.UTC)))
+
+	tags = map[string]string{
+		"facility": "user",
+		"severity": "notice",
+		"hostname": "web2",
+		"appname":  "cron",
+	}
+	if source != "" {
+		tags["source"] = source
+	}
+	acc.AssertContainsTaggedFields(t, "syslog",
+		map[string]interface{}{
+			"facility_code": 1,
+			"severity_code": 5,
+			"procid":        "7",
+			"message":       "job done",
+		},
+		tags)
+}
+
+func TestSyslogTCPOctetCounting(t *testing.T) {
+	s := newSyslog("tcp://127.0.0.1:0")
+	acc := &testutil.Accumulator{}
+	require.NoError(t, s.Start(acc))
+	defer s.Stop()
+
+	client, err := net.Dial("tcp", s.Closer.(net.Listener).Addr().String())
+	require.NoError(t, err)
+	defer client.Close()
+
+	_, err = client.Write([]byte(octetCounted(rfc5424Message) + octetCounted(rfc3164Message)))
+	require.NoError(t, err)
+
+	assertMetrics(t, acc, "127.0.0.1")
+}
+
+func TestSyslogTCPNonTransparent(t *testing.T) {
+	s := newSyslog("tcp://127.0.0.1:0")
+	s.Framing = "non-transparent"
+	acc := &testutil.Accumulator{}
+	require.NoError(t, s.Start(acc))
+	defer s.Stop()
+
+	client, err := net.Dial("tcp", s.Closer.(net.Listener).Addr().String())
+	require.NoError(t, err)
+
+	_, err = client.Write([]byte(rfc5424Message + "\n\n" + rfc3164Message))
+	require.NoError(t, err)
+	// the last message ends with the stream
+	client.Close()
+
+	assertMetrics(t, acc, "127.0.0.1")
+}
+
+func TestSyslogTCPTLS(t *testing.T) {
+	s := newSyslog("tcp://127.0.0.1:0")
+	s.ServerConfig = *pki.TLSServerConfig()
+	acc := &testutil.Accumulator{}
+	require.NoError(t, s.Start(acc))
+	defer s.Stop()
+
+	tlsCfg, err := pki.TLSClientConfig().TLSConfig()
+	require.NoError(t, err)
+
+	client, err := tls.Dial("tcp", s.Closer.(net.Listener).Addr().String(), tlsCfg)
+	require.NoError(t, err)
+	defer client.Close()
+
+	_, err = client.Write([]byte(octetCounted(rfc5424Message) + octetCounted(rfc3164Message)))
+	require.NoError(t, err)
+
+	assertMetrics(t, acc, "127.0.0.1")
+}
+
+func TestSyslogTCPInvalidFrame(t *testing.T) {
+	s := newSyslog("tcp://127.0.0.1:0")
+	acc := &testutil.Accumulator{}
+	require.NoError(t, s.Start(acc))
+	defer s.Stop()
+
+	client, err := net.Dial("tcp", s.Closer.(net.Listener).Addr().String())
+	require.NoError(t, err)
+	defer client.Close()
+
+	_, err = client.Write([]byte(rfc5424Message))
+	require.NoError(t, err)
+
+	acc.WaitError(1)
+	acc.Lock()
+	defer acc.Unlock()
+	assert.Contains(t, acc.Errors[0].Error(), "invalid octet counting frame length")
+}
+
+func TestSyslogTCPFrameTooLong(t *testing.T) {
+	// neither the space after the length nor the newline is ever sent
+	frames := map[string][]byte{
+		octetCounting:  []byte("1234567890"),
+		nonTransparent: bytes.Repeat([]byte("a"), maxMessageLength+1),
+	}
+	for framing, frame := range frames {
+		s := newSyslog("tcp://127.0.0.1:0")
+		s.Framing = framing
+		acc := &testutil.Accumulator{}
+		require.NoError(t, s.Start(acc))
+
+		client, err := net.Dial("tcp", s.Closer.(net.Listener).Addr().String())
+		require.NoError(t, err)
+
+		_, err = client.Write(frame)
+		require.NoError(t, err)
+
+		acc.WaitError(1)
+		acc.Lock()
+		if framing == octetCounting {
+			assert.Contains(t, acc.Errors[0].Error(), "invalid octet counting frame length")
+		} else {
+			assert.Contains(t, acc.Errors[0].Error(), "token too long")
+		}
+		acc.Unlock()
+
+		client.Close()
+		s.Stop()
+	}
+}
+
+func TestSyslogUDP(t *testing.T) {
+	s := newSyslog("udp://127.0.0.1:0")
+	acc := &testutil.Accumulator{}
+	require.NoError(t, s.Start(acc))
+	defer s.Stop()
+
+	client, err := net.Dial("udp", s.Closer.(net.PacketConn).LocalAddr().String())
+	require.NoError(t, err)
+	defer client.Close()
+
+	_, err = client.Write([]byte(rfc5424Message))
+	require.NoError(t, err)
+	_, err = client.Write([]byte(rfc3164Message + "\n"))
+	require.NoError(t, err)
+
+	assertMetrics(t, acc, "127.0.0.1")
+}
+
+func TestSyslogUnix(t *testing.T) {
+	os.Create("/tmp/telegraf_syslog_test.sock")
+	s := newSyslog("unix:///tmp/telegraf_syslog_test.sock")
+	s.Framing = "non-transparent"
+	acc := &testutil.Accumulator{}
+	require.NoError(t, s.Start(acc))
+	defer s.Stop()
+
+	client, err := net.Dial("unix", "/tmp/telegraf_syslog_test.sock")
+	require.NoError(t, err)
+	defer client.Close()
+
+	_, err = client.Write([]byte(rfc5424Message + "\n" + rfc3164Message + "\n"))
+	require.NoError(t, err)
+
+	assertMetrics(t, acc, "")
+}
+
+func TestSyslogUnixConnections(t *testing.T) {
+	os.Create("/tmp/telegraf_syslog_test.sock")
+	s := newSyslog("unix:///tmp/telegraf_syslog_test.sock")
+	s.Framing = "non-transparent"
+	acc := &testutil.Accumulator{}
+	require.NoError(t, s.Start(acc))
+	defer s.Stop()
+
+	// unix socket peers have the same remote address
+	for i := 0; i < 2; i++ {
+		client, err := net.Dial("unix", "/tmp/telegraf_syslog_test.sock")
+		require.NoError(t, err)
+		defer client.Close()
+
+		_, err = client.Write([]byte(rfc3164Message + "\n"))
+		require.NoError(t, err)
+	}
+	acc.Wait(2)
+
+	sl := s.Closer.(unixCloser).closer.(*streamListener)
+	sl.connectionsMtx.Lock()
+	assert.Len(t, sl.connections, 2)
+	sl.connectionsMtx.Unlock()
+}
+
+func TestSyslogTimezone(t *testing.T) {
+	s := newSyslog("udp://127.0.0.1:0")
+	s.Timezone = "UTC"
+	acc := &testutil.Accumulator{}
+	require.NoError(t, s.Start(acc))
+	defer s.Stop()
+
+	client, err := net.Dial("udp", s.Closer.(net.PacketConn).LocalAddr().String())
+	require.NoError(t, err)
+	defer client.Close()
+
+	_, err = client.Write([]byte(rfc3164Message + "\n"))
+	require.NoError(t, err)
+
+	acc.Wait(1)
+	assert.True(t, acc.HasTimestamp("syslog", time.Date(2018, time.January, 10, 11, 0, 1, 0, time.UTC)))
+}
+
+func TestSyslogInvalidConfig(t *testing.T) {
+	s := newSyslog("udp://127.0.0.1:0")
+	s.ServerConfig = *pki.TLSServerConfig()
+	require.Error(t, s.Start(&testutil.Accumulator{}))
+
+	s = newSyslog("tcp://127.0.0.1:0")
+	s.Framing = "lines"
+	require.Error(t, s.Start(&testutil.Accumulator{}))
+
+	s = newSyslog("127.0.0.1:6514")
+	require.Error(t, s.Start(&testutil.Accumulator{}))
+
+	s = newSyslog("udp://127.0.0.1:0")
+	s.Timezone = "Nowhere/Special"
+	require.Error(t, s.Start(&testutil.Accumulator{}))
+}
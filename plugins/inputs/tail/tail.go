@@ -25,10 +25,10 @@ type Tail struct {
 	Pipe          bool
 	WatchMethod   string
 
-	tailers []*tail.Tail
-	parser  parsers.Parser
-	wg      sync.WaitGroup
-	acc     telegraf.Accumulator
+	tailers    []*tail.Tail
+	parserFunc parsers.ParserFunc
+	wg         sync.WaitGroup
+	acc        telegraf.Accumulator
 
 	sync.Mutex
 }
@@ -103,6 +103,14 @@ func (t *Tail) Start(acc telegraf.Accumulator) error {
 			t.acc.AddError(fmt.Errorf("E! Error Glob %s failed to compile, %s", filepath, err))
 		}
 		for file, _ := range g.Match() {
+			// parsers keep state, ie the header of a file, and are not
+			// safe for concurrent use, each file has its own
+			parser, err := t.parserFunc()
+			if err != nil {
+				acc.AddError(fmt.Errorf("E! Error creating parser for %s: %s", file, err))
+				continue
+			}
+
 			tailer, err := tail.TailFile(file,
 				tail.Config{
 					ReOpen:    true,
@@ -119,7 +127,7 @@ func (t *Tail) Start(acc telegraf.Accumulator) error {
 			}
 			// create a goroutine for each "tailer"
 			t.wg.Add(1)
-			go t.receiver(tailer)
+			go t.receiver(parser, tailer)
 			t.tailers = append(t.tailers, tailer)
 		}
 	}
@@ -129,7 +137,7 @@ func (t *Tail) Start(acc telegraf.Accumulator) error {
 
 // this is launched as a goroutine to continuously watch a tailed logfile
 // for changes, parse any incoming msgs, and add to the accumulator.
-func (t *Tail) receiver(tailer *tail.Tail) {
+func (t *Tail) receiver(parser parsers.Parser, tailer *tail.Tail) {
 	defer t.wg.Done()
 
 	var m telegraf.Metric
@@ -144,9 +152,12 @@ func (t *Tail) receiver(tailer *tail.Tail) {
 		// Fix up files with Windows line endings.
 		text := strings.TrimRight(line.Text, "\r")
 
-		m, err = t.parser.ParseLine(text)
+		m, err = parser.ParseLine(text)
 		if err == nil {
-			t.acc.AddFields(m.Name(), m.Fields(), m.Tags(), m.Time())
+			// some parsers consume lines without a metric, ie csv headers
+			if m != nil {
+				t.acc.AddFields(m.Name(), m.Fields(), m.Tags(), m.Time())
+			}
 		} else {
 			t.acc.AddError(fmt.Errorf("E! Malformed log line in %s: [%s], Error: %s\n",
 				tailer.Filename, line.Text, err))
@@ -172,8 +183,8 @@ func (t *Tail) Stop() {
 	t.wg.Wait()
 }
 
-func (t *Tail) SetParser(parser parsers.Parser) {
-	t.parser = parser
+func (t *Tail) SetParserFunc(fn parsers.ParserFunc) {
+	t.parserFunc = fn
 }
 
 func init() {
@@ -5,6 +5,7 @@ import (
 	"os"
 	"runtime"
 	"testing"
+	"time"
 
 	"github.com/influxdata/telegraf/plugins/parsers"
 	"github.com/influxdata/telegraf/testutil"
@@ -23,8 +24,7 @@ func TestTailFromBeginning(t *testing.T) {
 	tt := NewTail()
 	tt.FromBeginning = true
 	tt.Files = []string{tmpfile.Name()}
-	p, _ := parsers.NewInfluxParser()
-	tt.SetParser(p)
+	tt.SetParserFunc(parsers.NewInfluxParser)
 	defer tt.Stop()
 	defer tmpfile.Close()
 
@@ -51,8 +51,7 @@ func TestTailFromEnd(t *testing.T) {
 
 	tt := NewTail()
 	tt.Files = []string{tmpfile.Name()}
-	p, _ := parsers.NewInfluxParser()
-	tt.SetParser(p)
+	tt.SetParserFunc(parsers.NewInfluxParser)
 	defer tt.Stop()
 	defer tmpfile.Close()
 
@@ -88,8 +87,7 @@ func TestTailBadLine(t *testing.T) {
 	tt := NewTail()
 	tt.FromBeginning = true
 	tt.Files = []string{tmpfile.Name()}
-	p, _ := parsers.NewInfluxParser()
-	tt.SetParser(p)
+	tt.SetParserFunc(parsers.NewInfluxParser)
 	defer tt.Stop()
 	defer tmpfile.Close()
 
@@ -114,8 +112,7 @@ func TestTailDosLineendings(t *testing.T) {
 	tt := NewTail()
 	tt.FromBeginning = true
 	tt.Files = []string{tmpfile.Name()}
-	p, _ := parsers.NewInfluxParser()
-	tt.SetParser(p)
+	tt.SetParserFunc(parsers.NewInfluxParser)
 	defer tt.Stop()
 	defer tmpfile.Close()
 
@@ -133,3 +130,48 @@ func TestTailDosLineendings(t *testing.T) {
 			"usage_idle": float64(200),
 		})
 }
+
+func TestTailMultipleFilesCSV(t *testing.T) {
+	var files []string
+	for _, content := range []string{
+		"time,value\n1500000000,1\n",
+		"# another file\ntime,value\n1500000001,2\n",
+	} {
+		tmpfile, err := ioutil.TempFile("", "")
+		require.NoError(t, err)
+		defer os.Remove(tmpfile.Name())
+		_, err = tmpfile.WriteString(content)
+		require.NoError(t, err)
+		require.NoError(t, tmpfile.Close())
+		files = append(files, tmpfile.Name())
+	}
+
+	tt := NewTail()
+	tt.FromBeginning = true
+	tt.Files = files
+	tt.SetParserFunc(func() (parsers.Parser, error) {
+		return parsers.NewParser(&parsers.Config{
+			DataFormat:         "csv",
+			MetricName:         "csv",
+			CSVComment:         "#",
+			CSVColumnNames:     []string{"time", "value"},
+			CSVTimestampColumn: "time",
+			CSVTimestampFormat: "unix",
+		})
+	})
+	defer tt.Stop()
+
+	acc := testutil.Accumulator{}
+	require.NoError(t, tt.Start(&acc))
+	require.Len(t, tt.tailers, 2)
+
+	// the header and comment lines of both files are skipped
+	acc.Wait(2)
+	assert.Empty(t, acc.Errors)
+	acc.AssertContainsFields(t, "csv", map[string]interface{}{"value": int64(1)})
+	acc.AssertContainsFields(t, "csv", map[string]interface{}{"value": int64(2)})
+	for _, m := range acc.Metrics {
+		assert.True(t, m.Time.Equal(time.Unix(1500000000, 0)) ||
+			m.Time.Equal(time.Unix(1500000001, 0)))
+	}
+}
@@ -2,6 +2,7 @@ package zipkin
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"log"
 	"net"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/influxdata/telegraf"
+	tlsint "github.com/influxdata/telegraf/internal/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/inputs/zipkin/trace"
 )
@@ -45,6 +47,12 @@ type Handler interface {
 const sampleConfig = `
   # path = "/api/v1/spans" # URL path for span data
   # port = 9411            # Port on which Telegraf listens
+
+  ## Optional TLS Config for the listener
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+  ## Enables client authentication if set.
+  # tls_allowed_cacerts = ["/etc/telegraf/clientca.pem"]
 `
 
 // Zipkin is a telegraf configuration structure for the zipkin input plugin,
@@ -54,6 +62,7 @@ type Zipkin struct {
 	ServiceAddress string
 	Port           int
 	Path           string
+	tlsint.ServerConfig
 
 	address   string
 	handler   Handler
@@ -93,12 +102,21 @@ func (z *Zipkin) Start(acc telegraf.Accumulator) error {
 		Handler: router,
 	}
 
+	tlsConfig, err := z.ServerConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+
 	addr := ":" + strconv.Itoa(z.Port)
 	ln, err := net.Listen("tcp", addr)
 	if err != nil {
 		return err
 	}
 
+	if tlsConfig != nil {
+		ln = tls.NewListener(ln, tlsConfig)
+	}
+
 	z.address = ln.Addr().String()
 	log.Printf("I! Started the zipkin listener on %s", z.address)
 
@@ -4,12 +4,15 @@ import (
 	"bytes"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestZipkinPlugin(t *testing.T) {
@@ -617,3 +620,36 @@ func postThriftData(datafile, address, contentType string) error {
 
 	return nil
 }
+
+func TestZipkinPluginTLS(t *testing.T) {
+	pki := testutil.NewPKI("../../../testutil/pki")
+	acc := testutil.Accumulator{}
+
+	z := &Zipkin{
+		Path:         "/api/v1/spans",
+		Port:         0,
+		ServerConfig: *pki.TLSServerConfig(),
+	}
+	require.NoError(t, z.Start(&acc))
+	defer z.Stop()
+
+	dat, err := ioutil.ReadFile("testdata/threespans.dat")
+	require.NoError(t, err)
+
+	tlsConfig, err := pki.TLSClientConfig().TLSConfig()
+	require.NoError(t, err)
+	client := &http.Client{
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+	}
+
+	_, port, err := net.SplitHostPort(z.address)
+	require.NoError(t, err)
+	resp, err := client.Post("https://localhost:"+port+"/api/v1/spans",
+		"application/x-thrift", bytes.NewReader(dat))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	acc.Wait(1)
+	assert.Equal(t, "zipkin", acc.Metrics[0].Measurement)
+}
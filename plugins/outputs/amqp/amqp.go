@@ -10,6 +10,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/serializers"
 
@@ -43,14 +44,7 @@ type AMQP struct {
 	// Valid options are "transient" and "persistent". default: "transient"
 	DeliveryMode string
 
-	// Path to CA file
-	SSLCA string `toml:"ssl_ca"`
-	// Path to host cert file
-	SSLCert string `toml:"ssl_cert"`
-	// Path to cert key file
-	SSLKey string `toml:"ssl_key"`
-	// Use SSL but skip chain & host verification
-	InsecureSkipVerify bool
+	tls.ClientConfig
 
 	sync.Mutex
 	c *client
@@ -99,11 +93,11 @@ var sampleConfig = `
   ## to 5s. 0s means no timeout (not recommended).
   # timeout = "5s"
 
-  ## Optional SSL Config
-  # ssl_ca = "/etc/telegraf/ca.pem"
-  # ssl_cert = "/etc/telegraf/cert.pem"
-  # ssl_key = "/etc/telegraf/key.pem"
-  ## Use SSL but skip chain & host verification
+  ## Optional TLS Config
+  # tls_ca = "/etc/telegraf/ca.pem"
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+  ## Use TLS but skip chain & host verification
   # insecure_skip_verify = false
 
   ## Data format to output.
@@ -137,8 +131,7 @@ func (q *AMQP) Connect() error {
 
 	var connection *amqp.Connection
 	// make new tls config
-	tls, err := internal.GetTLSConfig(
-		q.SSLCert, q.SSLKey, q.SSLCA, q.InsecureSkipVerify)
+	tls, err := q.ClientConfig.TLSConfig()
 	if err != nil {
 		return err
 	}
@@ -237,7 +230,7 @@ func (q *AMQP) Write(metrics []telegraf.Metric) error {
 		return fmt.Errorf("connection is not open")
 	}
 
-	outbuf := make(map[string][]byte)
+	batches := make(map[string][]telegraf.Metric)
 
 	for _, metric := range metrics {
 		var key string
@@ -247,18 +240,18 @@ func (q *AMQP) Write(metrics []telegraf.Metric) error {
 			}
 		}
 
-		buf, err := q.serializer.Serialize(metric)
+		batches[key] = append(batches[key], metric)
+	}
+
+	for key, batch := range batches {
+		buf, err := serializers.SerializeBatch(q.serializer, batch)
 		if err != nil {
 			return err
 		}
 
-		outbuf[key] = append(outbuf[key], buf...)
-	}
-
-	for key, buf := range outbuf {
 		// Note that since the channel is not in confirm mode, the absence of
 		// an error does not indicate successful delivery.
-		err := c.channel.Publish(
+		err = c.channel.Publish(
 			q.Exchange, // exchange
 			key,        // routing key
 			false,      // mandatory
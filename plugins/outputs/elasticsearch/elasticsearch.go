@@ -11,6 +11,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"gopkg.in/olivere/elastic.v5"
 )
@@ -28,11 +29,8 @@ type Elasticsearch struct {
 	ManageTemplate      bool
 	TemplateName        string
 	OverwriteTemplate   bool
-	SSLCA               string `toml:"ssl_ca"`   // Path to CA file
-	SSLCert             string `toml:"ssl_cert"` // Path to host cert file
-	SSLKey              string `toml:"ssl_key"`  // Path to cert key file
-	InsecureSkipVerify  bool   // Use SSL but skip chain & host verification
-	Client              *elastic.Client
+	tls.ClientConfig
+	Client *elastic.Client
 }
 
 var sampleConfig = `
@@ -69,11 +67,11 @@ var sampleConfig = `
   # default_tag_value = "none"
   index_name = "telegraf-%Y.%m.%d" # required.
 
-  ## Optional SSL Config
-  # ssl_ca = "/etc/telegraf/ca.pem"
-  # ssl_cert = "/etc/telegraf/cert.pem"
-  # ssl_key = "/etc/telegraf/key.pem"
-  ## Use SSL but skip chain & host verification
+  ## Optional TLS Config
+  # tls_ca = "/etc/telegraf/ca.pem"
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+  ## Use TLS but skip chain & host verification
   # insecure_skip_verify = false
 
   ## Template Config
@@ -96,7 +94,7 @@ func (a *Elasticsearch) Connect() error {
 
 	var clientOptions []elastic.ClientOptionFunc
 
-	tlsCfg, err := internal.GetTLSConfig(a.SSLCert, a.SSLKey, a.SSLCA, a.InsecureSkipVerify)
+	tlsCfg, err := a.ClientConfig.TLSConfig()
 	if err != nil {
 		return err
 	}
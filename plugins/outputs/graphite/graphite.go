@@ -10,7 +10,7 @@ import (
 	"time"
 
 	"github.com/influxdata/telegraf"
-	"github.com/influxdata/telegraf/internal"
+	tlsint "github.com/influxdata/telegraf/internal/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/serializers"
 )
@@ -23,14 +23,7 @@ type Graphite struct {
 	Timeout  int
 	conns    []net.Conn
 
-	// Path to CA file
-	SSLCA string `toml:"ssl_ca"`
-	// Path to host cert file
-	SSLCert string `toml:"ssl_cert"`
-	// Path to cert key file
-	SSLKey string `toml:"ssl_key"`
-	// Skip SSL verification
-	InsecureSkipVerify bool
+	tlsint.ClientConfig
 
 	// tls config
 	tlsConfig *tls.Config
@@ -49,11 +42,11 @@ var sampleConfig = `
   ## timeout in seconds for the write connection to graphite
   timeout = 2
 
-  ## Optional SSL Config
-  # ssl_ca = "/etc/telegraf/ca.pem"
-  # ssl_cert = "/etc/telegraf/cert.pem"
-  # ssl_key = "/etc/telegraf/key.pem"
-  ## Use SSL but skip chain & host verification
+  ## Optional TLS Config
+  # tls_ca = "/etc/telegraf/ca.pem"
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+  ## Use TLS but skip chain & host verification
   # insecure_skip_verify = false
 `
 
@@ -68,8 +61,7 @@ func (g *Graphite) Connect() error {
 
 	// Set tls config
 	var err error
-	g.tlsConfig, err = internal.GetTLSConfig(
-		g.SSLCert, g.SSLKey, g.SSLCA, g.InsecureSkipVerify)
+	g.tlsConfig, err = g.ClientConfig.TLSConfig()
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,248 @@
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/tls"
+	"github.com/influxdata/telegraf/plugins/outputs"
+	"github.com/influxdata/telegraf/plugins/serializers"
+	"github.com/influxdata/telegraf/plugins/serializers/json"
+)
+
+const (
+	defaultURL         = "http://127.0.0.1:8080/telegraf"
+	defaultMethod      = "POST"
+	defaultContentType = "text/plain; charset=utf-8"
+	jsonContentType    = "application/json"
+)
+
+type HTTP struct {
+	URL             string            `toml:"url"`
+	Method          string            `toml:"method"`
+	Timeout         internal.Duration `toml:"timeout"`
+	Headers         map[string]string `toml:"headers"`
+	Username        string            `toml:"username"`
+	Password        string            `toml:"password"`
+	ClientID        string            `toml:"client_id"`
+	ClientSecret    string            `toml:"client_secret"`
+	TokenURL        string            `toml:"token_url"`
+	Scopes          []string          `toml:"scopes"`
+	ContentEncoding string            `toml:"content_encoding"`
+	tls.ClientConfig
+
+	client      *http.Client
+	credentials *clientCredentials
+	serializer  serializers.Serializer
+}
+
+var sampleConfig = `
+  ## URL is the address to send metrics to
+  url = "http://127.0.0.1:8080/telegraf"
+
+  ## Timeout for HTTP message
+  # timeout = "5s"
+
+  ## HTTP method, one of: "POST" or "PUT"
+  # method = "POST"
+
+  ## HTTP Basic Auth credentials
+  # username = "username"
+  # password = "pa$$word"
+
+  ## OAuth2 Client Credentials Grant
+  # client_id = "clientid"
+  # client_secret = "secret"
+  # token_url = "https://identityprovider/oauth2/v1/token"
+  # scopes = ["urn:opc:idm:__myscopes__"]
+
+  ## Optional TLS Config
+  # tls_ca = "/etc/telegraf/ca.pem"
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+  ## Use TLS but skip chain & host verification
+  # insecure_skip_verify = false
+
+  ## Compress each HTTP request payload using GZIP.
+  # content_encoding = "gzip"
+
+  ## Additional HTTP headers
+  # [outputs.http.headers]
+  #   # Defaults to "application/json" for the json data_format
+  #   Content-Type = "text/plain; charset=utf-8"
+
+  ## Data format to output.
+  ## Each data format has its own unique set of configuration options, read
+  ## more about them here:
+  ## https://github.com/influxdata/telegraf/blob/master/docs/DATA_FORMATS_OUTPUT.md
+  # data_format = "influx"
+
+  ## Batch format of the json data format, the metrics of a write are sent
+  ## as newline delimited objects ("lines"), an array of objects ("array"),
+  ## or an object with the array in its "metrics" key ("object").
+  # json_batch_format = "lines"
+`
+
+func (h *HTTP) SetSerializer(serializer serializers.Serializer) {
+	h.serializer = serializer
+}
+
+func (h *HTTP) SampleConfig() string {
+	return sampleConfig
+}
+
+func (h *HTTP) Description() string {
+	return "A plugin that can transmit metrics over HTTP"
+}
+
+func (h *HTTP) Connect() error {
+	if h.Method == "" {
+		h.Method = defaultMethod
+	}
+	h.Method = strings.ToUpper(h.Method)
+	if h.Method != "POST" && h.Method != "PUT" {
+		return fmt.Errorf("invalid method [%s] %s", h.URL, h.Method)
+	}
+
+	switch h.ContentEncoding {
+	case "", "identity", "gzip":
+	default:
+		return fmt.Errorf("invalid content_encoding %q, must be one of: identity, gzip",
+			h.ContentEncoding)
+	}
+
+	if h.Timeout.Duration == 0 {
+		h.Timeout.Duration = 5 * time.Second
+	}
+
+	tlsCfg, err := h.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+
+	h.client = &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsCfg,
+		},
+		Timeout: h.Timeout.Duration,
+	}
+
+	if h.ClientID != "" || h.ClientSecret != "" || h.TokenURL != "" {
+		if h.ClientID == "" || h.ClientSecret == "" || h.TokenURL == "" {
+			return fmt.Errorf("client_id, client_secret and token_url are all " +
+				"required for OAuth2 authentication")
+		}
+		h.credentials = &clientCredentials{
+			tokenURL:     h.TokenURL,
+			clientID:     h.ClientID,
+			clientSecret: h.ClientSecret,
+			scopes:       h.Scopes,
+			client:       h.client,
+		}
+	}
+	return nil
+}
+
+func (h *HTTP) Close() error {
+	return nil
+}
+
+// Write sends the metrics in a single request, so batches are as large as
+// the metric_batch_size of the output.
+func (h *HTTP) Write(metrics []telegraf.Metric) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	body, err := serializers.SerializeBatch(h.serializer, metrics)
+	if err != nil {
+		return fmt.Errorf("could not serialize metrics: %s", err)
+	}
+	return h.write(body)
+}
+
+func (h *HTTP) write(body []byte) error {
+	if h.ContentEncoding == "gzip" {
+		var buf bytes.Buffer
+		gw := gzip.NewWriter(&buf)
+		if _, err := gw.Write(body); err != nil {
+			return err
+		}
+		if err := gw.Close(); err != nil {
+			return err
+		}
+		body = buf.Bytes()
+	}
+
+	req, err := http.NewRequest(h.Method, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("User-Agent", "Telegraf")
+	req.Header.Set("Content-Type", h.contentType())
+	if h.ContentEncoding == "gzip" {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for k, v := range h.Headers {
+		if strings.ToLower(k) == "host" {
+			req.Host = v
+		} else {
+			req.Header.Set(k, v)
+		}
+	}
+
+	if h.Username != "" || h.Password != "" {
+		req.SetBasicAuth(h.Username, h.Password)
+	} else if h.credentials != nil {
+		token, err := h.credentials.Token()
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(&io.LimitedReader{R: resp.Body, N: 512})
+		return fmt.Errorf("when writing to [%s] received status code: %d: %s",
+			h.URL, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	return nil
+}
+
+// contentType returns the default Content-Type of the requests, for the data
+// format of the serializer.
+func (h *HTTP) contentType() string {
+	if _, ok := h.serializer.(*json.JsonSerializer); ok {
+		return jsonContentType
+	}
+	return defaultContentType
+}
+
+func init() {
+	outputs.Add("http", func() telegraf.Output {
+		return &HTTP{
+			URL:    defaultURL,
+			Method: defaultMethod,
+			Timeout: internal.Duration{
+				Duration: 5 * time.Second,
+			},
+		}
+	})
+}
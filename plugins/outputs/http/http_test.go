@@ -0,0 +1,176 @@
+package http
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/serializers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getMetric(t *testing.T, value float64) telegraf.Metric {
+	m, err := metric.New("cpu",
+		map[string]string{"host": "a"},
+		map[string]interface{}{"value": value},
+		time.Unix(1500000000, 0))
+	require.NoError(t, err)
+	return m
+}
+
+func newHTTP(t *testing.T, url string, dataFormat string) *HTTP {
+	serializer, err := serializers.NewSerializer(&serializers.Config{
+		DataFormat:      dataFormat,
+		TimestampUnits:  time.Second,
+		JSONBatchFormat: "array",
+	})
+	require.NoError(t, err)
+
+	h := &HTTP{URL: url}
+	h.SetSerializer(serializer)
+	return h
+}
+
+func TestWriteLines(t *testing.T) {
+	var body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, defaultContentType, r.Header.Get("Content-Type"))
+		b, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		body = string(b)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	h := newHTTP(t, ts.URL, "influx")
+	require.NoError(t, h.Connect())
+	require.NoError(t, h.Write([]telegraf.Metric{getMetric(t, 1), getMetric(t, 2)}))
+	assert.Equal(t, "cpu,host=a value=1 1500000000000000000\n"+
+		"cpu,host=a value=2 1500000000000000000\n", body)
+}
+
+func TestWriteJSONArray(t *testing.T) {
+	var body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PUT", r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "value", r.Header.Get("X-Special-Header"))
+		b, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		body = string(b)
+	}))
+	defer ts.Close()
+
+	h := newHTTP(t, ts.URL, "json")
+	h.Method = "put"
+	h.Headers = map[string]string{
+		"X-Special-Header": "value",
+	}
+	require.NoError(t, h.Connect())
+	require.NoError(t, h.Write([]telegraf.Metric{getMetric(t, 1), getMetric(t, 2)}))
+	assert.JSONEq(t, `[
+		{"name":"cpu","tags":{"host":"a"},"fields":{"value":1},"timestamp":1500000000},
+		{"name":"cpu","tags":{"host":"a"},"fields":{"value":2},"timestamp":1500000000}
+	]`, body)
+}
+
+func TestWriteGzip(t *testing.T) {
+	var body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+		gr, err := gzip.NewReader(r.Body)
+		require.NoError(t, err)
+		b, err := ioutil.ReadAll(gr)
+		assert.NoError(t, err)
+		body = string(b)
+	}))
+	defer ts.Close()
+
+	h := newHTTP(t, ts.URL, "influx")
+	h.ContentEncoding = "gzip"
+	require.NoError(t, h.Connect())
+	require.NoError(t, h.Write([]telegraf.Metric{getMetric(t, 1)}))
+	assert.Equal(t, "cpu,host=a value=1 1500000000000000000\n", body)
+}
+
+func TestWriteBasicAuth(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "user", user)
+		assert.Equal(t, "pass", pass)
+	}))
+	defer ts.Close()
+
+	h := newHTTP(t, ts.URL, "influx")
+	h.Username = "user"
+	h.Password = "pass"
+	require.NoError(t, h.Connect())
+	require.NoError(t, h.Write([]telegraf.Metric{getMetric(t, 1)}))
+}
+
+func TestWriteOAuth2(t *testing.T) {
+	tokenRequests := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests++
+		user, pass, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "id", user)
+		assert.Equal(t, "secret", pass)
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.Form.Get("grant_type"))
+		assert.Equal(t, "a b", r.Form.Get("scope"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"token","token_type":"bearer","expires_in":3600}`))
+	})
+	mux.HandleFunc("/write", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	h := newHTTP(t, ts.URL+"/write", "influx")
+	h.ClientID = "id"
+	h.ClientSecret = "secret"
+	h.TokenURL = ts.URL + "/token"
+	h.Scopes = []string{"a", "b"}
+	require.NoError(t, h.Connect())
+	require.NoError(t, h.Write([]telegraf.Metric{getMetric(t, 1)}))
+	require.NoError(t, h.Write([]telegraf.Metric{getMetric(t, 2)}))
+
+	// the token is reused until it expires
+	assert.Equal(t, 1, tokenRequests)
+}
+
+func TestWriteStatusCode(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad request", http.StatusBadRequest)
+	}))
+	defer ts.Close()
+
+	h := newHTTP(t, ts.URL, "influx")
+	require.NoError(t, h.Connect())
+	require.Error(t, h.Write([]telegraf.Metric{getMetric(t, 1)}))
+}
+
+func TestConnectInvalidConfig(t *testing.T) {
+	h := newHTTP(t, "http://localhost", "influx")
+	h.Method = "GET"
+	require.Error(t, h.Connect())
+
+	h = newHTTP(t, "http://localhost", "influx")
+	h.ContentEncoding = "br"
+	require.Error(t, h.Connect())
+
+	h = newHTTP(t, "http://localhost", "influx")
+	h.ClientID = "id"
+	require.Error(t, h.Connect())
+}
@@ -0,0 +1,86 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// clientCredentials gets and caches access tokens with the OAuth2 client
+// credentials grant (RFC 6749, section 4.4).
+type clientCredentials struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scopes       []string
+	client       *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// expiryDelta is how long before its expiry a token is renewed, so it doesn't
+// expire in flight.
+const expiryDelta = 10 * time.Second
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// Token returns a valid access token, requesting a new one if the cached
+// token is missing or expired.
+func (c *clientCredentials) Token() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && (c.expires.IsZero() || time.Now().Before(c.expires)) {
+		return c.token, nil
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(c.scopes) > 0 {
+		form.Set("scope", strings.Join(c.scopes, " "))
+	}
+	req, err := http.NewRequest("POST", c.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(c.clientID), url.QueryEscape(c.clientSecret))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(&io.LimitedReader{R: resp.Body, N: 512})
+		return "", fmt.Errorf("token request to %s failed: %s: %s", c.tokenURL,
+			resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	var tr tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tr); err != nil {
+		return "", fmt.Errorf("invalid token response from %s: %s", c.tokenURL, err)
+	}
+	if tr.AccessToken == "" {
+		return "", fmt.Errorf("no access token in response from %s", c.tokenURL)
+	}
+
+	c.token = tr.AccessToken
+	c.expires = time.Time{}
+	if tr.ExpiresIn > 0 {
+		c.expires = time.Now().Add(time.Duration(tr.ExpiresIn)*time.Second - expiryDelta)
+	}
+	return c.token, nil
+}
@@ -6,7 +6,7 @@ import (
 	"strings"
 
 	"github.com/influxdata/telegraf"
-	"github.com/influxdata/telegraf/internal"
+	tlsint "github.com/influxdata/telegraf/internal/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/serializers"
 
@@ -44,15 +44,7 @@ type (
 		// TLS certificate authority
 		CA string
 
-		// Path to CA file
-		SSLCA string `toml:"ssl_ca"`
-		// Path to host cert file
-		SSLCert string `toml:"ssl_cert"`
-		// Path to cert key file
-		SSLKey string `toml:"ssl_key"`
-
-		// Skip SSL verification
-		InsecureSkipVerify bool
+		tlsint.ClientConfig
 
 		// SASL Username
 		SASLUsername string `toml:"sasl_username"`
@@ -134,11 +126,11 @@ var sampleConfig = `
   ##  The total number of times to retry sending a message
   max_retry = 3
 
-  ## Optional SSL Config
-  # ssl_ca = "/etc/telegraf/ca.pem"
-  # ssl_cert = "/etc/telegraf/cert.pem"
-  # ssl_key = "/etc/telegraf/key.pem"
-  ## Use SSL but skip chain & host verification
+  ## Optional TLS Config
+  # tls_ca = "/etc/telegraf/ca.pem"
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+  ## Use TLS but skip chain & host verification
   # insecure_skip_verify = false
 
   ## Optional SASL Config
@@ -200,13 +192,12 @@ func (k *Kafka) Connect() error {
 
 	// Legacy support ssl config
 	if k.Certificate != "" {
-		k.SSLCert = k.Certificate
-		k.SSLCA = k.CA
-		k.SSLKey = k.Key
+		k.TLSCert = k.Certificate
+		k.TLSCA = k.CA
+		k.TLSKey = k.Key
 	}
 
-	tlsConfig, err := internal.GetTLSConfig(
-		k.SSLCert, k.SSLKey, k.SSLCA, k.InsecureSkipVerify)
+	tlsConfig, err := k.ClientConfig.TLSConfig()
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,357 @@
+package prometheus_remote_write
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/prompb"
+	"github.com/influxdata/telegraf/internal/tls"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+var invalidNameCharRE = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+type PrometheusRemoteWrite struct {
+	URL         string            `toml:"url"`
+	Timeout     internal.Duration `toml:"timeout"`
+	Username    string            `toml:"username"`
+	Password    string            `toml:"password"`
+	BearerToken string            `toml:"bearer_token"`
+	Headers     map[string]string `toml:"headers"`
+	tls.ClientConfig
+
+	client *http.Client
+}
+
+var sampleConfig = `
+  ## URL of the remote write endpoint.
+  url = "http://127.0.0.1:9090/api/v1/write"
+
+  ## Timeout of the write requests.
+  # timeout = "5s"
+
+  ## Optional HTTP basic auth credentials, or bearer token.
+  # username = "username"
+  # password = "pa$$word"
+  # bearer_token = "token"
+
+  ## Additional HTTP headers, ie to set a tenant id.
+  # [outputs.prometheus_remote_write.headers]
+  #   X-Scope-OrgID = "telegraf"
+
+  ## Optional TLS Config
+  # tls_ca = "/etc/telegraf/ca.pem"
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+  ## Use TLS but skip chain & host verification
+  # insecure_skip_verify = false
+`
+
+func (p *PrometheusRemoteWrite) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *PrometheusRemoteWrite) Description() string {
+	return "Write metrics to a Prometheus remote write endpoint"
+}
+
+func (p *PrometheusRemoteWrite) Connect() error {
+	if p.URL == "" {
+		return fmt.Errorf("url is required")
+	}
+
+	tlsCfg, err := p.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+
+	p.client = &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsCfg,
+		},
+		Timeout: p.Timeout.Duration,
+	}
+	return nil
+}
+
+func (p *PrometheusRemoteWrite) Close() error {
+	return nil
+}
+
+// Write sends the metrics in a single request, so batches are as large as
+// the metric_batch_size of the output.
+func (p *PrometheusRemoteWrite) Write(metrics []telegraf.Metric) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	req := newWriteRequest()
+	for _, m := range metrics {
+		req.addMetric(m)
+	}
+
+	data, err := req.build().Marshal()
+	if err != nil {
+		return err
+	}
+	body := snappy.Encode(nil, data)
+
+	httpReq, err := http.NewRequest("POST", p.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Encoding", "snappy")
+	httpReq.Header.Set("Content-Type", "application/x-protobuf")
+	httpReq.Header.Set("User-Agent", "Telegraf")
+	httpReq.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	for k, v := range p.Headers {
+		httpReq.Header.Set(k, v)
+	}
+	if p.Username != "" || p.Password != "" {
+		httpReq.SetBasicAuth(p.Username, p.Password)
+	} else if p.BearerToken != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.BearerToken)
+	}
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+	msg, _ := ioutil.ReadAll(&io.LimitedReader{R: resp.Body, N: 512})
+	err = fmt.Errorf("remote write to %s failed: %s: %s", p.URL, resp.Status,
+		strings.TrimSpace(string(msg)))
+
+	// the endpoint rejected the metrics themselves, which would fail again
+	// if retried.
+	if resp.StatusCode/100 == 4 && resp.StatusCode != http.StatusTooManyRequests {
+		log.Printf("E! %s, dropping %d metrics\n", err, len(metrics))
+		return nil
+	}
+	return err
+}
+
+// writeRequest collects the samples of the metrics by series.
+type writeRequest struct {
+	series   map[string]*prompb.TimeSeries
+	order    []string
+	metadata map[string]prompb.MetricType
+}
+
+func newWriteRequest() *writeRequest {
+	return &writeRequest{
+		series:   make(map[string]*prompb.TimeSeries),
+		metadata: make(map[string]prompb.MetricType),
+	}
+}
+
+// addMetric adds the samples of a metric, the same way as the
+// prometheus_client output names them.
+func (r *writeRequest) addMetric(m telegraf.Metric) {
+	labels := make(map[string]string)
+	for k, v := range m.Tags() {
+		labels[sanitize(k)] = v
+	}
+	// Prometheus doesn't have a string value type, so convert string
+	// fields to labels.
+	for fn, fv := range m.Fields() {
+		if fv, ok := fv.(string); ok {
+			labels[sanitize(fn)] = fv
+		}
+	}
+
+	ts := m.Time().UnixNano() / int64(time.Millisecond)
+	name := sanitize(m.Name())
+
+	switch m.Type() {
+	case telegraf.Summary:
+		r.metadata[name] = prompb.MetricTypeSummary
+		for fn, fv := range m.Fields() {
+			value, ok := sampleValue(fv)
+			if !ok {
+				continue
+			}
+			switch fn {
+			case "sum":
+				r.add(name+"_sum", labels, "", "", value, ts)
+			case "count":
+				r.add(name+"_count", labels, "", "", value, ts)
+			default:
+				quantile, err := strconv.ParseFloat(fn, 64)
+				if err == nil {
+					r.add(name, labels, "quantile", formatFloat(quantile),
+						value, ts)
+				}
+			}
+		}
+	case telegraf.Histogram:
+		r.metadata[name] = prompb.MetricTypeHistogram
+		hasInf := false
+		for fn, fv := range m.Fields() {
+			value, ok := sampleValue(fv)
+			if !ok {
+				continue
+			}
+			switch fn {
+			case "sum":
+				r.add(name+"_sum", labels, "", "", value, ts)
+			case "count":
+				r.add(name+"_count", labels, "", "", value, ts)
+			default:
+				le, err := strconv.ParseFloat(fn, 64)
+				if err == nil {
+					hasInf = hasInf || math.IsInf(le, 1)
+					r.add(name+"_bucket", labels, "le", formatFloat(le),
+						value, ts)
+				}
+			}
+		}
+		// the +Inf bucket is required, and holds all the observations
+		if count, ok := sampleValue(m.Fields()["count"]); ok && !hasInf {
+			r.add(name+"_bucket", labels, "le", "+Inf", count, ts)
+		}
+	default:
+		for fn, fv := range m.Fields() {
+			value, ok := sampleValue(fv)
+			if !ok {
+				continue
+			}
+
+			// Special handling of value field; supports passthrough from
+			// the prometheus input.
+			var sname string
+			switch {
+			case m.Type() == telegraf.Counter && fn == "counter",
+				m.Type() == telegraf.Gauge && fn == "gauge",
+				fn == "value":
+				sname = name
+			default:
+				sname = sanitize(fmt.Sprintf("%s_%s", m.Name(), fn))
+			}
+
+			switch m.Type() {
+			case telegraf.Counter:
+				r.metadata[sname] = prompb.MetricTypeCounter
+			case telegraf.Gauge:
+				r.metadata[sname] = prompb.MetricTypeGauge
+			}
+			r.add(sname, labels, "", "", value, ts)
+		}
+	}
+}
+
+// add adds a sample to the series named name, with the given labels and
+// the optional extra label.
+func (r *writeRequest) add(
+	name string,
+	labels map[string]string,
+	extraName, extraValue string,
+	value float64,
+	ts int64,
+) {
+	series := make([]prompb.Label, 0, len(labels)+2)
+	series = append(series, prompb.Label{Name: "__name__", Value: name})
+	for k, v := range labels {
+		if k == "__name__" || k == extraName {
+			continue
+		}
+		series = append(series, prompb.Label{Name: k, Value: v})
+	}
+	if extraName != "" {
+		series = append(series, prompb.Label{Name: extraName, Value: extraValue})
+	}
+	sort.Slice(series, func(i, j int) bool {
+		return series[i].Name < series[j].Name
+	})
+
+	var key bytes.Buffer
+	for _, l := range series {
+		key.WriteString(l.Name)
+		key.WriteByte(0)
+		key.WriteString(l.Value)
+		key.WriteByte(0)
+	}
+
+	s, ok := r.series[key.String()]
+	if !ok {
+		s = &prompb.TimeSeries{Labels: series}
+		r.series[key.String()] = s
+		r.order = append(r.order, key.String())
+	}
+	s.Samples = append(s.Samples, prompb.Sample{Value: value, Timestamp: ts})
+}
+
+func (r *writeRequest) build() *prompb.WriteRequest {
+	req := &prompb.WriteRequest{
+		Timeseries: make([]prompb.TimeSeries, 0, len(r.order)),
+	}
+	for _, key := range r.order {
+		s := r.series[key]
+		// the samples of a series must be in time order
+		sort.SliceStable(s.Samples, func(i, j int) bool {
+			return s.Samples[i].Timestamp < s.Samples[j].Timestamp
+		})
+		req.Timeseries = append(req.Timeseries, *s)
+	}
+
+	names := make([]string, 0, len(r.metadata))
+	for name := range r.metadata {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		req.Metadata = append(req.Metadata, prompb.MetricMetadata{
+			Type:             r.metadata[name],
+			MetricFamilyName: name,
+		})
+	}
+	return req
+}
+
+func sanitize(value string) string {
+	return invalidNameCharRE.ReplaceAllString(value, "_")
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// sampleValue returns the value of a numeric field. Other fields are ignored.
+func sampleValue(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+func init() {
+	outputs.Add("prometheus_remote_write", func() telegraf.Output {
+		return &PrometheusRemoteWrite{
+			Timeout: internal.Duration{Duration: 5 * time.Second},
+		}
+	})
+}
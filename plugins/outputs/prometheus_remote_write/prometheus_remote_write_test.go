@@ -0,0 +1,209 @@
+package prometheus_remote_write
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/prompb"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/stretchr/testify/require"
+)
+
+func newMetric(
+	t *testing.T,
+	name string,
+	tags map[string]string,
+	fields map[string]interface{},
+	tm time.Time,
+	tp telegraf.ValueType,
+) telegraf.Metric {
+	m, err := metric.New(name, tags, fields, tm, tp)
+	require.NoError(t, err)
+	return m
+}
+
+func buildRequest(metrics ...telegraf.Metric) *prompb.WriteRequest {
+	r := newWriteRequest()
+	for _, m := range metrics {
+		r.addMetric(m)
+	}
+	return r.build()
+}
+
+func labels(kv ...string) []prompb.Label {
+	var l []prompb.Label
+	for i := 0; i < len(kv); i += 2 {
+		l = append(l, prompb.Label{Name: kv[i], Value: kv[i+1]})
+	}
+	return l
+}
+
+func TestBuild_Untyped(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	req := buildRequest(
+		newMetric(t, "cpu", map[string]string{"host": "a"},
+			map[string]interface{}{"value": 42.0}, now, telegraf.Untyped),
+		newMetric(t, "cpu", map[string]string{"host": "a"},
+			map[string]interface{}{"value": 41.0}, now.Add(-time.Second), telegraf.Untyped),
+		newMetric(t, "disk.io", map[string]string{"dev-name": "sda"},
+			map[string]interface{}{"reads": int64(3), "ok": true, "mode": "rw"},
+			now, telegraf.Untyped),
+	)
+
+	require.Equal(t, []prompb.TimeSeries{
+		{
+			Labels: labels("__name__", "cpu", "host", "a"),
+			Samples: []prompb.Sample{
+				{Value: 41, Timestamp: 1499999999000},
+				{Value: 42, Timestamp: 1500000000000},
+			},
+		},
+		{
+			Labels: labels("__name__", "disk_io_reads", "dev_name", "sda",
+				"mode", "rw"),
+			Samples: []prompb.Sample{{Value: 3, Timestamp: 1500000000000}},
+		},
+	}, req.Timeseries)
+	require.Empty(t, req.Metadata)
+}
+
+func TestBuild_CounterGauge(t *testing.T) {
+	now := time.Unix(0, 0)
+	req := buildRequest(
+		newMetric(t, "requests", nil,
+			map[string]interface{}{"counter": uint64(7)}, now, telegraf.Counter),
+		newMetric(t, "temp", nil,
+			map[string]interface{}{"gauge": 20.5}, now, telegraf.Gauge),
+	)
+
+	require.Equal(t, []prompb.TimeSeries{
+		{
+			Labels:  labels("__name__", "requests"),
+			Samples: []prompb.Sample{{Value: 7}},
+		},
+		{
+			Labels:  labels("__name__", "temp"),
+			Samples: []prompb.Sample{{Value: 20.5}},
+		},
+	}, req.Timeseries)
+	require.Equal(t, []prompb.MetricMetadata{
+		{Type: prompb.MetricTypeCounter, MetricFamilyName: "requests"},
+		{Type: prompb.MetricTypeGauge, MetricFamilyName: "temp"},
+	}, req.Metadata)
+}
+
+func TestBuild_Summary(t *testing.T) {
+	req := buildRequest(
+		newMetric(t, "rpc", nil,
+			map[string]interface{}{"0.5": 1.0, "sum": 10.0, "count": 4.0},
+			time.Unix(0, 0), telegraf.Summary),
+	)
+
+	series := make(map[string]float64)
+	for _, ts := range req.Timeseries {
+		key := ""
+		for _, l := range ts.Labels {
+			key += l.Name + "=" + l.Value + ","
+		}
+		series[key] = ts.Samples[0].Value
+	}
+	require.Equal(t, map[string]float64{
+		"__name__=rpc,quantile=0.5,": 1,
+		"__name__=rpc_sum,":          10,
+		"__name__=rpc_count,":        4,
+	}, series)
+	require.Equal(t, []prompb.MetricMetadata{
+		{Type: prompb.MetricTypeSummary, MetricFamilyName: "rpc"},
+	}, req.Metadata)
+}
+
+func TestBuild_Histogram(t *testing.T) {
+	req := buildRequest(
+		newMetric(t, "latency", map[string]string{"le": "x"},
+			map[string]interface{}{"0.1": 1.0, "1": 3.0, "sum": 2.5, "count": 5.0},
+			time.Unix(0, 0), telegraf.Histogram),
+	)
+
+	series := make(map[string]float64)
+	for _, ts := range req.Timeseries {
+		key := ""
+		for _, l := range ts.Labels {
+			key += l.Name + "=" + l.Value + ","
+		}
+		series[key] = ts.Samples[0].Value
+	}
+	require.Equal(t, map[string]float64{
+		"__name__=latency_bucket,le=0.1,":  1,
+		"__name__=latency_bucket,le=1,":    3,
+		"__name__=latency_bucket,le=+Inf,": 5,
+		"__name__=latency_sum,le=x,":       2.5,
+		"__name__=latency_count,le=x,":     5,
+	}, series)
+}
+
+func TestWrite(t *testing.T) {
+	var received prompb.WriteRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+		require.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		require.Equal(t, "tenant", r.Header.Get("X-Scope-OrgID"))
+		user, pass, ok := r.BasicAuth()
+		require.True(t, ok)
+		require.Equal(t, "user", user)
+		require.Equal(t, "pass", pass)
+
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		data, err := snappy.Decode(nil, body)
+		require.NoError(t, err)
+		require.NoError(t, received.Unmarshal(data))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	p := &PrometheusRemoteWrite{
+		URL:      ts.URL,
+		Username: "user",
+		Password: "pass",
+		Headers:  map[string]string{"X-Scope-OrgID": "tenant"},
+	}
+	require.NoError(t, p.Connect())
+
+	m := newMetric(t, "cpu", nil, map[string]interface{}{"value": 1.0},
+		time.Unix(1, 0), telegraf.Untyped)
+	require.NoError(t, p.Write([]telegraf.Metric{m}))
+	require.Equal(t, []prompb.TimeSeries{
+		{
+			Labels:  labels("__name__", "cpu"),
+			Samples: []prompb.Sample{{Value: 1, Timestamp: 1000}},
+		},
+	}, received.Timeseries)
+}
+
+func TestWrite_Errors(t *testing.T) {
+	status := http.StatusBadRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer ts.Close()
+
+	p := &PrometheusRemoteWrite{URL: ts.URL}
+	require.NoError(t, p.Connect())
+
+	m := newMetric(t, "cpu", nil, map[string]interface{}{"value": 1.0},
+		time.Unix(1, 0), telegraf.Untyped)
+
+	// rejected metrics are dropped
+	require.NoError(t, p.Write([]telegraf.Metric{m}))
+
+	// other errors are retried
+	status = http.StatusTooManyRequests
+	require.Error(t, p.Write([]telegraf.Metric{m}))
+	status = http.StatusInternalServerError
+	require.Error(t, p.Write([]telegraf.Metric{m}))
+}
@@ -104,19 +104,25 @@ func (sw *SocketWriter) Write(metrics []telegraf.Metric) error {
 		}
 	}
 
+	if !sw.isDatagram() {
+		// stream sockets get the whole batch in a single write
+		bs, err := serializers.SerializeBatch(sw.Serializer, metrics)
+		if err != nil {
+			return err
+		}
+		return sw.write(bs)
+	}
+
+	// datagram sockets get a packet per metric, to stay within the maximum
+	// packet size
 	for _, m := range metrics {
 		bs, err := sw.Serialize(m)
 		if err != nil {
 			//TODO log & keep going with remaining metrics
 			return err
 		}
-		if _, err := sw.Conn.Write(bs); err != nil {
+		if err := sw.write(bs); err != nil {
 			//TODO log & keep going with remaining strings
-			if err, ok := err.(net.Error); !ok || !err.Temporary() {
-				// permanent error. close the connection
-				sw.Close()
-				sw.Conn = nil
-			}
 			return err
 		}
 	}
@@ -124,6 +130,24 @@ func (sw *SocketWriter) Write(metrics []telegraf.Metric) error {
 	return nil
 }
 
+func (sw *SocketWriter) write(bs []byte) error {
+	if _, err := sw.Conn.Write(bs); err != nil {
+		if err, ok := err.(net.Error); !ok || !err.Temporary() {
+			// permanent error. close the connection
+			sw.Close()
+			sw.Conn = nil
+		}
+		return err
+	}
+	return nil
+}
+
+// isDatagram returns true if the socket sends datagrams rather than a stream.
+func (sw *SocketWriter) isDatagram() bool {
+	network := strings.SplitN(sw.Address, "://", 2)[0]
+	return strings.HasPrefix(network, "udp") || network == "unixgram"
+}
+
 // Close closes the connection. Noop if already closed.
 func (sw *SocketWriter) Close() error {
 	if sw.Conn == nil {
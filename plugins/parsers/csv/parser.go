@@ -0,0 +1,315 @@
+package csv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+)
+
+type Parser struct {
+	MetricName string
+	// HeaderRowCount is the number of rows of column names at the start of
+	// the data, the names of a column in several rows are concatenated.
+	HeaderRowCount int
+	// SkipRows is the number of rows to skip before the header.
+	SkipRows int
+	// SkipColumns is the number of columns to skip at the start of each row.
+	SkipColumns int
+	Delimiter   string
+	// Comment is the prefix of the lines to skip.
+	Comment   string
+	TrimSpace bool
+	// ColumnNames are the names of the columns, they take precedence over
+	// the names from the header rows. Columns without a name are named
+	// column1, column2, etc.
+	ColumnNames []string
+	// ColumnTypes are the types of the columns that are fields, in the
+	// order of the columns: int, float, bool or string. The type of a
+	// column without a type is detected from its value.
+	ColumnTypes       []string
+	TagColumns        []string
+	MeasurementColumn string
+	TimestampColumn   string
+	// TimestampFormat is the format of the timestamp column, see
+	// internal.ParseTimestamp.
+	TimestampFormat string
+	DefaultTags     map[string]string
+}
+
+// Validate checks the configuration of the parser.
+func (p *Parser) Validate() error {
+	if len([]rune(p.Delimiter)) > 1 {
+		return fmt.Errorf("csv_delimiter must be a single character, got: %s",
+			p.Delimiter)
+	}
+	if p.TimestampColumn != "" && p.TimestampFormat == "" {
+		return fmt.Errorf("csv_timestamp_column %q requires a csv_timestamp_format",
+			p.TimestampColumn)
+	}
+	for _, t := range p.ColumnTypes {
+		switch t {
+		case "", "int", "float", "bool", "string":
+		default:
+			return fmt.Errorf("invalid csv_column_types %q, must be one of: "+
+				"int, float, bool, string", t)
+		}
+	}
+	return nil
+}
+
+func (p *Parser) newReader(r io.Reader) *csv.Reader {
+	reader := csv.NewReader(r)
+	reader.Comma = ','
+	if p.Delimiter != "" {
+		reader.Comma = []rune(p.Delimiter)[0]
+	}
+	reader.TrimLeadingSpace = p.TrimSpace
+	// rows may have a different number of columns
+	reader.FieldsPerRecord = -1
+	return reader
+}
+
+// Parse parses CSV data, starting with the rows to skip and the header rows.
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	// the rows to skip don't have to be valid CSV
+	for i := 0; i < p.SkipRows; i++ {
+		n := bytes.IndexByte(buf, '\n')
+		if n < 0 {
+			return []telegraf.Metric{}, nil
+		}
+		buf = buf[n+1:]
+	}
+
+	reader := p.newReader(bytes.NewReader(p.removeComments(buf)))
+
+	var headerNames []string
+	for i := 0; i < p.HeaderRowCount; i++ {
+		header, err := reader.Read()
+		if err == io.EOF {
+			return []telegraf.Metric{}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		headerNames = p.appendHeader(headerNames, header)
+	}
+
+	metrics := make([]telegraf.Metric, 0)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		m, err := p.parseRecord(record, headerNames)
+		if err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics, nil
+}
+
+// ParseLine parses a single row. The lines are parsed independently of each
+// other, so the rows to skip and the header rows are not read, the columns
+// are named by ColumnNames. A nil metric is returned for blank lines, comment
+// lines and lines with the column names, ie the header of a tailed file.
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	if p.isComment(line) {
+		return nil, nil
+	}
+
+	record, err := p.newReader(strings.NewReader(line)).Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if p.isHeader(record) {
+		return nil, nil
+	}
+	return p.parseRecord(record, nil)
+}
+
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.DefaultTags = tags
+}
+
+func (p *Parser) isComment(line string) bool {
+	return p.Comment != "" && strings.HasPrefix(line, p.Comment)
+}
+
+// removeComments removes the comment lines of buf.
+func (p *Parser) removeComments(buf []byte) []byte {
+	if p.Comment == "" {
+		return buf
+	}
+	out := make([]byte, 0, len(buf))
+	for len(buf) > 0 {
+		line := buf
+		if n := bytes.IndexByte(buf, '\n'); n >= 0 {
+			line = buf[:n+1]
+		}
+		buf = buf[len(line):]
+		if !p.isComment(string(line)) {
+			out = append(out, line...)
+		}
+	}
+	return out
+}
+
+// isHeader returns true if the columns of the record are the ColumnNames.
+func (p *Parser) isHeader(record []string) bool {
+	if len(p.ColumnNames) == 0 || len(record) != p.SkipColumns+len(p.ColumnNames) {
+		return false
+	}
+	for i, name := range record[p.SkipColumns:] {
+		if p.TrimSpace {
+			name = strings.TrimSpace(name)
+		}
+		if name != p.ColumnNames[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// appendHeader concatenates the column names of a header row to the names of
+// the previous header rows.
+func (p *Parser) appendHeader(names []string, header []string) []string {
+	if len(header) > p.SkipColumns {
+		header = header[p.SkipColumns:]
+	} else {
+		header = nil
+	}
+	for i, name := range header {
+		if p.TrimSpace {
+			name = strings.TrimSpace(name)
+		}
+		if i < len(names) {
+			names[i] += name
+		} else {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// columnName returns the name of the column at index i.
+func (p *Parser) columnName(i int, headerNames []string) string {
+	if i < len(p.ColumnNames) {
+		return p.ColumnNames[i]
+	}
+	if i < len(headerNames) && headerNames[i] != "" {
+		return headerNames[i]
+	}
+	return "column" + strconv.Itoa(i+1)
+}
+
+func (p *Parser) parseRecord(record []string, headerNames []string) (telegraf.Metric, error) {
+	if len(record) > p.SkipColumns {
+		record = record[p.SkipColumns:]
+	} else {
+		record = nil
+	}
+
+	name := p.MetricName
+	tags := make(map[string]string)
+	for k, v := range p.DefaultTags {
+		tags[k] = v
+	}
+	fields := make(map[string]interface{})
+	timestamp := time.Now().UTC()
+
+	for i, value := range record {
+		if p.TrimSpace {
+			value = strings.TrimSpace(value)
+		}
+		column := p.columnName(i, headerNames)
+
+		switch {
+		case column == p.MeasurementColumn:
+			if value != "" {
+				name = value
+			}
+			continue
+		case column == p.TimestampColumn:
+			t, err := internal.ParseTimestamp(p.TimestampFormat, value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timestamp in column %s: %s",
+					column, err)
+			}
+			timestamp = t
+			continue
+		case p.isTag(column):
+			if value != "" {
+				tags[column] = value
+			}
+			continue
+		}
+
+		// empty values are missing fields
+		if value == "" {
+			continue
+		}
+
+		var columnType string
+		if i < len(p.ColumnTypes) {
+			columnType = p.ColumnTypes[i]
+		}
+		v, err := parseValue(value, columnType)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value in column %s: %s",
+				columnType, column, value)
+		}
+		fields[column] = v
+	}
+
+	return metric.New(name, tags, fields, timestamp)
+}
+
+func (p *Parser) isTag(column string) bool {
+	for _, tag := range p.TagColumns {
+		if tag == column {
+			return true
+		}
+	}
+	return false
+}
+
+// parseValue converts a value to the column type, or detects its type if the
+// column has no type.
+func parseValue(value string, columnType string) (interface{}, error) {
+	switch columnType {
+	case "int":
+		return strconv.ParseInt(value, 10, 64)
+	case "float":
+		return strconv.ParseFloat(value, 64)
+	case "bool":
+		return strconv.ParseBool(value)
+	case "string":
+		return value, nil
+	}
+
+	if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return v, nil
+	}
+	if v, err := strconv.ParseFloat(value, 64); err == nil {
+		return v, nil
+	}
+	if v, err := strconv.ParseBool(value); err == nil {
+		return v, nil
+	}
+	return value, nil
+}
@@ -0,0 +1,120 @@
+package csv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHeader(t *testing.T) {
+	p := &Parser{
+		MetricName:     "csv",
+		HeaderRowCount: 1,
+		TagColumns:     []string{"host"},
+	}
+	require.NoError(t, p.Validate())
+
+	metrics, err := p.Parse([]byte("host,usage,ok,state\n" +
+		"a,98.5,true,up\n" +
+		"b,10,false,\n"))
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+
+	assert.Equal(t, "csv", metrics[0].Name())
+	assert.Equal(t, map[string]string{"host": "a"}, metrics[0].Tags())
+	assert.Equal(t, map[string]interface{}{
+		"usage": 98.5,
+		"ok":    true,
+		"state": "up",
+	}, metrics[0].Fields())
+
+	// empty values are skipped
+	assert.Equal(t, map[string]interface{}{
+		"usage": int64(10),
+		"ok":    false,
+	}, metrics[1].Fields())
+}
+
+func TestParseColumnNamesAndTypes(t *testing.T) {
+	p := &Parser{
+		MetricName:  "csv",
+		Delimiter:   "\t",
+		Comment:     "//",
+		TrimSpace:   true,
+		ColumnNames: []string{"count", "value", "name"},
+		ColumnTypes: []string{"float", "string"},
+	}
+	require.NoError(t, p.Validate())
+
+	metrics, err := p.Parse([]byte("// a comment\n 10\t 42\t foo\textra\n"))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	assert.Equal(t, map[string]interface{}{
+		"count":   float64(10),
+		"value":   "42",
+		"name":    "foo",
+		"column4": "extra",
+	}, metrics[0].Fields())
+
+	_, err = p.Parse([]byte("ten\t42\n"))
+	assert.Error(t, err)
+}
+
+func TestParseSkipAndMultipleHeaders(t *testing.T) {
+	p := &Parser{
+		MetricName:        "csv",
+		SkipRows:          2,
+		SkipColumns:       1,
+		HeaderRowCount:    2,
+		MeasurementColumn: "name",
+		TimestampColumn:   "time",
+		TimestampFormat:   "unix",
+	}
+	require.NoError(t, p.Validate())
+
+	metrics, err := p.Parse([]byte("Report of \"today\n" +
+		"\n" +
+		"id,na,ti,usage\n" +
+		",me,me,_idle\n" +
+		"1,cpu,1500000000,98.5\n"))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	assert.Equal(t, "cpu", metrics[0].Name())
+	assert.Equal(t, map[string]interface{}{"usage_idle": 98.5}, metrics[0].Fields())
+	assert.Equal(t, time.Unix(1500000000, 0).UnixNano(), metrics[0].UnixNano())
+}
+
+func TestParseLine(t *testing.T) {
+	p := &Parser{
+		MetricName:      "csv",
+		Comment:         "//",
+		ColumnNames:     []string{"time", "value"},
+		TimestampColumn: "time",
+		TimestampFormat: "2006-01-02 15:04:05",
+	}
+	require.NoError(t, p.Validate())
+
+	// the header, blank and comment lines are skipped without a metric
+	for _, line := range []string{"time,value", "", "// a comment"} {
+		m, err := p.ParseLine(line)
+		require.NoError(t, err)
+		assert.Nil(t, m)
+	}
+
+	m, err := p.ParseLine("2017-07-14 02:40:00,1")
+	require.NoError(t, err)
+	require.NotNil(t, m)
+	assert.Equal(t, map[string]interface{}{"value": int64(1)}, m.Fields())
+	assert.Equal(t, time.Unix(1500000000, 0).UnixNano(), m.UnixNano())
+
+	_, err = p.ParseLine("yesterday,1")
+	assert.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	assert.Error(t, (&Parser{Delimiter: ";;"}).Validate())
+	assert.Error(t, (&Parser{TimestampColumn: "time"}).Validate())
+	assert.Error(t, (&Parser{ColumnTypes: []string{"long"}}).Validate())
+}
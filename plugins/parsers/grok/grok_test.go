@@ -958,3 +958,25 @@ func TestTimezoneLocalCompileFileAndParse(t *testing.T) {
 	assert.Equal(t, map[string]string{}, metricB.Tags())
 	assert.Equal(t, time.Date(2016, time.June, 4, 12, 41, 45, 0, time.Local).UnixNano(), metricB.UnixNano())
 }
+
+func TestParseMultipleLines(t *testing.T) {
+	p := &Parser{
+		Measurement: "test",
+		Patterns:    []string{"%{TESTLOG}"},
+		CustomPatterns: `
+			TESTLOG %{NUMBER:num:int} %{WORD:client:tag}
+		`,
+	}
+	assert.NoError(t, p.Compile())
+	p.SetDefaultTags(map[string]string{"host": "a", "client": "default"})
+
+	metrics, err := p.Parse([]byte("142 bot\r\n\nno match\n7 crawler\n"))
+	assert.NoError(t, err)
+	require.Len(t, metrics, 2)
+
+	assert.Equal(t, "test", metrics[0].Name())
+	assert.Equal(t, map[string]interface{}{"num": int64(142)}, metrics[0].Fields())
+	assert.Equal(t, map[string]string{"host": "a", "client": "bot"}, metrics[0].Tags())
+	assert.Equal(t, map[string]interface{}{"num": int64(7)}, metrics[1].Fields())
+	assert.Equal(t, map[string]string{"host": "a", "client": "crawler"}, metrics[1].Tags())
+}
@@ -4,11 +4,15 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/metric"
 )
 
@@ -16,24 +20,77 @@ type JSONParser struct {
 	MetricName  string
 	TagKeys     []string
 	DefaultTags map[string]string
+
+	query        string
+	queries      map[string]string
+	queryNames   []string
+	nameKey      string
+	timeKey      string
+	timeFormat   string
+	stringFields filter.Filter
 }
 
-func (p *JSONParser) parseArray(buf []byte) ([]telegraf.Metric, error) {
-	metrics := make([]telegraf.Metric, 0)
+// Config is the configuration of a JSONParser.
+type Config struct {
+	// MetricName is the name of the metrics, unless NameKey is set.
+	MetricName string
+	// TagKeys are the keys of the values that are tags.
+	TagKeys []string
+	// DefaultTags are added to all metrics.
+	DefaultTags map[string]string
 
-	var jsonOut []map[string]interface{}
-	err := json.Unmarshal(buf, &jsonOut)
+	// Query is the GJSON path of the object, or array of objects, to
+	// create metrics from. The whole document is used if empty, unless
+	// Queries are set.
+	Query string
+	// Queries maps measurement names to the GJSON path of the objects to
+	// create metrics with that name from, in addition to Query. The
+	// queries that do not match are skipped.
+	Queries map[string]string
+	// NameKey is the key of the value to use as metric name.
+	NameKey string
+	// TimeKey is the key of the timestamp of the metric. The current time
+	// is used if empty.
+	TimeKey string
+	// TimeFormat is the format of the timestamp, see
+	// internal.ParseTimestamp.
+	TimeFormat string
+	// StringFields are the keys of the string values that are fields,
+	// they can be glob patterns.
+	StringFields []string
+}
+
+// New returns a JSONParser with the given configuration.
+func New(config *Config) (*JSONParser, error) {
+	if config.TimeKey != "" && config.TimeFormat == "" {
+		return nil, fmt.Errorf("json_time_key %q requires a json_time_format",
+			config.TimeKey)
+	}
+	stringFields, err := filter.Compile(config.StringFields)
 	if err != nil {
-		err = fmt.Errorf("unable to parse out as JSON Array, %s", err)
 		return nil, err
 	}
-	for _, item := range jsonOut {
-		metrics, err = p.parseObject(metrics, item)
+	// the metrics of the queries are in the order of their names
+	queryNames := make([]string, 0, len(config.Queries))
+	for name := range config.Queries {
+		queryNames = append(queryNames, name)
 	}
-	return metrics, nil
+	sort.Strings(queryNames)
+	return &JSONParser{
+		MetricName:   config.MetricName,
+		TagKeys:      config.TagKeys,
+		DefaultTags:  config.DefaultTags,
+		query:        config.Query,
+		queries:      config.Queries,
+		queryNames:   queryNames,
+		nameKey:      config.NameKey,
+		timeKey:      config.TimeKey,
+		timeFormat:   config.TimeFormat,
+		stringFields: stringFields,
+	}, nil
 }
 
-func (p *JSONParser) parseObject(metrics []telegraf.Metric, jsonOut map[string]interface{}) ([]telegraf.Metric, error) {
+func (p *JSONParser) parseObject(metrics []telegraf.Metric, name string, jsonOut map[string]interface{}) ([]telegraf.Metric, error) {
 
 	tags := make(map[string]string)
 	for k, v := range p.DefaultTags {
@@ -46,19 +103,50 @@ func (p *JSONParser) parseObject(metrics []telegraf.Metric, jsonOut map[string]i
 			tags[tag] = v
 		case bool:
 			tags[tag] = strconv.FormatBool(v)
-		case float64:
-			tags[tag] = strconv.FormatFloat(v, 'f', -1, 64)
+		case json.Number:
+			tags[tag] = v.String()
 		}
 		delete(jsonOut, tag)
 	}
 
+	if p.nameKey != "" {
+		if v, ok := jsonOut[p.nameKey].(string); ok && v != "" {
+			name = v
+		}
+		delete(jsonOut, p.nameKey)
+	}
+
+	timestamp := time.Now().UTC()
+	if p.timeKey != "" {
+		v, ok := jsonOut[p.timeKey]
+		if !ok {
+			return nil, fmt.Errorf("JSON time key %q could not be found", p.timeKey)
+		}
+		// numbers are parsed from their text, so that unix_us and
+		// unix_ns timestamps are exact
+		if n, ok := v.(json.Number); ok {
+			v = n.String()
+		}
+		var err error
+		timestamp, err = internal.ParseTimestamp(p.timeFormat, v)
+		if err != nil {
+			return nil, err
+		}
+		delete(jsonOut, p.timeKey)
+	}
+
 	f := JSONFlattener{}
-	err := f.FlattenJSON("", jsonOut)
+	err := f.FullFlattenJSON("", jsonOut, p.stringFields != nil, false)
 	if err != nil {
 		return nil, err
 	}
+	for k, v := range f.Fields {
+		if _, ok := v.(string); ok && !p.stringFields.Match(k) {
+			delete(f.Fields, k)
+		}
+	}
 
-	metric, err := metric.New(p.MetricName, tags, f.Fields, time.Now().UTC())
+	metric, err := metric.New(name, tags, f.Fields, timestamp)
 
 	if err != nil {
 		return nil, err
@@ -72,17 +160,73 @@ func (p *JSONParser) Parse(buf []byte) ([]telegraf.Metric, error) {
 		return make([]telegraf.Metric, 0), nil
 	}
 
-	if !isarray(buf) {
-		metrics := make([]telegraf.Metric, 0)
-		var jsonOut map[string]interface{}
-		err := json.Unmarshal(buf, &jsonOut)
+	jsonOut, err := decode(buf)
+	if err != nil {
+		err = fmt.Errorf("unable to parse out as JSON, %s", err)
+		return nil, err
+	}
+
+	metrics := make([]telegraf.Metric, 0)
+	if p.query != "" || len(p.queries) == 0 {
+		v, ok := query(jsonOut, p.query)
+		if !ok {
+			return nil, fmt.Errorf("JSON query %q did not match", p.query)
+		}
+		metrics, err = p.parseValue(metrics, p.MetricName, v)
 		if err != nil {
-			err = fmt.Errorf("unable to parse out as JSON, %s", err)
 			return nil, err
 		}
-		return p.parseObject(metrics, jsonOut)
 	}
-	return p.parseArray(buf)
+	for _, name := range p.queryNames {
+		v, ok := query(jsonOut, p.queries[name])
+		if !ok {
+			continue
+		}
+		metrics, err = p.parseValue(metrics, name, v)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return metrics, nil
+}
+
+// decode decodes a JSON document. The numbers are kept as json.Number, so
+// that large integers and timestamps are not rounded to a float64.
+func decode(buf []byte) (interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(buf))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+	if _, err := d.Token(); err != io.EOF {
+		return nil, fmt.Errorf("invalid data after the JSON value")
+	}
+	return v, nil
+}
+
+// parseValue appends the metrics of an object, or array of objects, with the
+// given name to metrics.
+func (p *JSONParser) parseValue(metrics []telegraf.Metric, name string, v interface{}) ([]telegraf.Metric, error) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return p.parseObject(metrics, name, v)
+	case []interface{}:
+		for _, item := range v {
+			obj, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf(
+					"unable to parse out as JSON Array, element is a %T", item)
+			}
+			var err error
+			metrics, err = p.parseObject(metrics, name, obj)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return metrics, nil
+	}
+	return nil, fmt.Errorf("JSON must be an object or array of objects, not %T", v)
 }
 
 func (p *JSONParser) ParseLine(line string) (telegraf.Metric, error) {
@@ -146,6 +290,13 @@ func (f *JSONFlattener) FullFlattenJSON(
 		}
 	case float64:
 		f.Fields[fieldname] = t
+	case json.Number:
+		// numbers are float fields, whatever their text
+		n, err := t.Float64()
+		if err != nil {
+			return fmt.Errorf("JSON Flattener: invalid number %s (%s)", t, fieldname)
+		}
+		f.Fields[fieldname] = n
 	case string:
 		if convertString {
 			f.Fields[fieldname] = v.(string)
@@ -166,13 +317,3 @@ func (f *JSONFlattener) FullFlattenJSON(
 	}
 	return nil
 }
-
-func isarray(buf []byte) bool {
-	ia := bytes.IndexByte(buf, '[')
-	ib := bytes.IndexByte(buf, '{')
-	if ia > -1 && ia < ib {
-		return true
-	} else {
-		return false
-	}
-}
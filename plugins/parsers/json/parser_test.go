@@ -2,8 +2,10 @@ package json
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -428,3 +430,184 @@ func TestParseArrayWithTagKeys(t *testing.T) {
 		"othertag": "baz",
 	}, metrics[1].Tags())
 }
+
+const validJSONQuery = `
+{
+    "status": "ok",
+    "data": {
+        "hosts": [
+            {
+                "name": "cpu",
+                "host": "a",
+                "time": "2017-07-14T02:40:00Z",
+                "state": "up",
+                "usage": {"idle": 98.5}
+            },
+            {
+                "name": "mem",
+                "host": "b",
+                "time": "2017-07-14T02:40:01Z",
+                "state": "down",
+                "usage": {"idle": 50}
+            }
+        ]
+    }
+}
+`
+
+func TestParseWithQuery(t *testing.T) {
+	parser, err := New(&Config{
+		MetricName:   "json_test",
+		TagKeys:      []string{"host"},
+		Query:        "data.hosts",
+		NameKey:      "name",
+		TimeKey:      "time",
+		TimeFormat:   "RFC3339",
+		StringFields: []string{"sta*"},
+	})
+	require.NoError(t, err)
+
+	metrics, err := parser.Parse([]byte(validJSONQuery))
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+
+	assert.Equal(t, "cpu", metrics[0].Name())
+	assert.Equal(t, map[string]string{"host": "a"}, metrics[0].Tags())
+	assert.Equal(t, map[string]interface{}{
+		"state":      "up",
+		"usage_idle": 98.5,
+	}, metrics[0].Fields())
+	assert.Equal(t, time.Unix(1500000000, 0).UTC(), metrics[0].Time().UTC())
+
+	assert.Equal(t, "mem", metrics[1].Name())
+	assert.Equal(t, time.Unix(1500000001, 0).UTC(), metrics[1].Time().UTC())
+
+	// a single element of the array
+	parser, err = New(&Config{MetricName: "json_test", Query: "data.hosts.1.usage"})
+	require.NoError(t, err)
+	metrics, err = parser.Parse([]byte(validJSONQuery))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	assert.Equal(t, "json_test", metrics[0].Name())
+	assert.Equal(t, map[string]interface{}{"idle": float64(50)}, metrics[0].Fields())
+
+	parser, err = New(&Config{MetricName: "json_test", Query: "data.missing"})
+	require.NoError(t, err)
+	_, err = parser.Parse([]byte(validJSONQuery))
+	assert.Error(t, err)
+
+	// the query must select an object or array of objects
+	parser, err = New(&Config{MetricName: "json_test", Query: "status"})
+	require.NoError(t, err)
+	_, err = parser.Parse([]byte(validJSONQuery))
+	assert.Error(t, err)
+}
+
+func TestParseWithTimeKey(t *testing.T) {
+	parser, err := New(&Config{
+		MetricName: "json_test",
+		TimeKey:    "time",
+		TimeFormat: "unix_ms",
+	})
+	require.NoError(t, err)
+
+	metrics, err := parser.Parse([]byte(`{"a": 5, "time": 1500000000123}`))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	assert.Equal(t, map[string]interface{}{"a": float64(5)}, metrics[0].Fields())
+	assert.Equal(t, int64(1500000000123000000), metrics[0].Time().UnixNano())
+
+	_, err = parser.Parse([]byte(`{"a": 5}`))
+	assert.Error(t, err)
+
+	_, err = New(&Config{MetricName: "json_test", TimeKey: "time"})
+	assert.Error(t, err)
+}
+
+func TestQuery(t *testing.T) {
+	doc := map[string]interface{}{
+		"a.b": float64(1),
+		"list": []interface{}{
+			map[string]interface{}{"x": float64(1)},
+			map[string]interface{}{"x": float64(2)},
+			map[string]interface{}{"y": float64(3)},
+		},
+	}
+
+	tests := []struct {
+		path string
+		want interface{}
+		ok   bool
+	}{
+		{`a\.b`, float64(1), true},
+		{"a.b", nil, false},
+		{"list.1.x", float64(2), true},
+		{"list.3", nil, false},
+		{"list.#", float64(3), true},
+		{"list.#.x", []interface{}{float64(1), float64(2)}, true},
+	}
+	for _, test := range tests {
+		got, ok := query(doc, test.path)
+		assert.Equal(t, test.ok, ok, test.path)
+		assert.Equal(t, test.want, got, test.path)
+	}
+}
+
+func TestParseWithQueries(t *testing.T) {
+	parser, err := New(&Config{
+		MetricName: "json_test",
+		TagKeys:    []string{"host"},
+		Queries: map[string]string{
+			"hosts":   "data.hosts",
+			"idle":    "data.hosts.0.usage",
+			"missing": "data.missing",
+		},
+	})
+	require.NoError(t, err)
+
+	metrics, err := parser.Parse([]byte(validJSONQuery))
+	require.NoError(t, err)
+	require.Len(t, metrics, 3)
+
+	// in the order of the names
+	assert.Equal(t, "hosts", metrics[0].Name())
+	assert.Equal(t, map[string]string{"host": "a"}, metrics[0].Tags())
+	assert.Equal(t, "hosts", metrics[1].Name())
+	assert.Equal(t, map[string]string{"host": "b"}, metrics[1].Tags())
+	assert.Equal(t, "idle", metrics[2].Name())
+	assert.Equal(t, map[string]interface{}{"idle": 98.5}, metrics[2].Fields())
+
+	// json_query is parsed as well when set
+	parser, err = New(&Config{
+		MetricName: "json_test",
+		Query:      "data.hosts.1",
+		Queries:    map[string]string{"idle": "data.hosts.0.usage"},
+	})
+	require.NoError(t, err)
+	metrics, err = parser.Parse([]byte(validJSONQuery))
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+	assert.Equal(t, "json_test", metrics[0].Name())
+	assert.Equal(t, "idle", metrics[1].Name())
+}
+
+func TestParsePrecision(t *testing.T) {
+	parser, err := New(&Config{
+		MetricName: "json_test",
+		TagKeys:    []string{"id"},
+		TimeKey:    "time",
+		TimeFormat: "unix_ns",
+	})
+	require.NoError(t, err)
+
+	metrics, err := parser.Parse([]byte(
+		`{"id": 9007199254740993, "a": 5, "time": 1500000000123456789}`))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	assert.Equal(t, map[string]string{"id": "9007199254740993"}, metrics[0].Tags())
+	assert.Equal(t, map[string]interface{}{"a": float64(5)}, metrics[0].Fields())
+	assert.Equal(t, int64(1500000000123456789), metrics[0].Time().UnixNano())
+
+	_, err = parser.Parse([]byte(`{"a": 5} {"a": 6}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,72 @@
+package json
+
+import (
+	"strconv"
+)
+
+// query returns the value at path in the decoded JSON document v.
+//
+// The path uses the GJSON syntax: the keys of nested objects are separated
+// by dots, a dot in a key is escaped with a backslash, and a number is the
+// index of an element of an array. '#' is the number of elements of an
+// array, or, when followed by more keys, the array of the values at the rest
+// of the path for each element, ie "hosts.#.cpu". An empty path selects the
+// whole document.
+func query(v interface{}, path string) (interface{}, bool) {
+	if path == "" {
+		return v, true
+	}
+	return queryKeys(v, splitPath(path))
+}
+
+func queryKeys(v interface{}, keys []string) (interface{}, bool) {
+	for i, key := range keys {
+		switch t := v.(type) {
+		case map[string]interface{}:
+			var ok bool
+			if v, ok = t[key]; !ok {
+				return nil, false
+			}
+		case []interface{}:
+			if key == "#" {
+				if i == len(keys)-1 {
+					return float64(len(t)), true
+				}
+				results := make([]interface{}, 0, len(t))
+				for _, elem := range t {
+					if r, ok := queryKeys(elem, keys[i+1:]); ok {
+						results = append(results, r)
+					}
+				}
+				return results, true
+			}
+			n, err := strconv.Atoi(key)
+			if err != nil || n < 0 || n >= len(t) {
+				return nil, false
+			}
+			v = t[n]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// splitPath splits a path into its keys, on the dots that aren't escaped.
+func splitPath(path string) []string {
+	var keys []string
+	var key []byte
+	for i := 0; i < len(path); i++ {
+		switch {
+		case path[i] == '\\' && i+1 < len(path):
+			i++
+			key = append(key, path[i])
+		case path[i] == '.':
+			keys = append(keys, string(key))
+			key = key[:0]
+		default:
+			key = append(key, path[i])
+		}
+	}
+	return append(keys, string(key))
+}
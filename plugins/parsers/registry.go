@@ -6,7 +6,9 @@ import (
 	"github.com/influxdata/telegraf"
 
 	"github.com/influxdata/telegraf/plugins/parsers/collectd"
+	"github.com/influxdata/telegraf/plugins/parsers/csv"
 	"github.com/influxdata/telegraf/plugins/parsers/graphite"
+	"github.com/influxdata/telegraf/plugins/parsers/grok"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/plugins/parsers/json"
 	"github.com/influxdata/telegraf/plugins/parsers/nagios"
@@ -20,6 +22,17 @@ type ParserInput interface {
 	SetParser(parser Parser)
 }
 
+// ParserFunc creates a new parser.
+type ParserFunc func() (Parser, error)
+
+// ParserFuncInput is an interface for input plugins that need a parser for
+// each of their sources of data, as parsers keep state and are not safe for
+// concurrent use.
+type ParserFuncInput interface {
+	// SetParserFunc sets the function creating the parsers of the input
+	SetParserFunc(fn ParserFunc)
+}
+
 // Parser is an interface defining functions that a parser plugin must satisfy.
 type Parser interface {
 	// Parse takes a byte buffer separated by newlines
@@ -41,7 +54,8 @@ type Parser interface {
 // Config is a struct that covers the data types needed for all parser types,
 // and can be used to instantiate _any_ of the parsers.
 type Config struct {
-	// Dataformat can be one of: json, influx, graphite, value, nagios
+	// Dataformat can be one of: json, influx, graphite, value, nagios,
+	// collectd, csv, grok
 	DataFormat string
 
 	// Separator only applied to Graphite data.
@@ -51,6 +65,19 @@ type Config struct {
 
 	// TagKeys only apply to JSON data
 	TagKeys []string
+	// JSONQuery is the GJSON path of the object or array of objects to parse.
+	JSONQuery string
+	// JSONQueries maps measurement names to the GJSON path of their objects.
+	JSONQueries map[string]string
+	// JSONNameKey is the key of the value to use as measurement name.
+	JSONNameKey string
+	// JSONTimeKey is the key of the timestamp of the metrics.
+	JSONTimeKey string
+	// JSONTimeFormat is the format of the timestamp: unix, unix_ms, unix_us,
+	// unix_ns, the name of a time package layout or a Go time layout.
+	JSONTimeFormat string
+	// JSONStringFields are the keys of the string values to keep as fields.
+	JSONStringFields []string
 	// MetricName applies to JSON & value. This will be the name of the measurement.
 	MetricName string
 
@@ -64,6 +91,29 @@ type Config struct {
 	// DataType only applies to value, this will be the type to parse value to
 	DataType string
 
+	// CSV configuration, see csv.Parser
+	CSVHeaderRowCount    int
+	CSVSkipRows          int
+	CSVSkipColumns       int
+	CSVDelimiter         string
+	CSVComment           string
+	CSVTrimSpace         bool
+	CSVColumnNames       []string
+	CSVColumnTypes       []string
+	CSVTagColumns        []string
+	CSVMeasurementColumn string
+	CSVTimestampColumn   string
+	CSVTimestampFormat   string
+
+	// GrokPatterns are the patterns to match the lines with, see grok.Parser
+	GrokPatterns []string
+	// GrokCustomPatterns are pattern definitions, one per line
+	GrokCustomPatterns string
+	// GrokCustomPatternFiles are the paths of files with pattern definitions
+	GrokCustomPatternFiles []string
+	// GrokTimezone is the location of timestamps without an offset
+	GrokTimezone string
+
 	// DefaultTags are the default tags that will be added to all parsed metrics.
 	DefaultTags map[string]string
 }
@@ -74,8 +124,17 @@ func NewParser(config *Config) (Parser, error) {
 	var parser Parser
 	switch config.DataFormat {
 	case "json":
-		parser, err = NewJSONParser(config.MetricName,
-			config.TagKeys, config.DefaultTags)
+		parser, err = json.New(&json.Config{
+			MetricName:   config.MetricName,
+			TagKeys:      config.TagKeys,
+			DefaultTags:  config.DefaultTags,
+			Query:        config.JSONQuery,
+			Queries:      config.JSONQueries,
+			NameKey:      config.JSONNameKey,
+			TimeKey:      config.JSONTimeKey,
+			TimeFormat:   config.JSONTimeFormat,
+			StringFields: config.JSONStringFields,
+		})
 	case "value":
 		parser, err = NewValueParser(config.MetricName,
 			config.DataType, config.DefaultTags)
@@ -89,6 +148,10 @@ func NewParser(config *Config) (Parser, error) {
 	case "collectd":
 		parser, err = NewCollectdParser(config.CollectdAuthFile,
 			config.CollectdSecurityLevel, config.CollectdTypesDB)
+	case "csv":
+		parser, err = NewCSVParser(config)
+	case "grok":
+		parser, err = NewGrokParser(config)
 	default:
 		err = fmt.Errorf("Invalid data format: %s", config.DataFormat)
 	}
@@ -100,12 +163,11 @@ func NewJSONParser(
 	tagKeys []string,
 	defaultTags map[string]string,
 ) (Parser, error) {
-	parser := &json.JSONParser{
+	return json.New(&json.Config{
 		MetricName:  metricName,
 		TagKeys:     tagKeys,
 		DefaultTags: defaultTags,
-	}
-	return parser, nil
+	})
 }
 
 func NewNagiosParser() (Parser, error) {
@@ -143,3 +205,41 @@ func NewCollectdParser(
 ) (Parser, error) {
 	return collectd.NewCollectdParser(authFile, securityLevel, typesDB)
 }
+
+func NewCSVParser(config *Config) (Parser, error) {
+	parser := &csv.Parser{
+		MetricName:        config.MetricName,
+		HeaderRowCount:    config.CSVHeaderRowCount,
+		SkipRows:          config.CSVSkipRows,
+		SkipColumns:       config.CSVSkipColumns,
+		Delimiter:         config.CSVDelimiter,
+		Comment:           config.CSVComment,
+		TrimSpace:         config.CSVTrimSpace,
+		ColumnNames:       config.CSVColumnNames,
+		ColumnTypes:       config.CSVColumnTypes,
+		TagColumns:        config.CSVTagColumns,
+		MeasurementColumn: config.CSVMeasurementColumn,
+		TimestampColumn:   config.CSVTimestampColumn,
+		TimestampFormat:   config.CSVTimestampFormat,
+		DefaultTags:       config.DefaultTags,
+	}
+	if err := parser.Validate(); err != nil {
+		return nil, err
+	}
+	return parser, nil
+}
+
+func NewGrokParser(config *Config) (Parser, error) {
+	parser := &grok.Parser{
+		Measurement:        config.MetricName,
+		Patterns:           config.GrokPatterns,
+		CustomPatterns:     config.GrokCustomPatterns,
+		CustomPatternFiles: config.GrokCustomPatternFiles,
+		Timezone:           config.GrokTimezone,
+		DefaultTags:        config.DefaultTags,
+	}
+	if err := parser.Compile(); err != nil {
+		return nil, err
+	}
+	return parser, nil
+}
@@ -0,0 +1,383 @@
+package converter
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+var sampleConfig = `
+  ## Tags to convert
+  ##
+  ## The table key determines the target type, and the array of key-values
+  ## select the keys to convert.  The array may contain globs.
+  ##   <target-type> = [<tag-key>...]
+  [processors.converter.tags]
+    measurement = []
+    string = []
+    integer = []
+    float = []
+    boolean = []
+
+  ## Fields to convert
+  ##
+  ## The table key determines the target type, and the array of key-values
+  ## select the keys to convert.  The array may contain globs.
+  ##   <target-type> = [<field-key>...]
+  [processors.converter.fields]
+    measurement = []
+    tag = []
+    string = []
+    integer = []
+    float = []
+    boolean = []
+`
+
+type Conversion struct {
+	Measurement []string `toml:"measurement"`
+	Tag         []string `toml:"tag"`
+	String      []string `toml:"string"`
+	Integer     []string `toml:"integer"`
+	Float       []string `toml:"float"`
+	Boolean     []string `toml:"boolean"`
+}
+
+type Converter struct {
+	Tags   *Conversion `toml:"tags"`
+	Fields *Conversion `toml:"fields"`
+
+	once   sync.Once
+	err    error
+	tags   *conversionFilter
+	fields *conversionFilter
+}
+
+// conversionFilter holds the compiled globs of a Conversion.
+type conversionFilter struct {
+	Measurement filter.Filter
+	Tag         filter.Filter
+	String      filter.Filter
+	Integer     filter.Filter
+	Float       filter.Filter
+	Boolean     filter.Filter
+}
+
+func (c *Converter) SampleConfig() string {
+	return sampleConfig
+}
+
+func (c *Converter) Description() string {
+	return "Convert values to another metric value type"
+}
+
+// Stateless returns true, the processor can be applied concurrently.
+func (c *Converter) Stateless() bool {
+	return true
+}
+
+// Init compiles the conversions, so that invalid ones are reported when the
+// configuration is loaded.
+func (c *Converter) Init() error {
+	c.once.Do(func() {
+		c.err = c.compile()
+	})
+	return c.err
+}
+
+func (c *Converter) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	if err := c.Init(); err != nil {
+		return in
+	}
+
+	for i, m := range in {
+		name := m.Name()
+		tags := m.Tags()
+		fields := m.Fields()
+
+		tagsChanged := c.convertTags(&name, tags, fields)
+		fieldsChanged := c.convertFields(&name, tags, fields)
+		if !tagsChanged && !fieldsChanged {
+			continue
+		}
+
+		out, err := metric.New(name, tags, fields, m.Time(), m.Type())
+		if err != nil {
+			log.Printf("E! [processors.converter] could not convert metric %s: %s",
+				m.Name(), err)
+			continue
+		}
+		out.SetAggregate(m.IsAggregate())
+		in[i] = out
+	}
+	return in
+}
+
+func (c *Converter) compile() error {
+	var err error
+	c.tags, err = compileConversion(c.Tags)
+	if err != nil {
+		return err
+	}
+	if c.Tags != nil && len(c.Tags.Tag) > 0 {
+		return fmt.Errorf("tags cannot be converted to tags")
+	}
+
+	c.fields, err = compileConversion(c.Fields)
+	return err
+}
+
+func compileConversion(conv *Conversion) (*conversionFilter, error) {
+	if conv == nil {
+		return nil, nil
+	}
+
+	var err error
+	cf := &conversionFilter{}
+	cf.Measurement, err = filter.Compile(conv.Measurement)
+	if err != nil {
+		return nil, err
+	}
+	cf.Tag, err = filter.Compile(conv.Tag)
+	if err != nil {
+		return nil, err
+	}
+	cf.String, err = filter.Compile(conv.String)
+	if err != nil {
+		return nil, err
+	}
+	cf.Integer, err = filter.Compile(conv.Integer)
+	if err != nil {
+		return nil, err
+	}
+	cf.Float, err = filter.Compile(conv.Float)
+	if err != nil {
+		return nil, err
+	}
+	cf.Boolean, err = filter.Compile(conv.Boolean)
+	if err != nil {
+		return nil, err
+	}
+	return cf, nil
+}
+
+func match(f filter.Filter, key string) bool {
+	return f != nil && f.Match(key)
+}
+
+// convertTags converts tags to the measurement name or to fields, and
+// returns true if any tag was converted. Tags which cannot be converted are
+// kept.
+func (c *Converter) convertTags(
+	name *string,
+	tags map[string]string,
+	fields map[string]interface{},
+) bool {
+	if c.tags == nil {
+		return false
+	}
+
+	var changed bool
+	for key, value := range tags {
+		switch {
+		case match(c.tags.Measurement, key):
+			*name = value
+		case match(c.tags.String, key):
+			fields[key] = value
+		case match(c.tags.Integer, key):
+			v, ok := toInteger(value)
+			if !ok {
+				logConversionError("integer", key, value)
+				continue
+			}
+			fields[key] = v
+		case match(c.tags.Float, key):
+			v, ok := toFloat(value)
+			if !ok {
+				logConversionError("float", key, value)
+				continue
+			}
+			fields[key] = v
+		case match(c.tags.Boolean, key):
+			v, ok := toBool(value)
+			if !ok {
+				logConversionError("boolean", key, value)
+				continue
+			}
+			fields[key] = v
+		default:
+			continue
+		}
+		delete(tags, key)
+		changed = true
+	}
+	return changed
+}
+
+// convertFields converts fields to other field types, tags or the
+// measurement name, and returns true if any field was converted. Fields which
+// cannot be converted are dropped.
+func (c *Converter) convertFields(
+	name *string,
+	tags map[string]string,
+	fields map[string]interface{},
+) bool {
+	if c.fields == nil {
+		return false
+	}
+
+	var changed bool
+	for key, value := range fields {
+		switch {
+		case match(c.fields.Measurement, key):
+			if v, ok := toString(value); ok {
+				*name = v
+			} else {
+				logConversionError("measurement", key, value)
+			}
+			delete(fields, key)
+		case match(c.fields.Tag, key):
+			if v, ok := toString(value); ok {
+				tags[key] = v
+			} else {
+				logConversionError("tag", key, value)
+			}
+			delete(fields, key)
+		case match(c.fields.String, key):
+			if v, ok := toString(value); ok {
+				fields[key] = v
+			} else {
+				logConversionError("string", key, value)
+				delete(fields, key)
+			}
+		case match(c.fields.Integer, key):
+			if v, ok := toInteger(value); ok {
+				fields[key] = v
+			} else {
+				logConversionError("integer", key, value)
+				delete(fields, key)
+			}
+		case match(c.fields.Float, key):
+			if v, ok := toFloat(value); ok {
+				fields[key] = v
+			} else {
+				logConversionError("float", key, value)
+				delete(fields, key)
+			}
+		case match(c.fields.Boolean, key):
+			if v, ok := toBool(value); ok {
+				fields[key] = v
+			} else {
+				logConversionError("boolean", key, value)
+				delete(fields, key)
+			}
+		default:
+			continue
+		}
+		changed = true
+	}
+	return changed
+}
+
+func logConversionError(target, key string, value interface{}) {
+	log.Printf("D! [processors.converter] could not convert %q (%v) to %s",
+		key, value, target)
+}
+
+func toInteger(v interface{}) (int64, bool) {
+	switch value := v.(type) {
+	case int64:
+		return value, true
+	case uint64:
+		if value > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(value), true
+	case float64:
+		if math.IsNaN(value) || value < math.MinInt64 || value >= math.MaxInt64 {
+			return 0, false
+		}
+		return int64(value), true
+	case bool:
+		if value {
+			return 1, true
+		}
+		return 0, true
+	case string:
+		result, err := strconv.ParseInt(value, 0, 64)
+		if err != nil {
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return 0, false
+			}
+			return toInteger(f)
+		}
+		return result, true
+	}
+	return 0, false
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch value := v.(type) {
+	case int64:
+		return float64(value), true
+	case uint64:
+		return float64(value), true
+	case float64:
+		return value, true
+	case bool:
+		if value {
+			return 1.0, true
+		}
+		return 0.0, true
+	case string:
+		result, err := strconv.ParseFloat(value, 64)
+		return result, err == nil
+	}
+	return 0, false
+}
+
+func toBool(v interface{}) (bool, bool) {
+	switch value := v.(type) {
+	case int64:
+		return value != 0, true
+	case uint64:
+		return value != 0, true
+	case float64:
+		return value != 0, true
+	case bool:
+		return value, true
+	case string:
+		result, err := strconv.ParseBool(value)
+		return result, err == nil
+	}
+	return false, false
+}
+
+func toString(v interface{}) (string, bool) {
+	switch value := v.(type) {
+	case int64:
+		return strconv.FormatInt(value, 10), true
+	case uint64:
+		return strconv.FormatUint(value, 10), true
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(value), true
+	case string:
+		return value, true
+	}
+	return "", false
+}
+
+func init() {
+	processors.Add("converter", func() telegraf.Processor {
+		return &Converter{}
+	})
+}
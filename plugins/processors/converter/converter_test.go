@@ -0,0 +1,245 @@
+package converter
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMetric(
+	t *testing.T,
+	name string,
+	tags map[string]string,
+	fields map[string]interface{},
+) telegraf.Metric {
+	m, err := metric.New(name, tags, fields, time.Unix(1500000000, 0))
+	require.NoError(t, err)
+	return m
+}
+
+func TestConverter(t *testing.T) {
+	tests := []struct {
+		name      string
+		converter *Converter
+		input     telegraf.Metric
+		expected  telegraf.Metric
+	}{
+		{
+			name:      "empty",
+			converter: &Converter{},
+			input: newMetric(t, "cpu",
+				map[string]string{"host": "localhost"},
+				map[string]interface{}{"value": 42.0}),
+			expected: newMetric(t, "cpu",
+				map[string]string{"host": "localhost"},
+				map[string]interface{}{"value": 42.0}),
+		},
+		{
+			name: "tags to fields",
+			converter: &Converter{
+				Tags: &Conversion{
+					String:  []string{"string"},
+					Integer: []string{"int*"},
+					Float:   []string{"float"},
+					Boolean: []string{"bool"},
+				},
+			},
+			input: newMetric(t, "cpu",
+				map[string]string{
+					"string":    "howdy",
+					"int":       "42",
+					"int_hex":   "0x2a",
+					"int_float": "42.5",
+					"float":     "4.2",
+					"bool":      "true",
+					"host":      "localhost",
+				},
+				map[string]interface{}{"value": 42.0}),
+			expected: newMetric(t, "cpu",
+				map[string]string{"host": "localhost"},
+				map[string]interface{}{
+					"value":     42.0,
+					"string":    "howdy",
+					"int":       int64(42),
+					"int_hex":   int64(42),
+					"int_float": int64(42),
+					"float":     4.2,
+					"bool":      true,
+				}),
+		},
+		{
+			name: "tag to measurement",
+			converter: &Converter{
+				Tags: &Conversion{
+					Measurement: []string{"name"},
+				},
+			},
+			input: newMetric(t, "cpu",
+				map[string]string{"name": "processor"},
+				map[string]interface{}{"value": 42.0}),
+			expected: newMetric(t, "processor",
+				map[string]string{},
+				map[string]interface{}{"value": 42.0}),
+		},
+		{
+			name: "field types",
+			converter: &Converter{
+				Fields: &Conversion{
+					String:  []string{"a"},
+					Integer: []string{"b", "c"},
+					Float:   []string{"d"},
+					Boolean: []string{"e", "f"},
+				},
+			},
+			input: newMetric(t, "cpu",
+				map[string]string{},
+				map[string]interface{}{
+					"a": 4.2,
+					"b": "42",
+					"c": 42.9,
+					"d": int64(42),
+					"e": int64(0),
+					"f": "false",
+				}),
+			expected: newMetric(t, "cpu",
+				map[string]string{},
+				map[string]interface{}{
+					"a": "4.2",
+					"b": int64(42),
+					"c": int64(42),
+					"d": 42.0,
+					"e": false,
+					"f": false,
+				}),
+		},
+		{
+			name: "field to tag and measurement",
+			converter: &Converter{
+				Fields: &Conversion{
+					Measurement: []string{"name"},
+					Tag:         []string{"status"},
+				},
+			},
+			input: newMetric(t, "cpu",
+				map[string]string{},
+				map[string]interface{}{
+					"name":   "processor",
+					"status": int64(200),
+					"value":  42.0,
+				}),
+			expected: newMetric(t, "processor",
+				map[string]string{"status": "200"},
+				map[string]interface{}{"value": 42.0}),
+		},
+		{
+			name: "invalid values are dropped",
+			converter: &Converter{
+				Fields: &Conversion{
+					Integer: []string{"a"},
+					Boolean: []string{"b"},
+				},
+			},
+			input: newMetric(t, "cpu",
+				map[string]string{},
+				map[string]interface{}{
+					"a":     "howdy",
+					"b":     "maybe",
+					"value": 42.0,
+				}),
+			expected: newMetric(t, "cpu",
+				map[string]string{},
+				map[string]interface{}{"value": 42.0}),
+		},
+		{
+			name: "invalid tags are kept",
+			converter: &Converter{
+				Tags: &Conversion{
+					Integer: []string{"a"},
+					Float:   []string{"b"},
+					Boolean: []string{"c"},
+				},
+			},
+			input: newMetric(t, "cpu",
+				map[string]string{"a": "howdy", "b": "x", "c": "maybe"},
+				map[string]interface{}{"value": 42.0}),
+			expected: newMetric(t, "cpu",
+				map[string]string{"a": "howdy", "b": "x", "c": "maybe"},
+				map[string]interface{}{"value": 42.0}),
+		},
+		{
+			name: "converting last field keeps metric",
+			converter: &Converter{
+				Fields: &Conversion{
+					Tag: []string{"value"},
+				},
+			},
+			input: newMetric(t, "cpu",
+				map[string]string{},
+				map[string]interface{}{"value": 42.0}),
+			expected: newMetric(t, "cpu",
+				map[string]string{},
+				map[string]interface{}{"value": 42.0}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := tt.converter.Apply(tt.input)
+			require.Len(t, actual, 1)
+			assert.Equal(t, tt.expected.Name(), actual[0].Name())
+			assert.Equal(t, tt.expected.Tags(), actual[0].Tags())
+			assert.Equal(t, tt.expected.Fields(), actual[0].Fields())
+			assert.Equal(t, tt.expected.Time(), actual[0].Time())
+		})
+	}
+}
+
+func TestInvalidConfig(t *testing.T) {
+	c := &Converter{
+		Tags: &Conversion{
+			Tag: []string{"host"},
+		},
+	}
+	m := newMetric(t, "cpu",
+		map[string]string{"host": "localhost"},
+		map[string]interface{}{"value": 42.0})
+	actual := c.Apply(m)
+	require.Len(t, actual, 1)
+	assert.Equal(t, m, actual[0])
+}
+
+func TestToInteger(t *testing.T) {
+	_, ok := toInteger(math.NaN())
+	assert.False(t, ok)
+	_, ok = toInteger(1e20)
+	assert.False(t, ok)
+	v, ok := toInteger(true)
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), v)
+}
+
+func TestConvertUnsigned(t *testing.T) {
+	i, ok := toInteger(uint64(42))
+	assert.True(t, ok)
+	assert.Equal(t, int64(42), i)
+	_, ok = toInteger(uint64(math.MaxUint64))
+	assert.False(t, ok)
+
+	f, ok := toFloat(uint64(42))
+	assert.True(t, ok)
+	assert.Equal(t, 42.0, f)
+
+	b, ok := toBool(uint64(0))
+	assert.True(t, ok)
+	assert.False(t, b)
+
+	str, ok := toString(uint64(math.MaxUint64))
+	assert.True(t, ok)
+	assert.Equal(t, "18446744073709551615", str)
+}
@@ -0,0 +1,157 @@
+package regex
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+var sampleConfig = `
+  ## Tag and field conversions defined in separate sub-tables. The pattern
+  ## is applied to the value of the tag or field with the given key, and on
+  ## a match the value is replaced, capture groups being available as ${1}.
+  ## Only string fields are modified.
+  [[processors.regex.tags]]
+    ## Tag to change
+    key = "resp_code"
+    ## Regular expression to match on the tag value
+    pattern = "^(\\d)\\d\\d$"
+    ## Replacement for the matched value
+    replacement = "${1}xx"
+
+  [[processors.regex.fields]]
+    key = "request"
+    ## All the power of the Go regular expressions available here
+    ## For example, named subgroups
+    pattern = "^/api(?P<method>/[\\w/]+)\\S*"
+    replacement = "${method}"
+    ## If result_key is present, a new tag or field is created with the
+    ## result, instead of replacing the value in place.
+    result_key = "method"
+`
+
+type Converter struct {
+	Key         string `toml:"key"`
+	Pattern     string `toml:"pattern"`
+	Replacement string `toml:"replacement"`
+	ResultKey   string `toml:"result_key"`
+
+	regex *regexp.Regexp
+}
+
+type Regex struct {
+	Tags   []Converter `toml:"tags"`
+	Fields []Converter `toml:"fields"`
+
+	once sync.Once
+	err  error
+}
+
+func (r *Regex) SampleConfig() string {
+	return sampleConfig
+}
+
+func (r *Regex) Description() string {
+	return "Transforms tag and field values with regex pattern"
+}
+
+// Stateless returns true, the processor can be applied concurrently.
+func (r *Regex) Stateless() bool {
+	return true
+}
+
+// Init compiles the patterns, so that invalid ones are reported when the
+// configuration is loaded.
+func (r *Regex) Init() error {
+	r.once.Do(func() {
+		r.err = r.compile()
+	})
+	return r.err
+}
+
+func (r *Regex) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	if err := r.Init(); err != nil {
+		return in
+	}
+
+	for i, m := range in {
+		tags := m.Tags()
+		fields := m.Fields()
+
+		var changed bool
+		for _, c := range r.Tags {
+			value, ok := tags[c.Key]
+			if !ok {
+				continue
+			}
+			if newValue, ok := convert(c, value); ok {
+				tags[resultKey(c)] = newValue
+				changed = true
+			}
+		}
+		for _, c := range r.Fields {
+			value, ok := fields[c.Key].(string)
+			if !ok {
+				continue
+			}
+			if newValue, ok := convert(c, value); ok {
+				fields[resultKey(c)] = newValue
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+
+		out, err := metric.New(m.Name(), tags, fields, m.Time(), m.Type())
+		if err != nil {
+			log.Printf("E! [processors.regex] could not transform metric %s: %s",
+				m.Name(), err)
+			continue
+		}
+		out.SetAggregate(m.IsAggregate())
+		in[i] = out
+	}
+	return in
+}
+
+func (r *Regex) compile() error {
+	for _, converters := range [][]Converter{r.Tags, r.Fields} {
+		for i := range converters {
+			c := &converters[i]
+			re, err := regexp.Compile(c.Pattern)
+			if err != nil {
+				return fmt.Errorf("invalid pattern %q: %s", c.Pattern, err)
+			}
+			c.regex = re
+		}
+	}
+	return nil
+}
+
+// convert applies the converter to value, and returns false if the pattern
+// did not match.
+func convert(c Converter, value string) (string, bool) {
+	if !c.regex.MatchString(value) {
+		return "", false
+	}
+	return c.regex.ReplaceAllString(value, c.Replacement), true
+}
+
+func resultKey(c Converter) string {
+	if c.ResultKey != "" {
+		return c.ResultKey
+	}
+	return c.Key
+}
+
+func init() {
+	processors.Add("regex", func() telegraf.Processor {
+		return &Regex{}
+	})
+}
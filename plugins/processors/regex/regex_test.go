@@ -0,0 +1,241 @@
+package regex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newM1(t *testing.T) telegraf.Metric {
+	m, err := metric.New("access_log",
+		map[string]string{
+			"verb":      "GET",
+			"resp_code": "200",
+		},
+		map[string]interface{}{
+			"request": "/users/42/",
+		},
+		time.Unix(1500000000, 0),
+	)
+	require.NoError(t, err)
+	return m
+}
+
+func newM2(t *testing.T) telegraf.Metric {
+	m, err := metric.New("access_log",
+		map[string]string{
+			"verb":      "GET",
+			"resp_code": "200",
+		},
+		map[string]interface{}{
+			"request":       "/api/search/?category=plugins&q=regex&sort=asc",
+			"ignore_number": int64(200),
+			"ignore_bool":   true,
+		},
+		time.Unix(1500000000, 0),
+	)
+	require.NoError(t, err)
+	return m
+}
+
+func TestFieldConversions(t *testing.T) {
+	tests := []struct {
+		message        string
+		converter      Converter
+		expectedFields map[string]interface{}
+	}{
+		{
+			message: "Should change existing field",
+			converter: Converter{
+				Key:         "request",
+				Pattern:     "^/users/\\d+/$",
+				Replacement: "/users/{id}/",
+			},
+			expectedFields: map[string]interface{}{
+				"request": "/users/{id}/",
+			},
+		},
+		{
+			message: "Should add new field",
+			converter: Converter{
+				Key:         "request",
+				Pattern:     "^/users/\\d+/$",
+				Replacement: "/users/{id}/",
+				ResultKey:   "normalized_request",
+			},
+			expectedFields: map[string]interface{}{
+				"request":            "/users/42/",
+				"normalized_request": "/users/{id}/",
+			},
+		},
+		{
+			message: "Should not change on mismatch",
+			converter: Converter{
+				Key:         "request",
+				Pattern:     "^/posts/\\d+/$",
+				Replacement: "/posts/{id}/",
+			},
+			expectedFields: map[string]interface{}{
+				"request": "/users/42/",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		regex := &Regex{}
+		regex.Fields = []Converter{test.converter}
+
+		processed := regex.Apply(newM1(t))
+
+		expectedTags := map[string]string{
+			"verb":      "GET",
+			"resp_code": "200",
+		}
+
+		require.Len(t, processed, 1, test.message)
+		assert.Equal(t, test.expectedFields, processed[0].Fields(), test.message)
+		assert.Equal(t, expectedTags, processed[0].Tags(), "Should not change tags")
+		assert.Equal(t, "access_log", processed[0].Name(), "Should not change name")
+	}
+}
+
+func TestTagConversions(t *testing.T) {
+	tests := []struct {
+		message      string
+		converter    Converter
+		expectedTags map[string]string
+	}{
+		{
+			message: "Should change existing tag",
+			converter: Converter{
+				Key:         "resp_code",
+				Pattern:     "^(\\d)\\d\\d$",
+				Replacement: "${1}xx",
+			},
+			expectedTags: map[string]string{
+				"verb":      "GET",
+				"resp_code": "2xx",
+			},
+		},
+		{
+			message: "Should add new tag",
+			converter: Converter{
+				Key:         "resp_code",
+				Pattern:     "^(\\d)\\d\\d$",
+				Replacement: "${1}xx",
+				ResultKey:   "resp_code_group",
+			},
+			expectedTags: map[string]string{
+				"verb":            "GET",
+				"resp_code":       "200",
+				"resp_code_group": "2xx",
+			},
+		},
+		{
+			message: "Should not change on missing tag",
+			converter: Converter{
+				Key:         "status",
+				Pattern:     "^(\\d)\\d\\d$",
+				Replacement: "${1}xx",
+			},
+			expectedTags: map[string]string{
+				"verb":      "GET",
+				"resp_code": "200",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		regex := &Regex{}
+		regex.Tags = []Converter{test.converter}
+
+		processed := regex.Apply(newM1(t))
+
+		require.Len(t, processed, 1, test.message)
+		assert.Equal(t, test.expectedTags, processed[0].Tags(), test.message)
+		assert.Equal(t, map[string]interface{}{"request": "/users/42/"},
+			processed[0].Fields(), "Should not change fields")
+	}
+}
+
+func TestMultipleConversions(t *testing.T) {
+	regex := &Regex{
+		Tags: []Converter{
+			{
+				Key:         "resp_code",
+				Pattern:     "^(\\d)\\d\\d$",
+				Replacement: "${1}xx",
+				ResultKey:   "resp_code_group",
+			},
+			{
+				Key:         "resp_code_group",
+				Pattern:     "2xx",
+				Replacement: "OK",
+				ResultKey:   "resp_code_text",
+			},
+		},
+		Fields: []Converter{
+			{
+				Key:         "request",
+				Pattern:     "^/api(?P<method>/[\\w/]+)\\S*",
+				Replacement: "${method}",
+				ResultKey:   "method",
+			},
+			{
+				Key:         "request",
+				Pattern:     ".*category=(\\w+).*",
+				Replacement: "${1}",
+				ResultKey:   "search_category",
+			},
+			{
+				Key:         "ignore_number",
+				Pattern:     ".*",
+				Replacement: "",
+			},
+		},
+	}
+
+	processed := regex.Apply(newM2(t))
+
+	expectedFields := map[string]interface{}{
+		"request":         "/api/search/?category=plugins&q=regex&sort=asc",
+		"method":          "/search/",
+		"search_category": "plugins",
+		"ignore_number":   int64(200),
+		"ignore_bool":     true,
+	}
+	expectedTags := map[string]string{
+		"verb":            "GET",
+		"resp_code":       "200",
+		"resp_code_group": "2xx",
+		"resp_code_text":  "OK",
+	}
+
+	require.Len(t, processed, 1)
+	assert.Equal(t, expectedFields, processed[0].Fields())
+	assert.Equal(t, expectedTags, processed[0].Tags())
+}
+
+func TestInvalidPattern(t *testing.T) {
+	regex := &Regex{
+		Tags: []Converter{
+			{
+				Key:         "resp_code",
+				Pattern:     "^(\\d",
+				Replacement: "${1}xx",
+			},
+		},
+	}
+
+	assert.Error(t, regex.Init())
+
+	m := newM1(t)
+	processed := regex.Apply(m)
+	require.Len(t, processed, 1)
+	assert.Equal(t, m, processed[0])
+}
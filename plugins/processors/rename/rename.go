@@ -0,0 +1,102 @@
+package rename
+
+import (
+	"log"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+var sampleConfig = `
+  ## Each replacement renames one of a measurement, tag or field to dest.
+  ## Replacements are applied in order.
+  [[processors.rename.replace]]
+    measurement = "network_interface_throughput"
+    dest = "throughput"
+
+  [[processors.rename.replace]]
+    tag = "hostname"
+    dest = "host"
+
+  [[processors.rename.replace]]
+    field = "lower"
+    dest = "min"
+`
+
+type Replace struct {
+	Measurement string `toml:"measurement"`
+	Tag         string `toml:"tag"`
+	Field       string `toml:"field"`
+	Dest        string `toml:"dest"`
+}
+
+type Rename struct {
+	Replaces []Replace `toml:"replace"`
+}
+
+func (r *Rename) SampleConfig() string {
+	return sampleConfig
+}
+
+func (r *Rename) Description() string {
+	return "Rename measurements, tags, and fields that pass through this filter."
+}
+
+// Stateless returns true, the processor can be applied concurrently.
+func (r *Rename) Stateless() bool {
+	return true
+}
+
+func (r *Rename) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	for i, m := range in {
+		name := m.Name()
+		tags := m.Tags()
+		fields := m.Fields()
+
+		var changed bool
+		for _, replace := range r.Replaces {
+			if replace.Dest == "" {
+				continue
+			}
+			switch {
+			case replace.Measurement != "":
+				if name == replace.Measurement {
+					name = replace.Dest
+					changed = true
+				}
+			case replace.Tag != "":
+				if v, ok := tags[replace.Tag]; ok {
+					delete(tags, replace.Tag)
+					tags[replace.Dest] = v
+					changed = true
+				}
+			case replace.Field != "":
+				if v, ok := fields[replace.Field]; ok {
+					delete(fields, replace.Field)
+					fields[replace.Dest] = v
+					changed = true
+				}
+			}
+		}
+		if !changed {
+			continue
+		}
+
+		out, err := metric.New(name, tags, fields, m.Time(), m.Type())
+		if err != nil {
+			log.Printf("E! [processors.rename] could not rename metric %s: %s",
+				m.Name(), err)
+			continue
+		}
+		out.SetAggregate(m.IsAggregate())
+		in[i] = out
+	}
+	return in
+}
+
+func init() {
+	processors.Add("rename", func() telegraf.Processor {
+		return &Rename{}
+	})
+}
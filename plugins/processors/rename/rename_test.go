@@ -0,0 +1,90 @@
+package rename
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMetric(t *testing.T) telegraf.Metric {
+	m, err := metric.New("network_interface_throughput",
+		map[string]string{"hostname": "localhost", "region": "us-east-1"},
+		map[string]interface{}{"lower": int64(1), "upper": int64(10)},
+		time.Unix(1500000000, 0),
+		telegraf.Gauge)
+	require.NoError(t, err)
+	return m
+}
+
+func TestMeasurementRename(t *testing.T) {
+	r := &Rename{
+		Replaces: []Replace{
+			{Measurement: "network_interface_throughput", Dest: "throughput"},
+			{Measurement: "cpu", Dest: "processor"},
+		},
+	}
+	out := r.Apply(newMetric(t))
+	require.Len(t, out, 1)
+	assert.Equal(t, "throughput", out[0].Name())
+	assert.Equal(t, telegraf.Gauge, out[0].Type())
+	assert.Equal(t, time.Unix(1500000000, 0), out[0].Time())
+}
+
+func TestTagRename(t *testing.T) {
+	r := &Rename{
+		Replaces: []Replace{
+			{Tag: "hostname", Dest: "host"},
+			{Tag: "missing", Dest: "other"},
+		},
+	}
+	out := r.Apply(newMetric(t))
+	require.Len(t, out, 1)
+	assert.Equal(t,
+		map[string]string{"host": "localhost", "region": "us-east-1"},
+		out[0].Tags())
+}
+
+func TestFieldRename(t *testing.T) {
+	r := &Rename{
+		Replaces: []Replace{
+			{Field: "lower", Dest: "min"},
+			{Field: "upper", Dest: "max"},
+		},
+	}
+	out := r.Apply(newMetric(t))
+	require.Len(t, out, 1)
+	assert.Equal(t,
+		map[string]interface{}{"min": int64(1), "max": int64(10)},
+		out[0].Fields())
+}
+
+func TestRenameChain(t *testing.T) {
+	r := &Rename{
+		Replaces: []Replace{
+			{Tag: "hostname", Dest: "host"},
+			{Tag: "host", Dest: "server"},
+		},
+	}
+	out := r.Apply(newMetric(t))
+	require.Len(t, out, 1)
+	assert.Equal(t,
+		map[string]string{"server": "localhost", "region": "us-east-1"},
+		out[0].Tags())
+}
+
+func TestNoMatchUnchanged(t *testing.T) {
+	r := &Rename{
+		Replaces: []Replace{
+			{Measurement: "cpu", Dest: "processor"},
+		},
+	}
+	m := newMetric(t)
+	out := r.Apply(m)
+	require.Len(t, out, 1)
+	assert.Equal(t, m, out[0])
+}
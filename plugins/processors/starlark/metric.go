@@ -0,0 +1,287 @@
+package starlark
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"go.starlark.net/starlark"
+)
+
+// Metric is the Starlark representation of a telegraf.Metric. Its name and
+// time can be set, and its tags and fields are plain dicts that can be
+// modified in place.
+type Metric struct {
+	name      string
+	tags      *starlark.Dict
+	fields    *starlark.Dict
+	nsec      int64
+	mType     telegraf.ValueType
+	aggregate bool
+	frozen    bool
+}
+
+var (
+	_ starlark.HasAttrs    = (*Metric)(nil)
+	_ starlark.HasSetField = (*Metric)(nil)
+)
+
+func newMetric(m telegraf.Metric) *Metric {
+	sm := &Metric{
+		name:      m.Name(),
+		tags:      new(starlark.Dict),
+		fields:    new(starlark.Dict),
+		nsec:      m.UnixNano(),
+		mType:     m.Type(),
+		aggregate: m.IsAggregate(),
+	}
+
+	// keys are added in sorted order, so that scripts iterating over tags
+	// or fields behave the same way every time.
+	tags := m.Tags()
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		sm.tags.SetKey(starlark.String(k), starlark.String(tags[k]))
+	}
+
+	fields := m.Fields()
+	keys = make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if v, err := toValue(fields[k]); err == nil {
+			sm.fields.SetKey(starlark.String(k), v)
+		}
+	}
+	return sm
+}
+
+// toMetric converts the Starlark metric back into a telegraf.Metric.
+func (m *Metric) toMetric() (telegraf.Metric, error) {
+	tags := make(map[string]string, m.tags.Len())
+	for _, item := range m.tags.Items() {
+		k, ok := starlark.AsString(item[0])
+		if !ok {
+			return nil, fmt.Errorf("tag keys must be strings, found %s",
+				item[0].Type())
+		}
+		v, ok := starlark.AsString(item[1])
+		if !ok {
+			return nil, fmt.Errorf("tag %q must be a string, found %s",
+				k, item[1].Type())
+		}
+		tags[k] = v
+	}
+
+	fields := make(map[string]interface{}, m.fields.Len())
+	for _, item := range m.fields.Items() {
+		k, ok := starlark.AsString(item[0])
+		if !ok {
+			return nil, fmt.Errorf("field keys must be strings, found %s",
+				item[0].Type())
+		}
+		v, err := fromValue(item[1])
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %s", k, err)
+		}
+		fields[k] = v
+	}
+
+	out, err := metric.New(m.name, tags, fields, time.Unix(0, m.nsec), m.mType)
+	if err != nil {
+		return nil, err
+	}
+	out.SetAggregate(m.aggregate)
+	return out, nil
+}
+
+// copy returns an unfrozen deep copy of the metric.
+func (m *Metric) copy() *Metric {
+	c := *m
+	c.frozen = false
+	c.tags = new(starlark.Dict)
+	c.fields = new(starlark.Dict)
+	for _, item := range m.tags.Items() {
+		c.tags.SetKey(item[0], item[1])
+	}
+	for _, item := range m.fields.Items() {
+		c.fields.SetKey(item[0], item[1])
+	}
+	return &c
+}
+
+func (m *Metric) String() string {
+	return fmt.Sprintf("Metric(%q, tags=%s, fields=%s, time=%d)",
+		m.name, m.tags, m.fields, m.nsec)
+}
+
+func (m *Metric) Type() string {
+	return "Metric"
+}
+
+func (m *Metric) Freeze() {
+	m.frozen = true
+	m.tags.Freeze()
+	m.fields.Freeze()
+}
+
+func (m *Metric) Truth() starlark.Bool {
+	return true
+}
+
+func (m *Metric) Hash() (uint32, error) {
+	return 0, fmt.Errorf("unhashable type: Metric")
+}
+
+func (m *Metric) AttrNames() []string {
+	return []string{"fields", "name", "tags", "time"}
+}
+
+func (m *Metric) Attr(name string) (starlark.Value, error) {
+	switch name {
+	case "name":
+		return starlark.String(m.name), nil
+	case "tags":
+		return m.tags, nil
+	case "fields":
+		return m.fields, nil
+	case "time":
+		return starlark.MakeInt64(m.nsec), nil
+	}
+	return nil, nil
+}
+
+func (m *Metric) SetField(name string, value starlark.Value) error {
+	if m.frozen {
+		return fmt.Errorf("cannot modify frozen metric")
+	}
+
+	switch name {
+	case "name":
+		v, ok := starlark.AsString(value)
+		if !ok {
+			return fmt.Errorf("name must be a string, found %s", value.Type())
+		}
+		m.name = v
+	case "time":
+		i, ok := value.(starlark.Int)
+		if !ok {
+			return fmt.Errorf("time must be an int, found %s", value.Type())
+		}
+		nsec, ok := i.Int64()
+		if !ok {
+			return fmt.Errorf("time is out of range")
+		}
+		m.nsec = nsec
+	case "tags", "fields":
+		d, ok := value.(*starlark.Dict)
+		if !ok {
+			return fmt.Errorf("%s must be a dict, found %s", name, value.Type())
+		}
+		c := new(starlark.Dict)
+		for _, item := range d.Items() {
+			c.SetKey(item[0], item[1])
+		}
+		if name == "tags" {
+			m.tags = c
+		} else {
+			m.fields = c
+		}
+	default:
+		return starlark.NoSuchAttrError(
+			fmt.Sprintf("Metric has no attribute %q, must be one of %s",
+				name, strings.Join(m.AttrNames(), ", ")))
+	}
+	return nil
+}
+
+// toValue converts a field value into a Starlark value.
+func toValue(v interface{}) (starlark.Value, error) {
+	switch v := v.(type) {
+	case int64:
+		return starlark.MakeInt64(v), nil
+	case uint64:
+		return starlark.MakeUint64(v), nil
+	case float64:
+		return starlark.Float(v), nil
+	case string:
+		return starlark.String(v), nil
+	case bool:
+		return starlark.Bool(v), nil
+	}
+	return nil, fmt.Errorf("unsupported field type %T", v)
+}
+
+// fromValue converts a Starlark value into a field value.
+func fromValue(v starlark.Value) (interface{}, error) {
+	switch v := v.(type) {
+	case starlark.Int:
+		if i, ok := v.Int64(); ok {
+			return i, nil
+		}
+		if u, ok := v.Uint64(); ok {
+			return u, nil
+		}
+		return nil, fmt.Errorf("int is out of range")
+	case starlark.Float:
+		return float64(v), nil
+	case starlark.String:
+		return string(v), nil
+	case starlark.Bool:
+		return bool(v), nil
+	}
+	return nil, fmt.Errorf("unsupported type %s, must be int, float, "+
+		"string or bool", v.Type())
+}
+
+// builtins are the functions available to scripts, in addition to the
+// Starlark universe.
+var builtins = starlark.StringDict{
+	"Metric":   starlark.NewBuiltin("Metric", newMetricBuiltin),
+	"deepcopy": starlark.NewBuiltin("deepcopy", deepcopyBuiltin),
+}
+
+// newMetricBuiltin implements Metric(name), creating an empty metric with the
+// current time.
+func newMetricBuiltin(
+	_ *starlark.Thread,
+	fn *starlark.Builtin,
+	args starlark.Tuple,
+	kwargs []starlark.Tuple,
+) (starlark.Value, error) {
+	var name starlark.String
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "name", &name); err != nil {
+		return nil, err
+	}
+	return &Metric{
+		name:   string(name),
+		tags:   new(starlark.Dict),
+		fields: new(starlark.Dict),
+		nsec:   time.Now().UnixNano(),
+		mType:  telegraf.Untyped,
+	}, nil
+}
+
+// deepcopyBuiltin implements deepcopy(metric), so that a script can return
+// several metrics derived from the one it received.
+func deepcopyBuiltin(
+	_ *starlark.Thread,
+	fn *starlark.Builtin,
+	args starlark.Tuple,
+	kwargs []starlark.Tuple,
+) (starlark.Value, error) {
+	var m *Metric
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "metric", &m); err != nil {
+		return nil, err
+	}
+	return m.copy(), nil
+}
@@ -0,0 +1,188 @@
+package starlark
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/processors"
+	"go.starlark.net/resolve"
+	"go.starlark.net/starlark"
+)
+
+const applyFunc = "apply"
+
+var sampleConfig = `
+  ## Starlark source, which must define an apply function taking a metric
+  ## and returning None to drop it, a metric, or a list of metrics.
+  source = '''
+def apply(metric):
+	return metric
+'''
+
+  ## File containing a Starlark script, used instead of source.
+  # script = "/usr/local/etc/telegraf/script.star"
+`
+
+type Starlark struct {
+	Source string `toml:"source"`
+	Script string `toml:"script"`
+
+	once    sync.Once
+	err     error
+	applyFn *starlark.Function
+	// logOnce logs the error of Init once, instead of for every batch.
+	logOnce sync.Once
+
+	// Starlark threads must not be used concurrently, and Apply can be
+	// called from several goroutines.
+	mu     sync.Mutex
+	thread *starlark.Thread
+}
+
+func (s *Starlark) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *Starlark) Description() string {
+	return "Process metrics using a Starlark script"
+}
+
+// compile loads the script and looks up its apply function.
+func (s *Starlark) compile() error {
+	var src interface{}
+	filename := s.Script
+	switch {
+	case s.Source != "" && s.Script != "":
+		return fmt.Errorf("only one of source or script can be set")
+	case s.Script != "":
+		b, err := ioutil.ReadFile(s.Script)
+		if err != nil {
+			return err
+		}
+		src = b
+	case s.Source != "":
+		src = s.Source
+		filename = "processors.starlark"
+	default:
+		return fmt.Errorf("one of source or script must be set")
+	}
+
+	s.thread = &starlark.Thread{
+		Name: "processors.starlark",
+		Print: func(_ *starlark.Thread, msg string) {
+			log.Printf("I! [processors.starlark] %s", msg)
+		},
+		Load: func(_ *starlark.Thread, module string) (starlark.StringDict, error) {
+			return nil, fmt.Errorf("load is not supported")
+		},
+	}
+
+	globals, err := starlark.ExecFile(s.thread, filename, src, builtins)
+	if err != nil {
+		if evalErr, ok := err.(*starlark.EvalError); ok {
+			return fmt.Errorf("%s", evalErr.Backtrace())
+		}
+		return err
+	}
+
+	fn, ok := globals[applyFunc].(*starlark.Function)
+	if !ok {
+		return fmt.Errorf("%s function not defined", applyFunc)
+	}
+	if fn.NumParams() != 1 {
+		return fmt.Errorf("%s function must take one parameter", applyFunc)
+	}
+	s.applyFn = fn
+	return nil
+}
+
+// Init compiles the script. It is called when the configuration is loaded, so
+// that an invalid script is reported then.
+func (s *Starlark) Init() error {
+	s.once.Do(func() {
+		s.err = s.compile()
+	})
+	return s.err
+}
+
+func (s *Starlark) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	if err := s.Init(); err != nil {
+		s.logOnce.Do(func() {
+			log.Printf("E! [processors.starlark] %s, metrics are not processed", err)
+		})
+		return in
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]telegraf.Metric, 0, len(in))
+	for _, m := range in {
+		results, err := s.apply(m)
+		if err != nil {
+			log.Printf("E! [processors.starlark] %s", err)
+			out = append(out, m)
+			continue
+		}
+		out = append(out, results...)
+	}
+	return out
+}
+
+// apply runs the apply function of the script on a single metric. The
+// original metric is left untouched, so that it can be kept if the script
+// fails.
+func (s *Starlark) apply(m telegraf.Metric) ([]telegraf.Metric, error) {
+	rv, err := starlark.Call(s.thread, s.applyFn,
+		starlark.Tuple{newMetric(m)}, nil)
+	if err != nil {
+		if evalErr, ok := err.(*starlark.EvalError); ok {
+			return nil, fmt.Errorf("%s", evalErr.Backtrace())
+		}
+		return nil, err
+	}
+
+	var results []telegraf.Metric
+	switch rv := rv.(type) {
+	case starlark.NoneType:
+	case *Metric:
+		out, err := rv.toMetric()
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, out)
+	case *starlark.List:
+		for i := 0; i < rv.Len(); i++ {
+			sm, ok := rv.Index(i).(*Metric)
+			if !ok {
+				return nil, fmt.Errorf("%s returned a list containing %s, "+
+					"expected Metric", applyFunc, rv.Index(i).Type())
+			}
+			out, err := sm.toMetric()
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, out)
+		}
+	default:
+		return nil, fmt.Errorf("%s returned %s, expected None, Metric or "+
+			"list of Metric", applyFunc, rv.Type())
+	}
+	return results, nil
+}
+
+func init() {
+	// These are off by default in the Starlark resolver, but are needed by
+	// most metric transformations. Unbounded loops stay disallowed.
+	resolve.AllowFloat = true
+	resolve.AllowSet = true
+	resolve.AllowLambda = true
+	resolve.AllowNestedDef = true
+
+	processors.Add("starlark", func() telegraf.Processor {
+		return &Starlark{}
+	})
+}
@@ -0,0 +1,300 @@
+package starlark
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Unix(1500000000, 0)
+
+func newTestMetric(
+	t *testing.T,
+	name string,
+	tags map[string]string,
+	fields map[string]interface{},
+) telegraf.Metric {
+	m, err := metric.New(name, tags, fields, now)
+	require.NoError(t, err)
+	return m
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		input    []telegraf.Metric
+		expected []telegraf.Metric
+	}{
+		{
+			name: "passthrough",
+			source: `
+def apply(metric):
+	return metric
+`,
+			input: []telegraf.Metric{
+				newTestMetric(t, "cpu",
+					map[string]string{"host": "a"},
+					map[string]interface{}{"value": 42.0}),
+			},
+			expected: []telegraf.Metric{
+				newTestMetric(t, "cpu",
+					map[string]string{"host": "a"},
+					map[string]interface{}{"value": 42.0}),
+			},
+		},
+		{
+			name: "ratio of two fields",
+			source: `
+def apply(metric):
+	metric.fields["ratio"] = metric.fields["used"] / metric.fields["total"]
+	return metric
+`,
+			input: []telegraf.Metric{
+				newTestMetric(t, "mem",
+					map[string]string{},
+					map[string]interface{}{"used": int64(25), "total": int64(100)}),
+			},
+			expected: []telegraf.Metric{
+				newTestMetric(t, "mem",
+					map[string]string{},
+					map[string]interface{}{
+						"used":  int64(25),
+						"total": int64(100),
+						"ratio": 0.25,
+					}),
+			},
+		},
+		{
+			name: "rename from tag value",
+			source: `
+def apply(metric):
+	metric.name = metric.name + "_" + metric.tags.pop("kind")
+	return metric
+`,
+			input: []telegraf.Metric{
+				newTestMetric(t, "disk",
+					map[string]string{"kind": "ssd", "host": "a"},
+					map[string]interface{}{"value": true}),
+			},
+			expected: []telegraf.Metric{
+				newTestMetric(t, "disk_ssd",
+					map[string]string{"host": "a"},
+					map[string]interface{}{"value": true}),
+			},
+		},
+		{
+			name: "conditional drop",
+			source: `
+def apply(metric):
+	if metric.fields.get("value", 0) < 0:
+		return None
+	return metric
+`,
+			input: []telegraf.Metric{
+				newTestMetric(t, "temp",
+					map[string]string{},
+					map[string]interface{}{"value": int64(-1)}),
+				newTestMetric(t, "temp",
+					map[string]string{},
+					map[string]interface{}{"value": int64(1)}),
+			},
+			expected: []telegraf.Metric{
+				newTestMetric(t, "temp",
+					map[string]string{},
+					map[string]interface{}{"value": int64(1)}),
+			},
+		},
+		{
+			name: "split into several metrics",
+			source: `
+def apply(metric):
+	metrics = []
+	for k, v in metric.fields.items():
+		m = deepcopy(metric)
+		m.fields = {"value": v}
+		m.tags["field"] = k
+		metrics.append(m)
+	return metrics
+`,
+			input: []telegraf.Metric{
+				newTestMetric(t, "cpu",
+					map[string]string{},
+					map[string]interface{}{"user": 1.0, "system": 2.0}),
+			},
+			expected: []telegraf.Metric{
+				newTestMetric(t, "cpu",
+					map[string]string{"field": "system"},
+					map[string]interface{}{"value": 2.0}),
+				newTestMetric(t, "cpu",
+					map[string]string{"field": "user"},
+					map[string]interface{}{"value": 1.0}),
+			},
+		},
+		{
+			name: "new metric and time",
+			source: `
+def apply(metric):
+	m = Metric("count")
+	m.fields["fields"] = len(metric.fields)
+	m.time = metric.time
+	return [metric, m]
+`,
+			input: []telegraf.Metric{
+				newTestMetric(t, "cpu",
+					map[string]string{},
+					map[string]interface{}{"user": 1.0, "system": 2.0}),
+			},
+			expected: []telegraf.Metric{
+				newTestMetric(t, "cpu",
+					map[string]string{},
+					map[string]interface{}{"user": 1.0, "system": 2.0}),
+				newTestMetric(t, "count",
+					map[string]string{},
+					map[string]interface{}{"fields": int64(2)}),
+			},
+		},
+		{
+			name: "runtime error keeps metric",
+			source: `
+def apply(metric):
+	metric.tags["host"] = "b"
+	return metric.fields["missing"]
+`,
+			input: []telegraf.Metric{
+				newTestMetric(t, "cpu",
+					map[string]string{"host": "a"},
+					map[string]interface{}{"value": 42.0}),
+			},
+			expected: []telegraf.Metric{
+				newTestMetric(t, "cpu",
+					map[string]string{"host": "a"},
+					map[string]interface{}{"value": 42.0}),
+			},
+		},
+		{
+			name: "invalid field type keeps metric",
+			source: `
+def apply(metric):
+	metric.fields["value"] = [1, 2]
+	return metric
+`,
+			input: []telegraf.Metric{
+				newTestMetric(t, "cpu",
+					map[string]string{},
+					map[string]interface{}{"value": 42.0}),
+			},
+			expected: []telegraf.Metric{
+				newTestMetric(t, "cpu",
+					map[string]string{},
+					map[string]interface{}{"value": 42.0}),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Starlark{Source: tt.source}
+			actual := plugin.Apply(tt.input...)
+			require.Len(t, actual, len(tt.expected))
+			for i, m := range tt.expected {
+				assert.Equal(t, m.Name(), actual[i].Name())
+				assert.Equal(t, m.Tags(), actual[i].Tags())
+				assert.Equal(t, m.Fields(), actual[i].Fields())
+				if m.Name() != "count" {
+					assert.Equal(t, m.Time(), actual[i].Time())
+				}
+			}
+		})
+	}
+}
+
+func TestApplyPreservesType(t *testing.T) {
+	m, err := metric.New("cpu", map[string]string{},
+		map[string]interface{}{"value": int64(1)}, now, telegraf.Counter)
+	require.NoError(t, err)
+
+	plugin := &Starlark{Source: `
+def apply(metric):
+	metric.fields["value"] += 1
+	return metric
+`}
+	actual := plugin.Apply(m)
+	require.Len(t, actual, 1)
+	assert.Equal(t, telegraf.Counter, actual[0].Type())
+	assert.Equal(t, map[string]interface{}{"value": int64(2)}, actual[0].Fields())
+}
+
+func TestScriptFile(t *testing.T) {
+	f, err := ioutil.TempFile("", "telegraf-starlark")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(`
+def apply(metric):
+	metric.tags["scripted"] = "true"
+	return metric
+`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	plugin := &Starlark{Script: f.Name()}
+	actual := plugin.Apply(newTestMetric(t, "cpu",
+		map[string]string{}, map[string]interface{}{"value": 42.0}))
+	require.Len(t, actual, 1)
+	assert.Equal(t, map[string]string{"scripted": "true"}, actual[0].Tags())
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		plugin *Starlark
+	}{
+		{
+			name:   "no source",
+			plugin: &Starlark{},
+		},
+		{
+			name:   "syntax error",
+			plugin: &Starlark{Source: "def apply(metric)\n\treturn metric\n"},
+		},
+		{
+			name:   "missing apply",
+			plugin: &Starlark{Source: "def foo(metric):\n\treturn metric\n"},
+		},
+		{
+			name:   "wrong number of parameters",
+			plugin: &Starlark{Source: "def apply():\n\treturn None\n"},
+		},
+		{
+			name:   "load is not allowed",
+			plugin: &Starlark{Source: "load('os.star', 'system')\n"},
+		},
+		{
+			name: "source and script",
+			plugin: &Starlark{
+				Source: "def apply(metric):\n\treturn metric\n",
+				Script: "script.star",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Error(t, tt.plugin.Init())
+
+			// metrics are passed through unchanged
+			m := newTestMetric(t, "cpu",
+				map[string]string{}, map[string]interface{}{"value": 42.0})
+			actual := tt.plugin.Apply(m)
+			require.Len(t, actual, 1)
+			assert.Equal(t, m, actual[0])
+		})
+	}
+}
@@ -0,0 +1,7 @@
+package all
+
+import (
+	_ "github.com/influxdata/telegraf/plugins/secretstores/directory"
+	_ "github.com/influxdata/telegraf/plugins/secretstores/encrypted"
+	_ "github.com/influxdata/telegraf/plugins/secretstores/vault"
+)
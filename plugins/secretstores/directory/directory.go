@@ -0,0 +1,59 @@
+package directory
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/secretstores"
+)
+
+// Directory reads each secret from the file named after its key, such as the
+// secrets mounted by Docker or Kubernetes.
+type Directory struct {
+	Path string `toml:"path"`
+}
+
+var sampleConfig = `
+  ## Unique identifier of the store, used to reference its secrets, ie
+  ## password = "@{local:mysql_password}"
+  id = "local"
+
+  ## Directory holding one file per secret, named after the secret key.
+  path = "/run/secrets"
+`
+
+func (d *Directory) SampleConfig() string {
+	return sampleConfig
+}
+
+func (d *Directory) Description() string {
+	return "Read secrets from the files of a directory"
+}
+
+func (d *Directory) Get(key string) (string, error) {
+	if d.Path == "" {
+		return "", fmt.Errorf("path is required")
+	}
+
+	// keys are relative to the directory, and must not leave it
+	name := filepath.Clean(key)
+	if filepath.IsAbs(name) || name == ".." ||
+		strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid secret key %q", key)
+	}
+
+	secret, err := ioutil.ReadFile(filepath.Join(d.Path, name))
+	if err != nil {
+		return "", fmt.Errorf("could not read secret %q: %s", key, err)
+	}
+	return strings.TrimRight(string(secret), "\r\n"), nil
+}
+
+func init() {
+	secretstores.Add("directory", func() telegraf.SecretStore {
+		return &Directory{}
+	})
+}
@@ -0,0 +1,42 @@
+package directory
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirectory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "telegraf-secrets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "password"),
+		[]byte("s3cr3t\n"), 0600))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "mysql"), 0700))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "mysql", "user"),
+		[]byte("telegraf"), 0600))
+
+	d := &Directory{Path: dir}
+
+	secret, err := d.Get("password")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", secret)
+
+	secret, err = d.Get("mysql/user")
+	require.NoError(t, err)
+	assert.Equal(t, "telegraf", secret)
+
+	_, err = d.Get("missing")
+	assert.Error(t, err)
+
+	_, err = d.Get("../password")
+	assert.Error(t, err)
+
+	_, err = d.Get("/etc/passwd")
+	assert.Error(t, err)
+}
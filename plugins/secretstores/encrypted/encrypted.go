@@ -0,0 +1,60 @@
+package encrypted
+
+import (
+	"fmt"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/secretstores"
+)
+
+// Encrypted reads secrets from a local file encrypted with a password, which
+// can be created with the "telegraf secrets" command.
+type Encrypted struct {
+	Path     string `toml:"path"`
+	Password string `toml:"password"`
+
+	// secrets are decrypted once, on first use.
+	secrets map[string]string
+}
+
+var sampleConfig = `
+  ## Unique identifier of the store, used to reference its secrets, ie
+  ## password = "@{encrypted:mysql_password}"
+  id = "encrypted"
+
+  ## File holding the secrets, created with "telegraf secrets set".
+  path = "/etc/telegraf/secrets.json"
+
+  ## Password the secrets are encrypted with.
+  password = "$TELEGRAF_SECRETS_PASSWORD"
+`
+
+func (e *Encrypted) SampleConfig() string {
+	return sampleConfig
+}
+
+func (e *Encrypted) Description() string {
+	return "Read secrets from a password encrypted file"
+}
+
+func (e *Encrypted) Get(key string) (string, error) {
+	if e.secrets == nil {
+		secrets, err := Load(e.Path, e.Password)
+		if err != nil {
+			return "", err
+		}
+		e.secrets = secrets
+	}
+
+	secret, ok := e.secrets[key]
+	if !ok {
+		return "", fmt.Errorf("secret %q not found in %s", key, e.Path)
+	}
+	return secret, nil
+}
+
+func init() {
+	secretstores.Add("encrypted", func() telegraf.SecretStore {
+		return &Encrypted{}
+	})
+}
@@ -0,0 +1,64 @@
+package encrypted
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "telegraf-secrets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "secrets.json")
+
+	secrets := map[string]string{
+		"mysql_password": "s3cr3t",
+		"token":          "abc",
+	}
+	require.NoError(t, Save(path, "password", secrets))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	contents, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(contents), "s3cr3t")
+
+	loaded, err := Load(path, "password")
+	require.NoError(t, err)
+	assert.Equal(t, secrets, loaded)
+
+	_, err = Load(path, "wrong")
+	assert.Error(t, err)
+
+	_, err = Load(path, "")
+	assert.Error(t, err)
+}
+
+func TestEncrypted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "telegraf-secrets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "secrets.json")
+
+	require.NoError(t, Save(path, "password",
+		map[string]string{"mysql_password": "s3cr3t"}))
+
+	e := &Encrypted{Path: path, Password: "password"}
+	secret, err := e.Get("mysql_password")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", secret)
+
+	_, err = e.Get("missing")
+	assert.Error(t, err)
+
+	e = &Encrypted{Path: path, Password: "wrong"}
+	_, err = e.Get("mysql_password")
+	assert.Error(t, err)
+}
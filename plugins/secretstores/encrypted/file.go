@@ -0,0 +1,127 @@
+package encrypted
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	fileVersion = 1
+
+	// iterations of PBKDF2 deriving the encryption key from the password.
+	iterations = 100000
+	keyLength  = 32
+	saltLength = 16
+)
+
+// file is the layout of an encrypted secrets file. The secrets are stored as
+// a JSON object, encrypted with AES-256-GCM.
+type file struct {
+	Version int    `json:"version"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+// Load decrypts the secrets of the file at path.
+func Load(path, password string) (map[string]string, error) {
+	if password == "" {
+		return nil, errors.New("password is required")
+	}
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f file
+	if err := json.Unmarshal(contents, &f); err != nil {
+		return nil, fmt.Errorf("invalid secrets file %s: %s", path, err)
+	}
+	if f.Version != fileVersion {
+		return nil, fmt.Errorf("unsupported secrets file version %d", f.Version)
+	}
+
+	gcm, err := newGCM(password, f.Salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(f.Nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid secrets file %s: bad nonce", path)
+	}
+	data, err := gcm.Open(nil, f.Nonce, f.Data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt %s: wrong password or "+
+			"corrupted file", path)
+	}
+
+	secrets := make(map[string]string)
+	if err := json.Unmarshal(data, &secrets); err != nil {
+		return nil, fmt.Errorf("invalid secrets file %s: %s", path, err)
+	}
+	return secrets, nil
+}
+
+// Save encrypts the secrets to the file at path, replacing it. A new salt and
+// nonce are used every time.
+func Save(path, password string, secrets map[string]string) error {
+	if password == "" {
+		return errors.New("password is required")
+	}
+
+	data, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+
+	f := file{
+		Version: fileVersion,
+		Salt:    make([]byte, saltLength),
+	}
+	if _, err := io.ReadFull(rand.Reader, f.Salt); err != nil {
+		return err
+	}
+	gcm, err := newGCM(password, f.Salt)
+	if err != nil {
+		return err
+	}
+	f.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, f.Nonce); err != nil {
+		return err
+	}
+	f.Data = gcm.Seal(nil, f.Nonce, data, nil)
+
+	contents, err := json.Marshal(&f)
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first, so that the secrets are not lost if
+	// writing fails half way.
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, contents, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func newGCM(password string, salt []byte) (cipher.AEAD, error) {
+	if len(salt) != saltLength {
+		return nil, errors.New("invalid secrets file: bad salt")
+	}
+	block, err := aes.NewCipher(pbkdf2.Key([]byte(password), salt, iterations, keyLength, sha256.New))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
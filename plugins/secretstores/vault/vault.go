@@ -0,0 +1,176 @@
+package vault
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/tls"
+	"github.com/influxdata/telegraf/plugins/secretstores"
+)
+
+// Vault reads secrets from the key/value secrets engine of a HashiCorp Vault
+// compatible server.
+type Vault struct {
+	Address       string            `toml:"address"`
+	Token         string            `toml:"token"`
+	Mount         string            `toml:"mount"`
+	EngineVersion int               `toml:"engine_version"`
+	Timeout       internal.Duration `toml:"timeout"`
+	tls.ClientConfig
+
+	client *http.Client
+}
+
+var sampleConfig = `
+  ## Unique identifier of the store, used to reference its secrets as
+  ## "<path>#<field>", ie password = "@{vault:telegraf/mysql#password}"
+  id = "vault"
+
+  ## Address of the Vault server.
+  address = "https://127.0.0.1:8200"
+
+  ## Token used to authenticate, defaults to the VAULT_TOKEN environment
+  ## variable.
+  # token = "$VAULT_TOKEN"
+
+  ## Path the key/value secrets engine is mounted at, and its version.
+  # mount = "secret"
+  # engine_version = 2
+
+  ## Timeout of the requests to the server.
+  # timeout = "5s"
+
+  ## Optional TLS Config
+  # tls_ca = "/etc/telegraf/ca.pem"
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+  ## Use TLS but skip chain & host verification
+  # insecure_skip_verify = false
+`
+
+func (v *Vault) SampleConfig() string {
+	return sampleConfig
+}
+
+func (v *Vault) Description() string {
+	return "Read secrets from a HashiCorp Vault server"
+}
+
+// Get returns the secret referenced by key, in the form "<path>#<field>".
+// The field defaults to "value" when left out.
+func (v *Vault) Get(key string) (string, error) {
+	path, field := key, "value"
+	if i := strings.LastIndex(key, "#"); i >= 0 {
+		path, field = key[:i], key[i+1:]
+	}
+	path = strings.Trim(path, "/")
+	if path == "" || field == "" {
+		return "", fmt.Errorf("invalid secret key %q", key)
+	}
+
+	data, err := v.read(path)
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("field %q not found in secret %q", field, path)
+	}
+	secret, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("field %q of secret %q is not a string",
+			field, path)
+	}
+	return secret, nil
+}
+
+// read returns the fields of the secret at path.
+func (v *Vault) read(path string) (map[string]interface{}, error) {
+	if v.Address == "" {
+		return nil, fmt.Errorf("address is required")
+	}
+	if v.client == nil {
+		tlsCfg, err := v.ClientConfig.TLSConfig()
+		if err != nil {
+			return nil, err
+		}
+		v.client = &http.Client{
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: tlsCfg,
+			},
+			Timeout: v.Timeout.Duration,
+		}
+	}
+
+	url := strings.TrimRight(v.Address, "/") + "/v1/" +
+		strings.Trim(v.Mount, "/") + "/"
+	if v.EngineVersion == 2 {
+		url += "data/"
+	}
+	url += path
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	token := v.Token
+	if token == "" {
+		token = os.Getenv("VAULT_TOKEN")
+	}
+	req.Header.Set("X-Vault-Token", token)
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var body struct {
+			Errors []string `json:"errors"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		if len(body.Errors) > 0 {
+			return nil, fmt.Errorf("could not read secret %q: %s: %s",
+				path, resp.Status, strings.Join(body.Errors, ", "))
+		}
+		return nil, fmt.Errorf("could not read secret %q: %s", path,
+			resp.Status)
+	}
+
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("invalid response reading secret %q: %s",
+			path, err)
+	}
+
+	// version 2 of the engine nests the fields, next to their metadata.
+	if v.EngineVersion == 2 {
+		data, ok := body.Data["data"].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid response reading secret %q", path)
+		}
+		return data, nil
+	}
+	return body.Data, nil
+}
+
+func init() {
+	secretstores.Add("vault", func() telegraf.SecretStore {
+		return &Vault{
+			Mount:         "secret",
+			EngineVersion: 2,
+			Timeout:       internal.Duration{Duration: 5 * time.Second},
+		}
+	})
+}
@@ -0,0 +1,95 @@
+package vault
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newVaultStub returns a server answering like Vault for the given secrets,
+// keyed by request path.
+func newVaultStub(t *testing.T, secrets map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		data, ok := secrets[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+			return
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": data,
+		}))
+	}))
+}
+
+func TestVaultV2(t *testing.T) {
+	srv := newVaultStub(t, map[string]interface{}{
+		"/v1/secret/data/telegraf/mysql": map[string]interface{}{
+			"data": map[string]interface{}{
+				"password": "s3cr3t",
+				"value":    "default",
+				"port":     3306,
+			},
+			"metadata": map[string]interface{}{"version": 1},
+		},
+	})
+	defer srv.Close()
+
+	v := &Vault{
+		Address:       srv.URL,
+		Token:         "token",
+		Mount:         "secret",
+		EngineVersion: 2,
+	}
+
+	secret, err := v.Get("telegraf/mysql#password")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", secret)
+
+	secret, err = v.Get("telegraf/mysql")
+	require.NoError(t, err)
+	assert.Equal(t, "default", secret)
+
+	_, err = v.Get("telegraf/mysql#missing")
+	assert.Error(t, err)
+
+	_, err = v.Get("telegraf/mysql#port")
+	assert.Error(t, err)
+
+	_, err = v.Get("telegraf/missing#password")
+	assert.Error(t, err)
+
+	v.Token = "wrong"
+	_, err = v.Get("telegraf/mysql#password")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "permission denied")
+}
+
+func TestVaultV1(t *testing.T) {
+	srv := newVaultStub(t, map[string]interface{}{
+		"/v1/kv/telegraf/mysql": map[string]interface{}{
+			"password": "s3cr3t",
+		},
+	})
+	defer srv.Close()
+
+	v := &Vault{
+		Address:       srv.URL,
+		Token:         "token",
+		Mount:         "kv",
+		EngineVersion: 1,
+	}
+
+	secret, err := v.Get("telegraf/mysql#password")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", secret)
+}
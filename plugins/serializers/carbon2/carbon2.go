@@ -0,0 +1,99 @@
+package carbon2
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+)
+
+// spaceReplacer replaces the characters that separate the tags and the
+// value of a Carbon 2.0 line.
+var spaceReplacer = strings.NewReplacer(" ", "_", "\t", "_", "\n", "_")
+
+// Carbon2Serializer serializes metrics in the Carbon 2.0 (metrics20) format,
+// one line per numeric field:
+//
+//	metric=<name> field=<field> <tag>=<value>...  <value> <timestamp>
+//
+// The intrinsic tags are followed by two spaces, there are no meta tags.
+type Carbon2Serializer struct {
+}
+
+func (s *Carbon2Serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	return s.serialize(nil, metric), nil
+}
+
+// SerializeBatch serializes the metrics into a single buffer.
+func (s *Carbon2Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	var buf []byte
+	for _, metric := range metrics {
+		buf = s.serialize(buf, metric)
+	}
+	return buf, nil
+}
+
+func (s *Carbon2Serializer) serialize(buf []byte, metric telegraf.Metric) []byte {
+	tags := metric.Tags()
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	fields := metric.Fields()
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	timestamp := strconv.FormatInt(metric.UnixNano()/1000000000, 10)
+	for _, name := range names {
+		value, ok := formatValue(fields[name])
+		if !ok {
+			continue
+		}
+
+		buf = append(buf, "metric="...)
+		buf = append(buf, sanitize(metric.Name())...)
+		buf = append(buf, " field="...)
+		buf = append(buf, sanitize(name)...)
+		for _, k := range keys {
+			buf = append(buf, ' ')
+			buf = append(buf, sanitize(k)...)
+			buf = append(buf, '=')
+			buf = append(buf, sanitize(tags[k])...)
+		}
+		buf = append(buf, "  "...)
+		buf = append(buf, value...)
+		buf = append(buf, ' ')
+		buf = append(buf, timestamp...)
+		buf = append(buf, '\n')
+	}
+	return buf
+}
+
+// formatValue formats a numeric or boolean field value, string fields are
+// not serialized.
+func formatValue(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		if v {
+			return "1", true
+		}
+		return "0", true
+	}
+	return "", false
+}
+
+// sanitize replaces the whitespace and the equal signs of the tag keys and
+// values, which delimit the tags.
+func sanitize(s string) string {
+	return strings.Replace(spaceReplacer.Replace(s), "=", "-", -1)
+}
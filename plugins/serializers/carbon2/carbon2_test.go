@@ -0,0 +1,61 @@
+package carbon2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerialize(t *testing.T) {
+	m, err := metric.New("cpu",
+		map[string]string{"host": "a", "cpu": "cpu0"},
+		map[string]interface{}{
+			"usage_idle": 91.5,
+			"count":      int64(3),
+			"active":     true,
+			"state":      "up",
+		},
+		time.Unix(1500000000, 0))
+	require.NoError(t, err)
+
+	s := &Carbon2Serializer{}
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+	assert.Equal(t,
+		"metric=cpu field=active cpu=cpu0 host=a  1 1500000000\n"+
+			"metric=cpu field=count cpu=cpu0 host=a  3 1500000000\n"+
+			"metric=cpu field=usage_idle cpu=cpu0 host=a  91.5 1500000000\n",
+		string(buf))
+}
+
+func TestSerializeSanitize(t *testing.T) {
+	m, err := metric.New("disk usage",
+		map[string]string{"path": "/my data", "opt": "a=b"},
+		map[string]interface{}{"free bytes": int64(1)},
+		time.Unix(1500000000, 0))
+	require.NoError(t, err)
+
+	s := &Carbon2Serializer{}
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+	assert.Equal(t, "metric=disk_usage field=free_bytes opt=a-b path=/my_data  1 1500000000\n", string(buf))
+}
+
+func TestSerializeBatch(t *testing.T) {
+	m1, err := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(1500000000, 0))
+	require.NoError(t, err)
+	m2, err := metric.New("mem", map[string]string{}, map[string]interface{}{"value": 2.0}, time.Unix(1500000001, 0))
+	require.NoError(t, err)
+
+	s := &Carbon2Serializer{}
+	buf, err := s.SerializeBatch([]telegraf.Metric{m1, m2})
+	require.NoError(t, err)
+	assert.Equal(t,
+		"metric=cpu field=value  1 1500000000\n"+
+			"metric=mem field=value  2 1500000001\n",
+		string(buf))
+}
@@ -10,3 +10,18 @@ type InfluxSerializer struct {
 func (s *InfluxSerializer) Serialize(m telegraf.Metric) ([]byte, error) {
 	return m.Serialize(), nil
 }
+
+// SerializeBatch serializes the metrics into a single buffer, one line per
+// metric.
+func (s *InfluxSerializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	size := 0
+	for _, m := range metrics {
+		size += m.Len()
+	}
+
+	buf := make([]byte, 0, size)
+	for _, m := range metrics {
+		buf = append(buf, m.Serialize()...)
+	}
+	return buf, nil
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/metric"
 )
 
@@ -70,3 +71,18 @@ func TestSerializeMetricString(t *testing.T) {
 	expS := []string{fmt.Sprintf("cpu,cpu=cpu0 usage_idle=\"foobar\" %d", now.UnixNano())}
 	assert.Equal(t, expS, mS)
 }
+
+func TestSerializeBatch(t *testing.T) {
+	now := time.Unix(0, 0)
+	m1, err := metric.New("cpu", map[string]string{"cpu": "cpu0"},
+		map[string]interface{}{"usage_idle": float64(91.5)}, now)
+	assert.NoError(t, err)
+	m2, err := metric.New("mem", nil,
+		map[string]interface{}{"free": int64(42)}, now)
+	assert.NoError(t, err)
+
+	s := InfluxSerializer{}
+	buf, err := s.SerializeBatch([]telegraf.Metric{m1, m2})
+	assert.NoError(t, err)
+	assert.Equal(t, "cpu,cpu=cpu0 usage_idle=91.5 0\nmem free=42i 0\n", string(buf))
+}
@@ -2,6 +2,7 @@ package json
 
 import (
 	ejson "encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -9,9 +10,62 @@ import (
 
 type JsonSerializer struct {
 	TimestampUnits time.Duration
+	// BatchFormat is how SerializeBatch serializes a batch of metrics: as
+	// newline delimited objects ("lines", the default), as an array of
+	// objects ("array"), or as an object with the array of objects in its
+	// "metrics" key ("object").
+	BatchFormat string
 }
 
 func (s *JsonSerializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	serialized, err := ejson.Marshal(s.createObject(metric))
+	if err != nil {
+		return []byte{}, err
+	}
+	serialized = append(serialized, '\n')
+
+	return serialized, nil
+}
+
+// SerializeBatch serializes the metrics as a single JSON document, unless the
+// batch format is "lines".
+func (s *JsonSerializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	objects := make([]interface{}, 0, len(metrics))
+	for _, metric := range metrics {
+		objects = append(objects, s.createObject(metric))
+	}
+
+	var doc interface{}
+	switch s.BatchFormat {
+	case "", "lines":
+		var serialized []byte
+		for _, object := range objects {
+			b, err := ejson.Marshal(object)
+			if err != nil {
+				return []byte{}, err
+			}
+			serialized = append(serialized, b...)
+			serialized = append(serialized, '\n')
+		}
+		return serialized, nil
+	case "array":
+		doc = objects
+	case "object":
+		doc = map[string]interface{}{"metrics": objects}
+	default:
+		return []byte{}, fmt.Errorf("invalid JSON batch format: %s", s.BatchFormat)
+	}
+
+	serialized, err := ejson.Marshal(doc)
+	if err != nil {
+		return []byte{}, err
+	}
+	serialized = append(serialized, '\n')
+
+	return serialized, nil
+}
+
+func (s *JsonSerializer) createObject(metric telegraf.Metric) map[string]interface{} {
 	m := make(map[string]interface{})
 	units_nanoseconds := s.TimestampUnits.Nanoseconds()
 	// if the units passed in were less than or equal to zero,
@@ -23,11 +77,5 @@ func (s *JsonSerializer) Serialize(metric telegraf.Metric) ([]byte, error) {
 	m["fields"] = metric.Fields()
 	m["name"] = metric.Name()
 	m["timestamp"] = metric.UnixNano() / units_nanoseconds
-	serialized, err := ejson.Marshal(m)
-	if err != nil {
-		return []byte{}, err
-	}
-	serialized = append(serialized, '\n')
-
-	return serialized, nil
+	return m
 }
@@ -7,6 +7,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/metric"
 )
 
@@ -108,3 +109,36 @@ func TestSerializeMetricWithEscapes(t *testing.T) {
 	expS := []byte(fmt.Sprintf(`{"fields":{"U,age=Idle":90},"name":"My CPU","tags":{"cpu tag":"cpu0"},"timestamp":%d}`, now.Unix()) + "\n")
 	assert.Equal(t, string(expS), string(buf))
 }
+
+func TestSerializeBatch(t *testing.T) {
+	now := time.Unix(0, 0)
+	m1, err := metric.New("cpu", map[string]string{"cpu": "cpu0"},
+		map[string]interface{}{"usage_idle": float64(91.5)}, now)
+	assert.NoError(t, err)
+	m2, err := metric.New("mem", nil,
+		map[string]interface{}{"free": int64(42)}, now)
+	assert.NoError(t, err)
+	metrics := []telegraf.Metric{m1, m2}
+
+	cpu := `{"fields":{"usage_idle":91.5},"name":"cpu","tags":{"cpu":"cpu0"},"timestamp":0}`
+	mem := `{"fields":{"free":42},"name":"mem","tags":{},"timestamp":0}`
+
+	s := JsonSerializer{}
+	buf, err := s.SerializeBatch(metrics)
+	assert.NoError(t, err)
+	assert.Equal(t, cpu+"\n"+mem+"\n", string(buf))
+
+	s = JsonSerializer{BatchFormat: "array"}
+	buf, err = s.SerializeBatch(metrics)
+	assert.NoError(t, err)
+	assert.Equal(t, "["+cpu+","+mem+"]\n", string(buf))
+
+	s = JsonSerializer{BatchFormat: "object"}
+	buf, err = s.SerializeBatch(metrics)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"metrics":[`+cpu+","+mem+"]}\n", string(buf))
+
+	s = JsonSerializer{BatchFormat: "xml"}
+	_, err = s.SerializeBatch(metrics)
+	assert.Error(t, err)
+}
@@ -0,0 +1,279 @@
+package openmetrics
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+)
+
+var (
+	invalidNameChars  = regexp.MustCompile(`[^a-zA-Z0-9_:]`)
+	invalidLabelChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+)
+
+// OpenMetricsSerializer serializes metrics in the OpenMetrics text format,
+// the Prometheus exposition format with a "# EOF" line at the end.
+//
+// The numeric and boolean fields of counters, gauges and untyped metrics are
+// samples named <measurement>_<field>, or <measurement> for the "value" field
+// and for the "counter" and "gauge" fields of counters and gauges. The fields
+// of histograms are the cumulative bucket counts named by their upper
+// bound, "sum" and "count", the fields of summaries are the quantiles, "sum"
+// and "count". String fields are labels.
+type OpenMetricsSerializer struct {
+}
+
+// family is a metric family, the samples of a family are written together
+// after its TYPE line.
+type family struct {
+	name    string
+	typ     string
+	samples []string
+}
+
+// Serialize serializes the metric as a complete exposition, ending with a
+// "# EOF" line.
+func (s *OpenMetricsSerializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	return s.SerializeBatch([]telegraf.Metric{metric})
+}
+
+// SerializeBatch serializes the metrics as a single exposition, the samples
+// of a metric family are grouped after the TYPE line of the family, in the
+// order of the metrics.
+func (s *OpenMetricsSerializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	var families []*family
+	index := make(map[string]*family)
+	add := func(name, typ, sample string) {
+		f, ok := index[name]
+		if !ok {
+			f = &family{name: name, typ: typ}
+			index[name] = f
+			families = append(families, f)
+		}
+		f.samples = append(f.samples, sample)
+	}
+
+	for _, metric := range metrics {
+		labels := metricLabels(metric)
+		timestamp := formatTimestamp(metric.UnixNano())
+		name := sanitizeName(metric.Name())
+
+		switch metric.Type() {
+		case telegraf.Histogram:
+			buckets, sum, count := distribution(metric)
+			// the +Inf bucket is required, it counts all observations
+			if len(buckets) == 0 || !math.IsInf(buckets[len(buckets)-1].bound, 1) {
+				buckets = append(buckets, bucket{bound: math.Inf(1), value: count})
+			}
+			for _, b := range buckets {
+				add(name, "histogram", sample(name+"_bucket",
+					withLabel(labels, "le", formatFloat(b.bound)), b.value, timestamp))
+			}
+			add(name, "histogram", sample(name+"_sum", labels, sum, timestamp))
+			add(name, "histogram", sample(name+"_count", labels, count, timestamp))
+		case telegraf.Summary:
+			quantiles, sum, count := distribution(metric)
+			for _, q := range quantiles {
+				add(name, "summary", sample(name,
+					withLabel(labels, "quantile", formatFloat(q.bound)), q.value, timestamp))
+			}
+			add(name, "summary", sample(name+"_sum", labels, sum, timestamp))
+			add(name, "summary", sample(name+"_count", labels, count, timestamp))
+		default:
+			typ := "unknown"
+			switch metric.Type() {
+			case telegraf.Counter:
+				typ = "counter"
+			case telegraf.Gauge:
+				typ = "gauge"
+			}
+
+			for _, field := range sortedFields(metric) {
+				value, ok := floatValue(field.value)
+				if !ok {
+					continue
+				}
+
+				familyName := name
+				if field.key != "value" && field.key != typ {
+					familyName = sanitizeName(metric.Name() + "_" + field.key)
+				}
+				sampleName := familyName
+				if typ == "counter" {
+					// the samples of a counter end with _total, its
+					// family doesn't
+					familyName = strings.TrimSuffix(familyName, "_total")
+					sampleName = familyName + "_total"
+				}
+				add(familyName, typ, sample(sampleName, labels, value, timestamp))
+			}
+		}
+	}
+
+	var buf []byte
+	for _, f := range families {
+		buf = append(buf, "# TYPE "...)
+		buf = append(buf, f.name...)
+		buf = append(buf, ' ')
+		buf = append(buf, f.typ...)
+		buf = append(buf, '\n')
+		for _, s := range f.samples {
+			buf = append(buf, s...)
+		}
+	}
+	buf = append(buf, "# EOF\n"...)
+	return buf, nil
+}
+
+type label struct {
+	name  string
+	value string
+}
+
+type field struct {
+	key   string
+	value interface{}
+}
+
+type bucket struct {
+	bound float64
+	value float64
+}
+
+// metricLabels returns the tags and the string fields of the metric, sorted
+// by name.
+func metricLabels(metric telegraf.Metric) []label {
+	var labels []label
+	for k, v := range metric.Tags() {
+		labels = append(labels, label{name: sanitizeLabel(k), value: v})
+	}
+	for k, v := range metric.Fields() {
+		if s, ok := v.(string); ok {
+			labels = append(labels, label{name: sanitizeLabel(k), value: s})
+		}
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i].name < labels[j].name })
+	return labels
+}
+
+// withLabel returns the labels with an additional label at the end, as the
+// le and quantile labels are usually written.
+func withLabel(labels []label, name, value string) []label {
+	l := make([]label, 0, len(labels)+1)
+	l = append(l, labels...)
+	return append(l, label{name: name, value: value})
+}
+
+func sortedFields(metric telegraf.Metric) []field {
+	var fields []field
+	for k, v := range metric.Fields() {
+		fields = append(fields, field{key: k, value: v})
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].key < fields[j].key })
+	return fields
+}
+
+// distribution returns the buckets or quantiles of a histogram or summary,
+// sorted by their bound, and its sum and count.
+func distribution(metric telegraf.Metric) ([]bucket, float64, float64) {
+	var buckets []bucket
+	var sum, count float64
+	for k, v := range metric.Fields() {
+		value, ok := floatValue(v)
+		if !ok {
+			continue
+		}
+		switch k {
+		case "sum":
+			sum = value
+		case "count":
+			count = value
+		default:
+			bound, err := strconv.ParseFloat(k, 64)
+			if err == nil {
+				buckets = append(buckets, bucket{bound: bound, value: value})
+			}
+		}
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].bound < buckets[j].bound })
+	return buckets, sum, count
+}
+
+func sample(name string, labels []label, value float64, timestamp string) string {
+	b := make([]byte, 0, 64)
+	b = append(b, name...)
+	if len(labels) > 0 {
+		b = append(b, '{')
+		for i, l := range labels {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = append(b, l.name...)
+			b = append(b, `="`...)
+			b = append(b, labelValueEscaper.Replace(l.value)...)
+			b = append(b, '"')
+		}
+		b = append(b, '}')
+	}
+	b = append(b, ' ')
+	b = append(b, formatFloat(value)...)
+	b = append(b, ' ')
+	b = append(b, timestamp...)
+	b = append(b, '\n')
+	return string(b)
+}
+
+func floatValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// formatTimestamp formats the timestamp in seconds, with the fraction of a
+// second if it has one.
+func formatTimestamp(ns int64) string {
+	if ns < 0 {
+		return "-" + formatTimestamp(-ns)
+	}
+	sec := ns / 1000000000
+	frac := ns % 1000000000
+	if frac == 0 {
+		return strconv.FormatInt(sec, 10)
+	}
+	return strings.TrimRight(fmt.Sprintf("%d.%09d", sec, frac), "0")
+}
+
+func sanitizeName(name string) string {
+	name = invalidNameChars.ReplaceAllString(name, "_")
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
+
+func sanitizeLabel(name string) string {
+	name = invalidLabelChars.ReplaceAllString(name, "_")
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
@@ -0,0 +1,142 @@
+package openmetrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMetric(
+	t *testing.T,
+	name string,
+	tags map[string]string,
+	fields map[string]interface{},
+	tp telegraf.ValueType,
+) telegraf.Metric {
+	m, err := metric.New(name, tags, fields, time.Unix(1500000000, 0), tp)
+	require.NoError(t, err)
+	return m
+}
+
+func TestSerializeUntyped(t *testing.T) {
+	m := newMetric(t, "cpu",
+		map[string]string{"host": "a", "cpu-name": "cpu0"},
+		map[string]interface{}{
+			"usage_idle": 91.5,
+			"value":      int64(3),
+			"state":      "up",
+		},
+		telegraf.Untyped)
+
+	s := &OpenMetricsSerializer{}
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+	assert.Equal(t, `# TYPE cpu_usage_idle unknown
+cpu_usage_idle{cpu_name="cpu0",host="a",state="up"} 91.5 1500000000
+# TYPE cpu unknown
+cpu{cpu_name="cpu0",host="a",state="up"} 3 1500000000
+# EOF
+`, string(buf))
+}
+
+func TestSerializeCounterAndGauge(t *testing.T) {
+	counter := newMetric(t, "http_requests",
+		map[string]string{"code": "200"},
+		map[string]interface{}{"counter": int64(1027)},
+		telegraf.Counter)
+	gauge := newMetric(t, "queue",
+		map[string]string{},
+		map[string]interface{}{"gauge": 1.5, "max": int64(10)},
+		telegraf.Gauge)
+
+	s := &OpenMetricsSerializer{}
+	buf, err := s.SerializeBatch([]telegraf.Metric{counter, gauge})
+	require.NoError(t, err)
+	assert.Equal(t, `# TYPE http_requests counter
+http_requests_total{code="200"} 1027 1500000000
+# TYPE queue gauge
+queue 1.5 1500000000
+# TYPE queue_max gauge
+queue_max 10 1500000000
+# EOF
+`, string(buf))
+}
+
+func TestSerializeHistogram(t *testing.T) {
+	m := newMetric(t, "latency",
+		map[string]string{"path": "/"},
+		map[string]interface{}{
+			"0.5":   int64(2),
+			"0.1":   int64(1),
+			"1":     int64(4),
+			"sum":   2.5,
+			"count": int64(5),
+		},
+		telegraf.Histogram)
+
+	s := &OpenMetricsSerializer{}
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+	assert.Equal(t, `# TYPE latency histogram
+latency_bucket{path="/",le="0.1"} 1 1500000000
+latency_bucket{path="/",le="0.5"} 2 1500000000
+latency_bucket{path="/",le="1"} 4 1500000000
+latency_bucket{path="/",le="+Inf"} 5 1500000000
+latency_sum{path="/"} 2.5 1500000000
+latency_count{path="/"} 5 1500000000
+# EOF
+`, string(buf))
+}
+
+func TestSerializeSummary(t *testing.T) {
+	m := newMetric(t, "rpc_duration",
+		map[string]string{},
+		map[string]interface{}{
+			"0.99":  0.3,
+			"0.5":   0.1,
+			"sum":   12.5,
+			"count": int64(100),
+		},
+		telegraf.Summary)
+
+	s := &OpenMetricsSerializer{}
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+	assert.Equal(t, `# TYPE rpc_duration summary
+rpc_duration{quantile="0.5"} 0.1 1500000000
+rpc_duration{quantile="0.99"} 0.3 1500000000
+rpc_duration_sum 12.5 1500000000
+rpc_duration_count 100 1500000000
+# EOF
+`, string(buf))
+}
+
+func TestSerializeBatchGroupsFamilies(t *testing.T) {
+	m1 := newMetric(t, "cpu", map[string]string{"host": "a"},
+		map[string]interface{}{"value": 1.0}, telegraf.Gauge)
+	m2 := newMetric(t, "mem", map[string]string{"host": "a"},
+		map[string]interface{}{"value": 2.0}, telegraf.Gauge)
+	m3 := newMetric(t, "cpu", map[string]string{"host": "b\"\\c"},
+		map[string]interface{}{"value": 3.0}, telegraf.Gauge)
+
+	s := &OpenMetricsSerializer{}
+	buf, err := s.SerializeBatch([]telegraf.Metric{m1, m2, m3})
+	require.NoError(t, err)
+	assert.Equal(t, `# TYPE cpu gauge
+cpu{host="a"} 1 1500000000
+cpu{host="b\"\\c"} 3 1500000000
+# TYPE mem gauge
+mem{host="a"} 2 1500000000
+# EOF
+`, string(buf))
+}
+
+func TestFormatTimestamp(t *testing.T) {
+	assert.Equal(t, "1500000000", formatTimestamp(1500000000000000000))
+	assert.Equal(t, "1500000000.123", formatTimestamp(1500000000123000000))
+	assert.Equal(t, "-1.5", formatTimestamp(-1500000000))
+}
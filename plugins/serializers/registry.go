@@ -6,9 +6,11 @@ import (
 
 	"github.com/influxdata/telegraf"
 
+	"github.com/influxdata/telegraf/plugins/serializers/carbon2"
 	"github.com/influxdata/telegraf/plugins/serializers/graphite"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
 	"github.com/influxdata/telegraf/plugins/serializers/json"
+	"github.com/influxdata/telegraf/plugins/serializers/openmetrics"
 )
 
 // SerializerOutput is an interface for output plugins that are able to
@@ -27,10 +29,38 @@ type Serializer interface {
 	Serialize(metric telegraf.Metric) ([]byte, error)
 }
 
+// BatchSerializer is an interface implemented by serializers that can
+// serialize a batch of metrics into a single buffer, ie a single document.
+type BatchSerializer interface {
+	// SerializeBatch takes a batch of telegraf metrics and turns them into
+	// a byte buffer, that can be sent or written as one payload.
+	SerializeBatch(metrics []telegraf.Metric) ([]byte, error)
+}
+
+// SerializeBatch serializes the metrics with the SerializeBatch function of
+// the serializer if it is a BatchSerializer, or otherwise concatenates the
+// serialized metrics.
+func SerializeBatch(serializer Serializer, metrics []telegraf.Metric) ([]byte, error) {
+	if s, ok := serializer.(BatchSerializer); ok {
+		return s.SerializeBatch(metrics)
+	}
+
+	var buf []byte
+	for _, metric := range metrics {
+		b, err := serializer.Serialize(metric)
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, b...)
+	}
+	return buf, nil
+}
+
 // Config is a struct that covers the data types needed for all serializer types,
 // and can be used to instantiate _any_ of the serializers.
 type Config struct {
-	// Dataformat can be one of: influx, graphite, or json
+	// Dataformat can be one of: influx, graphite, json, carbon2 or
+	// openmetrics
 	DataFormat string
 
 	// Prefix to add to all measurements, only supports Graphite
@@ -42,6 +72,9 @@ type Config struct {
 
 	// Timestamp units to use for JSON formatted output
 	TimestampUnits time.Duration
+
+	// Batch format of JSON formatted output, one of: lines, array, object
+	JSONBatchFormat string
 }
 
 // NewSerializer a Serializer interface based on the given config.
@@ -54,15 +87,28 @@ func NewSerializer(config *Config) (Serializer, error) {
 	case "graphite":
 		serializer, err = NewGraphiteSerializer(config.Prefix, config.Template)
 	case "json":
-		serializer, err = NewJsonSerializer(config.TimestampUnits)
+		serializer, err = NewJsonSerializer(config.TimestampUnits,
+			config.JSONBatchFormat)
+	case "carbon2":
+		serializer, err = NewCarbon2Serializer()
+	case "openmetrics":
+		serializer, err = NewOpenMetricsSerializer()
 	default:
 		err = fmt.Errorf("Invalid data format: %s", config.DataFormat)
 	}
 	return serializer, err
 }
 
-func NewJsonSerializer(timestampUnits time.Duration) (Serializer, error) {
-	return &json.JsonSerializer{TimestampUnits: timestampUnits}, nil
+func NewJsonSerializer(timestampUnits time.Duration, batchFormat string) (Serializer, error) {
+	switch batchFormat {
+	case "", "lines", "array", "object":
+	default:
+		return nil, fmt.Errorf("Invalid JSON batch format: %s", batchFormat)
+	}
+	return &json.JsonSerializer{
+		TimestampUnits: timestampUnits,
+		BatchFormat:    batchFormat,
+	}, nil
 }
 
 func NewInfluxSerializer() (Serializer, error) {
@@ -75,3 +121,11 @@ func NewGraphiteSerializer(prefix, template string) (Serializer, error) {
 		Template: template,
 	}, nil
 }
+
+func NewCarbon2Serializer() (Serializer, error) {
+	return &carbon2.Carbon2Serializer{}, nil
+}
+
+func NewOpenMetricsSerializer() (Serializer, error) {
+	return &openmetrics.OpenMetricsSerializer{}, nil
+}
@@ -10,3 +10,13 @@ type Processor interface {
 	// Apply the filter to the given metric
 	Apply(in ...Metric) []Metric
 }
+
+// StatelessProcessor is a Processor that keeps no state between the metrics
+// it is applied to. Its Apply is safe for concurrent use, so that the agent
+// can apply it to several metrics at once.
+type StatelessProcessor interface {
+	Processor
+
+	// Stateless returns true if Apply can be called concurrently.
+	Stateless() bool
+}
@@ -0,0 +1,12 @@
+package telegraf
+
+type SecretStore interface {
+	// SampleConfig returns the default configuration of the SecretStore
+	SampleConfig() string
+
+	// Description returns a one-sentence description on the SecretStore
+	Description() string
+
+	// Get returns the secret stored under the given key
+	Get(key string) (string, error)
+}
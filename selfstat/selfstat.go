@@ -87,6 +87,12 @@ func RegisterTiming(measurement, field string, tags map[string]string) Stat {
 	})
 }
 
+// Unregister removes the given stat from the selfstat registry, so that it is
+// not returned by Metrics() anymore.
+func Unregister(s Stat) {
+	registry.unregister(s)
+}
+
 // Metrics returns all registered stats as telegraf metrics.
 func Metrics() []telegraf.Metric {
 	registry.mu.Lock()
@@ -143,6 +149,17 @@ func (r *rgstry) register(s Stat) Stat {
 	}
 }
 
+func (r *rgstry) unregister(s Stat) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if stats, ok := r.stats[s.Key()]; ok {
+		delete(stats, s.FieldName())
+		if len(stats) == 0 {
+			delete(r.stats, s.Key())
+		}
+	}
+}
+
 func key(measurement string, tags map[string]string) uint64 {
 	h := fnv.New64a()
 	h.Write([]byte(measurement))
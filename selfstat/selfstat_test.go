@@ -219,3 +219,24 @@ func TestRegisterMetricsAndVerify(t *testing.T) {
 		},
 	)
 }
+
+func TestUnregister(t *testing.T) {
+	testLock.Lock()
+	defer testCleanup()
+
+	s1 := Register("test", "test_field1", map[string]string{"test": "foo"})
+	s2 := Register("test", "test_field2", map[string]string{"test": "foo"})
+	s3 := Register("test", "test_field1", map[string]string{"test": "bar"})
+	assert.Len(t, Metrics(), 2)
+
+	Unregister(s1)
+	assert.Len(t, Metrics(), 2)
+	Unregister(s3)
+	assert.Len(t, Metrics(), 1)
+	Unregister(s2)
+	assert.Len(t, Metrics(), 0)
+
+	// the stat is registered again as a new one
+	s1 = Register("test", "test_field1", map[string]string{"test": "foo"})
+	assert.Equal(t, int64(0), s1.Get())
+}
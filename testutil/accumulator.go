@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -36,6 +37,8 @@ type Accumulator struct {
 	Discard  bool
 	Errors   []error
 	debug    bool
+
+	delivered chan telegraf.DeliveryInfo
 }
 
 func (a *Accumulator) NMetrics() uint64 {
@@ -153,6 +156,39 @@ func (a *Accumulator) AddError(err error) {
 	a.Unlock()
 }
 
+// WithTracking returns the Accumulator, which delivers the tracked metrics as
+// soon as they are added.
+func (a *Accumulator) WithTracking(maxTracked int) telegraf.TrackingAccumulator {
+	a.Lock()
+	defer a.Unlock()
+	a.delivered = make(chan telegraf.DeliveryInfo, maxTracked)
+	return a
+}
+
+// AddTrackingMetricGroup adds the metrics, and sends their delivery on
+// Delivered if WithTracking was called.
+func (a *Accumulator) AddTrackingMetricGroup(group []telegraf.Metric) telegraf.TrackingID {
+	tracked, id := metric.WithGroupTracking(group, func(info telegraf.DeliveryInfo) {
+		a.Lock()
+		delivered := a.delivered
+		a.Unlock()
+		if delivered != nil {
+			delivered <- info
+		}
+	})
+	for _, m := range tracked {
+		a.AddFields(m.Name(), m.Fields(), m.Tags(), m.Time())
+		m.Accept()
+	}
+	return id
+}
+
+func (a *Accumulator) Delivered() <-chan telegraf.DeliveryInfo {
+	a.Lock()
+	defer a.Unlock()
+	return a.delivered
+}
+
 func (a *Accumulator) SetPrecision(precision, interval time.Duration) {
 	return
 }
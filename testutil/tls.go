@@ -0,0 +1,60 @@
+package testutil
+
+import (
+	"path"
+
+	"github.com/influxdata/telegraf/internal/tls"
+)
+
+// PKI gives access to the certificates in testutil/pki, which are signed by
+// the same CA: a server certificate for localhost, and a client certificate
+// with the common name "telegraf-client".
+type PKI struct {
+	path string
+}
+
+// NewPKI returns the PKI found at path, usually a relative path to
+// testutil/pki from the test's package.
+func NewPKI(path string) *PKI {
+	return &PKI{path: path}
+}
+
+// TLSClientConfig returns a client config trusting the CA, and presenting
+// the client certificate.
+func (p *PKI) TLSClientConfig() *tls.ClientConfig {
+	return &tls.ClientConfig{
+		TLSCA:   p.CACertPath(),
+		TLSCert: p.ClientCertPath(),
+		TLSKey:  p.ClientKeyPath(),
+	}
+}
+
+// TLSServerConfig returns a server config presenting the server certificate,
+// and requiring client certificates signed by the CA.
+func (p *PKI) TLSServerConfig() *tls.ServerConfig {
+	return &tls.ServerConfig{
+		TLSCert:           p.ServerCertPath(),
+		TLSKey:            p.ServerKeyPath(),
+		TLSAllowedCACerts: []string{p.CACertPath()},
+	}
+}
+
+func (p *PKI) CACertPath() string {
+	return path.Join(p.path, "cacert.pem")
+}
+
+func (p *PKI) ServerCertPath() string {
+	return path.Join(p.path, "servercert.pem")
+}
+
+func (p *PKI) ServerKeyPath() string {
+	return path.Join(p.path, "serverkey.pem")
+}
+
+func (p *PKI) ClientCertPath() string {
+	return path.Join(p.path, "clientcert.pem")
+}
+
+func (p *PKI) ClientKeyPath() string {
+	return path.Join(p.path, "clientkey.pem")
+}